@@ -22,19 +22,40 @@ const (
 func main() {
 	const (
 		synopsis = "File system service utility."
+		usage    = "Must be called with a subcommand.\n\n" +
+			"The `-v` flag may precede the subcommand," +
+			" to print every error a command returns" +
+			" rather than only the first."
 	)
 	var (
-		name        = commandName()
-		arguments   = os.Args[1:]
-		subcommands = makeSubcommands()
-		ctx         = context.Background()
-		err         = command.SubcommandGroup(
+		name               = commandName()
+		arguments, verbose = parseVerbosity(os.Args[1:])
+		subcommands        = makeSubcommands()
+		ctx                = context.Background()
+		err                = command.SubcommandGroup(
 			name, synopsis,
 			subcommands,
+			command.WithUsage(usage),
 		).Execute(ctx, arguments...)
 	)
 	if err != nil {
-		exitWithErr(err)
+		exitWithErr(err, verbose)
+	}
+}
+
+// parseVerbosity removes the verbose flag from
+// the front of arguments (if present).
+// The flag must precede any subcommand names,
+// so that it cannot conflict with subcommand flags.
+func parseVerbosity(arguments []string) ([]string, bool) {
+	if len(arguments) == 0 {
+		return arguments, false
+	}
+	switch arguments[0] {
+	case "-v", "--v", "-verbose", "--verbose":
+		return arguments[1:], true
+	default:
+		return arguments, false
 	}
 }
 
@@ -53,12 +74,24 @@ func makeSubcommands() []command.Command {
 	return []command.Command{
 		commands.Daemon(),
 		commands.Shutdown(),
+		commands.Status(),
 		commands.Mount(),
 		commands.Unmount(),
+		commands.Mounts(),
+		commands.Count(),
+		commands.Verify(),
+		commands.Warm(),
+		commands.Key(),
+		commands.Selftest(),
+		commands.Tail(),
+		commands.Cat(),
+		commands.Get(),
+		commands.Bench(),
+		commands.Debug(),
 	}
 }
 
-func exitWithErr(err error) {
+func exitWithErr(err error, verbose bool) {
 	if errors.Is(err, flag.ErrHelp) {
 		// We must exit with the correct code,
 		// but don't need to print this error itself.
@@ -77,10 +110,61 @@ func exitWithErr(err error) {
 		// Operation failure.
 		code = failure
 	}
-	errStr := err.Error()
+	errStr := formatErr(err, verbose)
 	if !strings.HasSuffix(errStr, "\n") {
 		errStr += "\n"
 	}
 	os.Stderr.WriteString(errStr)
 	os.Exit(code)
 }
+
+// formatErr returns the message of `err`.
+// Unless `verbose` is set, errors which wrap
+// multiple errors are reduced to the message
+// of the first (primary) error.
+func formatErr(err error, verbose bool) string {
+	if verbose {
+		return err.Error()
+	}
+	if !isWrapped(err) {
+		return formatWrapped(err)
+	}
+	var (
+		errs    = err.(interface{ Unwrap() []error }).Unwrap()
+		primary = errs[0]
+	)
+	if isWrapped(primary) {
+		return formatErr(primary, verbose)
+	}
+	return primary.Error() +
+		"\n(use -v for more details)"
+}
+
+// formatWrapped formats the errors wrapped by `err`
+// (if any), and retains the message that `err`
+// prefixed to them (if it did).
+func formatWrapped(err error) string {
+	message := err.Error()
+	inner := errors.Unwrap(err)
+	if inner == nil {
+		return message
+	}
+	var (
+		innerMessage = inner.Error()
+		formatted    = formatErr(inner, false)
+	)
+	if formatted == innerMessage {
+		return message
+	}
+	if prefix, ok := strings.CutSuffix(message, innerMessage); ok {
+		return prefix + formatted
+	}
+	return formatted
+}
+
+// isWrapped reports whether `err`
+// wraps multiple (non-nil) errors.
+func isWrapped(err error) bool {
+	joined, ok := err.(interface{ Unwrap() []error })
+	return ok && len(joined.Unwrap()) > 1
+}
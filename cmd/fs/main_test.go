@@ -2,8 +2,10 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 )
 
@@ -36,6 +38,16 @@ func TestMainExit(t *testing.T) {
 			[]string{"-help"},
 			misuse,
 		},
+		{
+			"verbose no args",
+			[]string{"-v"},
+			misuse,
+		},
+		{
+			"verbose help flag",
+			[]string{"-v", "-help"},
+			misuse,
+		},
 	} {
 		var (
 			name = test.name
@@ -66,3 +78,48 @@ func TestMainExit(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatErr(t *testing.T) {
+	t.Parallel()
+	var (
+		primary   = errors.New("primary")
+		secondary = errors.New("secondary")
+		joined    = errors.Join(primary, secondary)
+		nested    = errors.Join(joined, errors.New("tertiary"))
+		wrapped   = fmt.Errorf("wrapped: %w", primary)
+		wrapJoin  = fmt.Errorf("wrapped: %w", nested)
+	)
+	for _, test := range []struct {
+		err     error
+		name    string
+		want    string
+		verbose bool
+	}{
+		{name: "single", err: primary, want: primary.Error()},
+		{name: "wrapped", err: wrapped, want: wrapped.Error()},
+		{name: "joined", err: joined, want: primary.Error()},
+		{name: "nested", err: nested, want: primary.Error()},
+		{name: "wrapped joined", err: wrapJoin, want: "wrapped: " + primary.Error()},
+		{name: "verbose", err: nested, want: nested.Error(), verbose: true},
+	} {
+		var (
+			err     = test.err
+			want    = test.want
+			verbose = test.verbose
+		)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got := formatErr(err, verbose)
+			if line, _, _ := strings.Cut(got, "\n"); !verbose {
+				got = line
+			}
+			if got != want {
+				t.Errorf("error message mismatch"+
+					"\ngot: %q"+
+					"\nwant: %q",
+					got, want,
+				)
+			}
+		})
+	}
+}
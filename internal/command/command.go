@@ -64,6 +64,7 @@ type (
 		name, synopsis, usage string
 		usageOutput           io.Writer
 		subcommands           []Command
+		markdownPath          string
 		glamour               bool
 	}
 
@@ -81,13 +82,71 @@ func (ue UsageError) Error() string { return ue.Err.Error() }
 // Unwrap implements the [errors.Unwrap] interface.
 func (ue UsageError) Unwrap() error { return ue.Err }
 
-func unexpectedArguments(name string, args []string) UsageError {
-	return UsageError{
-		Err: fmt.Errorf(
-			"`%s` does not take arguments but was provided: %s",
-			name, strings.Join(args, ","),
-		),
+func unexpectedArguments(command Command, args []string) UsageError {
+	err := fmt.Errorf(
+		"`%s` does not take arguments but was provided: %s",
+		command.Name(), strings.Join(args, ","),
+	)
+	if suggestion, ok := suggestSubcommand(command, args[0]); ok {
+		err = fmt.Errorf("%w - did you mean `%s`?", err, suggestion)
+	}
+	return UsageError{Err: err}
+}
+
+// suggestSubcommand returns the name of the subcommand
+// which is closest to `name` (if any are close enough).
+func suggestSubcommand(command Command, name string) (string, bool) {
+	const maximumDistance = 2
+	var (
+		suggestion string
+		closest    = maximumDistance + 1
+		lowerName  = strings.ToLower(name)
+	)
+	for _, subcommand := range command.Subcommands() {
+		var (
+			subname  = subcommand.Name()
+			distance = levenshtein(lowerName, strings.ToLower(subname))
+		)
+		// NOTE: Names which would have to be
+		// entirely replaced are not similar.
+		if distance < closest &&
+			distance < len([]rune(subname)) {
+			suggestion, closest = subname, distance
+		}
+	}
+	return suggestion, suggestion != ""
+}
+
+// levenshtein returns the number of single character
+// edits required to change `a` into `b`.
+func levenshtein(a, b string) int {
+	var (
+		source   = []rune(a)
+		target   = []rune(b)
+		previous = make([]int, len(target)+1)
+		current  = make([]int, len(target)+1)
+	)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i, sr := range source {
+		current[0] = i + 1
+		for j, tr := range target {
+			substitution := previous[j]
+			if sr != tr {
+				substitution++
+			}
+			current[j+1] = generic.Min(
+				generic.Min(
+					previous[j+1]+1, // Deletion.
+					current[j]+1,    // Insertion.
+				),
+				substitution,
+			)
+		}
+		previous, current = current, previous
 	}
+	return previous[len(target)]
 }
 
 // WithSubcommands provides a command with subcommands.
@@ -108,6 +167,14 @@ func WithUsageOutput(output io.Writer) Option {
 	}
 }
 
+// WithUsage replaces the usage text
+// that was provided to the constructor.
+func WithUsage(usage string) Option {
+	return func(settings *commandCommon) {
+		settings.usage = usage
+	}
+}
+
 // SubcommandGroup returns a command that only defers to subcommands.
 // Trying to execute the command itself will return [UsageError].
 func SubcommandGroup(name, synopsis string, subcommands []Command, options ...Option) Command {
@@ -137,6 +204,7 @@ func (cmd *commandCommon) parseFlags(flagSet *flag.FlagSet, arguments ...string)
 	var needHelp bool
 	bindHelpFlag(&needHelp, flagSet)
 	bindRenderFlag(&cmd.glamour, flagSet)
+	bindMarkdownFlag(&cmd.markdownPath, flagSet)
 	// Package [flag] has implicit handling for `-help` and `-h` flags.
 	// If they're not explicitly defined, but provided as arguments,
 	// [flag] will call `Usage` before returning from `Parse`.
@@ -232,7 +300,7 @@ func (cmd *commandCommon) printUsage(acceptsArgs bool, flagSet *flag.FlagSet) er
 		hasSubs     = len(subcommands) > 0
 		hasFlags    bool
 	)
-	flagSet.VisitAll(func(*flag.Flag) { hasFlags = true })
+	visitFlags(flagSet, func(*flag.Flag) { hasFlags = true })
 	printUsage(writeFn, usage, renderer)
 	printCommandLine(writeFn, name, hasSubs, hasFlags, acceptsArgs, renderer)
 	if hasFlags {
@@ -343,7 +411,7 @@ func printFlags(
 		flagText = render("Flags:")
 	}
 	writeFn(flagText + "\n")
-	flagSet.VisitAll(func(flg *flag.Flag) {
+	visitFlags(flagSet, func(flg *flag.Flag) {
 		const singleCharName = 2
 		var (
 			flagName  = "-" + flg.Name
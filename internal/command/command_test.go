@@ -1,12 +1,16 @@
 package command_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/djdv/go-filesystem-utils/internal/command"
@@ -19,6 +23,7 @@ func TestCommand(t *testing.T) {
 	t.Run("variadic", cmdVariadic)
 	t.Run("subcommands", cmdSubcommands)
 	t.Run("renderer", rendererTest)
+	t.Run("markdown", markdownTest)
 }
 
 func testHelpText(t *testing.T, cmd command.Command) {
@@ -481,6 +486,7 @@ func cmdSubcommands(t *testing.T) {
 	t.Run("help text", subcommandCmd)
 	t.Run("valid", subcommandValid)
 	t.Run("invalid", subcommandInvalid)
+	t.Run("suggestions", subcommandSuggestions)
 }
 
 func newTestSubcommands(t *testing.T) command.Command {
@@ -598,6 +604,77 @@ func subcommandInvalid(t *testing.T) {
 	testErrorParameters(t, cmd)
 }
 
+func subcommandSuggestions(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx         = context.Background()
+		output      = io.Discard
+		noopFn      = func(context.Context) error { return nil }
+		makeCommand = func(name string) command.Command {
+			return command.MakeNiladicCommand(
+				name, name+" synopsis", name+" usage", noopFn,
+				command.WithUsageOutput(output),
+			)
+		}
+		cmd = command.SubcommandGroup(
+			"fs", "Top level group",
+			[]command.Command{
+				command.SubcommandGroup(
+					"mount", "Mount group",
+					[]command.Command{
+						makeCommand("fuse"),
+						makeCommand("nfs"),
+					},
+					command.WithUsageOutput(output),
+				),
+				makeCommand("unmount"),
+				makeCommand("shutdown"),
+			},
+			command.WithUsageOutput(output),
+		)
+	)
+	const suggestionFmt = "did you mean `%s`?"
+	for _, test := range []struct {
+		arguments  []string
+		suggestion string
+	}{
+		{[]string{"moutn"}, "mount"},
+		{[]string{"unmonut"}, "unmount"},
+		{[]string{"Shutdown"}, "shutdown"},
+		{[]string{"mount", "fsue"}, "fuse"},
+		{[]string{"daemon"}, ""},
+		{[]string{"xyz"}, ""},
+		{[]string{"mount", "9p"}, ""},
+	} {
+		err := cmd.Execute(ctx, test.arguments...)
+		if !errors.As(err, new(command.UsageError)) {
+			t.Errorf("expected `UsageError` for %v, got: %v",
+				test.arguments, err,
+			)
+			continue
+		}
+		var (
+			message       = err.Error()
+			wantSuggested = test.suggestion != ""
+		)
+		if !wantSuggested {
+			if strings.Contains(message, "did you mean") {
+				t.Errorf("unexpected suggestion for %v: %s",
+					test.arguments, message,
+				)
+			}
+			continue
+		}
+		if want := fmt.Sprintf(suggestionFmt, test.suggestion); !strings.Contains(message, want) {
+			t.Errorf("suggestion mismatch for %v"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				test.arguments, message, want,
+			)
+		}
+	}
+}
+
 func rendererTest(t *testing.T) {
 	const (
 		glamourStyleKey = `GLAMOUR_STYLE`
@@ -633,3 +710,67 @@ func rendererTest(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func markdownTest(t *testing.T) {
+	t.Parallel()
+	const markdownFlag = "-usage-markdown"
+	var (
+		ctx      = context.Background()
+		groupCmd = newTestSubcommands(t)
+		output   bytes.Buffer
+		cmd      = command.MakeVariadicCommand[options](
+			"root", "root synopsis", "root usage",
+			func(context.Context, ...option) error { return nil },
+			command.WithUsageOutput(&output),
+			command.WithSubcommands(groupCmd),
+		)
+		directory = t.TempDir()
+		generate  = func(name string) string {
+			t.Helper()
+			path := filepath.Join(directory, name)
+			if err := cmd.Execute(ctx, markdownFlag, path); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(data)
+		}
+		first  = generate("first.md")
+		second = generate("second.md")
+	)
+	if first != second {
+		t.Errorf("markdown output is not deterministic"+
+			"\nfirst: %s"+
+			"\nsecond: %s",
+			first, second,
+		)
+	}
+	for _, want := range []string{
+		"root usage", "root top A 1", "1 usage",
+		"Subcommands:", "-help",
+	} {
+		if !strings.Contains(first, want) {
+			t.Errorf("markdown output does not contain \"%s\""+
+				"\ngot: %s",
+				want, first,
+			)
+		}
+	}
+	if err := cmd.Execute(ctx, "-help"); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, flag.ErrHelp,
+		)
+	}
+	for _, text := range []string{first, output.String()} {
+		if strings.Contains(text, markdownFlag) {
+			t.Errorf("hidden flag \"%s\" was printed"+
+				"\ngot: %s",
+				markdownFlag, text,
+			)
+		}
+	}
+}
@@ -85,6 +85,9 @@ func (fc *fixedCommand[ET, T, EC]) Execute(ctx context.Context, args ...string)
 	if err != nil {
 		return err
 	}
+	if path := fc.markdownPath; path != "" {
+		return writeMarkdownFile(path, fc)
+	}
 	if needHelp {
 		err = flag.ErrHelp
 	} else {
@@ -106,7 +109,7 @@ func (fc *fixedCommand[ET, T, EC]) execute(ctx context.Context, flagSet *flag.Fl
 	switch execFn := any(fc.executeFn).(type) {
 	case func(context.Context, ET) error:
 		if haveArgs {
-			execErr = unexpectedArguments(fc.name, arguments)
+			execErr = unexpectedArguments(fc, arguments)
 			break
 		}
 		execErr = execFn(ctx, settings)
@@ -115,3 +118,13 @@ func (fc *fixedCommand[ET, T, EC]) execute(ctx context.Context, flagSet *flag.Fl
 	}
 	return execErr
 }
+
+func (fc *fixedCommand[ET, T, EC]) usageFlags() (*flag.FlagSet, bool) {
+	var (
+		flagSet  = newFlagSet(fc.name)
+		settings T
+	)
+	ET(&settings).BindFlags(flagSet)
+	bindUsageFlags(flagSet)
+	return flagSet, fc.acceptsArgs()
+}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+)
+
+// usageFlagger is implemented by commands
+// which can construct their flag set
+// without being executed.
+type usageFlagger interface {
+	usageFlags() (flagSet *flag.FlagSet, acceptsArgs bool)
+}
+
+const markdownFlagName = "usage-markdown"
+
+// bindMarkdownFlag binds a hidden flag, intended
+// for maintainers (generating reference documentation).
+// It is omitted from help text.
+func bindMarkdownFlag(value *string, flagSet *flag.FlagSet) {
+	const markdownUsage = "write usage text for this command" +
+		" and its subcommands, as Markdown, to `file`"
+	flagSet.StringVar(value, markdownFlagName, "", markdownUsage)
+}
+
+// bindUsageFlags binds the flags shared by all commands,
+// to values which are discarded.
+func bindUsageFlags(flagSet *flag.FlagSet) {
+	bindHelpFlag(new(bool), flagSet)
+	bindRenderFlag(new(bool), flagSet)
+	bindMarkdownFlag(new(string), flagSet)
+}
+
+// visitFlags calls `fn` for each flag
+// that should be presented to the user.
+func visitFlags(flagSet *flag.FlagSet, fn func(*flag.Flag)) {
+	flagSet.VisitAll(func(flg *flag.Flag) {
+		if flg.Name == markdownFlagName {
+			return
+		}
+		fn(flg)
+	})
+}
+
+func writeMarkdownFile(name string, cmd Command) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	return errors.Join(
+		writeMarkdown(file, cmd),
+		file.Close(),
+	)
+}
+
+// writeMarkdown writes the (unstyled) usage text
+// of `cmd` and its subcommands (recursively),
+// in the order they were defined.
+func writeMarkdown(output io.Writer, cmd Command) error {
+	var (
+		wErr    error
+		writeFn = func(text string) {
+			if wErr != nil {
+				return
+			}
+			_, wErr = io.WriteString(output, text)
+		}
+	)
+	writeMarkdownCommand(writeFn, cmd, cmd.Name())
+	return wErr
+}
+
+func writeMarkdownCommand(writeFn writeStringFunc, cmd Command, name string) {
+	const (
+		fence     = "```\n"
+		separator = "\n---\n\n"
+	)
+	var (
+		subcommands = cmd.Subcommands()
+		hasSubs     = len(subcommands) > 0
+		flagSet     *flag.FlagSet
+		acceptsArgs bool
+		hasFlags    bool
+	)
+	if flagger, ok := cmd.(usageFlagger); ok {
+		flagSet, acceptsArgs = flagger.usageFlags()
+		visitFlags(flagSet, func(*flag.Flag) { hasFlags = true })
+	}
+	printUsage(writeFn, cmd.Usage(), nil)
+	writeFn(fence)
+	printCommandLine(writeFn, name, hasSubs, hasFlags, acceptsArgs, nil)
+	if hasFlags {
+		printFlags(writeFn, flagSet, nil)
+	}
+	if hasSubs {
+		printSubcommands(writeFn, subcommands, nil)
+	}
+	writeFn(fence)
+	for _, subcommand := range subcommands {
+		writeFn(separator)
+		writeMarkdownCommand(writeFn, subcommand,
+			name+" "+subcommand.Name(),
+		)
+	}
+}
@@ -45,6 +45,9 @@ func (nc *niladicCommand) Execute(ctx context.Context, args ...string) error {
 	if err != nil {
 		return err
 	}
+	if path := nc.markdownPath; path != "" {
+		return writeMarkdownFile(path, nc)
+	}
 	if needHelp {
 		err = flag.ErrHelp
 	} else {
@@ -63,7 +66,14 @@ func (nc *niladicCommand) execute(ctx context.Context, flagSet *flag.FlagSet) er
 		haveArgs  = len(arguments) > 0
 	)
 	if haveArgs {
-		return unexpectedArguments(nc.name, arguments)
+		return unexpectedArguments(nc, arguments)
 	}
 	return nc.executeFn(ctx)
 }
+
+func (nc *niladicCommand) usageFlags() (*flag.FlagSet, bool) {
+	flagSet := newFlagSet(nc.name)
+	bindUsageFlags(flagSet)
+	const acceptsArgs = false
+	return flagSet, acceptsArgs
+}
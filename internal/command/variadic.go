@@ -91,6 +91,9 @@ func (vc *variadicCommand[TS, T, ET, EC]) Execute(ctx context.Context, args ...s
 	if err != nil {
 		return err
 	}
+	if path := vc.markdownPath; path != "" {
+		return writeMarkdownFile(path, vc)
+	}
 	if needHelp {
 		err = flag.ErrHelp
 	} else {
@@ -112,7 +115,7 @@ func (vc *variadicCommand[TS, T, ET, EC]) execute(ctx context.Context, flagSet *
 	switch execFn := any(vc.executeFn).(type) {
 	case func(context.Context, ...T) error:
 		if haveArgs {
-			execErr = unexpectedArguments(vc.name, arguments)
+			execErr = unexpectedArguments(vc, arguments)
 			break
 		}
 		execErr = execFn(ctx, options...)
@@ -121,3 +124,13 @@ func (vc *variadicCommand[TS, T, ET, EC]) execute(ctx context.Context, flagSet *
 	}
 	return execErr
 }
+
+func (vc *variadicCommand[TS, T, ET, EC]) usageFlags() (*flag.FlagSet, bool) {
+	var (
+		flagSet = newFlagSet(vc.name)
+		options TS
+	)
+	ET(&options).BindFlags(flagSet)
+	bindUsageFlags(flagSet)
+	return flagSet, vc.acceptsArgs()
+}
@@ -0,0 +1,350 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	benchSettings[M fsMaker] struct {
+		guest   M
+		readers int
+		rounds  int
+		json    bool
+	}
+	benchOption[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] func(*benchSettings[GM]) error
+	benchOptions[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] []benchOption[GT, GM, GC]
+	// benchReport is the format of
+	// the benchmark output.
+	benchReport struct {
+		Guest    filesystem.ID `json:"guest"`
+		Readers  int           `json:"readers"`
+		Rounds   int           `json:"rounds"`
+		Opens    int           `json:"opens"`
+		Bytes    int64         `json:"bytes"`
+		Duration time.Duration `json:"duration"`
+		// Throughput is measured in megabytes per second.
+		Throughput float64      `json:"throughput"`
+		Open       benchLatency `json:"open"`
+	}
+	// benchLatency summarizes the
+	// durations of an operation.
+	benchLatency struct {
+		P50 time.Duration `json:"p50"`
+		P90 time.Duration `json:"p90"`
+		P99 time.Duration `json:"p99"`
+		Max time.Duration `json:"max"`
+	}
+	// benchSample is the data
+	// recorded by a single reader.
+	benchSample struct {
+		opens []time.Duration
+		read  int64
+	}
+)
+
+const (
+	benchReadersDefault = 1
+	benchRoundsDefault  = 1
+	errBenchNotFile     = generic.ConstError("not a regular file")
+)
+
+// Bench constructs the command which
+// measures the read performance of guest file systems.
+func Bench() command.Command {
+	const (
+		name     = "bench"
+		synopsis = "Measure guest file system read throughput."
+	)
+	return makeGuestCommandGroup(name, synopsis, makeIPFSBenchCommands())
+}
+
+func makeBenchCommand[
+	GC fsCmdGuest[GT, GM],
+	GM fsMaker,
+	GT any,
+](guest filesystem.ID,
+) command.Command {
+	type (
+		BO  = benchOption[GT, GM, GC]
+		BOS = benchOptions[GT, GM, GC]
+	)
+	var (
+		guestFormalName = string(guest)
+		cmdName         = strings.ToLower(guestFormalName)
+		synopsis        = fmt.Sprintf(
+			"Measure %s read throughput.", guestFormalName,
+		)
+		usage = guestCommandUsage[GC](guest, synopsis,
+			"reads the provided files (relative to the guest's root)"+
+				"\nin their entirety; reporting the overall throughput,"+
+				" and the latency of opening files."+
+				"\nEach reader reads every file, once per round."+
+				"\nThis is a diagnostic aid; results depend on"+
+				" the guest's configuration and its backing API.",
+		)
+	)
+	return command.MakeVariadicCommand[BOS](cmdName, synopsis, usage,
+		func(ctx context.Context, arguments []string, options ...BO) error {
+			if err := checkGuestArguments(arguments, 1, -1, "at least 1 file path"); err != nil {
+				return err
+			}
+			settings, err := BOS(options).make()
+			if err != nil {
+				return err
+			}
+			names := make([]string, len(arguments))
+			for i, argument := range arguments {
+				names[i] = guestPath(argument)
+			}
+			var report benchReport
+			if err := withGuestFS(settings.guest, func(fsys fs.FS) error {
+				var err error
+				report, err = benchRead(ctx, fsys,
+					names, settings.readers, settings.rounds,
+				)
+				return err
+			}); err != nil {
+				return err
+			}
+			report.Guest = guest
+			if settings.json {
+				err = json.NewEncoder(os.Stdout).Encode(report)
+			} else {
+				err = printBenchReport(os.Stdout, &report)
+			}
+			if err != nil {
+				return err
+			}
+			return ctx.Err()
+		})
+}
+
+func (bo *benchOptions[GT, GM, GC]) BindFlags(flagSet *flag.FlagSet) {
+	type settings = benchSettings[GM]
+	bindGuestFlags[GC](flagSet, bo, func(guest GM, bs *settings) {
+		bs.guest = guest
+	})
+	const (
+		readersName  = "readers"
+		readersUsage = "`count` of concurrent readers"
+	)
+	flagSetFunc(flagSet, readersName, readersUsage, bo,
+		func(value int, bs *settings) error {
+			bs.readers = value
+			return nil
+		}, validatePositive)
+	flagSet.Lookup(readersName).
+		DefValue = strconv.Itoa(benchReadersDefault)
+	const (
+		roundsName  = "rounds"
+		roundsUsage = "`count` of times each reader reads each file"
+	)
+	flagSetFunc(flagSet, roundsName, roundsUsage, bo,
+		func(value int, bs *settings) error {
+			bs.rounds = value
+			return nil
+		}, validatePositive)
+	flagSet.Lookup(roundsName).
+		DefValue = strconv.Itoa(benchRoundsDefault)
+	const (
+		jsonName  = "json"
+		jsonUsage = "print the results as JSON"
+	)
+	flagSetFunc(flagSet, jsonName, jsonUsage, bo,
+		func(value bool, bs *settings) error {
+			bs.json = value
+			return nil
+		})
+}
+
+func (bo benchOptions[GT, GM, GC]) make() (benchSettings[GM], error) {
+	settings := benchSettings[GM]{
+		readers: benchReadersDefault,
+		rounds:  benchRoundsDefault,
+	}
+	return settings, generic.ApplyOptions(&settings, bo...)
+}
+
+// benchRead reads each of the named files,
+// `rounds` times, from `readers` goroutines
+// and reports the results.
+// If any read fails, all readers are stopped.
+func benchRead(ctx context.Context, fsys fs.FS, names []string, readers, rounds int) (benchReport, error) {
+	for _, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return benchReport{}, err
+		}
+		if !info.Mode().IsRegular() {
+			return benchReport{}, &fs.PathError{
+				Op:   "bench",
+				Path: name,
+				Err:  errBenchNotFile,
+			}
+		}
+	}
+	var (
+		benchCtx, cancel = context.WithCancel(ctx)
+		samples          = make([]benchSample, readers)
+		errs             = make([]error, readers)
+		wg               sync.WaitGroup
+	)
+	defer cancel()
+	wg.Add(readers)
+	start := time.Now()
+	for i := range samples {
+		go func(i int) {
+			defer wg.Done()
+			samples[i], errs[i] = benchReader(benchCtx, fsys, names, rounds)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	if ctx.Err() == nil {
+		for i, err := range errs {
+			if errors.Is(err, context.Canceled) {
+				errs[i] = nil // Canceled by another reader's error.
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return benchReport{}, err
+	}
+	return makeBenchReport(samples, readers, rounds, elapsed), nil
+}
+
+func benchReader(ctx context.Context, fsys fs.FS, names []string, rounds int) (benchSample, error) {
+	sample := benchSample{
+		opens: make([]time.Duration, 0, len(names)*rounds),
+	}
+	for round := 0; round < rounds; round++ {
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return sample, err
+			}
+			opened, read, err := benchFile(fsys, name)
+			if err != nil {
+				return sample, err
+			}
+			sample.opens = append(sample.opens, opened)
+			sample.read += read
+		}
+	}
+	return sample, nil
+}
+
+// benchFile reads the file to its end, returning
+// how long it took to open, and how much was read.
+func benchFile(fsys fs.FS, name string) (time.Duration, int64, error) {
+	start := time.Now()
+	file, err := fsys.Open(name)
+	opened := time.Since(start)
+	if err != nil {
+		return 0, 0, err
+	}
+	read, err := io.Copy(io.Discard, file)
+	return opened, read, errors.Join(err, file.Close())
+}
+
+func makeBenchReport(samples []benchSample, readers, rounds int, elapsed time.Duration) benchReport {
+	var (
+		opens []time.Duration
+		read  int64
+	)
+	for _, sample := range samples {
+		opens = append(opens, sample.opens...)
+		read += sample.read
+	}
+	report := benchReport{
+		Readers:  readers,
+		Rounds:   rounds,
+		Opens:    len(opens),
+		Bytes:    read,
+		Duration: elapsed,
+		Open:     makeBenchLatency(opens),
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		const megabyte = 1_000_000
+		report.Throughput = float64(read) / megabyte / seconds
+	}
+	return report
+}
+
+func makeBenchLatency(durations []time.Duration) benchLatency {
+	if len(durations) == 0 {
+		return benchLatency{}
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+	return benchLatency{
+		P50: percentile(durations, 50),
+		P90: percentile(durations, 90),
+		P99: percentile(durations, 99),
+		Max: durations[len(durations)-1],
+	}
+}
+
+// percentile returns the nearest-rank value
+// from the (sorted, non-empty) durations.
+func percentile(sorted []time.Duration, rank int) time.Duration {
+	index := (rank*len(sorted) + 99) / 100
+	return sorted[generic.Max(index-1, 0)]
+}
+
+func printBenchReport(output io.Writer, report *benchReport) error {
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	var (
+		tabWriter = tabwriter.NewWriter(
+			output, minWidth, tabWidth, padding, padChar, flags,
+		)
+		precision = time.Microsecond
+		open      = report.Open
+	)
+	if _, err := fmt.Fprintf(tabWriter,
+		"guest:\t%s\nreaders:\t%d\nrounds:\t%d\nopens:\t%d\n"+
+			"read:\t%d bytes\nduration:\t%s\nthroughput:\t%.2f MB/s\n"+
+			"\nopen latency:\np50:\t%s\np90:\t%s\np99:\t%s\nmax:\t%s\n",
+		report.Guest, report.Readers, report.Rounds, report.Opens,
+		report.Bytes, report.Duration.Round(precision), report.Throughput,
+		open.P50.Round(precision), open.P90.Round(precision),
+		open.P99.Round(precision), open.Max.Round(precision),
+	); err != nil {
+		return err
+	}
+	return tabWriter.Flush()
+}
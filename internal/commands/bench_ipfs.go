@@ -0,0 +1,17 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+)
+
+func makeIPFSBenchCommands() []command.Command {
+	return []command.Command{
+		makeBenchCommand[*ipfsOptions, ipfsSettings](ipfs.IPFSID),
+		makeBenchCommand[*pinFSOptions, pinFSSettings](ipfs.PinFSID),
+		makeBenchCommand[*ipnsOptions, ipnsSettings](ipfs.IPNSID),
+		makeBenchCommand[*keyFSOptions, keyFSSettings](ipfs.KeyFSID),
+	}
+}
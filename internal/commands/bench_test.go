@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// benchCountFS tracks how many
+// of its files are currently open.
+type benchCountFS struct {
+	fstest.MapFS
+	open *atomic.Int64
+}
+
+type benchCountFile struct {
+	fs.File
+	open *atomic.Int64
+}
+
+func (bc benchCountFS) Open(name string) (fs.File, error) {
+	file, err := bc.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	bc.open.Add(1)
+	return benchCountFile{File: file, open: bc.open}, nil
+}
+
+func (bf benchCountFile) Close() error {
+	bf.open.Add(-1)
+	return bf.File.Close()
+}
+
+func TestBenchRead(t *testing.T) {
+	t.Parallel()
+	fsys := benchCountFS{
+		MapFS: fstest.MapFS{
+			"small": {Data: []byte("small")},
+			"large": {Data: []byte(strings.Repeat("large", 1024))},
+			"dir":   {Mode: fs.ModeDir},
+		},
+		open: new(atomic.Int64),
+	}
+	t.Run("read", func(t *testing.T) {
+		t.Parallel()
+		const (
+			readers = 4
+			rounds  = 3
+		)
+		names := []string{"small", "large"}
+		report, err := benchRead(context.Background(), fsys, names, readers, rounds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		const fileSize = len("small") + len("large")*1024
+		if got, want := report.Bytes, int64(fileSize*readers*rounds); got != want {
+			t.Errorf("bytes read mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				got, want,
+			)
+		}
+		if got, want := report.Opens, len(names)*readers*rounds; got != want {
+			t.Errorf("open count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				got, want,
+			)
+		}
+		if open := report.Open; open.P50 > open.P90 ||
+			open.P90 > open.P99 || open.P99 > open.Max {
+			t.Errorf("latencies are not ordered: %+v", open)
+		}
+		if open := fsys.open.Load(); open != 0 {
+			t.Errorf("%d files were left open", open)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, test := range []struct {
+			name string
+			want error
+		}{
+			{name: "dir", want: errBenchNotFile},
+			{name: "missing", want: fs.ErrNotExist},
+		} {
+			_, err := benchRead(context.Background(), fsys,
+				[]string{"small", test.name}, 1, 1,
+			)
+			if !errors.Is(err, test.want) {
+				t.Errorf("error mismatch for \"%s\""+
+					"\ngot: %v"+
+					"\nwant: %v",
+					test.name, err, test.want,
+				)
+			}
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := benchRead(ctx, fsys, []string{"small"}, 2, 1)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, context.Canceled,
+			)
+		}
+	})
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+	durations := make([]time.Duration, 100)
+	for i := range durations {
+		durations[i] = time.Duration(i + 1)
+	}
+	latency := makeBenchLatency(durations)
+	want := benchLatency{P50: 50, P90: 90, P99: 99, Max: 100}
+	if latency != want {
+		t.Errorf("latency mismatch"+
+			"\ngot: %+v"+
+			"\nwant: %+v",
+			latency, want,
+		)
+	}
+	single := makeBenchLatency([]time.Duration{7})
+	if want := (benchLatency{P50: 7, P90: 7, P99: 7, Max: 7}); single != want {
+		t.Errorf("latency mismatch"+
+			"\ngot: %+v"+
+			"\nwant: %+v",
+			single, want,
+		)
+	}
+}
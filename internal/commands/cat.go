@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	catSettings[M fsMaker] struct {
+		guest  M
+		offset int64
+		// length is negative if the file
+		// should be read to its end.
+		length int64
+		json   bool
+	}
+	catOption[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] func(*catSettings[GM]) error
+	catOptions[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] []catOption[GT, GM, GC]
+	// catReport is the format of
+	// the cat output (in JSON mode).
+	catReport struct {
+		Path   string `json:"path"`
+		Size   int64  `json:"size"`
+		Offset int64  `json:"offset"`
+		Length int64  `json:"length"`
+		// Data is encoded as base64.
+		Data []byte `json:"data"`
+	}
+)
+
+const (
+	catLengthDefault  = -1
+	errNegativeLength = generic.ConstError("must not be negative")
+)
+
+// Cat constructs the command which
+// prints (a range of) guest files.
+func Cat() command.Command {
+	const (
+		name     = "cat"
+		synopsis = "Print guest files."
+	)
+	return makeGuestCommandGroup(name, synopsis, makeIPFSCatCommands())
+}
+
+func makeCatCommand[
+	GC fsCmdGuest[GT, GM],
+	GM fsMaker,
+	GT any,
+](guest filesystem.ID,
+) command.Command {
+	type (
+		CO  = catOption[GT, GM, GC]
+		COS = catOptions[GT, GM, GC]
+	)
+	var (
+		guestFormalName = string(guest)
+		cmdName         = strings.ToLower(guestFormalName)
+		synopsis        = fmt.Sprintf(
+			"Print a %s file.", guestFormalName,
+		)
+		usage = guestCommandUsage[GC](guest, synopsis,
+			"prints the file at the provided path"+
+				"\n(relative to the guest's root)."+
+				"\nA range of the file may be printed by providing"+
+				" an offset and length."+
+				"\nOffsets beyond the end of the file print nothing.",
+		)
+	)
+	return command.MakeVariadicCommand[COS](cmdName, synopsis, usage,
+		func(ctx context.Context, arguments []string, options ...CO) error {
+			if err := checkGuestArguments(arguments, 1, 1, "1 path"); err != nil {
+				return err
+			}
+			settings, err := COS(options).make()
+			if err != nil {
+				return err
+			}
+			name := guestPath(arguments[0])
+			if err := withGuestFS(settings.guest, func(fsys fs.FS) error {
+				if settings.json {
+					return catJSON(os.Stdout, fsys, name,
+						settings.offset, settings.length,
+					)
+				}
+				_, _, err := catRange(os.Stdout, fsys, name,
+					settings.offset, settings.length,
+				)
+				return err
+			}); err != nil {
+				return err
+			}
+			return ctx.Err()
+		})
+}
+
+func (co *catOptions[GT, GM, GC]) BindFlags(flagSet *flag.FlagSet) {
+	type settings = catSettings[GM]
+	bindGuestFlags[GC](flagSet, co, func(guest GM, cs *settings) {
+		cs.guest = guest
+	})
+	const (
+		offsetName  = "offset"
+		offsetUsage = "`bytes` to skip before printing"
+	)
+	flagSetFunc(flagSet, offsetName, offsetUsage, co,
+		func(value int64, cs *settings) error {
+			if value < 0 {
+				return errNegativeLength
+			}
+			cs.offset = value
+			return nil
+		})
+	flagSet.Lookup(offsetName).
+		DefValue = "0"
+	const (
+		lengthName  = "length"
+		lengthUsage = "maximum number of `bytes` to print"
+	)
+	flagSetFunc(flagSet, lengthName, lengthUsage, co,
+		func(value int64, cs *settings) error {
+			if value < 0 {
+				return errNegativeLength
+			}
+			cs.length = value
+			return nil
+		})
+	flagSet.Lookup(lengthName).
+		DefValue = "to the end of the file"
+	const (
+		jsonName  = "json"
+		jsonUsage = "print the file's metadata and data (as base64) as JSON"
+	)
+	flagSetFunc(flagSet, jsonName, jsonUsage, co,
+		func(value bool, cs *settings) error {
+			cs.json = value
+			return nil
+		})
+}
+
+func (co catOptions[GT, GM, GC]) make() (catSettings[GM], error) {
+	settings := catSettings[GM]{
+		length: catLengthDefault,
+	}
+	return settings, generic.ApplyOptions(&settings, co...)
+}
+
+// catRange writes up to `length` bytes of the file
+// (starting from `offset`) to the output.
+// If `length` is negative, the file is read to its end.
+// The size of the file and the amount written are returned.
+func catRange(output io.Writer, fsys fs.FS, name string, offset, length int64) (size, written int64, err error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { err = errors.Join(err, file.Close()) }()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	if info.IsDir() {
+		return 0, 0, fmt.Errorf(`"%s" is a directory`, name)
+	}
+	size = info.Size()
+	if offset >= size {
+		return size, 0, nil
+	}
+	reader, err := rangeReader(file, offset, length)
+	if err != nil {
+		return size, 0, err
+	}
+	written, err = io.Copy(output, reader)
+	return size, written, err
+}
+
+// rangeReader returns a reader limited to the range.
+// If the file implements [io.ReaderAt] it's used,
+// otherwise the file is seeked (or read) to the offset.
+func rangeReader(file fs.File, offset, length int64) (io.Reader, error) {
+	if length < 0 {
+		length = math.MaxInt64 - offset
+	}
+	if readerAt, ok := filesystem.FileExtension[io.ReaderAt](file); ok {
+		return io.NewSectionReader(readerAt, offset, length), nil
+	}
+	if offset != 0 {
+		if err := skipTo(file, offset); err != nil {
+			return nil, err
+		}
+	}
+	return io.LimitReader(file, length), nil
+}
+
+func catJSON(output io.Writer, fsys fs.FS, name string, offset, length int64) error {
+	var buffer bytes.Buffer
+	size, written, err := catRange(&buffer, fsys, name, offset, length)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(output).Encode(catReport{
+		Path:   name,
+		Size:   size,
+		Offset: offset,
+		Length: written,
+		Data:   buffer.Bytes(),
+	})
+}
@@ -0,0 +1,17 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+)
+
+func makeIPFSCatCommands() []command.Command {
+	return []command.Command{
+		makeCatCommand[*ipfsOptions, ipfsSettings](ipfs.IPFSID),
+		makeCatCommand[*pinFSOptions, pinFSSettings](ipfs.PinFSID),
+		makeCatCommand[*ipnsOptions, ipnsSettings](ipfs.IPNSID),
+		makeCatCommand[*keyFSOptions, keyFSSettings](ipfs.KeyFSID),
+	}
+}
@@ -0,0 +1,9 @@
+//go:build noipfs
+
+package commands
+
+import "github.com/djdv/go-filesystem-utils/internal/command"
+
+func makeIPFSCatCommands() []command.Command {
+	return makeUnbuiltIPFSCommands()
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+type (
+	// streamFS hides the optional methods
+	// of its files, so that ranges must
+	// be reached by reading.
+	streamFS   struct{ fstest.MapFS }
+	streamFile struct{ fs.File }
+)
+
+func (sf streamFS) Open(name string) (fs.File, error) {
+	file, err := sf.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return streamFile{File: file}, nil
+}
+
+func TestCat(t *testing.T) {
+	t.Parallel()
+	const (
+		fileName = "file"
+		dirName  = "dir"
+		data     = "0123456789"
+	)
+	mapFS := fstest.MapFS{
+		fileName: &fstest.MapFile{Data: []byte(data)},
+		dirName:  &fstest.MapFile{Mode: fs.ModeDir},
+	}
+	systems := []struct {
+		name string
+		fsys fs.FS
+	}{
+		{"ReaderAt", mapFS},
+		{"Reader", streamFS{MapFS: mapFS}},
+	}
+	for _, system := range systems {
+		fsys := system.fsys
+		t.Run(system.name, func(t *testing.T) {
+			t.Parallel()
+			testCatRanges(t, fsys, fileName, data)
+			if _, _, err := catRange(new(bytes.Buffer), fsys, dirName, 0, -1); err == nil {
+				t.Error("expected error for directory, got nil")
+			}
+		})
+	}
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+		testCatJSON(t, mapFS, fileName, data)
+	})
+}
+
+func testCatRanges(t *testing.T, fsys fs.FS, name, data string) {
+	t.Helper()
+	size := int64(len(data))
+	for _, test := range []struct {
+		name           string
+		offset, length int64
+		want           string
+	}{
+		{"whole", 0, -1, data},
+		{"range", 2, 3, data[2:5]},
+		{"to end", 7, -1, data[7:]},
+		{"past end", 7, 100, data[7:]},
+		{"zero length", 2, 0, ""},
+		{"at end", size, -1, ""},
+		{"beyond end", size + 10, 5, ""},
+	} {
+		var output bytes.Buffer
+		_, _, err := catRange(&output, fsys, name, test.offset, test.length)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := output.String(); got != test.want {
+			t.Errorf("%s: output mismatch"+
+				"\ngot: %q"+
+				"\nwant: %q",
+				test.name, got, test.want,
+			)
+		}
+	}
+}
+
+func testCatJSON(t *testing.T, fsys fs.FS, name, data string) {
+	t.Helper()
+	const (
+		offset = 3
+		length = 4
+	)
+	var output bytes.Buffer
+	if err := catJSON(&output, fsys, name, offset, length); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		got  catReport
+		want = catReport{
+			Path:   name,
+			Size:   int64(len(data)),
+			Offset: offset,
+			Length: length,
+			Data:   []byte(data[offset : offset+length]),
+		}
+	)
+	if err := json.Unmarshal(output.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != want.Path ||
+		got.Size != want.Size ||
+		got.Offset != want.Offset ||
+		got.Length != want.Length ||
+		!bytes.Equal(got.Data, want.Data) {
+		t.Errorf("report mismatch"+
+			"\ngot: %+v"+
+			"\nwant: %+v",
+			got, want,
+		)
+	}
+}
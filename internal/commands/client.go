@@ -14,6 +14,7 @@ import (
 	"github.com/adrg/xdg"
 	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
 	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/djdv/go-filesystem-utils/internal/net/tunnel"
 	"github.com/djdv/p9/p9"
 	"github.com/multiformats/go-multiaddr"
 	manet "github.com/multiformats/go-multiaddr/net"
@@ -137,6 +138,24 @@ func (c *Client) getListeners() ([]multiaddr.Multiaddr, error) {
 	return maddrs, listenersDir.Close()
 }
 
+// listenerStatus retrieves the service's listener
+// addresses, and the amount of active connections.
+func (c *Client) listenerStatus() ([]multiaddr.Multiaddr, int, error) {
+	listenersDir, err := (*p9.Client)(c).Attach(listenersFileName)
+	if err != nil {
+		return nil, 0, err
+	}
+	maddrs, err := p9fs.GetListeners(listenersDir)
+	if err != nil {
+		return nil, 0, errors.Join(err, listenersDir.Close())
+	}
+	connections, err := p9fs.GetConnections(listenersDir)
+	if err != nil {
+		return nil, 0, errors.Join(err, listenersDir.Close())
+	}
+	return maddrs, len(connections), listenersDir.Close()
+}
+
 func launchAndConnect(exitInterval time.Duration, options ...p9.ClientOpt) (*Client, error) {
 	daemon, ipc, stderr, err := spawnDaemonProc(exitInterval)
 	if err != nil {
@@ -233,7 +252,7 @@ func systemServiceMaddrs() ([]multiaddr.Multiaddr, error) {
 
 func firstDialable(maddrs ...multiaddr.Multiaddr) (manet.Conn, error) {
 	for _, maddr := range maddrs {
-		if conn, err := manet.Dial(maddr); err == nil {
+		if conn, err := tunnel.Dial(maddr); err == nil {
 			return conn, nil
 		}
 	}
@@ -0,0 +1,370 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	entryType     uint8
+	countSettings struct {
+		entryType
+		byType, progress bool
+	}
+	countOption  func(*countSettings) error
+	countOptions []countOption
+	// countGuestSettings are the settings of
+	// commands which count guest directories
+	// (without mounting them).
+	countGuestSettings[M fsMaker] struct {
+		guest M
+		countSettings
+	}
+	countGuestOption[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] func(*countGuestSettings[GM]) error
+	countGuestOptions[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] []countGuestOption[GT, GM, GC]
+	// dirOpener opens the directory
+	// at the (command line) path.
+	dirOpener func(path string) (fs.File, error)
+	// dirCount holds the number of entries
+	// (by type) within a directory.
+	dirCount struct {
+		files, directories,
+		links, other int
+	}
+)
+
+const (
+	anyEntry entryType = iota
+	fileEntry
+	directoryEntry
+	linkEntry
+	minimumEntryType = fileEntry
+	maximumEntryType = linkEntry
+)
+
+const (
+	errCountEmpty  = generic.ConstError("no directories were provided")
+	errCountMixed  = generic.ConstError(`cannot combine "type" option with "by-type" option`)
+	errCountNotDir = generic.ConstError("not a directory")
+)
+
+// countBatchSize is the amount of entries requested
+// from the directory at a time.
+// Entries are counted as they're received, so memory
+// use does not grow with the size of the directory.
+const countBatchSize = 64
+
+func (et entryType) String() string {
+	switch et {
+	case fileEntry:
+		return "files"
+	case directoryEntry:
+		return "dirs"
+	case linkEntry:
+		return "links"
+	default:
+		return fmt.Sprintf("invalid: %d", et)
+	}
+}
+
+func parseEntryType(kind string) (entryType, error) {
+	return generic.ParseEnum(minimumEntryType, maximumEntryType, kind)
+}
+
+// Count constructs the command which
+// counts the entries of directories.
+func Count() command.Command {
+	const (
+		name     = "count"
+		synopsis = "Count directory entries."
+	)
+	usage := header("Count") +
+		"\n\n" + synopsis +
+		"\nAccepts local directories as arguments (E.g. mount points)." +
+		"\nGuest directories may be counted without mounting" +
+		" the guest, through the guest's subcommand." +
+		"\nEntries are counted as they're read," +
+		" without retaining their names."
+	guests := makeIPFSCountCommands()
+	sortCommands(guests)
+	return command.MakeVariadicCommand[countOptions](
+		name, synopsis, usage, countExecute,
+		command.WithSubcommands(guests...),
+	)
+}
+
+func makeCountCommand[
+	GC fsCmdGuest[GT, GM],
+	GM fsMaker,
+	GT any,
+](guest filesystem.ID,
+) command.Command {
+	type (
+		CO  = countGuestOption[GT, GM, GC]
+		COS = countGuestOptions[GT, GM, GC]
+	)
+	var (
+		guestFormalName = string(guest)
+		cmdName         = strings.ToLower(guestFormalName)
+		synopsis        = fmt.Sprintf(
+			"Count %s directory entries.", guestFormalName,
+		)
+		usage = guestCommandUsage[GC](guest, synopsis,
+			"counts the entries of the provided directories"+
+				"\n(relative to the guest's root).",
+		)
+	)
+	return command.MakeVariadicCommand[COS](cmdName, synopsis, usage,
+		func(ctx context.Context, arguments []string, options ...CO) error {
+			settings, err := COS(options).make()
+			if err != nil {
+				return err
+			}
+			return withGuestFS(settings.guest, func(fsys fs.FS) error {
+				open := func(path string) (fs.File, error) {
+					return fsys.Open(guestPath(path))
+				}
+				return countPaths(ctx, arguments, open, &settings.countSettings)
+			})
+		})
+}
+
+func (co *countOptions) BindFlags(flagSet *flag.FlagSet) {
+	bindCountFlags(flagSet, co,
+		func(settings *countSettings) *countSettings { return settings },
+	)
+}
+
+func (co countOptions) make() (countSettings, error) {
+	return makeWithOptions(co...)
+}
+
+func (co *countGuestOptions[GT, GM, GC]) BindFlags(flagSet *flag.FlagSet) {
+	type settings = countGuestSettings[GM]
+	bindGuestFlags[GC](flagSet, co, func(guest GM, cs *settings) {
+		cs.guest = guest
+	})
+	bindCountFlags(flagSet, co,
+		func(cs *settings) *countSettings { return &cs.countSettings },
+	)
+}
+
+func (co countGuestOptions[GT, GM, GC]) make() (countGuestSettings[GM], error) {
+	return makeWithOptions(co...)
+}
+
+// bindCountFlags binds the flags of the count
+// commands to the `countSettings` within `ST`.
+func bindCountFlags[
+	OSR optionsReference[OS, OT, ST],
+	OS optionSlice[OT, ST],
+	OT generic.OptionFunc[ST],
+	ST any,
+](flagSet *flag.FlagSet, options OSR, count func(*ST) *countSettings,
+) {
+	const typeName = "type"
+	typeUsage := fmt.Sprintf(
+		"only count entries of `kind`"+
+			"\none of: %s, %s, %s",
+		fileEntry, directoryEntry, linkEntry,
+	)
+	flagSetFunc(flagSet, typeName, typeUsage, options,
+		func(value entryType, settings *ST) error {
+			count(settings).entryType = value
+			return nil
+		})
+	const (
+		byTypeName  = "by-type"
+		byTypeUsage = "print the count of each type of entry"
+	)
+	flagSetFunc(flagSet, byTypeName, byTypeUsage, options,
+		func(value bool, settings *ST) error {
+			count(settings).byType = value
+			return nil
+		})
+	const (
+		progressName  = "progress"
+		progressUsage = "print the number of entries counted" +
+			" (to stderr) while counting"
+	)
+	flagSetFunc(flagSet, progressName, progressUsage, options,
+		func(value bool, settings *ST) error {
+			count(settings).progress = value
+			return nil
+		})
+}
+
+func countExecute(ctx context.Context, arguments []string, options ...countOption) error {
+	settings, err := countOptions(options).make()
+	if err != nil {
+		return err
+	}
+	open := func(path string) (fs.File, error) {
+		return os.Open(path)
+	}
+	return countPaths(ctx, arguments, open, &settings)
+}
+
+// countPaths counts the entries of each directory
+// and prints the results.
+func countPaths(ctx context.Context, paths []string, open dirOpener, settings *countSettings) error {
+	if len(paths) == 0 {
+		return command.UsageError{Err: errCountEmpty}
+	}
+	if settings.byType && settings.entryType != anyEntry {
+		return command.UsageError{Err: errCountMixed}
+	}
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	var (
+		errs      []error
+		tabWriter = tabwriter.NewWriter(
+			os.Stdout, minWidth, tabWidth, padding, padChar, flags,
+		)
+		reporter     *progressReporter
+		stopProgress = func() error { return nil }
+	)
+	if settings.progress {
+		reporter, stopProgress = startProgress(false)
+	}
+	if settings.byType {
+		if _, err := fmt.Fprintln(tabWriter,
+			"files\tdirs\tlinks\tother\ttotal\tpath",
+		); err != nil {
+			return err
+		}
+	}
+	for _, path := range paths {
+		count, err := countPath(ctx, open, path, reporter)
+		if err != nil {
+			if count == (dirCount{}) {
+				errs = append(errs, err)
+				continue
+			}
+			// Report what was counted,
+			// along with the error.
+			errs = append(errs, fmt.Errorf(
+				"%s: partial count (%d entries): %w",
+				path, count.total(), err,
+			))
+		}
+		if err := printCount(tabWriter, path, count, settings); err != nil {
+			return errors.Join(append(errs, err, stopProgress())...)
+		}
+	}
+	if err := stopProgress(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := tabWriter.Flush(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func countPath(ctx context.Context, open dirOpener, path string, reporter *progressReporter) (dirCount, error) {
+	file, err := open(path)
+	if err != nil {
+		return dirCount{}, err
+	}
+	directory, ok := file.(fs.ReadDirFile)
+	if !ok {
+		err := &fs.PathError{Op: "count", Path: path, Err: errCountNotDir}
+		return dirCount{}, errors.Join(err, file.Close())
+	}
+	count, err := countDir(ctx, directory, path, reporter)
+	return count, errors.Join(err, file.Close())
+}
+
+// countDir counts the entries of `directory`
+// until the end of the directory is reached,
+// an error is encountered, or the context is done.
+// The count returned is valid, even if an error
+// is returned (it contains the entries counted
+// prior to the error).
+// Each entry is reported to `reporter` as
+// an item within `name`.
+func countDir(ctx context.Context, directory fs.ReadDirFile, name string, reporter *progressReporter) (dirCount, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var count dirCount
+	for entry := range filesystem.StreamDir(ctx, countBatchSize, directory) {
+		if err := entry.Error(); err != nil {
+			return count, err
+		}
+		count.add(entry)
+		reporter.add(name, 0, 1)
+	}
+	return count, ctx.Err()
+}
+
+func (dc *dirCount) add(entry fs.DirEntry) {
+	switch typ := entry.Type(); {
+	case typ.IsDir():
+		dc.directories++
+	case typ&fs.ModeSymlink != 0:
+		dc.links++
+	case typ.IsRegular():
+		dc.files++
+	default:
+		dc.other++
+	}
+}
+
+func (dc dirCount) total() int {
+	return dc.files + dc.directories + dc.links + dc.other
+}
+
+func (dc dirCount) of(kind entryType) int {
+	switch kind {
+	case fileEntry:
+		return dc.files
+	case directoryEntry:
+		return dc.directories
+	case linkEntry:
+		return dc.links
+	default:
+		return dc.total()
+	}
+}
+
+func printCount(output io.Writer, path string, count dirCount, settings *countSettings) error {
+	if !settings.byType {
+		_, err := fmt.Fprintf(output, "%d\t%s\n",
+			count.of(settings.entryType), path,
+		)
+		return err
+	}
+	_, err := fmt.Fprintf(output, "%d\t%d\t%d\t%d\t%d\t%s\n",
+		count.files, count.directories,
+		count.links, count.other,
+		count.total(), path,
+	)
+	return err
+}
@@ -0,0 +1,17 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+)
+
+func makeIPFSCountCommands() []command.Command {
+	return []command.Command{
+		makeCountCommand[*ipfsOptions, ipfsSettings](ipfs.IPFSID),
+		makeCountCommand[*pinFSOptions, pinFSSettings](ipfs.PinFSID),
+		makeCountCommand[*ipnsOptions, ipnsSettings](ipfs.IPNSID),
+		makeCountCommand[*keyFSOptions, keyFSSettings](ipfs.KeyFSID),
+	}
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+type failingDir struct {
+	fs.ReadDirFile
+	err       error
+	remaining int
+}
+
+func (fd *failingDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	if fd.remaining == 0 {
+		return nil, fd.err
+	}
+	if count > fd.remaining {
+		count = fd.remaining
+	}
+	fd.remaining -= count
+	return fd.ReadDirFile.ReadDir(count)
+}
+
+func TestCountDir(t *testing.T) {
+	t.Parallel()
+	const (
+		files       = countBatchSize + 1
+		directories = 2
+		links       = 1
+	)
+	fsys := make(fstest.MapFS, files+directories+links)
+	for i := 0; i < files; i++ {
+		fsys[fmt.Sprintf("dir/file%d", i)] = new(fstest.MapFile)
+	}
+	for i := 0; i < directories; i++ {
+		fsys[fmt.Sprintf("dir/dir%d", i)] = &fstest.MapFile{Mode: fs.ModeDir}
+	}
+	for i := 0; i < links; i++ {
+		fsys[fmt.Sprintf("dir/link%d", i)] = &fstest.MapFile{Mode: fs.ModeSymlink}
+	}
+	want := dirCount{
+		files:       files,
+		directories: directories,
+		links:       links,
+	}
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		dir := openCountDir(t, fsys)
+		got, err := countDir(context.Background(), dir, "dir", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("count mismatch"+
+				"\ngot: %+v"+
+				"\nwant: %+v",
+				got, want,
+			)
+		}
+		for _, kind := range []entryType{
+			fileEntry, directoryEntry, linkEntry,
+		} {
+			if err := checkCount(kind, got, want); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	t.Run("partial", func(t *testing.T) {
+		t.Parallel()
+		const partial = countBatchSize / 2
+		var (
+			wantErr = errors.New("stream interrupted")
+			dir     = &failingDir{
+				ReadDirFile: openCountDir(t, fsys),
+				err:         wantErr,
+				remaining:   partial,
+			}
+		)
+		got, err := countDir(context.Background(), dir, "dir", nil)
+		if !errors.Is(err, wantErr) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, wantErr,
+			)
+		}
+		if total := got.total(); total != partial {
+			t.Errorf("partial count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				total, partial,
+			)
+		}
+	})
+}
+
+func TestCountPath(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx  = context.Background()
+		fsys = fstest.MapFS{
+			"dir/file": new(fstest.MapFile),
+		}
+		open = func(path string) (fs.File, error) {
+			return fsys.Open(guestPath(path))
+		}
+	)
+	count, err := countPath(ctx, open, "/dir", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := count.files, 1; got != want {
+		t.Errorf("file count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+	if _, err := countPath(ctx, open, "dir/file", nil); !errors.Is(err, errCountNotDir) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, errCountNotDir,
+		)
+	}
+}
+
+func openCountDir(t *testing.T, fsys fs.FS) fs.ReadDirFile {
+	t.Helper()
+	file, err := fsys.Open("dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := file.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	dir, ok := file.(fs.ReadDirFile)
+	if !ok {
+		t.Fatalf("%T does not implement %T", file, dir)
+	}
+	return dir
+}
+
+func checkCount(kind entryType, got, want dirCount) error {
+	if got, want := got.of(kind), want.of(kind); got != want {
+		return fmt.Errorf("%s count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			kind, got, want,
+		)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -10,7 +11,10 @@ import (
 	"log"
 	"net"
 	"os"
+	"os/signal"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -31,11 +35,37 @@ type (
 		systemLog, protocolLog ulog.Logger
 		serverMaddrs           []multiaddr.Multiaddr
 		exitInterval           time.Duration
+		// exitMax enables the idle backoff (if not 0);
+		// `exitInterval` doubles while the daemon is idle,
+		// and shutdown only happens after `exitMax`
+		// of continuous idleness.
+		exitMax time.Duration
 		nineIDs
 		permissions fs.FileMode
+		health      healthSettings
+		// messageSize limits the 9P message size
+		// negotiated with clients (if not 0).
+		messageSize uint32
+		// reloadSignal causes the daemon to reconcile
+		// its listeners with `serverMaddrs` (if not nil).
+		reloadSignal os.Signal
 	}
-	daemonOption  func(*daemonSettings) error
-	daemonOptions []daemonOption
+	// healthSettings controls how (and if)
+	// mounted guests are probed.
+	healthSettings struct {
+		interval  time.Duration
+		threshold int
+	}
+	// idleBackoff grows the idle check interval
+	// while the daemon is idle, and resets it
+	// when activity is observed.
+	idleBackoff struct {
+		idleSince          time.Time
+		min, max, interval time.Duration
+	}
+	// DaemonOption configures the daemon.
+	DaemonOption  func(*daemonSettings) error
+	daemonOptions []DaemonOption
 	nineIDs       struct {
 		uid p9.UID
 		gid p9.GID
@@ -62,6 +92,7 @@ type (
 		directory p9.File
 		name      string
 		shutdown
+		status
 	}
 	shutdown struct {
 		*p9fs.ChannelFile
@@ -69,6 +100,15 @@ type (
 		cancel context.CancelFunc
 		name   string
 	}
+	status struct {
+		*p9fs.GeneratorFile
+		started  time.Time
+		stopping *atomic.Uint32
+		name     string
+		// healthThreshold is the amount of consecutive
+		// probe failures which flag a guest as unhealthy.
+		healthThreshold int
+	}
 	daemonSystem struct {
 		log   ulog.Logger
 		files fileSystem
@@ -76,13 +116,24 @@ type (
 	handleFunc = func(io.ReadCloser, io.WriteCloser) error
 	serveFunc  = func(manet.Listener) error
 	checkFunc  = func() (bool, shutdownDisposition, error)
+	// activityFunc reports if some part
+	// of the daemon is currently in use.
+	activityFunc = func() (bool, error)
 
 	waitGroupChan[T any] struct {
 		ch      chan T
 		closing chan struct{}
 		sync.WaitGroup
 	}
-	wgErrs     = *waitGroupChan[error]
+	// errCollector aggregates errors from subsystems.
+	// Sending never blocks; errors beyond the
+	// collector's capacity are counted and dropped.
+	errCollector struct {
+		ch      chan error
+		dropped atomic.Int64
+		sync.WaitGroup
+	}
+	wgErrs     = *errCollector
 	wgShutdown = *waitGroupChan[shutdownDisposition]
 )
 
@@ -92,6 +143,11 @@ const (
 	apiGIDDefault         = p9.NoGID
 	apiPermissionsDefault = 0o751
 
+	healthIntervalDefault  = 30 * time.Second
+	healthThresholdDefault = 3
+
+	reloadSignalNone = "none"
+
 	errServe               = generic.ConstError("encountered error while serving")
 	errShutdownDisposition = generic.ConstError("invalid shutdown disposition")
 )
@@ -134,6 +190,15 @@ func (do *daemonOptions) BindFlags(flagSet *flag.FlagSet) {
 			settings.exitInterval = value
 			return nil
 		})
+	const (
+		exitMaxName  = exitAfterFlagName + "-max"
+		exitMaxUsage = "double the exit interval while the daemon is idle (up to `duration`)" +
+			"\nand only shutdown after being idle for the full duration"
+	)
+	flagSetFunc(flagSet, exitMaxName, exitMaxUsage, do,
+		func(value time.Duration, settings *daemonSettings) error {
+			return WithIdleBackoff(settings.exitInterval, value)(settings)
+		})
 	const (
 		uidName  = apiFlagPrefix + "uid"
 		uidUsage = "file owner's `uid`"
@@ -173,6 +238,110 @@ func (do *daemonOptions) BindFlags(flagSet *flag.FlagSet) {
 		})
 	flagSet.Lookup(permissionsName).
 		DefValue = modeToSymbolicPermissions(fs.FileMode(apiPermissionsDefault &^ p9.FileModeMask))
+	const (
+		healthIntervalName  = "health-interval"
+		healthIntervalUsage = "probe mounted guests every `interval` (0 disables probing)"
+	)
+	flagSetFunc(flagSet, healthIntervalName, healthIntervalUsage, do,
+		func(value time.Duration, settings *daemonSettings) error {
+			if value < 0 {
+				return fmt.Errorf(`"%s" flag must not be negative`, healthIntervalName)
+			}
+			settings.health.interval = value
+			return nil
+		})
+	flagSet.Lookup(healthIntervalName).
+		DefValue = healthIntervalDefault.String()
+	const (
+		healthThresholdName  = "health-threshold"
+		healthThresholdUsage = "flag guests as unhealthy after `count` consecutive probe failures"
+	)
+	flagSetFunc(flagSet, healthThresholdName, healthThresholdUsage, do,
+		func(value int, settings *daemonSettings) error {
+			if value < 1 {
+				return fmt.Errorf(`"%s" flag must be at least 1`, healthThresholdName)
+			}
+			settings.health.threshold = value
+			return nil
+		})
+	flagSet.Lookup(healthThresholdName).
+		DefValue = strconv.Itoa(healthThresholdDefault)
+	const (
+		msizeName  = apiFlagPrefix + "msize"
+		msizeUsage = "maximum 9P message size (in `bytes`) to negotiate with clients"
+	)
+	flagSetFunc(flagSet, msizeName, msizeUsage, do,
+		func(value uint32, settings *daemonSettings) error {
+			if value < p9net.MinimumMessageSize {
+				return fmt.Errorf(`"%s" flag must be at least %d`,
+					msizeName, p9net.MinimumMessageSize,
+				)
+			}
+			settings.messageSize = value
+			return nil
+		})
+	flagSet.Lookup(msizeName).
+		DefValue = "the size requested by the client"
+	const reloadName = "reload-signal"
+	reloadUsage := "reconcile listeners with the server flag's values" +
+		" when the daemon receives `signal`" +
+		"\none of: " + strings.Join(reloadSignalNames(), ", ")
+	flagSetFunc(flagSet, reloadName, reloadUsage, do,
+		func(value string, settings *daemonSettings) error {
+			sig, err := parseReloadSignal(value)
+			if err != nil {
+				return err
+			}
+			return WithReloadSignal(sig)(settings)
+		})
+	flagSet.Lookup(reloadName).
+		DefValue = reloadSignalDefault
+}
+
+// WithIdleBackoff enables idle shutdown with an interval
+// that starts at `min`, and doubles (up to `max`) each
+// time the daemon is found to be idle.
+// Activity resets the interval to `min`, and shutdown
+// only happens after `max` of continuous idleness.
+// A `max` of 0 restores the fixed interval.
+func WithIdleBackoff(min, max time.Duration) DaemonOption {
+	return func(settings *daemonSettings) error {
+		settings.exitInterval = min
+		settings.exitMax = max
+		return nil
+	}
+}
+
+// WithReloadSignal sets the signal which causes the daemon
+// to reconcile its listeners with its server addresses.
+// Listeners which are missing are re-established, and
+// listeners for other addresses are closed.
+// A nil signal disables reloading.
+func WithReloadSignal(sig os.Signal) DaemonOption {
+	return func(settings *daemonSettings) error {
+		settings.reloadSignal = sig
+		return nil
+	}
+}
+
+func reloadSignalNames() []string {
+	names := make([]string, 0, len(reloadSignals))
+	for name := range reloadSignals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseReloadSignal(name string) (os.Signal, error) {
+	sig, ok := reloadSignals[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf(
+			`invalid signal: "%s", want one of: %s`,
+			name, strings.Join(reloadSignalNames(), ", "),
+		)
+	}
+	return sig, nil
 }
 
 func (do daemonOptions) make() (daemonSettings, error) {
@@ -182,6 +351,11 @@ func (do daemonOptions) make() (daemonSettings, error) {
 			gid: apiGIDDefault,
 		},
 		permissions: apiPermissionsDefault,
+		health: healthSettings{
+			interval:  healthIntervalDefault,
+			threshold: healthThresholdDefault,
+		},
+		reloadSignal: reloadSignals[reloadSignalDefault],
 	}
 	if err := generic.ApplyOptions(&settings, do...); err != nil {
 		return daemonSettings{}, err
@@ -196,6 +370,13 @@ func (do daemonOptions) make() (daemonSettings, error) {
 	if settings.systemLog == nil {
 		settings.systemLog = ulog.Null
 	}
+	if max, min := settings.exitMax, settings.exitInterval; max != 0 &&
+		(min == 0 || max < min) {
+		return daemonSettings{}, fmt.Errorf(
+			"idle backoff maximum (%s) must be at least the exit interval (%s)",
+			max, min,
+		)
+	}
 	return settings, nil
 }
 
@@ -211,7 +392,7 @@ func Daemon() command.Command {
 	return command.MakeVariadicCommand[daemonOptions](name, synopsis, usage, daemonExecute)
 }
 
-func daemonExecute(ctx context.Context, options ...daemonOption) error {
+func daemonExecute(ctx context.Context, options ...DaemonOption) error {
 	settings, err := daemonOptions(options).make()
 	if err != nil {
 		return err
@@ -222,7 +403,7 @@ func daemonExecute(ctx context.Context, options ...daemonOption) error {
 	if err != nil {
 		return err
 	}
-	const errBuffer = 0
+	const errBuffer = 64
 	var (
 		fsys   = system.files
 		path   = fsys.path
@@ -230,19 +411,22 @@ func daemonExecute(ctx context.Context, options ...daemonOption) error {
 		log    = system.log
 		server = makeServer(
 			newAttacher(path, root),
+			settings.messageSize,
 			settings.protocolLog,
 		)
 		stopSend,
 		stopReceive = makeStoppers(ctx)
 		lsnStop,
 		srvStop,
-		mntStop = splitStopper(stopReceive)
+		mntStop,
+		statusStop = splitStopper(stopReceive)
 		listenSys = fsys.listen
 		listeners = listenSys.listeners
-		errs      = newWaitGroupChan[error](errBuffer)
+		errs      = newErrCollector(errBuffer)
 	)
 	handleListeners(server.Serve, listeners, errs, log)
 	go watchListenersStopper(listenSys.cancel, lsnStop, log)
+	go watchStatusStopper(fsys.control.status.stopping, statusStop)
 	serviceWg := handleStopSequence(dCtx,
 		server, srvStop,
 		fsys.mount, mntStop,
@@ -262,11 +446,20 @@ func daemonExecute(ctx context.Context, options ...daemonOption) error {
 		control, handleFn,
 		serviceWg, errs,
 	)
+	if sig := settings.reloadSignal; sig != nil {
+		maddrs := settings.serverMaddrs
+		go reloadOnSignal(dCtx, sig, func() error {
+			return reloadListeners(listener, permissions, maddrs...)
+		}, log)
+	}
 	idleCheckInterval := settings.exitInterval
-	setupExtraStopWriters(idleCheckInterval, &fsys,
+	setupExtraStopWriters(idleCheckInterval, settings.exitMax, &fsys,
 		stopSend, errs,
 		log,
 	)
+	if interval := settings.health.interval; interval > 0 {
+		go probeMounts(dCtx, fsys.mount.MountFile, interval, log)
+	}
 	return watchService(ctx, serviceWg,
 		stopSend, errs,
 		log,
@@ -308,23 +501,28 @@ func makeStoppers(ctx context.Context) (wgShutdown, <-chan shutdownDisposition)
 	return shutdownSend, shutdownReceive
 }
 
-func makeServer(fsys p9.Attacher, log ulog.Logger) *p9net.Server {
+func makeServer(fsys p9.Attacher, messageSize uint32, log ulog.Logger) *p9net.Server {
 	var options []p9net.ServerOpt
 	if log != nil {
-		options = []p9net.ServerOpt{
+		options = append(options,
 			p9net.WithServerLogger(log),
-		}
+		)
+	}
+	if messageSize != 0 {
+		options = append(options,
+			p9net.WithMessageSize(messageSize),
+		)
 	}
 	return p9net.NewServer(fsys, options...)
 }
 
-func splitStopper(shutdownLevels <-chan shutdownDisposition) (_, _, _ <-chan shutdownDisposition) {
-	var lsnShutdownSignals,
-		srvShutdownSignals,
-		mntShutdownSignals <-chan shutdownDisposition
-	relayUnordered(shutdownLevels, &lsnShutdownSignals,
-		&srvShutdownSignals, &mntShutdownSignals)
-	return lsnShutdownSignals, srvShutdownSignals, mntShutdownSignals
+// splitStopper relays shutdown levels to each subsystem
+// (listeners, server, mounts, and status; in that order).
+// See [relayLevels] for the guarantees each receiver has.
+func splitStopper(shutdownLevels <-chan shutdownDisposition) (_, _, _, _ <-chan shutdownDisposition) {
+	const subsystems = 4
+	relays := relayLevels(shutdownLevels, subsystems)
+	return relays[0], relays[1], relays[2], relays[3]
 }
 
 func handleListeners(serveFn serveFunc,
@@ -399,6 +597,74 @@ func listenOn(listener *p9fs.Listener, permissions p9.FileMode,
 	return failureSignal
 }
 
+// reloadOnSignal calls `reload` each time
+// the signal is received, until the context is done.
+func reloadOnSignal(ctx context.Context, sig os.Signal,
+	reload func() error, log ulog.Logger,
+) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sig)
+	defer signal.Stop(signals)
+	for {
+		select {
+		case <-signals:
+			log.Print("reload signal received - reconciling listeners")
+			if err := reload(); err != nil {
+				log.Print(err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadListeners closes listeners which were not
+// requested for any of the `maddrs`, and listens
+// on any of the `maddrs` which are not active.
+// Listeners which remain (and the connections
+// accepted by any listener) are not affected.
+func reloadListeners(listener p9.File, permissions p9.FileMode,
+	maddrs ...multiaddr.Multiaddr,
+) error {
+	requests, err := p9fs.GetListenerRequests(listener)
+	if err != nil {
+		return err
+	}
+	var (
+		errs       []error
+		configured = make(map[string]struct{}, len(maddrs))
+		active     = make(map[string]struct{}, len(requests))
+	)
+	for _, maddr := range maddrs {
+		configured[maddr.String()] = struct{}{}
+	}
+	for _, maddr := range requests {
+		key := maddr.String()
+		active[key] = struct{}{}
+		if _, ok := configured[key]; ok {
+			continue
+		}
+		if err := p9fs.Unlisten(listener, maddr); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"could not stop listening on: %s - %w",
+				maddr, err,
+			))
+		}
+	}
+	for _, maddr := range maddrs {
+		if _, ok := active[maddr.String()]; ok {
+			continue
+		}
+		if err := p9fs.Listen(listener, maddr, permissions); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"could not listen on: %s - %w",
+				maddr, err,
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func setupIPCHandler(ctx context.Context, procExitCh <-chan bool,
 	control p9.File, handlerFn handleFunc,
 	serviceWg *sync.WaitGroup, errs wgErrs,
@@ -415,7 +681,7 @@ func setupIPCHandler(ctx context.Context, procExitCh <-chan bool,
 }
 
 func setupExtraStopWriters(
-	idleCheck time.Duration, fsys *fileSystem,
+	idleCheck, idleMax time.Duration, fsys *fileSystem,
 	stopper wgShutdown,
 	errs wgErrs, log ulog.Logger,
 ) {
@@ -424,12 +690,18 @@ func setupExtraStopWriters(
 	errs.Add(2)
 	go stopOnUnreachable(fsys, stopper, errs, log)
 	go stopOnShutdownWrite(shutdownFileData, stopper, errs, log)
-	if idleCheck != 0 {
-		stopper.Add(1)
-		errs.Add(1)
-		idleCheckFn := makeIdleChecker(fsys, idleCheck, log)
-		go stopWhen(idleCheckFn, idleCheck, stopper, errs)
+	if idleCheck == 0 {
+		return
 	}
+	stopper.Add(1)
+	errs.Add(1)
+	if idleMax != 0 {
+		backoff := newIdleBackoff(idleCheck, idleMax)
+		go stopWhenIdle(fsys, backoff, stopper, errs, log)
+		return
+	}
+	idleCheckFn := makeIdleChecker(fsys, idleCheck, log)
+	go stopWhen(idleCheckFn, idleCheck, stopper, errs)
 }
 
 func newWaitGroupChan[T any](size int) *waitGroupChan[T] {
@@ -461,11 +733,44 @@ func (wc *waitGroupChan[T]) waitThenCloseCh() {
 	close(wc.ch)
 }
 
+func newErrCollector(size int) *errCollector {
+	return &errCollector{
+		ch: make(chan error, size),
+	}
+}
+
+// send queues the error if there's room in the buffer,
+// otherwise the error is dropped and counted.
+func (ec *errCollector) send(err error) (sent bool) {
+	select {
+	case ec.ch <- err:
+		return true
+	default:
+		ec.dropped.Add(1)
+		return false
+	}
+}
+
+// waitThenCloseCh waits for all senders to finish,
+// reports how many errors were dropped (if any),
+// then closes the channel.
+// The channel must be drained concurrently.
+func (ec *errCollector) waitThenCloseCh() {
+	ec.WaitGroup.Wait()
+	if dropped := ec.dropped.Load(); dropped != 0 {
+		ec.ch <- fmt.Errorf(
+			"%d errors were dropped (error buffer full)",
+			dropped,
+		)
+	}
+	close(ec.ch)
+}
+
 func newSystem(ctx context.Context, set *daemonSettings) (*daemonSystem, error) {
 	var (
 		uid       = set.uid
 		gid       = set.gid
-		fsys, err = newFileSystem(ctx, uid, gid)
+		fsys, err = newFileSystem(ctx, uid, gid, set.health.threshold)
 		system    = &daemonSystem{
 			files: fsys,
 			log:   set.systemLog,
@@ -474,7 +779,7 @@ func newSystem(ctx context.Context, set *daemonSettings) (*daemonSystem, error)
 	return system, err
 }
 
-func newFileSystem(ctx context.Context, uid p9.UID, gid p9.GID) (fileSystem, error) {
+func newFileSystem(ctx context.Context, uid p9.UID, gid p9.GID, healthThreshold int) (fileSystem, error) {
 	const permissions = p9fs.ReadUser | p9fs.WriteUser | p9fs.ExecuteUser |
 		p9fs.ReadGroup | p9fs.ExecuteGroup |
 		p9fs.ReadOther | p9fs.ExecuteOther
@@ -510,6 +815,10 @@ func newFileSystem(ctx context.Context, uid p9.UID, gid p9.GID) (fileSystem, err
 		listen:  listen,
 		control: control,
 	}
+	system.control.status.healthThreshold = healthThreshold
+	if err := linkStatus(&system, path, uid, gid, permissions); err != nil {
+		return fileSystem{}, err
+	}
 	return system, linkSystems(&system)
 }
 
@@ -580,9 +889,106 @@ func newControl(ctx context.Context,
 			ch:          shutdownCh,
 			cancel:      cancel,
 		},
+		status: status{
+			name:     statusFileName,
+			started:  time.Now(),
+			stopping: new(atomic.Uint32),
+		},
 	}, nil
 }
 
+func linkStatus(system *fileSystem, path ninePath,
+	uid p9.UID, gid p9.GID, permissions p9.FileMode,
+) error {
+	var (
+		control         = &system.control
+		filePermissions = permissions ^ (p9fs.ExecuteOther | p9fs.ExecuteGroup | p9fs.ExecuteUser)
+	)
+	_, statusFile, err := p9fs.NewGeneratorFile(
+		func() ([]byte, error) { return marshalStatus(system) },
+		p9fs.WithParent[p9fs.GeneratorOption](control.directory, statusFileName),
+		p9fs.WithPath[p9fs.GeneratorOption](path),
+		p9fs.WithUID[p9fs.GeneratorOption](uid),
+		p9fs.WithGID[p9fs.GeneratorOption](gid),
+		p9fs.WithPermissions[p9fs.GeneratorOption](filePermissions),
+	)
+	if err != nil {
+		return err
+	}
+	control.status.GeneratorFile = statusFile
+	return control.directory.Link(statusFile, statusFileName)
+}
+
+func marshalStatus(system *fileSystem) ([]byte, error) {
+	var (
+		status    = &system.control.status
+		listeners = system.listen.Listener
+	)
+	maddrs, err := p9fs.GetListeners(listeners)
+	if err != nil {
+		return nil, err
+	}
+	connections, err := p9fs.GetConnections(listeners)
+	if err != nil {
+		return nil, err
+	}
+	mounts, err := p9fs.CountMounts(system.mount.MountFile)
+	if err != nil {
+		return nil, err
+	}
+	healths, err := p9fs.MountHealths(system.mount.MountFile, newDecodeTargetFunc())
+	if err != nil {
+		return nil, err
+	}
+	listenerStrings := make([]string, len(maddrs))
+	for i, maddr := range maddrs {
+		listenerStrings[i] = maddr.String()
+	}
+	serviceStatus := ServiceStatus{
+		Started:     status.started,
+		Uptime:      time.Since(status.started).Round(time.Second).String(),
+		Listeners:   listenerStrings,
+		Mounts:      mounts,
+		Connections: len(connections),
+		Guests:      makeGuestStatuses(healths, status.healthThreshold),
+	}
+	if level := shutdownDisposition(status.stopping.Load()); level != dontShutdown {
+		serviceStatus.Stopping = true
+		serviceStatus.Shutdown = level.String()
+	}
+	return json.Marshal(serviceStatus)
+}
+
+// makeGuestStatuses converts mount healths to their
+// status format (sorted by target), flagging guests which
+// have failed at least `threshold` consecutive probes.
+func makeGuestStatuses(healths []p9fs.MountHealth, threshold int) []GuestStatus {
+	if len(healths) == 0 {
+		return nil
+	}
+	statuses := make([]GuestStatus, len(healths))
+	for i, health := range healths {
+		guestStatus := GuestStatus{
+			Host:        string(health.Host),
+			Guest:       string(health.Guest),
+			Target:      health.Target,
+			LastProbe:   health.LastProbe,
+			LastSuccess: health.LastSuccess,
+			Failures:    health.Failures,
+			Unhealthy:   health.Failures >= threshold,
+			Handles:     health.Handles,
+		}
+		if err := health.LastError; err != nil {
+			guestStatus.LastError = err.Error()
+		}
+		statuses[i] = guestStatus
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Target < statuses[j].Target
+	})
+	return statuses
+}
+
 func linkSystems(system *fileSystem) error {
 	root := system.root
 	for _, file := range []struct {
@@ -736,6 +1142,58 @@ func populateSelectSendCases[T any](value T, channels []reflect.Value, cases []r
 	}
 }
 
+// relayLevels relays shutdown levels from `levels`
+// to each of the returned channels, which are
+// closed after `levels` is closed.
+// Levels must be strictly increasing (see [sequentialLeveling]).
+// Each relay holds at most one pending level; if its receiver
+// has not received that level by the time a higher level
+// arrives, the pending level is replaced by the higher one.
+// As a result, receivers never block the relay (or each other),
+// observe levels in increasing order, and always observe the
+// highest level exactly once (if they continue receiving).
+func relayLevels(levels <-chan shutdownDisposition, count int) []<-chan shutdownDisposition {
+	var (
+		relays   = make([]chan shutdownDisposition, count)
+		receives = make([]<-chan shutdownDisposition, count)
+	)
+	for i := range relays {
+		relay := make(chan shutdownDisposition, 1)
+		relays[i] = relay
+		receives[i] = relay
+	}
+	go func() {
+		for level := range levels {
+			for _, relay := range relays {
+				replacePending(relay, level)
+			}
+		}
+		for _, relay := range relays {
+			close(relay)
+		}
+	}()
+	return receives
+}
+
+// replacePending sends the level to the relay, replacing
+// the relay's pending level if it has one.
+// The relay must only be sent to by the caller.
+func replacePending(relay chan shutdownDisposition, level shutdownDisposition) {
+	select {
+	case relay <- level:
+		return
+	default:
+	}
+	select {
+	case <-relay: // Superseded.
+	default: // Received since the first attempt.
+	}
+	relay <- level
+}
+
+// sequentialLeveling relays levels from `stopper`
+// to `filtered`, only if they're higher than
+// every level which preceded them.
 func sequentialLeveling(stopper <-chan shutdownDisposition, filtered chan<- shutdownDisposition) {
 	var highestSeen shutdownDisposition
 	for level := range stopper {
@@ -756,6 +1214,37 @@ func watchListenersStopper(cancel context.CancelFunc,
 	}
 }
 
+func watchStatusStopper(stopping *atomic.Uint32, stopper <-chan shutdownDisposition) {
+	for level := range stopper {
+		stopping.Store(uint32(level))
+	}
+}
+
+// probeMounts probes the mounted guests every interval,
+// until the context is done. Each round of probes is
+// bounded by the interval; a guest whose probe has not
+// returned by the next round is considered to have failed.
+func probeMounts(ctx context.Context, mounts p9.File,
+	interval time.Duration, log ulog.Logger,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			probeCtx, cancel := context.WithTimeout(ctx, interval)
+			err := p9fs.ProbeMounts(probeCtx, mounts)
+			cancel()
+			if err != nil && ctx.Err() == nil &&
+				!errors.Is(err, context.DeadlineExceeded) {
+				log.Print("probing mounts: ", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func serverStopper(ctx context.Context,
 	server *p9net.Server, stopper <-chan shutdownDisposition,
 	errs wgErrs, log ulog.Logger,
@@ -1012,27 +1501,103 @@ func stopWhen(checkFn checkFunc, interval time.Duration,
 	}
 }
 
+// stopWhenIdle is like [stopWhen] with a [makeIdleChecker] check,
+// but waits according to the backoff between checks.
+func stopWhenIdle(fsys *fileSystem, backoff *idleBackoff,
+	stopper wgShutdown,
+	errs wgErrs, log ulog.Logger,
+) {
+	defer func() {
+		errs.Done()
+		stopper.Done()
+	}()
+	timer := time.NewTimer(backoff.interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			// Connections count as active if they were
+			// used at any point since the previous check.
+			idleCheckFn := makeIdleChecker(fsys, backoff.interval, ulog.Null)
+			idle, _, err := idleCheckFn()
+			if err != nil {
+				errs.send(err)
+				return
+			}
+			stop, wait := backoff.next(!idle, time.Now())
+			if stop {
+				log.Printf("daemon has been idle for %s - idle shutdown", backoff.max)
+				stopper.send(immediateShutdown)
+				return
+			}
+			timer.Reset(wait)
+		case <-stopper.Closing():
+			return
+		}
+	}
+}
+
+func newIdleBackoff(min, max time.Duration) *idleBackoff {
+	return &idleBackoff{
+		min:      min,
+		max:      max,
+		interval: min,
+	}
+}
+
+// next records activity (or the lack of it) at `now`,
+// and returns whether the daemon has been continuously
+// idle for the maximum duration. If not, the duration
+// to wait before the next check is also returned.
+func (ib *idleBackoff) next(active bool, now time.Time) (bool, time.Duration) {
+	if active {
+		ib.idleSince = time.Time{}
+		ib.interval = ib.min
+		return false, ib.interval
+	}
+	if ib.idleSince.IsZero() {
+		ib.idleSince = now
+	}
+	idle := now.Sub(ib.idleSince)
+	if idle >= ib.max {
+		return true, 0
+	}
+	ib.interval = generic.Min(ib.interval*2, ib.max-idle)
+	return false, ib.interval
+}
+
 // makeIdleChecker prevents the process from lingering around
 // if a client closes all services, then disconnects.
+// The daemon is considered idle when it has no mounts,
+// no recently active connections, and no open handles.
 func makeIdleChecker(fsys *fileSystem, interval time.Duration, log ulog.Logger) checkFunc {
 	var (
 		mounts    = fsys.mount.MountFile
 		listeners = fsys.listen.Listener
 	)
+	return newIdleChecker(log,
+		func() (bool, error) { return hasEntries(mounts) },
+		func() (bool, error) { return hasActiveClients(listeners, interval) },
+		func() (bool, error) { return hasOpenHandles(mounts) },
+	)
+}
+
+// newIdleChecker returns a check which
+// requests shutdown if none of the
+// activity functions report activity.
+func newIdleChecker(log ulog.Logger, activityFns ...activityFunc) checkFunc {
 	const (
 		keepRunning = false
 		stopRunning = true
-		idleMessage = "daemon has no active mounts or connections" +
-			" - idle shutdown"
+		idleMessage = "daemon has no active mounts, connections," +
+			" or open handles - idle shutdown"
 	)
 	return func() (bool, shutdownDisposition, error) {
-		mounted, err := hasEntries(mounts)
-		if mounted || err != nil {
-			return keepRunning, dontShutdown, err
-		}
-		activeConns, err := hasActiveClients(listeners, interval)
-		if activeConns || err != nil {
-			return keepRunning, dontShutdown, err
+		for _, activityFn := range activityFns {
+			active, err := activityFn()
+			if active || err != nil {
+				return keepRunning, dontShutdown, err
+			}
 		}
 		log.Print(idleMessage)
 		return stopRunning, immediateShutdown, nil
@@ -1047,6 +1612,11 @@ func hasEntries(fsys p9.File) (bool, error) {
 	return len(ents) > 0, nil
 }
 
+func hasOpenHandles(mounts p9.File) (bool, error) {
+	handles, err := p9fs.OpenHandles(mounts)
+	return handles > 0, err
+}
+
 func hasActiveClients(listeners p9.File, threshold time.Duration) (bool, error) {
 	infos, err := p9fs.GetConnections(listeners)
 	if err != nil {
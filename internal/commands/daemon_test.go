@@ -0,0 +1,461 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/djdv/p9/p9"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/u-root/uio/ulog"
+)
+
+func TestSplitStopper(t *testing.T) {
+	t.Parallel()
+	const (
+		iterations = 64
+		senders    = 32
+	)
+	for i := 0; i < iterations; i++ {
+		testSplitStopperBurst(t, senders, int64(i))
+	}
+}
+
+// testSplitStopperBurst sends levels concurrently,
+// and checks that each subsystem observes increasing
+// levels, ending with the highest level sent.
+func testSplitStopperBurst(t *testing.T, senders int, seed int64) {
+	t.Helper()
+	var (
+		levelCount  = int(maximumShutdown - minimumShutdown + 1)
+		levels      = make([]shutdownDisposition, senders)
+		stopSend    = make(chan shutdownDisposition)
+		stopReceive = make(chan shutdownDisposition)
+		sendWg      sync.WaitGroup
+		highest     shutdownDisposition
+		lsn, srv,
+		mnt, status = splitStopper(stopReceive)
+		subsystems = []<-chan shutdownDisposition{lsn, srv, mnt, status}
+		observed   = make([][]shutdownDisposition, len(subsystems))
+		receiveWg  sync.WaitGroup
+	)
+	go func() {
+		sequentialLeveling(stopSend, stopReceive)
+		close(stopReceive)
+	}()
+	for i, subsystem := range subsystems {
+		receiveWg.Add(1)
+		go func(i int, levels <-chan shutdownDisposition) {
+			defer receiveWg.Done()
+			for level := range levels {
+				observed[i] = append(observed[i], level)
+				// Slow receivers must not delay the others,
+				// nor have their levels reordered.
+				if i%2 == 0 {
+					time.Sleep(100 * time.Microsecond)
+				}
+			}
+		}(i, subsystem)
+	}
+	for i := range levels {
+		level := minimumShutdown + shutdownDisposition(i%levelCount)
+		if level > highest {
+			highest = level
+		}
+		levels[i] = level
+	}
+	rand.New(rand.NewSource(seed)).Shuffle(len(levels), func(i, j int) {
+		levels[i], levels[j] = levels[j], levels[i]
+	})
+	for _, level := range levels {
+		sendWg.Add(1)
+		go func(level shutdownDisposition) {
+			defer sendWg.Done()
+			stopSend <- level
+		}(level)
+	}
+	sendWg.Wait()
+	close(stopSend)
+	receiveWg.Wait()
+	for i, levels := range observed {
+		if len(levels) == 0 {
+			t.Fatalf("subsystem %d observed no levels", i)
+		}
+		for j := 1; j < len(levels); j++ {
+			if levels[j] <= levels[j-1] {
+				t.Fatalf("subsystem %d observed levels out of order: %v",
+					i, levels,
+				)
+			}
+		}
+		if last := levels[len(levels)-1]; last != highest {
+			t.Fatalf("subsystem %d final level mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				i, last, highest,
+			)
+		}
+	}
+}
+
+func TestErrCollector(t *testing.T) {
+	t.Parallel()
+	const (
+		bufferSize = 4
+		senders    = bufferSize * 8
+		dropped    = senders - bufferSize
+		timeout    = 10 * time.Second
+		errFlood   = generic.ConstError("flood")
+	)
+	var (
+		errs      = newErrCollector(bufferSize)
+		serviceWg sync.WaitGroup
+		stopSend  = newWaitGroupChan[shutdownDisposition](0)
+	)
+	// Nothing receives from the collector
+	// until every sender has returned.
+	errs.Add(senders)
+	var sendersWg sync.WaitGroup
+	sendersWg.Add(senders)
+	for i := 0; i < senders; i++ {
+		go func() {
+			defer func() { errs.Done(); sendersWg.Done() }()
+			errs.send(errFlood)
+		}()
+	}
+	sent := make(chan struct{})
+	go func() { sendersWg.Wait(); close(sent) }()
+	select {
+	case <-sent:
+	case <-time.After(timeout):
+		t.Fatal("senders blocked on a full error buffer")
+	}
+	result := make(chan error, 1)
+	go func() {
+		result <- watchService(context.Background(),
+			&serviceWg, stopSend, errs, ulog.Null,
+		)
+	}()
+	var err error
+	select {
+	case err = <-result:
+	case <-time.After(timeout):
+		t.Fatal("service did not shut down")
+	}
+	if !errors.Is(err, errFlood) {
+		t.Errorf("flood errors were not returned: %v", err)
+	}
+	if want := fmt.Sprintf("%d errors were dropped", dropped); err == nil ||
+		!strings.Contains(err.Error(), want) {
+		t.Errorf("overflow was not reported"+
+			"\ngot: %v"+
+			"\nwant: %s",
+			err, want,
+		)
+	}
+}
+
+func TestGuestStatuses(t *testing.T) {
+	t.Parallel()
+	const (
+		threshold = 3
+		errProbe  = generic.ConstError("probe failed")
+	)
+	healths := []p9fs.MountHealth{
+		{
+			MountInfo: p9fs.MountInfo{Target: "c"},
+			LastError: errProbe,
+			Failures:  threshold,
+		},
+		{
+			MountInfo: p9fs.MountInfo{Target: "a"},
+		},
+		{
+			MountInfo: p9fs.MountInfo{Target: "b"},
+			LastError: errProbe,
+			Failures:  threshold - 1,
+		},
+	}
+	if statuses := makeGuestStatuses(nil, threshold); statuses != nil {
+		t.Errorf("expected no statuses, got: %v", statuses)
+	}
+	statuses := makeGuestStatuses(healths, threshold)
+	want := []struct {
+		target    string
+		lastError string
+		unhealthy bool
+	}{
+		{target: "a"},
+		{target: "b", lastError: errProbe.Error()},
+		{target: "c", lastError: errProbe.Error(), unhealthy: true},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("status count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			len(statuses), len(want),
+		)
+	}
+	for i, status := range statuses {
+		if status.Target != want[i].target ||
+			status.LastError != want[i].lastError ||
+			status.Unhealthy != want[i].unhealthy {
+			t.Errorf("status %d mismatch"+
+				"\ngot: %+v"+
+				"\nwant: %+v",
+				i, status, want[i],
+			)
+		}
+	}
+	if got := unhealthyTargets(statuses); got != "c" {
+		t.Errorf("unhealthy targets mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, "c",
+		)
+	}
+}
+
+func TestIdleChecker(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const (
+		healthThreshold = 3
+		interval        = time.Second
+	)
+	system, err := newFileSystem(ctx, p9.NoUID, p9.NoGID, healthThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const fileName = "file"
+	// Stands in for a mounted host's open files.
+	guest, err := filesystem.NewHandleLimitFS(fstest.MapFS{fileName: {}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mounts    = system.mount.MountFile
+		listeners = system.listen.Listener
+		idleCheck = newIdleChecker(ulog.Null,
+			func() (bool, error) { return hasEntries(mounts) },
+			func() (bool, error) { return hasActiveClients(listeners, interval) },
+			func() (bool, error) { return hasOpenHandles(mounts) },
+			func() (bool, error) { return guest.Handles() > 0, nil },
+		)
+		idleMatch = func(t *testing.T, want bool) {
+			t.Helper()
+			stop, _, err := idleCheck()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stop != want {
+				t.Errorf("idle shutdown mismatch"+
+					"\ngot: %t"+
+					"\nwant: %t",
+					stop, want,
+				)
+			}
+		}
+	)
+	file, err := guest.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idleMatch(t, false)
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	idleMatch(t, true)
+}
+
+func TestIdleBackoff(t *testing.T) {
+	t.Parallel()
+	const (
+		min = time.Second
+		max = 10 * time.Second
+	)
+	var (
+		backoff = newIdleBackoff(min, max)
+		now     = time.Now()
+		step    = func(t *testing.T, active bool, wantStop bool, wantWait time.Duration) {
+			t.Helper()
+			stop, wait := backoff.next(active, now)
+			if stop != wantStop {
+				t.Fatalf("shutdown mismatch"+
+					"\ngot: %t"+
+					"\nwant: %t",
+					stop, wantStop,
+				)
+			}
+			if wait != wantWait {
+				t.Fatalf("interval mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					wait, wantWait,
+				)
+			}
+			now = now.Add(wait)
+		}
+	)
+	step(t, false, false, 2*time.Second)
+	step(t, false, false, 4*time.Second)
+	// Activity resets the interval and idle time.
+	step(t, true, false, min)
+	step(t, false, false, 2*time.Second)
+	step(t, false, false, 4*time.Second)
+	// The interval is capped by the remaining idle time.
+	step(t, false, false, 4*time.Second)
+	step(t, false, true, 0)
+}
+
+func TestWithIdleBackoff(t *testing.T) {
+	t.Parallel()
+	const (
+		min = time.Second
+		max = time.Minute
+	)
+	settings, err := daemonOptions{WithIdleBackoff(min, max)}.make()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.exitInterval != min || settings.exitMax != max {
+		t.Errorf("backoff mismatch"+
+			"\ngot: %s, %s"+
+			"\nwant: %s, %s",
+			settings.exitInterval, settings.exitMax,
+			min, max,
+		)
+	}
+	for _, option := range []DaemonOption{
+		WithIdleBackoff(max, min),
+		WithIdleBackoff(0, max),
+	} {
+		if _, err := (daemonOptions{option}).make(); err == nil {
+			t.Error("expected backoff to be rejected")
+		}
+	}
+}
+
+func TestReloadListeners(t *testing.T) {
+	t.Parallel()
+	const (
+		permissions    = 0o751
+		listenerBuffer = 3
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	_, listenerDir, listeners, err := p9fs.NewListener(ctx,
+		p9fs.WithBuffer[p9fs.ListenerOption](listenerBuffer),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		kept    = newReloadMaddr(t)
+		removed = newReloadMaddr(t)
+		added   = newReloadMaddr(t)
+		started = make(map[string]manet.Listener, listenerBuffer)
+	)
+	for _, maddr := range []multiaddr.Multiaddr{kept, removed} {
+		if err := p9fs.Listen(listenerDir, maddr, permissions); err != nil {
+			t.Fatal(err)
+		}
+		listener := <-listeners
+		t.Cleanup(func() { listener.Close() })
+		started[maddr.String()] = listener
+	}
+	if err := reloadListeners(listenerDir, permissions, kept, added); err != nil {
+		t.Fatal(err)
+	}
+	listener := <-listeners
+	t.Cleanup(func() { listener.Close() })
+	if got, want := listener.Multiaddr().String(), added.String(); got != want {
+		t.Errorf("listener mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, want,
+		)
+	}
+	if _, err := started[removed.String()].Accept(); err == nil {
+		t.Error("expected removed listener to be closed")
+	}
+	requests, err := p9fs.GetListenerRequests(listenerDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, len(requests))
+	for i, maddr := range requests {
+		got[i] = maddr.String()
+	}
+	want := []string{kept.String(), added.String()}
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("listener requests mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, want,
+		)
+	}
+	// Reloading again should not change anything.
+	if err := reloadListeners(listenerDir, permissions, kept, added); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case listener := <-listeners:
+		listener.Close()
+		t.Error("unexpected listener after redundant reload")
+	default:
+	}
+}
+
+// newReloadMaddr returns a loopback TCP address
+// which was free at the time of the call.
+func newReloadMaddr(t *testing.T) multiaddr.Multiaddr {
+	t.Helper()
+	stdListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := stdListener.Addr().(*net.TCPAddr).Port
+	if err := stdListener.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return multiaddr.StringCast(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", port))
+}
+
+func TestWithReloadSignal(t *testing.T) {
+	t.Parallel()
+	settings, err := daemonOptions{WithReloadSignal(nil)}.make()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig := settings.reloadSignal; sig != nil {
+		t.Errorf("expected reloading to be disabled, got signal: %v", sig)
+	}
+	settings, err = daemonOptions{WithReloadSignal(os.Interrupt)}.make()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := settings.reloadSignal, os.Interrupt; got != want {
+		t.Errorf("signal mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, want,
+		)
+	}
+}
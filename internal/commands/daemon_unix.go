@@ -9,6 +9,19 @@ import (
 	"syscall"
 )
 
+// reloadSignalDefault is the name of
+// the signal which reloads the daemon.
+const reloadSignalDefault = "hup"
+
+// reloadSignals maps the values accepted
+// by the daemon's reload flag to signals.
+var reloadSignals = map[string]os.Signal{
+	reloadSignalNone: nil,
+	"hup":            syscall.SIGHUP,
+	"usr1":           syscall.SIGUSR1,
+	"usr2":           syscall.SIGUSR2,
+}
+
 func registerSystemStoppers(_ context.Context, shutdownSend wgShutdown) {
 	shutdownSend.Add(2)
 	go stopOnSignalLinear(shutdownSend, os.Interrupt)
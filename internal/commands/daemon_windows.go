@@ -38,6 +38,18 @@ import (
 
 type wndProcFunc func(win.HWND, uint32, uintptr, uintptr) uintptr
 
+// reloadSignalDefault is the name of
+// the signal which reloads the daemon.
+// Windows has no equivalent of `SIGHUP`,
+// so reloading is disabled.
+const reloadSignalDefault = reloadSignalNone
+
+// reloadSignals maps the values accepted
+// by the daemon's reload flag to signals.
+var reloadSignals = map[string]os.Signal{
+	reloadSignalNone: nil,
+}
+
 func registerSystemStoppers(ctx context.Context, shutdownSend wgShutdown) {
 	shutdownSend.Add(2)
 	// NOTE: [Go 1.20] This must be `syscall.SIGTERM`
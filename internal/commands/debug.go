@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/p9/p9"
+)
+
+type (
+	// DebugTreeEntry is the format of each
+	// file within the service's file tree.
+	DebugTreeEntry struct {
+		Name     string           `json:"name"`
+		Type     string           `json:"type"`
+		Size     uint64           `json:"size"`
+		Children []DebugTreeEntry `json:"children,omitempty"`
+	}
+	debugTreeSettings struct {
+		clientSettings
+		depth int
+		json  bool
+	}
+	debugTreeOption  func(*debugTreeSettings) error
+	debugTreeOptions []debugTreeOption
+)
+
+// Debug constructs the command which
+// inspects the internals of the file system service.
+func Debug() command.Command {
+	const (
+		name     = "debug"
+		synopsis = "Inspect the system service (for debugging)."
+	)
+	return command.SubcommandGroup(name, synopsis, makeDebugSubcommands())
+}
+
+func makeDebugSubcommands() []command.Command {
+	return []command.Command{
+		makeDebugTreeCommand(),
+	}
+}
+
+func makeDebugTreeCommand() command.Command {
+	const (
+		name     = "tree"
+		synopsis = "Print the service's 9P file tree."
+	)
+	usage := header("Debug tree") +
+		"\n\n" + synopsis +
+		"\nThis is a debugging aid; the structure of" +
+		"\nthe tree is internal and may change at any time."
+	return command.MakeVariadicCommand[debugTreeOptions](name, synopsis, usage, debugTreeExecute)
+}
+
+func (do *debugTreeOptions) BindFlags(flagSet *flag.FlagSet) {
+	var clientOptions clientOptions
+	(&clientOptions).BindFlags(flagSet)
+	*do = append(*do, func(ds *debugTreeSettings) error {
+		subset, err := clientOptions.make()
+		if err != nil {
+			return err
+		}
+		ds.clientSettings = subset
+		return nil
+	})
+	const (
+		depthName  = "depth"
+		depthUsage = "maximum number of directory `levels` to descend" +
+			"\nif <= 0, the whole tree is printed"
+	)
+	flagSetFunc(flagSet, depthName, depthUsage, do,
+		func(value int, settings *debugTreeSettings) error {
+			settings.depth = value
+			return nil
+		})
+	const (
+		jsonName  = "json"
+		jsonUsage = "print the tree as JSON"
+	)
+	flagSetFunc(flagSet, jsonName, jsonUsage, do,
+		func(value bool, settings *debugTreeSettings) error {
+			settings.json = value
+			return nil
+		})
+}
+
+func (do debugTreeOptions) make() (debugTreeSettings, error) {
+	return makeWithOptions(do...)
+}
+
+func debugTreeExecute(ctx context.Context, options ...debugTreeOption) error {
+	settings, err := debugTreeOptions(options).make()
+	if err != nil {
+		return err
+	}
+	const autoLaunchDaemon = false
+	client, err := settings.getClient(autoLaunchDaemon)
+	if err != nil {
+		return fmt.Errorf("could not get client (server down?): %w", err)
+	}
+	entries, err := client.DebugTree(ctx, settings.depth)
+	if err != nil {
+		return errors.Join(err, client.Close())
+	}
+	if err := client.Close(); err != nil {
+		return err
+	}
+	if settings.json {
+		err = json.NewEncoder(os.Stdout).Encode(entries)
+	} else {
+		err = printDebugTree(os.Stdout, entries)
+	}
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// DebugTree retrieves the service's file tree,
+// up to `depth` directories deep (if > 0).
+func (c *Client) DebugTree(ctx context.Context, depth int) ([]DebugTreeEntry, error) {
+	root, err := (*p9.Client)(c).Attach("")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readDebugTree(ctx, root, depth)
+	if err != nil {
+		return nil, errors.Join(err, root.Close())
+	}
+	return entries, root.Close()
+}
+
+func readDebugTree(ctx context.Context, root p9.File, depth int) ([]DebugTreeEntry, error) {
+	tree, err := p9fs.ReadTree(ctx, root, depth)
+	if err != nil {
+		return nil, err
+	}
+	return makeDebugTree(tree), nil
+}
+
+func makeDebugTree(tree []p9fs.TreeEntry) []DebugTreeEntry {
+	if tree == nil {
+		return nil
+	}
+	entries := make([]DebugTreeEntry, len(tree))
+	for i, entry := range tree {
+		entries[i] = DebugTreeEntry{
+			Name:     entry.Name,
+			Type:     debugTypeName(entry.Type),
+			Size:     entry.Size,
+			Children: makeDebugTree(entry.Children),
+		}
+	}
+	return entries
+}
+
+func debugTypeName(typ p9.QIDType) string {
+	switch {
+	case typ&p9.TypeDir != 0:
+		return "directory"
+	case typ&p9.TypeSymlink != 0:
+		return "symlink"
+	default:
+		return "file"
+	}
+}
+
+func printDebugTree(output io.Writer, entries []DebugTreeEntry) error {
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	tabWriter := tabwriter.NewWriter(
+		output, minWidth, tabWidth, padding, padChar, flags,
+	)
+	if err := printDebugEntries(tabWriter, entries, 0); err != nil {
+		return err
+	}
+	return tabWriter.Flush()
+}
+
+func printDebugEntries(output io.Writer, entries []DebugTreeEntry, level int) error {
+	const indent = "  "
+	for _, entry := range entries {
+		name := strings.Repeat(indent, level) + entry.Name
+		if entry.Type == "directory" {
+			name += "/"
+		}
+		if _, err := fmt.Fprintf(output,
+			"%s\t%s\t%d\n", name, entry.Type, entry.Size,
+		); err != nil {
+			return err
+		}
+		if err := printDebugEntries(output, entry.Children, level+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
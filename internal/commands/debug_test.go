@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/djdv/p9/p9"
+)
+
+func TestDebugTree(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	const healthThreshold = 3
+	system, err := newFileSystem(ctx, p9.NoUID, p9.NoGID, healthThreshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("full", func(t *testing.T) {
+		t.Parallel()
+		entries, err := readDebugTree(ctx, system.root, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		debugNamesMatch(t, entries,
+			controlFileName, listenersFileName, mountsFileName,
+		)
+		control := entries[0]
+		if control.Type != "directory" {
+			t.Errorf("%s type mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				control.Name, control.Type, "directory",
+			)
+		}
+		debugNamesMatch(t, control.Children,
+			shutdownFileName, statusFileName,
+		)
+		var output strings.Builder
+		if err := printDebugTree(&output, entries); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range []string{
+			controlFileName + "/",
+			"  " + statusFileName + " ",
+		} {
+			if !strings.Contains(output.String(), line) {
+				t.Errorf("output is missing %q\n%s",
+					line, output.String(),
+				)
+			}
+		}
+	})
+	t.Run("depth", func(t *testing.T) {
+		t.Parallel()
+		const depth = 1
+		entries, err := readDebugTree(ctx, system.root, depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		debugNamesMatch(t, entries,
+			controlFileName, listenersFileName, mountsFileName,
+		)
+		for _, entry := range entries {
+			if entry.Children != nil {
+				t.Errorf("%s has children beyond depth %d: %v",
+					entry.Name, depth, entry.Children,
+				)
+			}
+		}
+	})
+}
+
+func debugNamesMatch(t *testing.T, entries []DebugTreeEntry, want ...string) {
+	t.Helper()
+	got := make([]string, len(entries))
+	for i, entry := range entries {
+		got[i] = entry.Name
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("entry mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, want,
+		)
+	}
+}
@@ -76,6 +76,8 @@ const (
 	permSymText    = 't'
 )
 
+const errNotPositive = generic.ConstError("must be positive")
+
 func makeWithOptions[OT generic.OptionFunc[T], T any](options ...OT) (T, error) {
 	var settings T
 	return settings, generic.ApplyOptions(&settings, options...)
@@ -484,6 +486,11 @@ func underline(text string) string {
 	)
 }
 
+// flagSetFunc registers a flag whose value is parsed as `VT`
+// and passed to `setter` when the options are applied.
+// Validators (if any) are called with the value
+// when it is parsed, so that the [flag] package
+// reports their errors along with the flag's name.
 func flagSetFunc[
 	OSR optionsReference[OS, OT, ST],
 	OS optionSlice[OT, ST],
@@ -491,21 +498,30 @@ func flagSetFunc[
 	setterFn func(VT, *ST) error,
 	ST, VT any,
 ](flagSet *flag.FlagSet, name, usage string,
-	options OSR, setter setterFn,
+	options OSR, setter setterFn, validators ...func(VT) error,
 ) {
 	// `bool` flags don't require a value and this
 	// must be conveyed to the [flag] package.
 	if _, ok := any(setter).(func(bool, *ST) error); ok {
 		boolFunc(flagSet, name, usage, func(parameter string) error {
-			return parseAndSet(parameter, options, setter)
+			return parseAndSet(name, parameter, options, setter, validators)
 		})
 		return
 	}
 	funcFlag[VT](flagSet, name, usage, func(parameter string) error {
-		return parseAndSet(parameter, options, setter)
+		return parseAndSet(name, parameter, options, setter, validators)
 	})
 }
 
+// validatePositive may be used as a
+// validator for counting flags.
+func validatePositive(value int) error {
+	if value < 1 {
+		return errNotPositive
+	}
+	return nil
+}
+
 func funcFlag[T any](flagSet *flag.FlagSet, name, usage string, fn func(string) error) {
 	flagSet.Var(genericFuncValue[T](fn), name, usage)
 }
@@ -516,14 +532,30 @@ func parseAndSet[
 	OT generic.OptionFunc[ST],
 	setterFn func(VT, *ST) error,
 	ST, VT any,
-](parameter string, options OSR, setter setterFn,
+](name, parameter string, options OSR, setter setterFn,
+	validators []func(VT) error,
 ) error {
 	value, err := parseFlag[VT](parameter)
 	if err != nil {
 		return err
 	}
+	for _, validate := range validators {
+		if err := validate(value); err != nil {
+			return err
+		}
+	}
 	*options = append(*options, func(settings *ST) error {
-		return setter(value, settings)
+		if err := setter(value, settings); err != nil {
+			// Setters are deferred until the options
+			// are applied, so the [flag] package can't
+			// annotate their errors for us.
+			// Mimic its format instead.
+			return fmt.Errorf(
+				"invalid value %q for flag -%s: %w",
+				parameter, name, err,
+			)
+		}
+		return nil
 	})
 	return nil
 }
@@ -542,8 +574,14 @@ func parseFlag[V any](parameter string) (value V, err error) {
 		*typed, err = multiaddr.NewMultiaddr(parameter)
 	case *shutdownDisposition:
 		*typed, err = parseShutdownLevel(parameter)
+	case *entryType:
+		*typed, err = parseEntryType(parameter)
+	case *mountsEncoding:
+		*typed, err = parseMountsEncoding(parameter)
 	case *int:
 		*typed, err = strconv.Atoi(parameter)
+	case *int64:
+		*typed, err = strconv.ParseInt(parameter, 0, 64)
 	case *fuseID:
 		*typed, err = parseID[fuseID](parameter)
 	case *p9.UID:
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFlagSetterErr(t *testing.T) {
+	t.Parallel()
+	type (
+		settings struct{ count int }
+		option   func(*settings) error
+		options  []option
+	)
+	const (
+		name  = "count"
+		value = "-1"
+	)
+	var (
+		opts    options
+		flagSet = flag.NewFlagSet(t.Name(), flag.ContinueOnError)
+		wantErr = errors.New("must be positive")
+	)
+	flagSetFunc(flagSet, name, "", &opts,
+		func(value int, settings *settings) error {
+			if value < 0 {
+				return wantErr
+			}
+			settings.count = value
+			return nil
+		})
+	if err := flagSet.Parse([]string{"-" + name, value}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := makeWithOptions(opts...)
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, wantErr,
+		)
+	}
+	for _, want := range []string{"-" + name, value} {
+		if got := err.Error(); !strings.Contains(got, want) {
+			t.Errorf("error does not mention \"%s\""+
+				"\ngot: %s",
+				want, got,
+			)
+		}
+	}
+}
+
+func TestFlagValidator(t *testing.T) {
+	t.Parallel()
+	type (
+		settings struct{ count int }
+		option   func(*settings) error
+		options  []option
+	)
+	const (
+		name  = "count"
+		value = "0"
+	)
+	var (
+		opts    options
+		flagSet = flag.NewFlagSet(t.Name(), flag.ContinueOnError)
+	)
+	flagSet.SetOutput(io.Discard)
+	flagSetFunc(flagSet, name, "", &opts,
+		func(value int, settings *settings) error {
+			settings.count = value
+			return nil
+		}, validatePositive)
+	err := flagSet.Parse([]string{"-" + name, value})
+	if err == nil {
+		t.Fatal("expected validator to reject value")
+	}
+	for _, want := range []string{
+		"-" + name, value, errNotPositive.Error(),
+	} {
+		if got := err.Error(); !strings.Contains(got, want) {
+			t.Errorf("error does not mention \"%s\""+
+				"\ngot: %s",
+				want, got,
+			)
+		}
+	}
+	if len(opts) != 0 {
+		t.Error("rejected value was added to options")
+	}
+}
@@ -0,0 +1,424 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	getSettings[M fsMaker] struct {
+		guest    M
+		workers  int
+		existing existPolicy
+		progress bool
+	}
+	getOption[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] func(*getSettings[GM]) error
+	getOptions[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] []getOption[GT, GM, GC]
+	// existPolicy determines what happens when
+	// a destination file already exists.
+	existPolicy uint8
+	// exportJob is a regular file
+	// to be copied by a worker.
+	exportJob struct {
+		name, target string
+		mode         fs.FileMode
+	}
+	exportResult struct {
+		err     error
+		name    string
+		written int64
+	}
+	// exportedDir is a directory created during an export;
+	// its permissions are applied after its contents are written.
+	exportedDir struct {
+		target string
+		mode   fs.FileMode
+	}
+)
+
+const (
+	existFail existPolicy = iota
+	existSkip
+	existOverwrite
+)
+
+const (
+	getWorkersDefault = 8
+	errGetMixed       = generic.ConstError(`cannot combine "skip" option with "overwrite" option`)
+	errGetLink        = generic.ConstError("file system does not support reading symbolic links")
+)
+
+// Get constructs the command which copies
+// guest files to the local file system.
+func Get() command.Command {
+	const (
+		name     = "get"
+		synopsis = "Copy guest files to the local file system."
+	)
+	return makeGuestCommandGroup(name, synopsis, makeIPFSGetCommands())
+}
+
+func makeGetCommand[
+	GC fsCmdGuest[GT, GM],
+	GM fsMaker,
+	GT any,
+](guest filesystem.ID,
+) command.Command {
+	type (
+		GO  = getOption[GT, GM, GC]
+		GOS = getOptions[GT, GM, GC]
+	)
+	var (
+		guestFormalName = string(guest)
+		cmdName         = strings.ToLower(guestFormalName)
+		synopsis        = fmt.Sprintf(
+			"Copy %s files to the local file system.", guestFormalName,
+		)
+		usage = guestCommandUsage[GC](guest, synopsis,
+			"copies the provided path (relative to the guest's root)"+
+				"\ninto the local directory, preserving its structure,"+
+				" permissions, and symbolic links."+
+				"\nThe contents of directories are copied into the local directory,"+
+				" while files are copied to a file of the same name within it."+
+				"\nExisting files are not replaced unless requested.",
+		)
+	)
+	return command.MakeVariadicCommand[GOS](cmdName, synopsis, usage,
+		func(ctx context.Context, arguments []string, options ...GO) error {
+			if err := checkGuestArguments(arguments, 2, 2,
+				"guest path and local directory",
+			); err != nil {
+				return err
+			}
+			settings, err := GOS(options).make()
+			if err != nil {
+				return err
+			}
+			if err := withGuestFS(settings.guest, func(fsys fs.FS) error {
+				var (
+					reporter     *progressReporter
+					stopProgress func() error
+				)
+				if settings.progress {
+					reporter, stopProgress = startProgress(false)
+				}
+				err := exportTree(ctx, fsys,
+					guestPath(arguments[0]), arguments[1],
+					settings.workers, settings.existing, reporter,
+				)
+				if stopProgress != nil {
+					err = errors.Join(err, stopProgress())
+				}
+				return err
+			}); err != nil {
+				return err
+			}
+			return ctx.Err()
+		})
+}
+
+func (gop *getOptions[GT, GM, GC]) BindFlags(flagSet *flag.FlagSet) {
+	type settings = getSettings[GM]
+	bindGuestFlags[GC](flagSet, gop, func(guest GM, gs *settings) {
+		gs.guest = guest
+	})
+	const (
+		workersName  = "workers"
+		workersUsage = "maximum `count` of files to copy concurrently"
+	)
+	flagSetFunc(flagSet, workersName, workersUsage, gop,
+		func(value int, gs *settings) error {
+			gs.workers = value
+			return nil
+		}, validatePositive)
+	flagSet.Lookup(workersName).
+		DefValue = strconv.Itoa(getWorkersDefault)
+	const (
+		skipName  = "skip"
+		skipUsage = "skip files which already exist locally"
+	)
+	flagSetFunc(flagSet, skipName, skipUsage, gop,
+		func(value bool, gs *settings) error {
+			return gs.setExisting(existSkip, value)
+		})
+	const (
+		overwriteName  = "overwrite"
+		overwriteUsage = "replace files which already exist locally"
+	)
+	flagSetFunc(flagSet, overwriteName, overwriteUsage, gop,
+		func(value bool, gs *settings) error {
+			return gs.setExisting(existOverwrite, value)
+		})
+	const (
+		progressName  = "progress"
+		progressUsage = "report progress to stderr while copying"
+	)
+	flagSetFunc(flagSet, progressName, progressUsage, gop,
+		func(value bool, gs *settings) error {
+			gs.progress = value
+			return nil
+		})
+}
+
+func (gs *getSettings[M]) setExisting(policy existPolicy, enabled bool) error {
+	if !enabled {
+		if gs.existing == policy {
+			gs.existing = existFail
+		}
+		return nil
+	}
+	if gs.existing != existFail && gs.existing != policy {
+		return errGetMixed
+	}
+	gs.existing = policy
+	return nil
+}
+
+func (gop getOptions[GT, GM, GC]) make() (getSettings[GM], error) {
+	settings := getSettings[GM]{
+		workers: getWorkersDefault,
+	}
+	return settings, generic.ApplyOptions(&settings, gop...)
+}
+
+// exportTree copies `root` (recursively) from `fsys` into
+// the local `destination` directory (creating it if needed).
+// Regular files are copied by up to `workers` goroutines,
+// and progress is reported for each file copied.
+func exportTree(ctx context.Context, fsys fs.FS, root, destination string,
+	workers int, existing existPolicy, reporter *progressReporter,
+) error {
+	if err := os.MkdirAll(destination, 0o755); err != nil {
+		return err
+	}
+	var (
+		exportCtx, cancel = context.WithCancel(ctx)
+		jobs              = make(chan exportJob)
+		results           = make(chan exportResult)
+		copyErr           = make(chan error, 1)
+		wg                sync.WaitGroup
+	)
+	defer cancel()
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				written, err := exportFile(fsys, job, existing)
+				results <- exportResult{
+					name:    job.name,
+					written: written,
+					err:     err,
+				}
+			}
+		}()
+	}
+	go func() {
+		var errs []error
+		for result := range results {
+			if err := result.err; err != nil {
+				errs = append(errs, err)
+				cancel()
+				continue
+			}
+			reporter.add(result.name, result.written, 1)
+		}
+		copyErr <- errors.Join(errs...)
+	}()
+	directories, walkErr := exportWalk(exportCtx, fsys, root, destination, existing, jobs)
+	close(jobs)
+	wg.Wait()
+	close(results)
+	if errors.Is(walkErr, context.Canceled) && ctx.Err() == nil {
+		walkErr = nil // Canceled by a copy error.
+	}
+	err := errors.Join(walkErr, <-copyErr)
+	// Deepest directories first, so that read-only
+	// permissions don't prevent changes to children.
+	for i := len(directories) - 1; i >= 0; i-- {
+		dir := directories[i]
+		err = errors.Join(err, os.Chmod(dir.target, dir.mode))
+	}
+	return err
+}
+
+// exportWalk creates the directories and links within `root`,
+// and sends regular files to be copied.
+// Directories that were created are returned
+// in the order they were walked.
+func exportWalk(ctx context.Context, fsys fs.FS, root, destination string,
+	existing existPolicy, jobs chan<- exportJob,
+) ([]exportedDir, error) {
+	var directories []exportedDir
+	err := fs.WalkDir(fsys, root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		target := exportTarget(root, name, destination, entry.IsDir())
+		switch typ := entry.Type(); {
+		case typ.IsDir():
+			if name == root {
+				return nil // Destination already exists.
+			}
+			skip, created, err := exportDirectory(target, existing)
+			if err != nil {
+				return err
+			}
+			if skip {
+				return fs.SkipDir
+			}
+			if !created {
+				return nil // Merged with an existing directory.
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			directories = append(directories, exportedDir{
+				target: target,
+				mode:   info.Mode().Perm(),
+			})
+			return nil
+		case typ&fs.ModeSymlink != 0:
+			return exportLink(fsys, name, target, existing)
+		case typ.IsRegular():
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			select {
+			case jobs <- exportJob{
+				name:   name,
+				target: target,
+				mode:   info.Mode().Perm(),
+			}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		default:
+			return nil // Devices, pipes, etc. are not copied.
+		}
+	})
+	return directories, err
+}
+
+// exportTarget returns the local path for `name`.
+func exportTarget(root, name, destination string, isDir bool) string {
+	if name == root {
+		if isDir {
+			return destination
+		}
+		return filepath.Join(destination, path.Base(name))
+	}
+	relative := name
+	if root != filesystem.Root {
+		relative = strings.TrimPrefix(name, root+"/")
+	}
+	return filepath.Join(destination, filepath.FromSlash(relative))
+}
+
+// prepareTarget handles an existing file at `target`
+// according to the policy. If the target should
+// not be written to, `skip` is true.
+func prepareTarget(target string, existing existPolicy) (skip bool, err error) {
+	if _, err := os.Lstat(target); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return false, err
+	}
+	switch existing {
+	case existSkip:
+		return true, nil
+	case existOverwrite:
+		return false, os.Remove(target)
+	default:
+		return false, &fs.PathError{Op: "get", Path: target, Err: fs.ErrExist}
+	}
+}
+
+// exportDirectory creates the directory at `target`,
+// or merges with an existing directory.
+// If the contents of the directory should
+// not be written, `skip` is true.
+func exportDirectory(target string, existing existPolicy) (skip, created bool, err error) {
+	if info, err := os.Lstat(target); err == nil && info.IsDir() {
+		return false, false, nil
+	}
+	if skip, err = prepareTarget(target, existing); skip || err != nil {
+		return skip, false, err
+	}
+	// Writable until the export is done.
+	const permissions = 0o700
+	if err := os.Mkdir(target, permissions); err != nil {
+		return false, false, err
+	}
+	return false, true, nil
+}
+
+func exportLink(fsys fs.FS, name, target string, existing existPolicy) error {
+	linker, ok := filesystem.Extension[filesystem.ReadlinkFS](fsys)
+	if !ok {
+		return &fs.PathError{Op: "readlink", Path: name, Err: errGetLink}
+	}
+	link, err := linker.Readlink(name)
+	if err != nil {
+		return err
+	}
+	skip, err := prepareTarget(target, existing)
+	if skip || err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
+
+// exportFile streams the contents of the
+// job's file to its target.
+func exportFile(fsys fs.FS, job exportJob, existing existPolicy) (int64, error) {
+	skip, err := prepareTarget(job.target, existing)
+	if skip || err != nil {
+		return 0, err
+	}
+	source, err := fsys.Open(job.name)
+	if err != nil {
+		return 0, err
+	}
+	// NOTE: Exclusive creation detects names which
+	// collide locally (e.g. on case-insensitive systems).
+	const flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	target, err := os.OpenFile(job.target, flags, job.mode)
+	if err != nil {
+		if existing == existSkip && errors.Is(err, fs.ErrExist) {
+			err = nil
+		}
+		return 0, errors.Join(err, source.Close())
+	}
+	written, err := io.Copy(target, source)
+	return written, errors.Join(err, target.Close(), source.Close())
+}
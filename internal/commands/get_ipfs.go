@@ -0,0 +1,17 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+)
+
+func makeIPFSGetCommands() []command.Command {
+	return []command.Command{
+		makeGetCommand[*ipfsOptions, ipfsSettings](ipfs.IPFSID),
+		makeGetCommand[*pinFSOptions, pinFSSettings](ipfs.PinFSID),
+		makeGetCommand[*ipnsOptions, ipnsSettings](ipfs.IPNSID),
+		makeGetCommand[*keyFSOptions, keyFSSettings](ipfs.KeyFSID),
+	}
+}
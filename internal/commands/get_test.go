@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+// exportMapFS reads the targets of
+// symbolic links from their data.
+type exportMapFS struct{ fstest.MapFS }
+
+func (em exportMapFS) Readlink(name string) (string, error) {
+	file, ok := em.MapFS[name]
+	if !ok || file.Mode.Type() != fs.ModeSymlink {
+		return "", fs.ErrInvalid
+	}
+	return string(file.Data), nil
+}
+
+func TestExportTree(t *testing.T) {
+	t.Parallel()
+	fsys := exportMapFS{
+		MapFS: fstest.MapFS{
+			"root":            {Mode: fs.ModeDir | 0o755},
+			"root/file":       {Data: []byte("file"), Mode: 0o640},
+			"root/sub":        {Mode: fs.ModeDir | 0o555},
+			"root/sub/nested": {Data: []byte("nested"), Mode: 0o444},
+			"root/link":       {Data: []byte("file"), Mode: fs.ModeSymlink | 0o777},
+			"other":           {Data: []byte("other"), Mode: 0o644},
+		},
+	}
+	const workers = 2
+	export := func(t *testing.T, root, destination string, existing existPolicy) error {
+		t.Helper()
+		return exportTree(context.Background(), fsys,
+			root, destination, workers, existing, nil,
+		)
+	}
+	t.Run("tree", func(t *testing.T) {
+		t.Parallel()
+		destination := filepath.Join(t.TempDir(), "export")
+		if err := export(t, "root", destination, existFail); err != nil {
+			t.Fatal(err)
+		}
+		exportedFileMatch(t, destination, "file", "file", 0o640)
+		exportedFileMatch(t, destination, "sub/nested", "nested", 0o444)
+		subPath := filepath.Join(destination, "sub")
+		t.Cleanup(func() {
+			// Allow the temporary directory to be removed.
+			if err := os.Chmod(subPath, 0o755); err != nil {
+				t.Error(err)
+			}
+		})
+		exportedModeMatch(t, subPath, 0o555)
+		if runtime.GOOS != "windows" {
+			target, err := os.Readlink(filepath.Join(destination, "link"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if target != "file" {
+				t.Errorf("link target mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					target, "file",
+				)
+			}
+		}
+		if _, err := os.Lstat(filepath.Join(destination, "other")); err == nil {
+			t.Error("file outside of root was exported")
+		}
+	})
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		destination := t.TempDir()
+		if err := export(t, "other", destination, existFail); err != nil {
+			t.Fatal(err)
+		}
+		exportedFileMatch(t, destination, "other", "other", 0o644)
+	})
+	t.Run("existing", func(t *testing.T) {
+		t.Parallel()
+		var (
+			destination = t.TempDir()
+			filePath    = filepath.Join(destination, "other")
+		)
+		if err := os.WriteFile(filePath, []byte("local"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := export(t, "other", destination, existFail); !errors.Is(err, fs.ErrExist) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, fs.ErrExist,
+			)
+		}
+		if err := export(t, "other", destination, existSkip); err != nil {
+			t.Fatal(err)
+		}
+		exportedFileMatch(t, destination, "other", "local", 0o600)
+		if err := export(t, "other", destination, existOverwrite); err != nil {
+			t.Fatal(err)
+		}
+		exportedFileMatch(t, destination, "other", "other", 0o644)
+	})
+}
+
+func exportedFileMatch(t *testing.T, destination, name, want string, mode fs.FileMode) {
+	t.Helper()
+	filePath := filepath.Join(destination, filepath.FromSlash(name))
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != want {
+		t.Errorf("\"%s\" data mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			name, got, want,
+		)
+	}
+	exportedModeMatch(t, filePath, mode)
+}
+
+func exportedModeMatch(t *testing.T, filePath string, want fs.FileMode) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		return // Only the read-only bit is stored.
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != want {
+		t.Errorf("\"%s\" mode mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			filePath, got, want,
+		)
+	}
+}
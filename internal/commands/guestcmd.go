@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	// fsMaker constructs a guest file system directly,
+	// rather than through the file system service.
+	fsMaker interface {
+		makeFS() (fs.FS, error)
+	}
+	// fsCmdGuest is the constraint for guest options
+	// of commands which construct guests directly.
+	fsCmdGuest[T any, M fsMaker] interface {
+		*T
+		command.FlagBinder
+		make() (M, error)
+		usage(filesystem.Host) string
+	}
+)
+
+// makeGuestCommandGroup returns a group of the guest
+// subcommands; or if there are none, a command which
+// explains that no guests were built in.
+func makeGuestCommandGroup(name, synopsis string, guests []command.Command) command.Command {
+	if len(guests) != 0 {
+		sortCommands(guests)
+		return command.SubcommandGroup(name, synopsis, guests)
+	}
+	const usage = "No guest APIs were built into this executable."
+	return command.MakeNiladicCommand(
+		name, synopsis, usage,
+		func(ctx context.Context) error {
+			return command.UsageError{
+				Err: generic.ConstError("no guest systems"),
+			}
+		},
+	)
+}
+
+// guestCommandUsage returns the usage text for a command
+// which constructs the guest directly.
+// `action` describes what is done with the guest.
+func guestCommandUsage[
+	GC fsCmdGuest[GT, GM],
+	GM fsMaker,
+	GT any,
+](guest filesystem.ID, synopsis, action string,
+) string {
+	return header(synopsis) + "\n\n" +
+		"Constructs the guest (without mounting it), and " + action +
+		"\n\n" + underline(string(guest)) + "\n" +
+		GC(nil).usage("")
+}
+
+// checkGuestArguments returns a usage error if the count
+// of arguments is not within [minimum, maximum].
+// A negative maximum allows any count above the minimum.
+// `expected` describes the arguments to the user.
+func checkGuestArguments(arguments []string, minimum, maximum int, expected string) error {
+	count := len(arguments)
+	if count >= minimum &&
+		(maximum < 0 || count <= maximum) {
+		return nil
+	}
+	return command.UsageError{
+		Err: fmt.Errorf(
+			"expected %s, got %d arguments",
+			expected, count,
+		),
+	}
+}
+
+// bindGuestFlags binds the guest's flags to the flag set,
+// and appends an option which passes the guest's
+// constructor to `setter`.
+func bindGuestFlags[
+	GC fsCmdGuest[GT, GM],
+	OSR optionsReference[OS, OT, ST],
+	OS optionSlice[OT, ST],
+	OT generic.OptionFunc[ST],
+	GM fsMaker,
+	GT, ST any,
+](flagSet *flag.FlagSet, options OSR, setter func(GM, *ST),
+) {
+	var guest GC = new(GT)
+	guest.BindFlags(flagSet)
+	*options = append(*options, func(settings *ST) error {
+		maker, err := guest.make()
+		if err != nil {
+			return err
+		}
+		setter(maker, settings)
+		return nil
+	})
+}
+
+// withGuestFS constructs the guest's file system,
+// calls `fn` with it, then closes it (if needed).
+func withGuestFS(guest fsMaker, fn func(fs.FS) error) error {
+	fsys, err := guest.makeFS()
+	if err != nil {
+		return err
+	}
+	err = fn(fsys)
+	if closer, ok := fsys.(io.Closer); ok {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// guestPath converts (slash prefixed) paths
+// from the command line to [fs.ValidPath] form.
+func guestPath(name string) string {
+	if fs.ValidPath(name) {
+		return name
+	}
+	return path.Clean(strings.TrimPrefix(name, "/"))
+}
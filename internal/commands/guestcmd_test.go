@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+)
+
+func TestCheckGuestArguments(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name             string
+		arguments        []string
+		minimum, maximum int
+		valid            bool
+	}{
+		{name: "exact", arguments: []string{"a"}, minimum: 1, maximum: 1, valid: true},
+		{name: "too few", minimum: 1, maximum: 1},
+		{name: "too many", arguments: []string{"a", "b"}, minimum: 1, maximum: 1},
+		{name: "optional", minimum: 0, maximum: 1, valid: true},
+		{name: "unbounded", arguments: []string{"a", "b", "c"}, minimum: 1, maximum: -1, valid: true},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := checkGuestArguments(test.arguments,
+				test.minimum, test.maximum, "arguments",
+			)
+			if test.valid {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			var usageErr command.UsageError
+			if !errors.As(err, &usageErr) {
+				t.Errorf("error mismatch"+
+					"\ngot: %v"+
+					"\nwant: %T",
+					err, usageErr,
+				)
+			}
+		})
+	}
+}
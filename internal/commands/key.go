@@ -0,0 +1,29 @@
+package commands
+
+import "github.com/djdv/go-filesystem-utils/internal/command"
+
+// Key constructs the command which
+// manages the IPFS node's (IPNS) keys.
+func Key() command.Command {
+	const (
+		name     = "key"
+		synopsis = "Manage IPNS keys."
+	)
+	return command.SubcommandGroup(name, synopsis, makeKeySubcommands())
+}
+
+func makeKeySubcommands() []command.Command {
+	type makeCommand func() command.Command
+	var (
+		commandMakers = []makeCommand{
+			makeKeyListCommand,
+			makeKeyGenerateCommand,
+			makeKeyRemoveCommand,
+		}
+		commands = make([]command.Command, 0, len(commandMakers))
+	)
+	for _, makeCommand := range commandMakers {
+		commands = append(commands, makeCommand())
+	}
+	return commands
+}
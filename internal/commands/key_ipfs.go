@@ -0,0 +1,200 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	keySettings struct {
+		guest   ipfs.KeyFSGuest
+		keyType string
+		json    bool
+	}
+	keyOption  func(*keySettings) error
+	keyOptions []keyOption
+	// keyGenerateOptions extends [keyOptions]
+	// with flags specific to key generation.
+	keyGenerateOptions []keyOption
+)
+
+func makeKeyListCommand() command.Command {
+	const (
+		name     = "list"
+		synopsis = "List keys."
+	)
+	usage := header("Key list") +
+		"\n\n" + synopsis +
+		"\nPrints the name and peer ID of each key" +
+		" stored by the IPFS node."
+	return command.MakeVariadicCommand[keyOptions](name, synopsis, usage,
+		func(ctx context.Context, options ...keyOption) error {
+			settings, err := keyOptions(options).make()
+			if err != nil {
+				return err
+			}
+			keys, err := settings.guest.ListKeys(ctx)
+			if err != nil {
+				return err
+			}
+			return printKeys(os.Stdout, keys, settings.json)
+		})
+}
+
+func makeKeyGenerateCommand() command.Command {
+	const (
+		name     = "gen"
+		synopsis = "Generate a key."
+	)
+	usage := header("Key generate") +
+		"\n\n" + synopsis +
+		"\nCreates a new key with the provided name," +
+		" and prints its name and peer ID."
+	return command.MakeVariadicCommand[keyGenerateOptions](name, synopsis, usage,
+		func(ctx context.Context, arguments []string, options ...keyOption) error {
+			keyName, err := keyNameArgument(arguments)
+			if err != nil {
+				return err
+			}
+			settings, err := keyGenerateOptions(options).make()
+			if err != nil {
+				return err
+			}
+			var generateOptions []ipfs.KeyGenerateOption
+			if keyType := settings.keyType; keyType != "" {
+				generateOptions = append(generateOptions,
+					ipfs.WithKeyType(keyType),
+				)
+			}
+			key, err := settings.guest.GenerateKey(ctx, keyName, generateOptions...)
+			if err != nil {
+				return err
+			}
+			return printKeys(os.Stdout, []ipfs.KeyInfo{key}, settings.json)
+		})
+}
+
+func makeKeyRemoveCommand() command.Command {
+	const (
+		name     = "rm"
+		synopsis = "Remove a key."
+	)
+	usage := header("Key remove") +
+		"\n\n" + synopsis +
+		"\nRemoves the key with the provided name," +
+		" and prints its name and peer ID." +
+		"\nThe node's own key (\"" + ipfs.SelfKeyName + "\")" +
+		" can not be removed."
+	return command.MakeVariadicCommand[keyOptions](name, synopsis, usage,
+		func(ctx context.Context, arguments []string, options ...keyOption) error {
+			keyName, err := keyNameArgument(arguments)
+			if err != nil {
+				return err
+			}
+			settings, err := keyOptions(options).make()
+			if err != nil {
+				return err
+			}
+			key, err := settings.guest.RemoveKey(ctx, keyName)
+			if err != nil {
+				return err
+			}
+			return printKeys(os.Stdout, []ipfs.KeyInfo{key}, settings.json)
+		})
+}
+
+func keyNameArgument(arguments []string) (string, error) {
+	if len(arguments) != 1 {
+		return "", command.UsageError{
+			Err: fmt.Errorf(
+				"expected 1 key name, got %d",
+				len(arguments),
+			),
+		}
+	}
+	return arguments[0], nil
+}
+
+func (ko *keyOptions) BindFlags(flagSet *flag.FlagSet) {
+	var ipfsOptions ipfsOptions
+	(&ipfsOptions).bindFlagsVarient(ipfs.KeyFSID, flagSet)
+	*ko = append(*ko, func(settings *keySettings) error {
+		subset, err := ipfsOptions.make()
+		if err != nil {
+			return err
+		}
+		settings.guest.IPFSGuest = ipfs.IPFSGuest(subset)
+		return nil
+	})
+	const (
+		jsonName  = "json"
+		jsonUsage = "print keys as JSON"
+	)
+	flagSetFunc(flagSet, jsonName, jsonUsage, ko,
+		func(value bool, settings *keySettings) error {
+			settings.json = value
+			return nil
+		})
+}
+
+func (ko keyOptions) make() (keySettings, error) {
+	var settings keySettings
+	return settings, generic.ApplyOptions(&settings, ko...)
+}
+
+func (kg *keyGenerateOptions) BindFlags(flagSet *flag.FlagSet) {
+	(*keyOptions)(kg).BindFlags(flagSet)
+	const (
+		typeName  = "type"
+		typeUsage = "key `algorithm` (rsa or ed25519)"
+	)
+	flagSetFunc(flagSet, typeName, typeUsage, kg,
+		func(value string, settings *keySettings) error {
+			settings.keyType = value
+			return nil
+		})
+	flagSet.Lookup(typeName).
+		DefValue = "the node's default"
+}
+
+func (kg keyGenerateOptions) make() (keySettings, error) {
+	return keyOptions(kg).make()
+}
+
+func printKeys(output io.Writer, keys []ipfs.KeyInfo, asJSON bool) error {
+	if asJSON {
+		if keys == nil {
+			keys = []ipfs.KeyInfo{}
+		}
+		return json.NewEncoder(output).Encode(keys)
+	}
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	tabWriter := tabwriter.NewWriter(
+		output, minWidth, tabWidth, padding, padChar, flags,
+	)
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(tabWriter, "%s\t%s\n",
+			key.Name, key.ID,
+		); err != nil {
+			return err
+		}
+	}
+	return tabWriter.Flush()
+}
@@ -0,0 +1,19 @@
+//go:build noipfs
+
+package commands
+
+import "github.com/djdv/go-filesystem-utils/internal/command"
+
+func makeKeyListCommand() command.Command     { return makeUnbuiltKeyCommand("list") }
+func makeKeyGenerateCommand() command.Command { return makeUnbuiltKeyCommand("gen") }
+func makeKeyRemoveCommand() command.Command   { return makeUnbuiltKeyCommand("rm") }
+
+func makeUnbuiltKeyCommand(name string) command.Command {
+	return &unbuiltCommand{
+		name: name,
+		err: unbuiltError{
+			system: "IPFS",
+			tag:    "noipfs",
+		},
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/command"
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
@@ -15,6 +18,7 @@ import (
 	"github.com/djdv/go-filesystem-utils/internal/generic"
 	"github.com/djdv/p9/p9"
 	"github.com/jaevor/go-nanoid"
+	"github.com/multiformats/go-multiaddr"
 )
 
 type (
@@ -38,6 +42,7 @@ type (
 		usage(filesystem.Host) string
 	}
 	mountSettings struct {
+		host, guest json.RawMessage
 		permissions p9.FileMode
 		uid         p9.UID
 		gid         p9.GID
@@ -49,6 +54,8 @@ type (
 		host       HM
 		guest      GM
 		apiOptions []MountOption
+		timeout    time.Duration
+		dryRun     bool
 	}
 	mountCmdOption[
 		// Host/Guest marshaller constructor types.
@@ -69,6 +76,17 @@ type (
 		GC mountCmdGuest[GT, GM],
 	] []mountCmdOption[HT, GT, HM, GM, HC, GC]
 	MountOption func(*mountSettings) error
+	// MountRef is a handle to a mount point
+	// requested by [MountFS].
+	MountRef struct {
+		daemon multiaddr.Multiaddr
+		host   filesystem.Host
+		guest  filesystem.ID
+		name   string
+		// Target is the mount point's target,
+		// as decoded from its host data.
+		Target string
+	}
 )
 
 const (
@@ -98,6 +116,26 @@ func WithGID(gid p9.GID) MountOption {
 	}
 }
 
+// WithHostData sets the data used by [MountFS]
+// to construct the mount point's host.
+// The value is encoded as JSON.
+func WithHostData(data any) MountOption {
+	return func(ms *mountSettings) (err error) {
+		ms.host, err = json.Marshal(data)
+		return err
+	}
+}
+
+// WithGuestData sets the data used by [MountFS]
+// to construct the mount point's guest.
+// The value is encoded as JSON.
+func WithGuestData(data any) MountOption {
+	return func(ms *mountSettings) (err error) {
+		ms.guest, err = json.Marshal(data)
+		return err
+	}
+}
+
 func (mo *mountCmdOptions[HT, GT, HM, GM, HC, GC]) BindFlags(flagSet *flag.FlagSet) {
 	type cmdSettings = mountCmdSettings[HM, GM]
 	var clientOptions clientOptions
@@ -185,6 +223,26 @@ func (mo *mountCmdOptions[HT, GT, HM, GM, HC, GC]) BindFlags(flagSet *flag.FlagS
 		})
 	flagSet.Lookup(permissionsName).
 		DefValue = modeToSymbolicPermissions(permissions)
+	const (
+		timeoutName  = "mount-timeout"
+		timeoutUsage = "`duration` each mount point has to be mounted within" +
+			"\nif <= 0, mount operations remain pending until they complete or are canceled"
+	)
+	flagSetFunc(flagSet, timeoutName, timeoutUsage, mo,
+		func(value time.Duration, settings *cmdSettings) error {
+			settings.timeout = value
+			return nil
+		})
+	const (
+		dryRunName  = "dry-run"
+		dryRunUsage = "print the mount point data (one per line) instead of" +
+			"\nsending it to the file system service"
+	)
+	flagSetFunc(flagSet, dryRunName, dryRunUsage, mo,
+		func(value bool, settings *cmdSettings) error {
+			settings.dryRun = value
+			return nil
+		})
 }
 
 func (mo mountCmdOptions[HT, GT, HM, GM, HC, GC]) make() (mountCmdSettings[HM, GM], error) {
@@ -205,13 +263,7 @@ func (mp *mountCmdSettings[HM, GM]) marshalMountpoints(args ...string) ([][]byte
 		if err != nil {
 			return nil, err
 		}
-		datum, err := json.Marshal(struct {
-			Host  json.RawMessage `json:"host,omitempty"`
-			Guest json.RawMessage `json:"guest,omitempty"`
-		}{
-			Host:  hostData,
-			Guest: guestData,
-		})
+		datum, err := marshalMountpoint(hostData, guestData, mp.timeout)
 		if err != nil {
 			return nil, err
 		}
@@ -220,6 +272,34 @@ func (mp *mountCmdSettings[HM, GM]) marshalMountpoints(args ...string) ([][]byte
 	return data, nil
 }
 
+// marshalMountpoint encodes a mount point
+// in the format expected by the service.
+func marshalMountpoint(host, guest json.RawMessage, timeout time.Duration) ([]byte, error) {
+	return json.Marshal(struct {
+		Host    json.RawMessage `json:"host,omitempty"`
+		Guest   json.RawMessage `json:"guest,omitempty"`
+		Timeout time.Duration   `json:"timeout,omitempty"`
+	}{
+		Host:    host,
+		Guest:   guest,
+		Timeout: timeout,
+	})
+}
+
+// printMountpoints writes each mount point's data,
+// as it would be sent to the service, followed by a newline.
+func printMountpoints(output io.Writer, data [][]byte) error {
+	for _, datum := range data {
+		if _, err := output.Write(datum); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(output, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Mount constructs the command which requests
 // the file system service to mount a system.
 func Mount() command.Command {
@@ -333,6 +413,12 @@ func makeMountCommand[
 			if err != nil {
 				return err
 			}
+			if settings.dryRun {
+				if err := printMountpoints(os.Stdout, data); err != nil {
+					return err
+				}
+				return ctx.Err()
+			}
 			const autoLaunchDaemon = true
 			client, err := settings.getClient(autoLaunchDaemon)
 			if err != nil {
@@ -349,18 +435,110 @@ func makeMountCommand[
 		})
 }
 
-func (c *Client) Mount(host filesystem.Host, fsid filesystem.ID, data [][]byte, options ...MountOption) error {
+// MountFS connects to the service at `daemonMaddr`
+// and requests it to mount `guest` via `host`.
+// The mount point's data is provided via the
+// [WithHostData] and [WithGuestData] options.
+func MountFS(ctx context.Context, daemonMaddr multiaddr.Multiaddr,
+	guest filesystem.ID, host filesystem.Host, options ...MountOption,
+) (MountRef, error) {
+	set, err := makeMountSettings(options...)
+	if err != nil {
+		return MountRef{}, err
+	}
+	datum, err := marshalMountpoint(set.host, set.guest, 0)
+	if err != nil {
+		return MountRef{}, err
+	}
+	// Decoded before the request is made, so that
+	// the handle never refers to an unknown target.
+	target, err := newDecodeTargetFunc()(host, guest, datum)
+	if err != nil {
+		return MountRef{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return MountRef{}, err
+	}
+	client, err := Connect(daemonMaddr)
+	if err != nil {
+		return MountRef{}, err
+	}
+	names, err := client.mount(host, guest, [][]byte{datum}, set)
+	if err != nil {
+		return MountRef{}, errors.Join(err, client.Close())
+	}
+	if err := client.Close(); err != nil {
+		return MountRef{}, err
+	}
+	return MountRef{
+		daemon: daemonMaddr,
+		host:   host,
+		guest:  guest,
+		name:   names[0],
+		Target: target,
+	}, nil
+}
+
+// Unmount connects to the service
+// and removes the mount point.
+func (mr MountRef) Unmount() error {
+	client, err := Connect(mr.daemon)
+	if err != nil {
+		return err
+	}
+	if err := client.unlinkMount(mr.host, mr.guest, mr.name); err != nil {
+		return errors.Join(err, client.Close())
+	}
+	return client.Close()
+}
+
+func (c *Client) unlinkMount(host filesystem.Host, fsid filesystem.ID, name string) error {
+	mounts, err := (*p9.Client)(c).Attach(mountsFileName)
+	if err != nil {
+		return err
+	}
+	wnames := []string{string(host), string(fsid)}
+	_, guests, err := mounts.Walk(wnames)
+	if err != nil {
+		err = receiveError(mounts, err)
+		return errors.Join(err, mounts.Close())
+	}
+	const unlinkFlags = 0
+	err = guests.UnlinkAt(name, unlinkFlags)
+	err = errors.Join(err, guests.Close())
+	if err != nil {
+		err = receiveError(mounts, err)
+	}
+	return errors.Join(err, mounts.Close())
+}
+
+func makeMountSettings(options ...MountOption) (mountSettings, error) {
 	set := mountSettings{
 		permissions: mountAPIPermissionsDefault,
 		uid:         apiUIDDefault,
 		gid:         apiGIDDefault,
 	}
 	if err := generic.ApplyOptions(&set, options...); err != nil {
+		return mountSettings{}, err
+	}
+	return set, nil
+}
+
+func (c *Client) Mount(host filesystem.Host, fsid filesystem.ID, data [][]byte, options ...MountOption) error {
+	set, err := makeMountSettings(options...)
+	if err != nil {
 		return err
 	}
+	_, err = c.mount(host, fsid, data, set)
+	return err
+}
+
+// mount creates a file for each datum,
+// and returns the names of those files.
+func (c *Client) mount(host filesystem.Host, fsid filesystem.ID, data [][]byte, set mountSettings) ([]string, error) {
 	mounts, err := (*p9.Client)(c).Attach(mountsFileName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var (
 		hostName    = string(host)
@@ -373,7 +551,7 @@ func (c *Client) Mount(host filesystem.Host, fsid filesystem.ID, data [][]byte,
 	guests, err := p9fs.MkdirAll(mounts, wnames, permissions, uid, gid)
 	if err != nil {
 		err = receiveError(mounts, err)
-		return errors.Join(err, mounts.Close())
+		return nil, errors.Join(err, mounts.Close())
 	}
 	const (
 		mountIDLength  = 9
@@ -381,10 +559,11 @@ func (c *Client) Mount(host filesystem.Host, fsid filesystem.ID, data [][]byte,
 	)
 	idGen, err := nanoid.CustomASCII(base58Alphabet, mountIDLength)
 	if err != nil {
-		return errors.Join(err, mounts.Close(), guests.Close())
+		return nil, errors.Join(err, mounts.Close(), guests.Close())
 	}
 	var (
 		errs            []error
+		names           = make([]string, 0, len(data))
 		filePermissions = permissions ^ (p9fs.ExecuteOther | p9fs.ExecuteGroup | p9fs.ExecuteUser)
 	)
 	for _, data := range data {
@@ -392,7 +571,9 @@ func (c *Client) Mount(host filesystem.Host, fsid filesystem.ID, data [][]byte,
 		if err := newMountFile(guests, filePermissions, uid, gid,
 			name, data); err != nil {
 			errs = append(errs, err)
+			continue
 		}
+		names = append(names, name)
 	}
 	if errs != nil {
 		err = errors.Join(errs...)
@@ -401,7 +582,7 @@ func (c *Client) Mount(host filesystem.Host, fsid filesystem.ID, data [][]byte,
 	if err != nil {
 		err = receiveError(mounts, err)
 	}
-	return errors.Join(err, mounts.Close())
+	return names, errors.Join(err, mounts.Close())
 }
 
 func newMountFile(idRoot p9.File,
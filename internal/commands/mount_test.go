@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+// stubMarshaller marshals its argument as
+// a JSON string (or fails with `err`).
+type stubMarshaller struct{ err error }
+
+func (sm stubMarshaller) marshal(argument string) ([]byte, error) {
+	if err := sm.err; err != nil {
+		return nil, err
+	}
+	return json.Marshal(argument)
+}
+
+func TestMountDryRun(t *testing.T) {
+	t.Parallel()
+	t.Run("print", func(t *testing.T) {
+		t.Parallel()
+		settings := mountCmdSettings[stubMarshaller, stubMarshaller]{}
+		data, err := settings.marshalMountpoints("first", "second")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var output bytes.Buffer
+		if err := printMountpoints(&output, data); err != nil {
+			t.Fatal(err)
+		}
+		// Each line must be exactly
+		// what would have been sent.
+		lines := bytes.Split(bytes.TrimSuffix(output.Bytes(), []byte("\n")), []byte("\n"))
+		if got, want := len(lines), len(data); got != want {
+			t.Fatalf("line count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				got, want,
+			)
+		}
+		for i, line := range lines {
+			if !bytes.Equal(line, data[i]) {
+				t.Errorf("mount point data mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					line, data[i],
+				)
+			}
+		}
+		const want = `{"host":"first","guest":"first"}` + "\n" +
+			`{"host":"second","guest":"second"}` + "\n"
+		if got := output.String(); got != want {
+			t.Errorf("output mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				got, want,
+			)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		const errTarget = generic.ConstError("malformed target")
+		settings := mountCmdSettings[stubMarshaller, stubMarshaller]{
+			host: stubMarshaller{err: errTarget},
+		}
+		if _, err := settings.marshalMountpoints("target"); !errors.Is(err, errTarget) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, errTarget,
+			)
+		}
+	})
+}
+
+func TestMountFS(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	t.Run("invalid data", func(t *testing.T) {
+		t.Parallel()
+		_, err := MountFS(ctx, nil, "guest", "host",
+			WithHostData(make(chan struct{})),
+		)
+		var typeErr *json.UnsupportedTypeError
+		if !errors.As(err, &typeErr) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %T",
+				err, typeErr,
+			)
+		}
+	})
+	t.Run("unknown host", func(t *testing.T) {
+		t.Parallel()
+		// The target must be decodable
+		// before the service is dialed.
+		if _, err := MountFS(ctx, nil, "guest", "host",
+			WithHostData("target"),
+			WithGuestData("data"),
+		); err == nil {
+			t.Error("expected error for unknown host")
+		}
+	})
+}
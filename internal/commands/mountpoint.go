@@ -1,11 +1,13 @@
 package commands
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"strings"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
@@ -31,6 +33,10 @@ type (
 	] struct {
 		Host  HT `json:"host"`
 		Guest GT `json:"guest"`
+		// Timeout bounds the duration of
+		// the guest's construction and the
+		// host's mount operation (if > 0).
+		Timeout time.Duration `json:"timeout,omitempty"`
 	}
 	mountPointHosts  map[filesystem.Host]p9fs.MakeGuestFunc
 	mountPointGuests map[filesystem.ID]p9fs.MakeMountPointFunc
@@ -185,7 +191,16 @@ func (mp *mountPoint[HT, GT, HC, GC]) ParseField(key, value string) error {
 	const (
 		hostPrefix  = "host."
 		guestPrefix = "guest."
+		timeoutKey  = "timeout"
 	)
+	if key == timeoutKey {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		mp.Timeout = timeout
+		return nil
+	}
 	var (
 		prefix string
 		parser p9fs.FieldParser
@@ -217,8 +232,11 @@ func (mp *mountPoint[HT, GT, HC, GC]) ParseField(key, value string) error {
 	default:
 		const wildcard = "*"
 		return p9fs.FieldError{
-			Key:   key,
-			Tried: []string{hostPrefix + wildcard, guestPrefix + wildcard},
+			Key: key,
+			Tried: []string{
+				hostPrefix + wildcard, guestPrefix + wildcard,
+				timeoutKey,
+			},
 		}
 	}
 	var (
@@ -240,14 +258,33 @@ func (mp *mountPoint[HT, GT, HC, GC]) ParseField(key, value string) error {
 	return fErr
 }
 
+// MakeFS constructs the guest via the guest registry,
+// so that mount points and commands which construct
+// guests directly share the same constructors.
 func (mp *mountPoint[HT, GT, HC, GC]) MakeFS() (fs.FS, error) {
-	return GC(&mp.Guest).MakeFS()
+	guest := GC(&mp.Guest)
+	return makeRegisteredFS(guest.GuestID(), guest)
+}
+
+// makeRegisteredFS encodes the guest's settings
+// and passes them to the constructor registered
+// for `id` (see [filesystem.RegisterGuest]).
+func makeRegisteredFS(id filesystem.ID, guest any) (fs.FS, error) {
+	data, err := json.Marshal(guest)
+	if err != nil {
+		return nil, err
+	}
+	return filesystem.MakeGuest(id, data)
 }
 
 func (mp *mountPoint[HT, GT, HC, GC]) Mount(fsys fs.FS) (io.Closer, error) {
 	return HC(&mp.Host).Mount(fsys)
 }
 
+func (mp *mountPoint[HT, GT, HC, GC]) MountTimeout() time.Duration {
+	return mp.Timeout
+}
+
 func (mp *mountPoint[HT, GT, HC, GC]) HostID() filesystem.Host {
 	return HC(&mp.Host).HostID()
 }
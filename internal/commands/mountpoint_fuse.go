@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/command"
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
@@ -179,6 +180,16 @@ func (fo *fuseOptions) BindFlags(flagSet *flag.FlagSet) {
 			settings.CaseInsensitive = value
 			return nil
 		})
+	const (
+		foldName  = prefix + "case-fold"
+		foldUsage = "retry lookups of files which do not exist, without regard to case" +
+			"\n(for case insensitive hosts, with case sensitive file systems)"
+	)
+	flagSetFunc(flagSet, foldName, foldUsage, fo,
+		func(value bool, settings *fuseSettings) error {
+			settings.CaseFold = value
+			return nil
+		})
 	const (
 		deleteName  = prefix + "delete-access"
 		deleteUsage = "informs the host that the hosted file system implements \"Access\" which understands the \"DELETE_OK\" flag"
@@ -188,6 +199,53 @@ func (fo *fuseOptions) BindFlags(flagSet *flag.FlagSet) {
 			settings.DeleteAccess = value
 			return nil
 		})
+	const (
+		chownName  = prefix + "ignore-chown"
+		chownUsage = "ownership changes succeed without effect (rather than failing with EROFS) if the hosted file system can not store ownership"
+	)
+	flagSetFunc(flagSet, chownName, chownUsage, fo,
+		func(value bool, settings *fuseSettings) error {
+			settings.IgnoreChown = value
+			return nil
+		})
+	const (
+		cacheName  = prefix + "attr-cache-timeout"
+		cacheUsage = "`duration` the host may cache file attributes and entries for" +
+			"\nlower values reflect changes to mutable file systems sooner" +
+			"\nbut increase the amount of calls made to the file system" +
+			"\n(0 disables caching)"
+	)
+	flagSetFunc(flagSet, cacheName, cacheUsage, fo,
+		func(value time.Duration, settings *fuseSettings) error {
+			if err := combinedCheck(); err != nil {
+				return err
+			}
+			if value < 0 {
+				return fmt.Errorf(`"%s" flag must not be negative`, cacheName)
+			}
+			explicitFlags = append(explicitFlags, cacheName)
+			settings.AttrCacheTimeout = &value
+			return nil
+		})
+	flagSet.Lookup(cacheName).
+		DefValue = "the FUSE library's default"
+	const (
+		placeholderName  = prefix + "placeholder-size"
+		placeholderUsage = "`bytes` to report as the size of files whose size is not yet known" +
+			"\n(rather than 0; for programs which skip empty files)" +
+			"\nthis is misleading, reads still end at the file's actual length" +
+			"\n(0 disables the placeholder)"
+	)
+	flagSetFunc(flagSet, placeholderName, placeholderUsage, fo,
+		func(value int64, settings *fuseSettings) error {
+			if value < 0 {
+				return fmt.Errorf(`"%s" flag must not be negative`, placeholderName)
+			}
+			settings.PlaceholderSize = value
+			return nil
+		})
+	flagSet.Lookup(placeholderName).
+		DefValue = "0"
 }
 
 func (fo fuseOptions) make() (fuseSettings, error) {
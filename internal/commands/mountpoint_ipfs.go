@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -77,11 +79,23 @@ func prefixIDFlag(system filesystem.ID) string {
 
 func (*ipfsOptions) usage(filesystem.Host) string {
 	return string(ipfs.IPFSID) + " provides an empty root directory." +
-		"\nChild paths are forwarded to the IPFS API."
+		"\nChild paths are forwarded to the IPFS API." +
+		"\nIf a path prefix is provided, it's used as the root instead."
 }
 
 func (io *ipfsOptions) BindFlags(flagSet *flag.FlagSet) {
 	io.bindFlagsVarient(ipfs.IPFSID, flagSet)
+	var (
+		flagPrefix = prefixIDFlag(ipfs.IPFSID)
+		prefixName = flagPrefix + "path-prefix"
+	)
+	const prefixUsage = "directory `path` to use as the root" +
+		"\n(E.g. `Qm.../sub` or `/ipfs/Qm.../sub`)"
+	flagSetFunc(flagSet, prefixName, prefixUsage, io,
+		func(value string, settings *ipfsSettings) error {
+			settings.PathPrefix = value
+			return nil
+		})
 }
 
 func (io *ipfsOptions) bindFlagsVarient(system filesystem.ID, flagSet *flag.FlagSet) {
@@ -130,6 +144,70 @@ func (io *ipfsOptions) bindFlagsVarient(system filesystem.ID, flagSet *flag.Flag
 			settings.DirectoryCacheCount = value
 			return nil
 		})
+	caseName := flagPrefix + "case-insensitive"
+	const caseUsage = "retry lookups which do not exist" +
+		" by matching names without regard to case"
+	flagSetFunc(flagSet, caseName, caseUsage, io,
+		func(value bool, settings *ipfsSettings) error {
+			settings.CaseInsensitive = value
+			return nil
+		})
+	contentTypeName := flagPrefix + "content-type"
+	const contentTypeUsage = "detect the media type of files when they're opened" +
+		"\n(from their extension, or leading bytes)"
+	flagSetFunc(flagSet, contentTypeName, contentTypeUsage, io,
+		func(value bool, settings *ipfsSettings) error {
+			settings.ContentType = value
+			return nil
+		})
+	ipldName := flagPrefix + "ipld-directories"
+	const ipldUsage = "present IPLD nodes (e.g. DAG-CBOR) as directories" +
+		"\n(links become symbolic links to their CID)"
+	flagSetFunc(flagSet, ipldName, ipldUsage, io,
+		func(value bool, settings *ipfsSettings) error {
+			settings.IPLDDirectories = value
+			return nil
+		})
+	linkTargetName := flagPrefix + "link-target-max"
+	const linkTargetUsage = "maximum `length` of symbolic link targets" +
+		"\nlinks with longer targets can not be read"
+	flagSetFunc(flagSet, linkTargetName, linkTargetUsage, io,
+		func(value int, settings *ipfsSettings) error {
+			settings.MaxLinkTargetLen = value
+			return nil
+		})
+	flagSet.Lookup(linkTargetName).
+		DefValue = strconv.Itoa(ipfs.DefaultMaxLinkTargetLen)
+	readdirName := flagPrefix + "readdir-workers"
+	const readdirUsage = "number of directory entries to resolve" +
+		" concurrently when listing" +
+		"\nif <= 0, entries are resolved when they're used"
+	flagSetFunc(flagSet, readdirName, readdirUsage, io,
+		func(value int, settings *ipfsSettings) error {
+			settings.ReaddirWorkers = value
+			return nil
+		})
+	handleLimitName := flagPrefix + "handle-limit"
+	const handleLimitUsage = "maximum `count` of files which may be open at once" +
+		"\nif 0, the count is not limited"
+	flagSetFunc(flagSet, handleLimitName, handleLimitUsage, io,
+		func(value int, settings *ipfsSettings) error {
+			if value < 0 {
+				return generic.ConstError("must not be negative")
+			}
+			settings.HandleLimit = value
+			return nil
+		})
+	userAgentName := flagPrefix + "user-agent"
+	const userAgentUsage = "`identifier` to send in the User-Agent header" +
+		" of API requests"
+	flagSetFunc(flagSet, userAgentName, userAgentUsage, io,
+		func(value string, settings *ipfsSettings) error {
+			settings.UserAgent = value
+			return nil
+		})
+	flagSet.Lookup(userAgentName).
+		DefValue = ipfs.DefaultUserAgent()
 }
 
 func (io ipfsOptions) make() (ipfsSettings, error) {
@@ -154,6 +232,10 @@ func (set ipfsSettings) marshal(string) ([]byte, error) {
 	return json.Marshal(set)
 }
 
+func (set ipfsSettings) makeFS() (fs.FS, error) {
+	return makeRegisteredFS(ipfs.IPFSID, (*ipfs.IPFSGuest)(&set))
+}
+
 func (*pinFSOptions) usage(filesystem.Host) string {
 	return guestOverlayText(ipfs.PinFSID, ipfs.IPFSID) +
 		" which provides a root containing" +
@@ -184,6 +266,16 @@ func (po *pinFSOptions) BindFlags(flagSet *flag.FlagSet) {
 		})
 	flagSet.Lookup(expiryName).
 		DefValue = pinfsExpiryDefault.String()
+	const (
+		prefetchName  = "pinfs-prefetch"
+		prefetchUsage = "fetch the pin set in the background when mounted" +
+			"\n(has no effect if the cache is disabled)"
+	)
+	flagSetFunc(flagSet, prefetchName, prefetchUsage, po,
+		func(value bool, settings *pinFSSettings) error {
+			settings.InitialPins = value
+			return nil
+		})
 }
 
 func (po pinFSOptions) make() (pinFSSettings, error) {
@@ -194,6 +286,10 @@ func (set pinFSSettings) marshal(string) ([]byte, error) {
 	return json.Marshal(set)
 }
 
+func (set pinFSSettings) makeFS() (fs.FS, error) {
+	return makeRegisteredFS(ipfs.PinFSID, (*ipfs.PinFSGuest)(&set))
+}
+
 func (*ipnsOptions) usage(filesystem.Host) string {
 	return guestOverlayText(ipfs.IPNSID, ipfs.IPFSID) +
 		" which provides an empty root." +
@@ -246,6 +342,10 @@ func (set ipnsSettings) marshal(string) ([]byte, error) {
 	return json.Marshal(set)
 }
 
+func (set ipnsSettings) makeFS() (fs.FS, error) {
+	return makeRegisteredFS(ipfs.IPNSID, (*ipfs.IPNSGuest)(&set))
+}
+
 func (*keyFSOptions) usage(filesystem.Host) string {
 	return guestOverlayText(ipfs.KeyFSID, ipfs.IPNSID) +
 		" which provides a root" +
@@ -264,6 +364,16 @@ func (ko *keyFSOptions) BindFlags(flagSet *flag.FlagSet) {
 		settings.IPNSGuest = ipfs.IPNSGuest(subset)
 		return nil
 	})
+	const (
+		proxyUsage = "only resolve paths which begin with a key's name" +
+			"\n(other IPNS names will not exist)"
+	)
+	proxyName := prefixIDFlag(ipfs.KeyFSID) + "disable-proxy"
+	flagSetFunc(flagSet, proxyName, proxyUsage, ko,
+		func(value bool, settings *keyFSSettings) error {
+			settings.ProxyDisabled = value
+			return nil
+		})
 }
 
 func (ko keyFSOptions) make() (keyFSSettings, error) {
@@ -274,6 +384,10 @@ func (set keyFSSettings) marshal(string) ([]byte, error) {
 	return json.Marshal(set)
 }
 
+func (set keyFSSettings) makeFS() (fs.FS, error) {
+	return makeRegisteredFS(ipfs.KeyFSID, (*ipfs.KeyFSGuest)(&set))
+}
+
 func getIPFSAPI() ([]multiaddr.Multiaddr, error) {
 	location, err := getIPFSAPIPath()
 	if err != nil {
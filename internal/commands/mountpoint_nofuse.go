@@ -13,7 +13,7 @@ type fuseID uint32
 const fuseHost = filesystem.Host("")
 
 func makeFUSECommand() command.Command {
-	return nil
+	return makeUnbuiltCommand("FUSE", "nofuse")
 }
 
 func makeFUSEHost(ninePath, bool) (filesystem.Host, p9fs.MakeGuestFunc) {
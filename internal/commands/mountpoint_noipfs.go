@@ -13,7 +13,17 @@ func makeIPFSCommands[
 	HT any,
 ](filesystem.Host,
 ) []command.Command {
-	return nil
+	return makeUnbuiltIPFSCommands()
+}
+
+func makeUnbuiltIPFSCommands() []command.Command {
+	const tag = "noipfs"
+	return []command.Command{
+		makeUnbuiltCommand("IPFS", tag),
+		makeUnbuiltCommand("PinFS", tag),
+		makeUnbuiltCommand("IPNS", tag),
+		makeUnbuiltCommand("KeyFS", tag),
+	}
 }
 
 func makeIPFSGuests[
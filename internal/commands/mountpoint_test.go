@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	registryHost  struct{}
+	registryGuest struct {
+		Name string `json:"name"`
+	}
+)
+
+const registryGuestID filesystem.ID = "registry-test"
+
+func (*registryHost) Mount(fs.FS) (io.Closer, error) { return nil, nil }
+func (*registryHost) HostID() filesystem.Host        { return "registry-test" }
+
+func (*registryGuest) MakeFS() (fs.FS, error) {
+	return nil, generic.ConstError("guest was constructed directly")
+}
+func (*registryGuest) GuestID() filesystem.ID { return registryGuestID }
+
+func TestMountPointRegistry(t *testing.T) {
+	t.Parallel()
+	const name = "file"
+	if err := filesystem.RegisterGuest(registryGuestID,
+		func(data []byte) (fs.FS, error) {
+			var guest registryGuest
+			if err := json.Unmarshal(data, &guest); err != nil {
+				return nil, err
+			}
+			return fstest.MapFS{guest.Name: new(fstest.MapFile)}, nil
+		},
+	); err != nil {
+		t.Fatal(err)
+	}
+	point := mountPoint[registryHost, registryGuest, *registryHost, *registryGuest]{
+		Guest: registryGuest{Name: name},
+	}
+	fsys, err := point.MakeFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat(fsys, name); err != nil {
+		t.Error("guest was not constructed from its settings:", err)
+	}
+	if _, err := makeRegisteredFS("unregistered", registryGuest{}); err == nil {
+		t.Error("expected unregistered guest to be rejected")
+	}
+}
@@ -0,0 +1,356 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/djdv/p9/p9"
+	"github.com/multiformats/go-multiaddr"
+)
+
+type (
+	mountsEncoding uint8
+	mountsSettings struct {
+		clientSettings
+		encoding mountsEncoding
+		pending  bool
+		dump     bool
+		restore  bool
+	}
+	mountsOption  func(*mountsSettings) error
+	mountsOptions []mountsOption
+	// mountDump is the format of each
+	// mount point within a dump.
+	mountDump struct {
+		Host  filesystem.Host `json:"host"`
+		Guest filesystem.ID   `json:"guest"`
+		Data  json.RawMessage `json:"data"`
+	}
+	// mountRecord is the format of each
+	// mount point within a JSON listing.
+	mountRecord struct {
+		Host    filesystem.Host `json:"host"`
+		Guest   filesystem.ID   `json:"guest"`
+		Target  string          `json:"target"`
+		Pending bool            `json:"pending"`
+		// Maddrs are the listener addresses of
+		// the service which hosts the mount point.
+		Maddrs []string `json:"maddrs"`
+	}
+)
+
+const (
+	mountsTextEncoding mountsEncoding = iota + 1
+	mountsJSONEncoding
+	mountsMinimumEncoding = mountsTextEncoding
+	mountsMaximumEncoding = mountsJSONEncoding
+	mountsEncodingDefault = mountsTextEncoding
+)
+
+const (
+	errMountsMixed    = generic.ConstError(`cannot combine "dump" option with "encoding" option`)
+	errRestoreMixed   = generic.ConstError(`cannot combine "restore" option with listing options`)
+	errIncompleteDump = generic.ConstError("mount point dump is missing its host, guest, or data")
+)
+
+func (encoding mountsEncoding) String() string {
+	switch encoding {
+	case mountsTextEncoding:
+		return "text"
+	case mountsJSONEncoding:
+		return "json"
+	default:
+		return fmt.Sprintf("invalid: %d", encoding)
+	}
+}
+
+func parseMountsEncoding(encoding string) (mountsEncoding, error) {
+	return generic.ParseEnum(mountsMinimumEncoding, mountsMaximumEncoding, encoding)
+}
+
+// Mounts constructs the command which lists
+// the file system service's mount points.
+func Mounts() command.Command {
+	const (
+		name     = "mounts"
+		synopsis = "List mounted file systems."
+	)
+	usage := header("Mounts") +
+		"\n\n" + synopsis +
+		"\nMounts which have not completed yet are marked as pending." +
+		"\nWhen dumping, each mount point is printed as a line of JSON" +
+		"\ncontaining its host, guest, and the data used to mount it." +
+		"\nThe JSON encoding prints a single array of mount points," +
+		"\nincluding the addresses the service is listening on." +
+		"\nWhen restoring, a dump is read from stdin" +
+		"\nand each of its mount points is mounted again."
+	return command.MakeVariadicCommand[mountsOptions](name, synopsis, usage, mountsExecute)
+}
+
+func (mo *mountsOptions) BindFlags(flagSet *flag.FlagSet) {
+	var clientOptions clientOptions
+	(&clientOptions).BindFlags(flagSet)
+	*mo = append(*mo, func(ms *mountsSettings) error {
+		subset, err := clientOptions.make()
+		if err != nil {
+			return err
+		}
+		ms.clientSettings = subset
+		return nil
+	})
+	const (
+		pendingName  = "pending"
+		pendingUsage = "only list mounts which are still pending"
+	)
+	flagSetFunc(flagSet, pendingName, pendingUsage, mo,
+		func(value bool, settings *mountsSettings) error {
+			settings.pending = value
+			return nil
+		})
+	const (
+		dumpName  = "dump"
+		dumpUsage = "print the data of each mount point" +
+			"\n(which can be used to mount them again)"
+	)
+	flagSetFunc(flagSet, dumpName, dumpUsage, mo,
+		func(value bool, settings *mountsSettings) error {
+			settings.dump = value
+			return nil
+		})
+	const (
+		restoreName  = "restore"
+		restoreUsage = "read a dump from stdin and mount each mount point within it"
+	)
+	flagSetFunc(flagSet, restoreName, restoreUsage, mo,
+		func(value bool, settings *mountsSettings) error {
+			settings.restore = value
+			return nil
+		})
+	const encodingName = "encoding"
+	encodingUsage := fmt.Sprintf(
+		"output `format`"+
+			"\none of: %s, %s",
+		mountsTextEncoding, mountsJSONEncoding,
+	)
+	flagSetFunc(flagSet, encodingName, encodingUsage, mo,
+		func(value mountsEncoding, settings *mountsSettings) error {
+			settings.encoding = value
+			return nil
+		})
+	flagSet.Lookup(encodingName).
+		DefValue = mountsEncodingDefault.String()
+}
+
+func (mo mountsOptions) make() (mountsSettings, error) {
+	settings := mountsSettings{
+		encoding: mountsEncodingDefault,
+	}
+	return settings, generic.ApplyOptions(&settings, mo...)
+}
+
+func mountsExecute(ctx context.Context, options ...mountsOption) error {
+	settings, err := mountsOptions(options).make()
+	if err != nil {
+		return err
+	}
+	if settings.dump && settings.encoding != mountsTextEncoding {
+		return command.UsageError{Err: errMountsMixed}
+	}
+	if settings.restore {
+		if settings.dump || settings.pending ||
+			settings.encoding != mountsTextEncoding {
+			return command.UsageError{Err: errRestoreMixed}
+		}
+		if err := restoreMounts(&settings.clientSettings, os.Stdin); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+	const autoLaunchDaemon = false
+	client, err := settings.getClient(autoLaunchDaemon)
+	if err != nil {
+		return fmt.Errorf("could not get client (server down?): %w", err)
+	}
+	mounts, err := client.ListMounts()
+	if err != nil {
+		return errors.Join(err, client.Close())
+	}
+	var maddrs []multiaddr.Multiaddr
+	if settings.encoding == mountsJSONEncoding {
+		if maddrs, err = client.getListeners(); err != nil {
+			return errors.Join(err, client.Close())
+		}
+	}
+	if err := client.Close(); err != nil {
+		return err
+	}
+	if settings.pending {
+		filtered := mounts[:0]
+		for _, mount := range mounts {
+			if mount.Pending {
+				filtered = append(filtered, mount)
+			}
+		}
+		mounts = filtered
+	}
+	switch {
+	case settings.dump:
+		err = dumpMounts(os.Stdout, mounts)
+	case settings.encoding == mountsJSONEncoding:
+		err = encodeMounts(os.Stdout, mounts, maddrs)
+	default:
+		err = printMounts(os.Stdout, mounts)
+	}
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func dumpMounts(output io.Writer, mounts []p9fs.MountInfo) error {
+	encoder := json.NewEncoder(output)
+	for _, mount := range mounts {
+		if err := encoder.Encode(mountDump{
+			Host:  mount.Host,
+			Guest: mount.Guest,
+			Data:  mount.Data,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadDump decodes the mount points
+// written by [dumpMounts].
+func loadDump(input io.Reader) ([]mountDump, error) {
+	var (
+		decoder = json.NewDecoder(input)
+		dumps   []mountDump
+	)
+	for {
+		var dump mountDump
+		if err := decoder.Decode(&dump); err != nil {
+			if errors.Is(err, io.EOF) {
+				return dumps, nil
+			}
+			return nil, err
+		}
+		if dump.Host == "" || dump.Guest == "" ||
+			len(dump.Data) == 0 {
+			return nil, errIncompleteDump
+		}
+		dumps = append(dumps, dump)
+	}
+}
+
+// restoreMounts requests the service to mount
+// each mount point within the dump.
+// The dump is decoded before anything is mounted,
+// so that malformed input mounts nothing.
+func restoreMounts(settings *clientSettings, input io.Reader) error {
+	dumps, err := loadDump(input)
+	if err != nil {
+		return fmt.Errorf("could not load dump: %w", err)
+	}
+	if len(dumps) == 0 {
+		return nil
+	}
+	const autoLaunchDaemon = true
+	client, err := settings.getClient(autoLaunchDaemon)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, dump := range dumps {
+		data := [][]byte{dump.Data}
+		if err := client.Mount(dump.Host, dump.Guest, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(append(errs, client.Close())...)
+}
+
+// encodeMounts prints the mount points as a JSON array.
+// (Which is empty rather than null when there are none.)
+// Multiaddrs are encoded in their string form.
+func encodeMounts(output io.Writer, mounts []p9fs.MountInfo, maddrs []multiaddr.Multiaddr) error {
+	var (
+		records      = make([]mountRecord, len(mounts))
+		maddrStrings = make([]string, len(maddrs))
+	)
+	for i, maddr := range maddrs {
+		maddrStrings[i] = maddr.String()
+	}
+	for i, mount := range mounts {
+		records[i] = mountRecord{
+			Host:    mount.Host,
+			Guest:   mount.Guest,
+			Target:  mount.Target,
+			Pending: mount.Pending,
+			Maddrs:  maddrStrings,
+		}
+	}
+	return json.NewEncoder(output).Encode(records)
+}
+
+func printMounts(output io.Writer, mounts []p9fs.MountInfo) error {
+	if len(mounts) == 0 {
+		return nil
+	}
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	tabWriter := tabwriter.NewWriter(
+		output, minWidth, tabWidth, padding, padChar, flags,
+	)
+	if _, err := fmt.Fprintln(tabWriter,
+		"target\thost\tguest\tstatus",
+	); err != nil {
+		return err
+	}
+	for _, mount := range mounts {
+		status := "mounted"
+		if mount.Pending {
+			status = "pending"
+		}
+		if _, err := fmt.Fprintf(tabWriter,
+			"%s\t%s\t%s\t%s\n",
+			mount.Target, mount.Host, mount.Guest, status,
+		); err != nil {
+			return err
+		}
+	}
+	return tabWriter.Flush()
+}
+
+// ListMounts retrieves a description of each
+// mount point (including pending mounts)
+// from the service.
+func (c *Client) ListMounts() ([]p9fs.MountInfo, error) {
+	mounts, err := (*p9.Client)(c).Attach(mountsFileName)
+	if err != nil {
+		return nil, err
+	}
+	decodeFn := newDecodeTargetFunc()
+	infos, err := p9fs.ListMounts(mounts, decodeFn)
+	if err != nil {
+		err = receiveError(mounts, err)
+		return nil, errors.Join(err, mounts.Close())
+	}
+	return infos, mounts.Close()
+}
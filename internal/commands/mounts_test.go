@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/multiformats/go-multiaddr"
+)
+
+func TestEncodeMounts(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name   string
+		mounts []p9fs.MountInfo
+		maddrs []string
+		want   string
+	}{
+		{
+			name: "empty",
+			want: "[]\n",
+		},
+		{
+			name: "mounts",
+			mounts: []p9fs.MountInfo{
+				{
+					Host:   "FUSE",
+					Guest:  "IPFS",
+					Target: "/ipfs",
+					Data:   []byte(`{"unused":true}`),
+				},
+				{
+					Host:    "FUSE",
+					Guest:   "IPNS",
+					Target:  "/ipns",
+					Pending: true,
+				},
+			},
+			maddrs: []string{"/ip4/127.0.0.1/tcp/564"},
+			want: `[{"host":"FUSE","guest":"IPFS","target":"/ipfs","pending":false,` +
+				`"maddrs":["/ip4/127.0.0.1/tcp/564"]},` +
+				`{"host":"FUSE","guest":"IPNS","target":"/ipns","pending":true,` +
+				`"maddrs":["/ip4/127.0.0.1/tcp/564"]}]` + "\n",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			maddrs := make([]multiaddr.Multiaddr, len(test.maddrs))
+			for i, maddr := range test.maddrs {
+				maddrs[i] = multiaddr.StringCast(maddr)
+			}
+			var output bytes.Buffer
+			if err := encodeMounts(&output, test.mounts, maddrs); err != nil {
+				t.Fatal(err)
+			}
+			if got := output.String(); got != test.want {
+				t.Errorf("output mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					got, test.want,
+				)
+			}
+		})
+	}
+}
+
+func TestLoadDump(t *testing.T) {
+	t.Parallel()
+	mounts := []p9fs.MountInfo{
+		{
+			Host:   "FUSE",
+			Guest:  "IPFS",
+			Target: "/ipfs",
+			Data:   []byte(`{"host":{"point":"/ipfs"},"guest":{}}`),
+		},
+		{
+			Host:   "FUSE",
+			Guest:  "IPNS",
+			Target: "/ipns",
+			Data:   []byte(`{"host":{"point":"/ipns"},"guest":{}}`),
+		},
+	}
+	var output bytes.Buffer
+	if err := dumpMounts(&output, mounts); err != nil {
+		t.Fatal(err)
+	}
+	dumps, err := loadDump(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dumps) != len(mounts) {
+		t.Fatalf("dump count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			len(dumps), len(mounts),
+		)
+	}
+	for i, dump := range dumps {
+		mount := mounts[i]
+		if dump.Host != mount.Host ||
+			dump.Guest != mount.Guest ||
+			!bytes.Equal(dump.Data, mount.Data) {
+			t.Errorf("dump %d mismatch"+
+				"\ngot: %s %s %s"+
+				"\nwant: %s %s %s",
+				i,
+				dump.Host, dump.Guest, dump.Data,
+				mount.Host, mount.Guest, mount.Data,
+			)
+		}
+	}
+	for _, input := range []string{
+		`{"host":"FUSE","guest":"IPFS"}`,
+		`{"host":"FUSE","guest":"IPFS","data":`,
+	} {
+		if _, err := loadDump(strings.NewReader(input)); err == nil {
+			t.Errorf("expected dump to be rejected: %s", input)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+type (
+	// progress is a (cumulative) snapshot
+	// of a long running operation's progress.
+	progress struct {
+		Path      string `json:"path"`
+		BytesDone int64  `json:"bytesDone"`
+		ItemsDone int    `json:"itemsDone"`
+	}
+	// progressReporter accumulates progress and sends
+	// events without blocking the operation reporting them.
+	// Reporters must only be used by a single goroutine.
+	// A nil reporter discards all progress.
+	progressReporter struct {
+		events  chan progress
+		current progress
+	}
+	// progressRenderer writes progress events as they're received.
+	progressRenderer struct {
+		output   io.Writer
+		encoder  *json.Encoder
+		interval time.Duration
+		width    int
+	}
+)
+
+// progressInterval is the minimum duration
+// between updates of the (text) progress line.
+const progressInterval = 100 * time.Millisecond
+
+func newProgressReporter() *progressReporter {
+	// NOTE: The buffer holds the latest event,
+	// so that the reporter never waits on the renderer.
+	return &progressReporter{events: make(chan progress, 1)}
+}
+
+// startProgress renders progress to stderr until the returned
+// function is called; which renders the final event, and
+// returns any error encountered while rendering.
+// If `asJSON` is true, events are rendered as JSON objects.
+func startProgress(asJSON bool) (*progressReporter, func() error) {
+	var (
+		reporter = newProgressReporter()
+		renderer = progressRenderer{
+			output:   os.Stderr,
+			interval: progressInterval,
+		}
+		renderErr = make(chan error, 1)
+	)
+	if asJSON {
+		renderer.encoder = json.NewEncoder(os.Stderr)
+	}
+	go func() { renderErr <- renderer.render(reporter.events) }()
+	return reporter, func() error {
+		close(reporter.events)
+		return <-renderErr
+	}
+}
+
+// add accumulates progress for the item at `path`.
+func (pr *progressReporter) add(path string, bytes int64, items int) {
+	if pr == nil {
+		return
+	}
+	current := &pr.current
+	current.Path = path
+	current.BytesDone += bytes
+	current.ItemsDone += items
+	pr.send(*current)
+}
+
+// send replaces any event that has not been received yet,
+// since events are cumulative and the latest supersedes it.
+func (pr *progressReporter) send(event progress) {
+	select {
+	case pr.events <- event:
+		return
+	default:
+	}
+	select {
+	case <-pr.events:
+	default:
+	}
+	select {
+	case pr.events <- event:
+	default:
+	}
+}
+
+// render writes events until the channel is closed.
+// Text is rendered as a single line which is rewritten
+// (at most once per interval), while JSON is rendered as
+// an object per event.
+// Events continue to be received after write errors, so
+// that the reporter is never blocked by the renderer.
+func (pr *progressRenderer) render(events <-chan progress) error {
+	if pr.encoder != nil {
+		var err error
+		for event := range events {
+			if err == nil {
+				err = pr.encoder.Encode(event)
+			}
+		}
+		return err
+	}
+	var (
+		ticker  = time.NewTicker(pr.interval)
+		latest  progress
+		pending bool
+		err     error
+	)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				if err != nil || latest == (progress{}) {
+					return err
+				}
+				if err = pr.writeLine(latest); err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(pr.output)
+				return err
+			}
+			latest, pending = event, true
+		case <-ticker.C:
+			if !pending || err != nil {
+				continue
+			}
+			err = pr.writeLine(latest)
+			pending = false
+		}
+	}
+}
+
+// writeLine overwrites the previous line
+// (padding it, if the new line is shorter).
+func (pr *progressRenderer) writeLine(event progress) error {
+	line := fmt.Sprintf("%d items, %d bytes: %s",
+		event.ItemsDone, event.BytesDone, event.Path,
+	)
+	width := len(line)
+	if padding := pr.width - width; padding > 0 {
+		line += strings.Repeat(" ", padding)
+	}
+	pr.width = width
+	_, err := fmt.Fprint(pr.output, "\r"+line)
+	return err
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	t.Parallel()
+	t.Run("reporter", testProgressReporter)
+	t.Run("text", testProgressText)
+	t.Run("JSON", testProgressJSON)
+}
+
+func testProgressReporter(t *testing.T) {
+	t.Parallel()
+	const (
+		name  = "file"
+		items = 1024
+		size  = 2
+	)
+	var nilReporter *progressReporter
+	nilReporter.add(name, size, 1) // Must not panic.
+	reporter := newProgressReporter()
+	// Nothing receives these events,
+	// so the reporter must drop them rather than block.
+	for i := 0; i < items; i++ {
+		reporter.add(name, size, 1)
+	}
+	want := progress{
+		Path:      name,
+		BytesDone: items * size,
+		ItemsDone: items,
+	}
+	select {
+	case got := <-reporter.events:
+		if got != want {
+			t.Errorf("progress mismatch"+
+				"\ngot: %+v"+
+				"\nwant: %+v",
+				got, want,
+			)
+		}
+	default:
+		t.Fatal("expected pending progress event")
+	}
+}
+
+func testProgressText(t *testing.T) {
+	t.Parallel()
+	var (
+		output   bytes.Buffer
+		renderer = progressRenderer{
+			output:   &output,
+			interval: time.Hour,
+		}
+		events = make(chan progress, 2)
+	)
+	events <- progress{Path: "first/long/path", ItemsDone: 1}
+	events <- progress{Path: "second", BytesDone: 8, ItemsDone: 2}
+	close(events)
+	if err := renderer.render(events); err != nil {
+		t.Fatal(err)
+	}
+	const want = "\r2 items, 8 bytes: second\n"
+	if got := output.String(); got != want {
+		t.Errorf("output mismatch"+
+			"\ngot: %q"+
+			"\nwant: %q",
+			got, want,
+		)
+	}
+}
+
+func testProgressJSON(t *testing.T) {
+	t.Parallel()
+	var (
+		output   bytes.Buffer
+		renderer = progressRenderer{
+			output:  &output,
+			encoder: json.NewEncoder(&output),
+		}
+		want = []progress{
+			{Path: "first", ItemsDone: 1},
+			{Path: "second", BytesDone: 8, ItemsDone: 2},
+		}
+		events = make(chan progress, len(want))
+	)
+	for _, event := range want {
+		events <- event
+	}
+	close(events)
+	if err := renderer.render(events); err != nil {
+		t.Fatal(err)
+	}
+	decoder := json.NewDecoder(strings.NewReader(output.String()))
+	for _, wantEvent := range want {
+		var got progress
+		if err := decoder.Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != wantEvent {
+			t.Errorf("progress mismatch"+
+				"\ngot: %+v"+
+				"\nwant: %+v",
+				got, wantEvent,
+			)
+		}
+	}
+	if decoder.More() {
+		t.Error("unexpected trailing output")
+	}
+}
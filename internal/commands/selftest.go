@@ -0,0 +1,369 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	selftestSettings[M fsMaker] struct {
+		guest      M
+		readLength int
+		json       bool
+	}
+	selftestOption[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] func(*selftestSettings[GM]) error
+	selftestOptions[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] []selftestOption[GT, GM, GC]
+	// selftestResult is the outcome
+	// of a single self-test operation.
+	selftestResult struct {
+		Operation string        `json:"operation"`
+		Status    testStatus    `json:"status"`
+		Duration  time.Duration `json:"duration"`
+		Detail    string        `json:"detail,omitempty"`
+	}
+	// selftestReport is the format of
+	// the self-test output.
+	selftestReport struct {
+		Guest   filesystem.ID    `json:"guest"`
+		Results []selftestResult `json:"results"`
+		Passed  int              `json:"passed"`
+		Failed  int              `json:"failed"`
+		Skipped int              `json:"skipped"`
+	}
+	testStatus string
+	// selftestRunner performs operations in sequence,
+	// recording their results.
+	selftestRunner struct {
+		results []selftestResult
+	}
+	errSkipped string
+)
+
+const (
+	testPassed  testStatus = "pass"
+	testFailed  testStatus = "fail"
+	testSkipped testStatus = "skip"
+
+	selftestReadLengthDefault = 512
+	errSelftestFailed         = generic.ConstError("self-test failed")
+)
+
+func (e errSkipped) Error() string { return string(e) }
+
+// Selftest constructs the command which
+// exercises guest file systems directly
+// (without mounting them).
+func Selftest() command.Command {
+	const (
+		name     = "selftest"
+		synopsis = "Test guest file system operations."
+	)
+	return makeGuestCommandGroup(name, synopsis, makeIPFSSelftestCommands())
+}
+
+func makeSelftestCommand[
+	GC fsCmdGuest[GT, GM],
+	GM fsMaker,
+	GT any,
+](guest filesystem.ID,
+) command.Command {
+	type (
+		SO  = selftestOption[GT, GM, GC]
+		SOS = selftestOptions[GT, GM, GC]
+	)
+	var (
+		guestFormalName = string(guest)
+		cmdName         = strings.ToLower(guestFormalName)
+		synopsis        = fmt.Sprintf(
+			"Test %s operations.", guestFormalName,
+		)
+		usage = guestCommandUsage[GC](guest, synopsis,
+			"performs a series of operations"+
+				"\nagainst it; reporting which succeeded, and how long they took."+
+				"\nAccepts an optional path (relative to the guest's root)"+
+				" to use as the test entry."+
+				"\nIf no path is provided, the first entry of the root is used."+
+				"\nExits with an error if any operations fail.",
+		)
+	)
+	return command.MakeVariadicCommand[SOS](cmdName, synopsis, usage,
+		func(ctx context.Context, arguments []string, options ...SO) error {
+			if err := checkGuestArguments(arguments, 0, 1, "at most 1 path"); err != nil {
+				return err
+			}
+			settings, err := SOS(options).make()
+			if err != nil {
+				return err
+			}
+			var entry string
+			if len(arguments) == 1 {
+				entry = arguments[0]
+			}
+			report := selftestReport{
+				Guest: guest,
+				Results: selftest(ctx, settings.guest,
+					entry, settings.readLength,
+				),
+			}
+			report.tally()
+			if settings.json {
+				err = json.NewEncoder(os.Stdout).Encode(report)
+			} else {
+				err = printSelftestReport(os.Stdout, &report)
+			}
+			if err != nil {
+				return err
+			}
+			if failed := report.Failed; failed != 0 {
+				return fmt.Errorf("%w: %d of %d operations",
+					errSelftestFailed, failed, len(report.Results),
+				)
+			}
+			return ctx.Err()
+		})
+}
+
+func (so *selftestOptions[GT, GM, GC]) BindFlags(flagSet *flag.FlagSet) {
+	type settings = selftestSettings[GM]
+	bindGuestFlags[GC](flagSet, so, func(guest GM, ss *settings) {
+		ss.guest = guest
+	})
+	const (
+		readName  = "read"
+		readUsage = "maximum number of `bytes` to read from the test entry" +
+			"\nif <= 0, file data is not read"
+	)
+	flagSetFunc(flagSet, readName, readUsage, so,
+		func(value int, ss *settings) error {
+			ss.readLength = value
+			return nil
+		})
+	flagSet.Lookup(readName).
+		DefValue = strconv.Itoa(selftestReadLengthDefault)
+	const (
+		jsonName  = "json"
+		jsonUsage = "print the results as JSON"
+	)
+	flagSetFunc(flagSet, jsonName, jsonUsage, so,
+		func(value bool, ss *settings) error {
+			ss.json = value
+			return nil
+		})
+}
+
+func (so selftestOptions[GT, GM, GC]) make() (selftestSettings[GM], error) {
+	settings := selftestSettings[GM]{
+		readLength: selftestReadLengthDefault,
+	}
+	return settings, generic.ApplyOptions(&settings, so...)
+}
+
+// selftest constructs the guest file system,
+// exercises it, then closes it.
+// If `entry` is empty, the first entry
+// of the root directory is used.
+func selftest(ctx context.Context, guest fsMaker, entry string, readLength int) []selftestResult {
+	var (
+		runner selftestRunner
+		fsys   fs.FS
+	)
+	if !runner.run(ctx, "construct", func() (string, error) {
+		var err error
+		fsys, err = guest.makeFS()
+		return "", err
+	}) {
+		return runner.results
+	}
+	selftestFS(ctx, &runner, fsys, entry, readLength)
+	runner.run(ctx, "close", func() (string, error) {
+		if closer, ok := fsys.(io.Closer); ok {
+			return "", closer.Close()
+		}
+		return "", errSkipped("file system does not need to be closed")
+	})
+	return runner.results
+}
+
+func selftestFS(ctx context.Context, runner *selftestRunner, fsys fs.FS, entry string, readLength int) {
+	runner.run(ctx, "stat root", func() (string, error) {
+		info, err := fs.Stat(fsys, filesystem.Root)
+		if err != nil {
+			return "", err
+		}
+		return info.Mode().String(), nil
+	})
+	runner.run(ctx, "read root", func() (string, error) {
+		entries, err := fs.ReadDir(fsys, filesystem.Root)
+		if err != nil {
+			return "", err
+		}
+		if entry == "" && len(entries) != 0 {
+			entry = entries[0].Name()
+		}
+		return fmt.Sprintf("%d entries", len(entries)), nil
+	})
+	if entry != "" {
+		entry = guestPath(entry)
+	}
+	var info fs.FileInfo
+	runner.run(ctx, "stat entry", func() (string, error) {
+		if entry == "" {
+			return "", errSkipped("no entry to test")
+		}
+		var err error
+		if info, err = fs.Stat(fsys, entry); err != nil {
+			return "", err
+		}
+		return entry + ": " + info.Mode().String(), nil
+	})
+	runner.run(ctx, "open entry", func() (string, error) {
+		if info == nil {
+			return "", errSkipped("entry not available")
+		}
+		if info.Mode().Type() == fs.ModeSymlink {
+			return "", errSkipped("entry is a symbolic link")
+		}
+		return readEntry(fsys, entry, info, readLength)
+	})
+	runner.run(ctx, "readlink", func() (string, error) {
+		if info == nil || info.Mode().Type() != fs.ModeSymlink {
+			return "", errSkipped("entry is not a symbolic link")
+		}
+		linker, ok := filesystem.Extension[filesystem.ReadlinkFS](fsys)
+		if !ok {
+			return "", errSkipped("file system does not support links")
+		}
+		return linker.Readlink(entry)
+	})
+}
+
+// readEntry opens the entry, reading the first
+// entry of directories or the first bytes of files.
+func readEntry(fsys fs.FS, name string, info fs.FileInfo, readLength int) (detail string, err error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { err = errors.Join(err, file.Close()) }()
+	if info.IsDir() {
+		directory, ok := file.(fs.ReadDirFile)
+		if !ok {
+			return "", fmt.Errorf("%T does not implement ReadDir", file)
+		}
+		entries, err := directory.ReadDir(1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return fmt.Sprintf("read %d entries", len(entries)), nil
+	}
+	if readLength <= 0 {
+		return "opened", nil
+	}
+	read, err := io.CopyN(io.Discard, file, int64(readLength))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return fmt.Sprintf("read %d bytes", read), nil
+}
+
+// run performs the operation (unless the context is done)
+// and reports whether it succeeded.
+// Operations may return [errSkipped] to indicate
+// they're not applicable.
+func (sr *selftestRunner) run(ctx context.Context, operation string, fn func() (string, error)) bool {
+	result := selftestResult{Operation: operation}
+	if err := ctx.Err(); err != nil {
+		result.Status = testSkipped
+		result.Detail = err.Error()
+		sr.results = append(sr.results, result)
+		return false
+	}
+	var (
+		start       = time.Now()
+		detail, err = fn()
+	)
+	result.Duration = time.Since(start)
+	var skipped errSkipped
+	switch {
+	case err == nil:
+		result.Status = testPassed
+		result.Detail = detail
+	case errors.As(err, &skipped):
+		result.Status = testSkipped
+		result.Duration = 0
+		result.Detail = err.Error()
+	default:
+		result.Status = testFailed
+		result.Detail = err.Error()
+	}
+	sr.results = append(sr.results, result)
+	return result.Status == testPassed
+}
+
+func (report *selftestReport) tally() {
+	for _, result := range report.Results {
+		switch result.Status {
+		case testPassed:
+			report.Passed++
+		case testFailed:
+			report.Failed++
+		case testSkipped:
+			report.Skipped++
+		}
+	}
+}
+
+func printSelftestReport(output io.Writer, report *selftestReport) error {
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	tabWriter := tabwriter.NewWriter(
+		output, minWidth, tabWidth, padding, padChar, flags,
+	)
+	if _, err := fmt.Fprintln(tabWriter,
+		"operation\tstatus\tduration\tdetail",
+	); err != nil {
+		return err
+	}
+	for _, result := range report.Results {
+		if _, err := fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n",
+			result.Operation, result.Status,
+			result.Duration.Round(time.Microsecond), result.Detail,
+		); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(tabWriter,
+		"\npassed:\t%d\nfailed:\t%d\nskipped:\t%d\n",
+		report.Passed, report.Failed, report.Skipped,
+	); err != nil {
+		return err
+	}
+	return tabWriter.Flush()
+}
@@ -0,0 +1,17 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+)
+
+func makeIPFSSelftestCommands() []command.Command {
+	return []command.Command{
+		makeSelftestCommand[*ipfsOptions, ipfsSettings](ipfs.IPFSID),
+		makeSelftestCommand[*pinFSOptions, pinFSSettings](ipfs.PinFSID),
+		makeSelftestCommand[*ipnsOptions, ipnsSettings](ipfs.IPNSID),
+		makeSelftestCommand[*keyFSOptions, keyFSSettings](ipfs.KeyFSID),
+	}
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type (
+	mapFSMaker struct {
+		fstest.MapFS
+		err error
+	}
+	closingMapFS struct {
+		fstest.MapFS
+		closed *bool
+	}
+	// linkMapFS reports `linkName` as a symbolic link,
+	// without following it.
+	linkMapFS struct {
+		fstest.MapFS
+	}
+	linkInfo struct{}
+)
+
+const (
+	linkName   = "link"
+	linkTarget = "dir/file"
+)
+
+func (lm linkMapFS) makeFS() (fs.FS, error) { return lm, nil }
+
+func (lm linkMapFS) Stat(name string) (fs.FileInfo, error) {
+	if name == linkName {
+		return linkInfo{}, nil
+	}
+	return fs.Stat(lm.MapFS, name)
+}
+
+func (lm linkMapFS) Symlink(oldname, newname string) error {
+	return fs.ErrPermission
+}
+
+func (lm linkMapFS) Readlink(name string) (string, error) {
+	if name == linkName {
+		return linkTarget, nil
+	}
+	return "", fs.ErrInvalid
+}
+
+func (linkInfo) Name() string       { return linkName }
+func (linkInfo) Size() int64        { return int64(len(linkTarget)) }
+func (linkInfo) Mode() fs.FileMode  { return fs.ModeSymlink | 0o777 }
+func (linkInfo) ModTime() time.Time { return time.Time{} }
+func (linkInfo) IsDir() bool        { return false }
+func (linkInfo) Sys() any           { return nil }
+
+func (mm mapFSMaker) makeFS() (fs.FS, error) { return mm.MapFS, mm.err }
+
+func (cm closingMapFS) makeFS() (fs.FS, error) { return cm, nil }
+
+func (cm closingMapFS) Close() error {
+	*cm.closed = true
+	return nil
+}
+
+func TestSelftest(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		linkTarget: &fstest.MapFile{Data: []byte("data")},
+	}
+	for _, test := range []struct {
+		name, entry string
+		guest       fsMaker
+		want        []testStatus
+	}{
+		{
+			name:  "directory",
+			guest: mapFSMaker{MapFS: fsys},
+			want: []testStatus{
+				testPassed, testPassed, testPassed,
+				testPassed, testPassed, testSkipped, testSkipped,
+			},
+		},
+		{
+			name:  "file",
+			entry: "/dir/file",
+			guest: mapFSMaker{MapFS: fsys},
+			want: []testStatus{
+				testPassed, testPassed, testPassed,
+				testPassed, testPassed, testSkipped, testSkipped,
+			},
+		},
+		{
+			name:  "link",
+			entry: linkName,
+			guest: linkMapFS{MapFS: fsys},
+			want: []testStatus{
+				testPassed, testPassed, testPassed,
+				testPassed, testSkipped, testPassed, testSkipped,
+			},
+		},
+		{
+			name:  "missing",
+			entry: "missing",
+			guest: mapFSMaker{MapFS: fsys},
+			want: []testStatus{
+				testPassed, testPassed, testPassed,
+				testFailed, testSkipped, testSkipped, testSkipped,
+			},
+		},
+		{
+			name:  "empty",
+			guest: mapFSMaker{MapFS: fstest.MapFS{}},
+			want: []testStatus{
+				testPassed, testPassed, testPassed,
+				testSkipped, testSkipped, testSkipped, testSkipped,
+			},
+		},
+		{
+			name:  "construct",
+			guest: mapFSMaker{err: errors.New("unreachable")},
+			want:  []testStatus{testFailed},
+		},
+	} {
+		var (
+			entry = test.entry
+			guest = test.guest
+			want  = test.want
+		)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			results := selftest(context.Background(), guest, entry, selftestReadLengthDefault)
+			if got := resultStatuses(results); !reflect.DeepEqual(got, want) {
+				t.Errorf("status mismatch"+
+					"\ngot: %v"+
+					"\nwant: %v"+
+					"\nresults: %+v",
+					got, want, results,
+				)
+			}
+		})
+	}
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+		var (
+			closed  bool
+			guest   = closingMapFS{MapFS: fsys, closed: &closed}
+			results = selftest(context.Background(), guest, "", 0)
+		)
+		if !closed {
+			t.Error("guest was not closed")
+		}
+		if last := results[len(results)-1]; last.Status != testPassed {
+			t.Errorf("close result mismatch"+
+				"\ngot: %+v"+
+				"\nwant: %s",
+				last, testPassed,
+			)
+		}
+	})
+}
+
+// resultStatuses returns the status of each
+// result, in the order they were performed.
+func resultStatuses(results []selftestResult) []testStatus {
+	statuses := make([]testStatus, len(results))
+	for i, result := range results {
+		statuses[i] = result.Status
+	}
+	return statuses
+}
@@ -48,4 +48,9 @@ const (
 	// by writing a [shutdownDisposition] (string or byte)
 	// value to the file.
 	shutdownFileName = "shutdown"
+
+	// statusFileName is the name used by servers
+	// to host a 9P file which reports the service's
+	// status (as JSON) when read.
+	statusFileName = "status"
 )
@@ -0,0 +1,274 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/djdv/p9/p9"
+	"github.com/multiformats/go-multiaddr"
+)
+
+type (
+	// ServiceStatus is the format of the
+	// service's status file.
+	ServiceStatus struct {
+		Started     time.Time `json:"started"`
+		Uptime      string    `json:"uptime"`
+		Shutdown    string    `json:"shutdown,omitempty"`
+		Listeners   []string  `json:"listeners"`
+		Mounts      int       `json:"mounts"`
+		Connections int       `json:"connections"`
+		Stopping    bool      `json:"stopping"`
+		// Guests describes the health
+		// of each mounted guest.
+		Guests []GuestStatus `json:"guests,omitempty"`
+	}
+	// GuestStatus is the result of
+	// probing a mounted guest.
+	GuestStatus struct {
+		Host        string    `json:"host"`
+		Guest       string    `json:"guest"`
+		Target      string    `json:"target"`
+		LastProbe   time.Time `json:"lastProbe"`
+		LastSuccess time.Time `json:"lastSuccess"`
+		LastError   string    `json:"lastError,omitempty"`
+		// Failures is the amount of
+		// consecutive probes which failed.
+		Failures int `json:"failures,omitempty"`
+		// Unhealthy is set when failures reach the daemon's
+		// threshold; the guest may need to be remounted.
+		Unhealthy bool `json:"unhealthy,omitempty"`
+		// Handles is the amount of files
+		// the host is holding open.
+		Handles int `json:"handles,omitempty"`
+	}
+	// listenerStatus is the format used when
+	// only listeners are requested.
+	listenerStatus struct {
+		Listeners   []string `json:"listeners"`
+		Connections int      `json:"connections"`
+	}
+	statusSettings struct {
+		clientSettings
+		json, listeners bool
+	}
+	statusOption  func(*statusSettings) error
+	statusOptions []statusOption
+)
+
+// Status constructs the command which
+// requests the file system service's status.
+func Status() command.Command {
+	const (
+		name     = "status"
+		synopsis = "Query the system service."
+	)
+	usage := header("Status") +
+		"\n\nRetrieve the status of the file system service." +
+		"\nWhen only listeners are requested, each listener's" +
+		"\nmultiaddr is printed on its own line (for use by scripts)," +
+		"\nfollowed by the amount of active connections."
+	return command.MakeVariadicCommand[statusOptions](name, synopsis, usage, statusExecute)
+}
+
+func (so *statusOptions) BindFlags(flagSet *flag.FlagSet) {
+	var clientOptions clientOptions
+	(&clientOptions).BindFlags(flagSet)
+	*so = append(*so, func(ss *statusSettings) error {
+		subset, err := clientOptions.make()
+		if err != nil {
+			return err
+		}
+		ss.clientSettings = subset
+		return nil
+	})
+	const (
+		jsonName  = "json"
+		jsonUsage = "print the status as JSON"
+	)
+	flagSetFunc(flagSet, jsonName, jsonUsage, so,
+		func(value bool, settings *statusSettings) error {
+			settings.json = value
+			return nil
+		})
+	const (
+		listenersName  = "listeners"
+		listenersUsage = "only print the service's listener addresses and connection count"
+	)
+	flagSetFunc(flagSet, listenersName, listenersUsage, so,
+		func(value bool, settings *statusSettings) error {
+			settings.listeners = value
+			return nil
+		})
+}
+
+func (so statusOptions) make() (statusSettings, error) {
+	return makeWithOptions(so...)
+}
+
+func statusExecute(ctx context.Context, options ...statusOption) error {
+	settings, err := statusOptions(options).make()
+	if err != nil {
+		return err
+	}
+	const autoLaunchDaemon = false
+	client, err := settings.getClient(autoLaunchDaemon)
+	if err != nil {
+		return fmt.Errorf("could not get client (server down?): %w", err)
+	}
+	if settings.listeners {
+		err = queryListeners(client, settings.json)
+	} else {
+		err = queryStatus(client, settings.json)
+	}
+	if err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func queryListeners(client *Client, asJSON bool) error {
+	maddrs, connections, err := client.listenerStatus()
+	if err != nil {
+		return errors.Join(err, client.Close())
+	}
+	if err := client.Close(); err != nil {
+		return err
+	}
+	return printListeners(os.Stdout, maddrs, connections, asJSON)
+}
+
+func queryStatus(client *Client, asJSON bool) error {
+	data, err := client.statusData()
+	if err != nil {
+		return errors.Join(err, client.Close())
+	}
+	if err := client.Close(); err != nil {
+		return err
+	}
+	if asJSON {
+		_, err := os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	var status ServiceStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return err
+	}
+	return printStatus(os.Stdout, &status)
+}
+
+// printListeners prints each multiaddr on its own line,
+// followed by the connection count.
+// Or as a JSON object containing both.
+// (Listeners are an empty array rather than null.)
+func printListeners(output io.Writer, maddrs []multiaddr.Multiaddr,
+	connections int, asJSON bool,
+) error {
+	maddrStrings := make([]string, len(maddrs))
+	for i, maddr := range maddrs {
+		maddrStrings[i] = maddr.String()
+	}
+	if asJSON {
+		return json.NewEncoder(output).Encode(listenerStatus{
+			Listeners:   maddrStrings,
+			Connections: connections,
+		})
+	}
+	for _, maddr := range maddrStrings {
+		if _, err := fmt.Fprintln(output, maddr); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(output, "connections: %d\n", connections)
+	return err
+}
+
+func printStatus(output io.Writer, status *ServiceStatus) error {
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	tabWriter := tabwriter.NewWriter(
+		output, minWidth, tabWidth, padding, padChar, flags,
+	)
+	shutdown := "no"
+	if status.Stopping {
+		shutdown = status.Shutdown
+	}
+	for _, pair := range []struct {
+		key, value string
+	}{
+		{key: "started", value: status.Started.Format(time.RFC3339)},
+		{key: "uptime", value: status.Uptime},
+		{key: "listeners", value: strings.Join(status.Listeners, ", ")},
+		{key: "mounts", value: fmt.Sprint(status.Mounts)},
+		{key: "connections", value: fmt.Sprint(status.Connections)},
+		{key: "shutdown", value: shutdown},
+		{key: "unhealthy", value: unhealthyTargets(status.Guests)},
+	} {
+		if _, err := fmt.Fprintf(tabWriter,
+			"%s:\t%s\n", pair.key, pair.value,
+		); err != nil {
+			return err
+		}
+	}
+	return tabWriter.Flush()
+}
+
+func unhealthyTargets(guests []GuestStatus) string {
+	var targets []string
+	for _, guest := range guests {
+		if guest.Unhealthy {
+			targets = append(targets, guest.Target)
+		}
+	}
+	if targets == nil {
+		return "none"
+	}
+	return strings.Join(targets, ", ")
+}
+
+// Status retrieves the status of the service.
+func (c *Client) Status() (ServiceStatus, error) {
+	data, err := c.statusData()
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	var status ServiceStatus
+	return status, json.Unmarshal(data, &status)
+}
+
+func (c *Client) statusData() ([]byte, error) {
+	controlDir, err := (*p9.Client)(c).Attach(controlFileName)
+	if err != nil {
+		return nil, err
+	}
+	_, statusFile, err := controlDir.Walk([]string{statusFileName})
+	if err != nil {
+		err = receiveError(controlDir, err)
+		return nil, errors.Join(err, controlDir.Close())
+	}
+	data, err := p9fs.ReadAll(statusFile)
+	if err != nil {
+		err = receiveError(controlDir, err)
+		return nil, errors.Join(err, statusFile.Close(), controlDir.Close())
+	}
+	if len(data) == 0 {
+		err = generic.ConstError("service returned empty status")
+	}
+	return data, errors.Join(err, statusFile.Close(), controlDir.Close())
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/multiformats/go-multiaddr"
+)
+
+func TestPrintListeners(t *testing.T) {
+	t.Parallel()
+	var maddrs []multiaddr.Multiaddr
+	for _, maddr := range []string{
+		"/ip4/127.0.0.1/tcp/564",
+		"/unix/tmp/fs.sock",
+	} {
+		maddrs = append(maddrs, multiaddr.StringCast(maddr))
+	}
+	for _, test := range []struct {
+		name        string
+		maddrs      []multiaddr.Multiaddr
+		connections int
+		asJSON      bool
+		want        string
+	}{
+		{
+			name:        "lines",
+			maddrs:      maddrs,
+			connections: 2,
+			want: "/ip4/127.0.0.1/tcp/564\n/unix/tmp/fs.sock\n" +
+				"connections: 2\n",
+		},
+		{
+			name:        "json",
+			maddrs:      maddrs,
+			connections: 2,
+			asJSON:      true,
+			want: `{"listeners":["/ip4/127.0.0.1/tcp/564","/unix/tmp/fs.sock"],` +
+				`"connections":2}` + "\n",
+		},
+		{
+			name:   "empty json",
+			asJSON: true,
+			want:   `{"listeners":[],"connections":0}` + "\n",
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var output bytes.Buffer
+			if err := printListeners(&output, test.maddrs, test.connections, test.asJSON); err != nil {
+				t.Fatal(err)
+			}
+			if got := output.String(); got != test.want {
+				t.Errorf("output mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					got, test.want,
+				)
+			}
+		})
+	}
+}
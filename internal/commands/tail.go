@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	tailSettings[M fsMaker] struct {
+		guest    M
+		interval time.Duration
+		follow   bool
+	}
+	tailOption[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] func(*tailSettings[GM]) error
+	tailOptions[
+		GT any,
+		GM fsMaker,
+		GC fsCmdGuest[GT, GM],
+	] []tailOption[GT, GM, GC]
+	// tailFollower copies a file's data to its output,
+	// and tracks how much of the file was copied.
+	tailFollower struct {
+		fsys     fs.FS
+		output   io.Writer
+		messages io.Writer
+		name     string
+		offset   int64
+	}
+)
+
+const tailIntervalDefault = 1 * time.Second
+
+// Tail constructs the command which
+// prints (and optionally follows) guest files.
+func Tail() command.Command {
+	const (
+		name     = "tail"
+		synopsis = "Print and follow guest files."
+	)
+	return makeGuestCommandGroup(name, synopsis, makeIPFSTailCommands())
+}
+
+// makeTailCommand makes a tail command for the guest.
+// If `immutable` is true, files within the guest can
+// never change, so they are printed but not followed.
+func makeTailCommand[
+	GC fsCmdGuest[GT, GM],
+	GM fsMaker,
+	GT any,
+](guest filesystem.ID, immutable bool,
+) command.Command {
+	type (
+		TO  = tailOption[GT, GM, GC]
+		TOS = tailOptions[GT, GM, GC]
+	)
+	var (
+		guestFormalName = string(guest)
+		cmdName         = strings.ToLower(guestFormalName)
+		synopsis        = fmt.Sprintf(
+			"Print and follow a %s file.", guestFormalName,
+		)
+		usage = guestCommandUsage[GC](guest, synopsis,
+			"prints the file at the provided path"+
+				"\n(relative to the guest's root)."+
+				"\nIf following, the file is polled for changes in size"+
+				" and new data is printed as it's appended."+
+				"\nIf the file shrinks (E.g. it was truncated or replaced),"+
+				" it's printed again from the beginning.",
+		)
+	)
+	return command.MakeVariadicCommand[TOS](cmdName, synopsis, usage,
+		func(ctx context.Context, arguments []string, options ...TO) error {
+			if err := checkGuestArguments(arguments, 1, 1, "1 path"); err != nil {
+				return err
+			}
+			settings, err := TOS(options).make()
+			if err != nil {
+				return err
+			}
+			return withGuestFS(settings.guest, func(fsys fs.FS) error {
+				tailer := tailFollower{
+					fsys:     fsys,
+					output:   os.Stdout,
+					messages: os.Stderr,
+					name:     guestPath(arguments[0]),
+				}
+				return tailer.tail(ctx, settings.follow, immutable, settings.interval)
+			})
+		})
+}
+
+func (to *tailOptions[GT, GM, GC]) BindFlags(flagSet *flag.FlagSet) {
+	type settings = tailSettings[GM]
+	bindGuestFlags[GC](flagSet, to, func(guest GM, ts *settings) {
+		ts.guest = guest
+	})
+	const (
+		followName  = "f"
+		followUsage = "follow the file, printing data as it's appended" +
+			"\n(until interrupted)"
+	)
+	flagSetFunc(flagSet, followName, followUsage, to,
+		func(value bool, ts *settings) error {
+			ts.follow = value
+			return nil
+		})
+	const (
+		intervalName  = "interval"
+		intervalUsage = "`duration` between checks for changes" +
+			" when following"
+	)
+	flagSetFunc(flagSet, intervalName, intervalUsage, to,
+		func(value time.Duration, ts *settings) error {
+			if value <= 0 {
+				return generic.ConstError("must be positive")
+			}
+			ts.interval = value
+			return nil
+		})
+	flagSet.Lookup(intervalName).
+		DefValue = tailIntervalDefault.String()
+}
+
+func (to tailOptions[GT, GM, GC]) make() (tailSettings[GM], error) {
+	settings := tailSettings[GM]{
+		interval: tailIntervalDefault,
+	}
+	return settings, generic.ApplyOptions(&settings, to...)
+}
+
+func (tf *tailFollower) tail(ctx context.Context, follow, immutable bool, interval time.Duration) error {
+	info, err := fs.Stat(tf.fsys, tf.name)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf(`"%s" is a directory`, tf.name)
+	}
+	if err := tf.copyTo(info.Size()); err != nil {
+		return err
+	}
+	if !follow {
+		return nil
+	}
+	if immutable {
+		_, err := fmt.Fprintf(tf.messages,
+			"%s: file can not change, not following\n",
+			tf.name,
+		)
+		return err
+	}
+	return tf.follow(ctx, interval)
+}
+
+// follow polls the file until the context is done.
+func (tf *tailFollower) follow(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := tf.poll(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (tf *tailFollower) poll() error {
+	info, err := fs.Stat(tf.fsys, tf.name)
+	if err != nil {
+		return err
+	}
+	// NOTE: Files are only identified by their path,
+	// so a replacement is indistinguishable from
+	// an append; unless the new file is smaller.
+	switch size := info.Size(); {
+	case size < tf.offset:
+		tf.offset = 0
+		if _, err := fmt.Fprintf(tf.messages,
+			"%s: file truncated\n", tf.name,
+		); err != nil {
+			return err
+		}
+		return tf.copyTo(size)
+	case size > tf.offset:
+		return tf.copyTo(size)
+	default:
+		return nil
+	}
+}
+
+// copyTo copies data from the current offset
+// up to `size` (or the end of the file, if it
+// was truncated since its size was checked).
+func (tf *tailFollower) copyTo(size int64) (err error) {
+	file, err := tf.fsys.Open(tf.name)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, file.Close()) }()
+	if tf.offset != 0 {
+		if err := skipTo(file, tf.offset); err != nil {
+			return err
+		}
+	}
+	copied, err := io.CopyN(tf.output, file, size-tf.offset)
+	tf.offset += copied
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func skipTo(file fs.File, offset int64) error {
+	if seeker, ok := file.(io.Seeker); ok {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, file, offset)
+	return err
+}
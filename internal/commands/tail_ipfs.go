@@ -0,0 +1,23 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+)
+
+func makeIPFSTailCommands() []command.Command {
+	// IPFS and PinFS paths are content addressed,
+	// while IPNS (and KeyFS) names may be republished.
+	const (
+		immutable = true
+		mutable   = false
+	)
+	return []command.Command{
+		makeTailCommand[*ipfsOptions, ipfsSettings](ipfs.IPFSID, immutable),
+		makeTailCommand[*pinFSOptions, pinFSSettings](ipfs.PinFSID, immutable),
+		makeTailCommand[*ipnsOptions, ipnsSettings](ipfs.IPNSID, mutable),
+		makeTailCommand[*keyFSOptions, keyFSSettings](ipfs.KeyFSID, mutable),
+	}
+}
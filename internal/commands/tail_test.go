@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type (
+	// growingFS serves snapshots of a
+	// single file, which may be modified.
+	growingFS struct {
+		mu   sync.Mutex
+		data []byte
+	}
+	lockedBuffer struct {
+		mu     sync.Mutex
+		buffer bytes.Buffer
+	}
+)
+
+const growingFileName = "log"
+
+func (gf *growingFS) snapshot() fstest.MapFS {
+	gf.mu.Lock()
+	defer gf.mu.Unlock()
+	return fstest.MapFS{
+		growingFileName: &fstest.MapFile{
+			Data: append([]byte(nil), gf.data...),
+		},
+	}
+}
+
+func (gf *growingFS) Open(name string) (fs.File, error) {
+	return gf.snapshot().Open(name)
+}
+
+func (gf *growingFS) set(data string) {
+	gf.mu.Lock()
+	defer gf.mu.Unlock()
+	gf.data = []byte(data)
+}
+
+func (gf *growingFS) append(data string) {
+	gf.mu.Lock()
+	defer gf.mu.Unlock()
+	gf.data = append(gf.data, data...)
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buffer.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.buffer.String()
+}
+
+func TestTail(t *testing.T) {
+	t.Parallel()
+	t.Run("immutable", func(t *testing.T) {
+		t.Parallel()
+		const data = "static"
+		var (
+			fsys     = &growingFS{data: []byte(data)}
+			output   lockedBuffer
+			messages lockedBuffer
+			tailer   = tailFollower{
+				fsys:     fsys,
+				output:   &output,
+				messages: &messages,
+				name:     growingFileName,
+			}
+		)
+		const (
+			follow    = true
+			immutable = true
+		)
+		if err := tailer.tail(context.Background(), follow, immutable, time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+		if got := output.String(); got != data {
+			t.Errorf("output mismatch"+
+				"\ngot: %q"+
+				"\nwant: %q",
+				got, data,
+			)
+		}
+		if messages.String() == "" {
+			t.Error("expected a message about not following")
+		}
+	})
+	t.Run("follow", func(t *testing.T) {
+		t.Parallel()
+		var (
+			fsys        = &growingFS{data: []byte("first\n")}
+			output      lockedBuffer
+			messages    lockedBuffer
+			ctx, cancel = context.WithCancel(context.Background())
+			tailer      = tailFollower{
+				fsys:     fsys,
+				output:   &output,
+				messages: &messages,
+				name:     growingFileName,
+			}
+			errs = make(chan error, 1)
+		)
+		defer cancel()
+		go func() {
+			const (
+				follow    = true
+				immutable = false
+				interval  = time.Millisecond
+			)
+			errs <- tailer.tail(ctx, follow, immutable, interval)
+		}()
+		waitForOutput(t, &output, "first\n")
+		fsys.append("second\n")
+		waitForOutput(t, &output, "first\nsecond\n")
+		fsys.set("new\n")
+		waitForOutput(t, &output, "first\nsecond\nnew\n")
+		if got := messages.String(); !strings.Contains(got, "truncated") {
+			t.Errorf("expected truncation message, got: %q", got)
+		}
+		cancel()
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func waitForOutput(t *testing.T, output *lockedBuffer, want string) {
+	t.Helper()
+	const (
+		timeout  = 5 * time.Second
+		interval = time.Millisecond
+	)
+	deadline := time.Now().Add(timeout)
+	for {
+		got := output.String()
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("output mismatch"+
+				"\ngot: %q"+
+				"\nwant: %q",
+				got, want,
+			)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+)
+
+type (
+	// unbuiltCommand is registered in place of commands
+	// whose system was excluded by build constraints,
+	// so that invoking them explains why they're unavailable.
+	unbuiltCommand struct {
+		name string
+		err  unbuiltError
+	}
+	// unbuiltError describes a system which
+	// was excluded by build constraints.
+	unbuiltError struct {
+		system, tag string
+	}
+)
+
+// makeUnbuiltCommand returns a command named after the system.
+func makeUnbuiltCommand(system, tag string) command.Command {
+	return &unbuiltCommand{
+		name: strings.ToLower(system),
+		err: unbuiltError{
+			system: system,
+			tag:    tag,
+		},
+	}
+}
+
+func (ue unbuiltError) Error() string {
+	return fmt.Sprintf(
+		"%s support was not built into this binary"+
+			" (rebuild without -tags=%s)",
+		ue.system, ue.tag,
+	)
+}
+
+func (uc *unbuiltCommand) Name() string                { return uc.name }
+func (uc *unbuiltCommand) Synopsis() string            { return uc.err.Error() }
+func (uc *unbuiltCommand) Usage() string               { return uc.err.Error() }
+func (*unbuiltCommand) Subcommands() []command.Command { return nil }
+
+// Execute returns the same error, regardless of
+// the arguments; which are not parsed, since their
+// flags would not be defined.
+func (uc *unbuiltCommand) Execute(context.Context, ...string) error {
+	return uc.err
+}
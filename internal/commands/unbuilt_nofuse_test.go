@@ -0,0 +1,10 @@
+//go:build nofuse
+
+package commands
+
+import "testing"
+
+func TestUnbuiltFUSE(t *testing.T) {
+	t.Parallel()
+	testUnbuilt(t, makeFUSECommand(), "nofuse")
+}
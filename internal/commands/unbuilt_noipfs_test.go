@@ -0,0 +1,25 @@
+//go:build noipfs
+
+package commands
+
+import "testing"
+
+func TestUnbuiltIPFS(t *testing.T) {
+	t.Parallel()
+	const tag = "noipfs"
+	commands := append(
+		makeUnbuiltIPFSCommands(),
+		makeVerifyPinsCommand(),
+		makeWarmIPFSCommand(),
+		makeKeyListCommand(),
+		makeKeyGenerateCommand(),
+		makeKeyRemoveCommand(),
+	)
+	for _, cmd := range commands {
+		cmd := cmd
+		t.Run(cmd.Name(), func(t *testing.T) {
+			t.Parallel()
+			testUnbuilt(t, cmd, tag)
+		})
+	}
+}
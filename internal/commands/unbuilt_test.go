@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+)
+
+// testUnbuilt checks that the command explains
+// which build tag excluded it, even if it was
+// called with arguments it can not parse.
+func testUnbuilt(t *testing.T, cmd command.Command, tag string) {
+	t.Helper()
+	err := cmd.Execute(context.Background(), "-undefined-flag", "argument")
+	var unbuiltErr unbuiltError
+	if !errors.As(err, &unbuiltErr) {
+		t.Fatalf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %T",
+			err, unbuiltErr,
+		)
+	}
+	if got := unbuiltErr.tag; got != tag {
+		t.Errorf("tag mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, tag,
+		)
+	}
+	if want := "-tags=" + tag; !strings.Contains(err.Error(), want) {
+		t.Errorf("error message does not mention \"%s\": %s", want, err)
+	}
+}
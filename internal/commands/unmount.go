@@ -6,6 +6,10 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/djdv/go-filesystem-utils/internal/command"
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
@@ -16,22 +20,35 @@ import (
 
 type (
 	unmountSettings struct {
-		all bool
+		all, cancel bool
 	}
 	UnmountOption      func(*unmountSettings) error
 	unmountCmdSettings struct {
 		clientSettings
 		apiOptions []UnmountOption
+		check      bool
 	}
 	unmountCmdOption  func(*unmountCmdSettings) error
 	unmountCmdOptions []unmountCmdOption
 	decodeFunc        func([]byte) (string, error)
 	decoders          map[filesystem.Host]decodeFunc
+	// UnmountCheck describes a mount point
+	// which could be unmounted.
+	UnmountCheck struct {
+		Target string
+		// Handles is the amount of files
+		// the host is holding open.
+		Handles int
+	}
 )
 
 const (
 	errUnmountMixed = generic.ConstError(`cannot combine "all" option with arguments`)
 	errUnmountEmpty = generic.ConstError(`neither parameters nor "all" option was provided`)
+	errCancelAll    = generic.ConstError(`cannot combine "cancel" option with "all" option`)
+	errCheckCancel  = generic.ConstError(`cannot combine "check" option with "cancel" option`)
+	errNotMounted   = generic.ConstError("not mounted")
+	errUnmountBusy  = generic.ConstError("mount point has open handles")
 )
 
 func UnmountAll(b bool) UnmountOption {
@@ -41,6 +58,15 @@ func UnmountAll(b bool) UnmountOption {
 	}
 }
 
+// UnmountCancel aborts pending mount operations
+// for the targets, rather than unmounting them.
+func UnmountCancel(b bool) UnmountOption {
+	return func(us *unmountSettings) error {
+		us.cancel = b
+		return nil
+	}
+}
+
 func (uo *unmountCmdOptions) BindFlags(flagSet *flag.FlagSet) {
 	var clientOptions clientOptions
 	(&clientOptions).BindFlags(flagSet)
@@ -61,6 +87,24 @@ func (uo *unmountCmdOptions) BindFlags(flagSet *flag.FlagSet) {
 			settings.apiOptions = append(settings.apiOptions, UnmountAll(value))
 			return nil
 		})
+	const (
+		cancelName  = "cancel"
+		cancelUsage = "cancel pending mounts instead of unmounting"
+	)
+	flagSetFunc(flagSet, cancelName, cancelUsage, uo,
+		func(value bool, settings *unmountCmdSettings) error {
+			settings.apiOptions = append(settings.apiOptions, UnmountCancel(value))
+			return nil
+		})
+	const (
+		checkName  = "check"
+		checkUsage = "report whether targets are busy instead of unmounting"
+	)
+	flagSetFunc(flagSet, checkName, checkUsage, uo,
+		func(value bool, settings *unmountCmdSettings) error {
+			settings.check = value
+			return nil
+		})
 }
 
 func (uo unmountCmdOptions) make() (unmountCmdSettings, error) {
@@ -76,7 +120,10 @@ func Unmount() command.Command {
 	)
 	usage := header("Unmount") +
 		"\n\n" + synopsis +
-		"\nAccepts mountpoints as arguments."
+		"\nAccepts mountpoints as arguments." +
+		"\nPending mounts may be aborted with the cancel flag." +
+		"\nThe check flag reports whether targets have open handles" +
+		" without unmounting them, exiting with an error if any do."
 	return command.MakeVariadicCommand[unmountCmdOptions](name, synopsis, usage, unmountExecute)
 }
 
@@ -91,12 +138,11 @@ func unmountExecute(ctx context.Context, arguments []string, options ...unmountC
 		return err
 	}
 	apiOptions := settings.apiOptions
+	if settings.check {
+		return unmountCheckExecute(ctx, client, arguments, apiOptions...)
+	}
 	if err := client.Unmount(ctx, arguments, apiOptions...); err != nil {
-		if errors.Is(err, errUnmountEmpty) ||
-			errors.Is(err, errUnmountMixed) {
-			err = command.UsageError{Err: err}
-		}
-		return errors.Join(err, client.Close())
+		return errors.Join(unmountUsageError(err), client.Close())
 	}
 	if err := client.Close(); err != nil {
 		return err
@@ -104,23 +150,84 @@ func unmountExecute(ctx context.Context, arguments []string, options ...unmountC
 	return ctx.Err()
 }
 
+func unmountCheckExecute(ctx context.Context, client *Client,
+	targets []string, options ...UnmountOption,
+) error {
+	checks, err := client.CheckUnmount(targets, options...)
+	if err := errors.Join(err, client.Close()); err != nil {
+		// Report what was found before failing.
+		return errors.Join(
+			unmountUsageError(err),
+			printUnmountChecks(os.Stdout, checks),
+		)
+	}
+	if err := printUnmountChecks(os.Stdout, checks); err != nil {
+		return err
+	}
+	if busy := busyTargets(checks); busy != nil {
+		return fmt.Errorf(
+			"%w: %s",
+			errUnmountBusy, strings.Join(busy, ", "),
+		)
+	}
+	return ctx.Err()
+}
+
+func unmountUsageError(err error) error {
+	if errors.Is(err, errUnmountEmpty) ||
+		errors.Is(err, errUnmountMixed) ||
+		errors.Is(err, errCancelAll) ||
+		errors.Is(err, errCheckCancel) {
+		return command.UsageError{Err: err}
+	}
+	return err
+}
+
+func printUnmountChecks(output io.Writer, checks []UnmountCheck) error {
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	tabWriter := tabwriter.NewWriter(
+		output, minWidth, tabWidth, padding, padChar, flags,
+	)
+	for _, check := range checks {
+		state := "clean"
+		if handles := check.Handles; handles != 0 {
+			state = fmt.Sprintf("busy (%d open handles)", handles)
+		}
+		if _, err := fmt.Fprintf(tabWriter,
+			"%s:\t%s\n", check.Target, state,
+		); err != nil {
+			return err
+		}
+	}
+	return tabWriter.Flush()
+}
+
+func busyTargets(checks []UnmountCheck) []string {
+	var busy []string
+	for _, check := range checks {
+		if check.Handles != 0 {
+			busy = append(busy, check.Target)
+		}
+	}
+	return busy
+}
+
 func (c *Client) Unmount(ctx context.Context, targets []string, options ...UnmountOption) error {
 	settings, err := makeWithOptions(options...)
 	if err != nil {
 		return err
 	}
-	var (
-		unmountAll  = settings.all
-		haveTargets = len(targets) != 0
-	)
-	if unmountAll && haveTargets {
-		return fmt.Errorf(
-			"%w: %v",
-			errUnmountMixed, targets,
-		)
+	if err := settings.validate(targets); err != nil {
+		return err
 	}
-	if !haveTargets && !unmountAll {
-		return errUnmountEmpty
+	if settings.all && settings.cancel {
+		return errCancelAll
 	}
 	mounts, err := (*p9.Client)(c).Attach(mountsFileName)
 	if err != nil {
@@ -133,14 +240,101 @@ func (c *Client) Unmount(ctx context.Context, targets []string, options ...Unmou
 		}
 		return mounts.Close()
 	}
-	decodeFn := newDecodeTargetFunc()
-	if err := p9fs.UnmountTargets(mounts, targets, decodeFn); err != nil {
+	var (
+		decodeFn  = newDecodeTargetFunc()
+		unmountFn = p9fs.UnmountTargets
+	)
+	if settings.cancel {
+		unmountFn = p9fs.CancelMounts
+	}
+	if err := unmountFn(mounts, targets, decodeFn); err != nil {
 		err = receiveError(mounts, err)
 		return errors.Join(err, mounts.Close())
 	}
 	return mounts.Close()
 }
 
+// CheckUnmount reports the open handles of each target,
+// without unmounting them. Targets which are not
+// mounted (including pending mounts) are reported
+// via the returned error.
+func (c *Client) CheckUnmount(targets []string, options ...UnmountOption) ([]UnmountCheck, error) {
+	settings, err := makeWithOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.validate(targets); err != nil {
+		return nil, err
+	}
+	if settings.cancel {
+		return nil, errCheckCancel
+	}
+	status, err := c.Status()
+	if err != nil {
+		return nil, err
+	}
+	if settings.all {
+		targets = nil
+	}
+	return checkUnmount(status.Guests, targets)
+}
+
+func (settings *unmountSettings) validate(targets []string) error {
+	var (
+		unmountAll  = settings.all
+		haveTargets = len(targets) != 0
+	)
+	if unmountAll && haveTargets {
+		return fmt.Errorf(
+			"%w: %v",
+			errUnmountMixed, targets,
+		)
+	}
+	if !haveTargets && !unmountAll {
+		return errUnmountEmpty
+	}
+	return nil
+}
+
+// checkUnmount returns the check for each target
+// within guests (or all guests if targets is nil).
+func checkUnmount(guests []GuestStatus, targets []string) ([]UnmountCheck, error) {
+	if targets == nil {
+		checks := make([]UnmountCheck, len(guests))
+		for i, guest := range guests {
+			checks[i] = UnmountCheck{
+				Target:  guest.Target,
+				Handles: guest.Handles,
+			}
+		}
+		return checks, nil
+	}
+	var (
+		checks  = make([]UnmountCheck, 0, len(targets))
+		missing []string
+	)
+find:
+	for _, target := range targets {
+		for _, guest := range guests {
+			if guest.Target == target {
+				checks = append(checks, UnmountCheck{
+					Target:  target,
+					Handles: guest.Handles,
+				})
+				continue find
+			}
+		}
+		missing = append(missing, fmt.Sprintf(`"%s"`, target))
+	}
+	if missing != nil {
+		return checks, fmt.Errorf(
+			"%w: %s",
+			errNotMounted, strings.Join(missing, ", "),
+		)
+	}
+	return checks, nil
+}
+
 func newDecodeTargetFunc() p9fs.DecodeTargetFunc {
 	type makeDecoderFunc func() (filesystem.Host, decodeFunc)
 	var (
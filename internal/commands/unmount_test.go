@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUnmountCheck(t *testing.T) {
+	t.Parallel()
+	const (
+		busy    = "busy"
+		clean   = "clean"
+		missing = "missing"
+	)
+	guests := []GuestStatus{
+		{Target: busy, Handles: 1},
+		{Target: clean},
+	}
+	t.Run("busy", func(t *testing.T) {
+		t.Parallel()
+		checks, err := checkUnmount(guests, []string{busy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		checksMatch(t, checks, busy+": busy (1 open handles)\n")
+		if got := busyTargets(checks); len(got) != 1 || got[0] != busy {
+			t.Errorf("busy targets mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				got, []string{busy},
+			)
+		}
+	})
+	t.Run("clean", func(t *testing.T) {
+		t.Parallel()
+		checks, err := checkUnmount(guests, []string{clean})
+		if err != nil {
+			t.Fatal(err)
+		}
+		checksMatch(t, checks, clean+": clean\n")
+		if got := busyTargets(checks); got != nil {
+			t.Errorf("clean target reported as busy: %v", got)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		checks, err := checkUnmount(guests, []string{clean, missing})
+		if !errors.Is(err, errNotMounted) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, errNotMounted,
+			)
+		}
+		checksMatch(t, checks, clean+": clean\n")
+	})
+	t.Run("all", func(t *testing.T) {
+		t.Parallel()
+		checks, err := checkUnmount(guests, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checksMatch(t, checks,
+			busy+":  busy (1 open handles)\n"+
+				clean+": clean\n",
+		)
+	})
+}
+
+func checksMatch(t *testing.T, checks []UnmountCheck, want string) {
+	t.Helper()
+	var output strings.Builder
+	if err := printUnmountChecks(&output, checks); err != nil {
+		t.Fatal(err)
+	}
+	if got := output.String(); got != want {
+		t.Errorf("output mismatch"+
+			"\ngot: %q"+
+			"\nwant: %q",
+			got, want,
+		)
+	}
+}
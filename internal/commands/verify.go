@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+// Verify constructs the command which
+// checks the health of guest file systems.
+func Verify() command.Command {
+	const (
+		name     = "verify"
+		synopsis = "Verify file system data."
+	)
+	if subcommands := makeVerifySubcommands(); len(subcommands) != 0 {
+		return command.SubcommandGroup(name, synopsis, subcommands)
+	}
+	const usage = "No verifiable guest APIs were built into this executable."
+	return command.MakeNiladicCommand(
+		name, synopsis, usage,
+		func(ctx context.Context) error {
+			return command.UsageError{
+				Err: generic.ConstError("no guest systems"),
+			}
+		},
+	)
+}
+
+func makeVerifySubcommands() []command.Command {
+	type makeCommand func() command.Command
+	var (
+		commandMakers = []makeCommand{
+			makeVerifyPinsCommand,
+		}
+		commands = make([]command.Command, 0, len(commandMakers))
+	)
+	for _, makeCommand := range commandMakers {
+		// Commands can be nil if system
+		// is disabled by build constraints.
+		if command := makeCommand(); command != nil {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
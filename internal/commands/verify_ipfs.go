@@ -0,0 +1,242 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	verifyPinsSettings struct {
+		guest      ipfs.PinFSGuest
+		timeout    time.Duration
+		workers    int
+		readLength int
+		json       bool
+		progress   bool
+	}
+	verifyPinsOption  func(*verifyPinsSettings) error
+	verifyPinsOptions []verifyPinsOption
+	// pinReport is the format of
+	// the pin verification output.
+	pinReport struct {
+		Pins      []ipfs.PinStatus `json:"pins"`
+		Healthy   int              `json:"healthy"`
+		Unhealthy int              `json:"unhealthy"`
+	}
+)
+
+const errUnhealthyPins = generic.ConstError("unhealthy pins")
+
+func makeVerifyPinsCommand() command.Command {
+	const (
+		name     = "pins"
+		synopsis = "Verify that pins can be retrieved."
+	)
+	usage := header("Pins") +
+		"\n\n" + synopsis +
+		"\nEach recursive pin from the IPFS node is retrieved" +
+		" (concurrently) and reported as unhealthy" +
+		" if it can not be retrieved in time." +
+		"\nExits with an error if any pins are unhealthy."
+	return command.MakeVariadicCommand[verifyPinsOptions](name, synopsis, usage, verifyPinsExecute)
+}
+
+func (vo *verifyPinsOptions) BindFlags(flagSet *flag.FlagSet) {
+	var ipfsOptions ipfsOptions
+	(&ipfsOptions).bindFlagsVarient(ipfs.PinFSID, flagSet)
+	*vo = append(*vo, func(settings *verifyPinsSettings) error {
+		subset, err := ipfsOptions.make()
+		if err != nil {
+			return err
+		}
+		settings.guest.IPFSGuest = ipfs.IPFSGuest(subset)
+		return nil
+	})
+	const (
+		timeoutName  = "timeout"
+		timeoutUsage = "`duration` each pin has to be retrieved within" +
+			"\nif <= 0, pins are waited on indefinitely"
+	)
+	flagSetFunc(flagSet, timeoutName, timeoutUsage, vo,
+		func(value time.Duration, settings *verifyPinsSettings) error {
+			settings.timeout = value
+			return nil
+		})
+	flagSet.Lookup(timeoutName).
+		DefValue = ipfs.DefaultVerifyTimeout.String()
+	const (
+		workersName  = "workers"
+		workersUsage = "maximum `count` of pins to verify concurrently"
+	)
+	flagSetFunc(flagSet, workersName, workersUsage, vo,
+		func(value int, settings *verifyPinsSettings) error {
+			if value < 1 {
+				return generic.ConstError("must be positive")
+			}
+			settings.workers = value
+			return nil
+		})
+	flagSet.Lookup(workersName).
+		DefValue = strconv.Itoa(ipfs.DefaultVerifyWorkers)
+	const (
+		readName  = "read"
+		readUsage = "maximum number of `bytes` to read from each file" +
+			"\nif <= 0, only metadata is retrieved"
+	)
+	flagSetFunc(flagSet, readName, readUsage, vo,
+		func(value int, settings *verifyPinsSettings) error {
+			settings.readLength = value
+			return nil
+		})
+	flagSet.Lookup(readName).
+		DefValue = strconv.Itoa(ipfs.DefaultVerifyReadLength)
+	const (
+		jsonName  = "json"
+		jsonUsage = "print the results as JSON"
+	)
+	flagSetFunc(flagSet, jsonName, jsonUsage, vo,
+		func(value bool, settings *verifyPinsSettings) error {
+			settings.json = value
+			return nil
+		})
+	const (
+		progressName  = "progress"
+		progressUsage = "print the number of pins verified" +
+			" (to stderr) while verifying" +
+			"\n(as JSON objects, if the -json flag is set)"
+	)
+	flagSetFunc(flagSet, progressName, progressUsage, vo,
+		func(value bool, settings *verifyPinsSettings) error {
+			settings.progress = value
+			return nil
+		})
+}
+
+func (vo verifyPinsOptions) make() (verifyPinsSettings, error) {
+	settings := verifyPinsSettings{
+		timeout:    ipfs.DefaultVerifyTimeout,
+		workers:    ipfs.DefaultVerifyWorkers,
+		readLength: ipfs.DefaultVerifyReadLength,
+	}
+	return settings, generic.ApplyOptions(&settings, vo...)
+}
+
+func verifyPinsExecute(ctx context.Context, options ...verifyPinsOption) error {
+	settings, err := verifyPinsOptions(options).make()
+	if err != nil {
+		return err
+	}
+	verifyOptions := []ipfs.VerifyOption{
+		ipfs.WithVerifyTimeout(settings.timeout),
+		ipfs.WithVerifyWorkers(settings.workers),
+		ipfs.WithVerifyReadLength(int64(settings.readLength)),
+	}
+	stopProgress := func() error { return nil }
+	if settings.progress {
+		var reporter *progressReporter
+		reporter, stopProgress = startProgress(settings.json)
+		verifyOptions = append(verifyOptions, ipfs.WithVerifyProgress(
+			func(status ipfs.PinStatus, bytesRead int64) {
+				reporter.add(status.CID, bytesRead, 1)
+			},
+		))
+	}
+	statuses, err := settings.guest.VerifyPins(ctx, verifyOptions...)
+	if progressErr := stopProgress(); progressErr != nil {
+		err = errors.Join(err, progressErr)
+	}
+	if len(statuses) == 0 && err != nil {
+		return err
+	}
+	// Pins which were verified are reported,
+	// even if the listing was interrupted.
+	var (
+		verifyErr = err
+		report    = makePinReport(statuses)
+	)
+	if settings.json {
+		err = json.NewEncoder(os.Stdout).Encode(report)
+	} else {
+		err = printPinReport(os.Stdout, &report)
+	}
+	if err := errors.Join(verifyErr, err); err != nil {
+		return err
+	}
+	if unhealthy := report.Unhealthy; unhealthy != 0 {
+		return fmt.Errorf("%w: %d of %d",
+			errUnhealthyPins, unhealthy, len(report.Pins),
+		)
+	}
+	return ctx.Err()
+}
+
+func makePinReport(statuses []ipfs.PinStatus) pinReport {
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].CID < statuses[j].CID
+	})
+	report := pinReport{Pins: statuses}
+	for _, status := range statuses {
+		if status.Healthy {
+			report.Healthy++
+		} else {
+			report.Unhealthy++
+		}
+	}
+	if report.Pins == nil {
+		report.Pins = []ipfs.PinStatus{}
+	}
+	return report
+}
+
+func printPinReport(output io.Writer, report *pinReport) error {
+	const (
+		minWidth = 0
+		tabWidth = 0
+		padding  = 1
+		padChar  = ' '
+		flags    = 0
+	)
+	tabWriter := tabwriter.NewWriter(
+		output, minWidth, tabWidth, padding, padChar, flags,
+	)
+	if report.Unhealthy != 0 {
+		if _, err := fmt.Fprintln(tabWriter, "unhealthy\terror"); err != nil {
+			return err
+		}
+		for _, status := range report.Pins {
+			if status.Healthy {
+				continue
+			}
+			if _, err := fmt.Fprintf(tabWriter, "%s\t%s\n",
+				status.CID, status.Error,
+			); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(tabWriter); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(tabWriter,
+		"healthy:\t%d\nunhealthy:\t%d\n",
+		report.Healthy, report.Unhealthy,
+	); err != nil {
+		return err
+	}
+	return tabWriter.Flush()
+}
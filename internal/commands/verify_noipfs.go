@@ -0,0 +1,15 @@
+//go:build noipfs
+
+package commands
+
+import "github.com/djdv/go-filesystem-utils/internal/command"
+
+func makeVerifyPinsCommand() command.Command {
+	return &unbuiltCommand{
+		name: "pins",
+		err: unbuiltError{
+			system: "IPFS",
+			tag:    "noipfs",
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+// Warm constructs the command which
+// pre-fetches data for guest file systems.
+func Warm() command.Command {
+	const (
+		name     = "warm"
+		synopsis = "Fetch file system data ahead of use."
+	)
+	if subcommands := makeWarmSubcommands(); len(subcommands) != 0 {
+		return command.SubcommandGroup(name, synopsis, subcommands)
+	}
+	const usage = "No warmable guest APIs were built into this executable."
+	return command.MakeNiladicCommand(
+		name, synopsis, usage,
+		func(ctx context.Context) error {
+			return command.UsageError{
+				Err: generic.ConstError("no guest systems"),
+			}
+		},
+	)
+}
+
+func makeWarmSubcommands() []command.Command {
+	type makeCommand func() command.Command
+	var (
+		commandMakers = []makeCommand{
+			makeWarmIPFSCommand,
+		}
+		commands = make([]command.Command, 0, len(commandMakers))
+	)
+	for _, makeCommand := range commandMakers {
+		// Commands can be nil if system
+		// is disabled by build constraints.
+		if command := makeCommand(); command != nil {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
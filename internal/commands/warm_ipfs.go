@@ -0,0 +1,109 @@
+//go:build !noipfs
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/djdv/go-filesystem-utils/internal/command"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/ipfs/go-cid"
+)
+
+type (
+	warmIPFSSettings struct {
+		guest   ipfs.IPFSGuest
+		workers int
+		entries bool
+	}
+	warmIPFSOption  func(*warmIPFSSettings) error
+	warmIPFSOptions []warmIPFSOption
+)
+
+const warmWorkersDefault = 8
+
+func makeWarmIPFSCommand() command.Command {
+	const (
+		name     = "ipfs"
+		synopsis = "Fetch IPFS nodes ahead of use."
+	)
+	usage := header("IPFS") +
+		"\n\n" + synopsis +
+		"\nEach CID provided is retrieved (concurrently) by the IPFS node," +
+		" so that later access to it does not have to wait on the network." +
+		"\nThe amount of CIDs must not exceed the node cache size."
+	return command.MakeVariadicCommand[warmIPFSOptions](name, synopsis, usage, warmIPFSExecute)
+}
+
+func (wo *warmIPFSOptions) BindFlags(flagSet *flag.FlagSet) {
+	var ipfsOptions ipfsOptions
+	(&ipfsOptions).bindFlagsVarient(ipfs.IPFSID, flagSet)
+	*wo = append(*wo, func(settings *warmIPFSSettings) error {
+		subset, err := ipfsOptions.make()
+		if err != nil {
+			return err
+		}
+		settings.guest = ipfs.IPFSGuest(subset)
+		return nil
+	})
+	const (
+		workersName  = "workers"
+		workersUsage = "maximum `count` of nodes to fetch concurrently"
+	)
+	flagSetFunc(flagSet, workersName, workersUsage, wo,
+		func(value int, settings *warmIPFSSettings) error {
+			if value < 1 {
+				return generic.ConstError("must be positive")
+			}
+			settings.workers = value
+			return nil
+		})
+	flagSet.Lookup(workersName).
+		DefValue = strconv.Itoa(warmWorkersDefault)
+	const (
+		entriesName  = "entries"
+		entriesUsage = "also list the entries of directories"
+	)
+	flagSetFunc(flagSet, entriesName, entriesUsage, wo,
+		func(value bool, settings *warmIPFSSettings) error {
+			settings.entries = value
+			return nil
+		})
+}
+
+func (wo warmIPFSOptions) make() (warmIPFSSettings, error) {
+	settings := warmIPFSSettings{
+		workers: warmWorkersDefault,
+	}
+	return settings, generic.ApplyOptions(&settings, wo...)
+}
+
+func warmIPFSExecute(ctx context.Context, arguments []string, options ...warmIPFSOption) error {
+	if len(arguments) == 0 {
+		return command.UsageError{
+			Err: generic.ConstError("expected at least 1 CID"),
+		}
+	}
+	cids := make([]cid.Cid, len(arguments))
+	for i, argument := range arguments {
+		c, err := cid.Decode(argument)
+		if err != nil {
+			return command.UsageError{
+				Err: fmt.Errorf("invalid CID \"%s\": %w", argument, err),
+			}
+		}
+		cids[i] = c
+	}
+	settings, err := warmIPFSOptions(options).make()
+	if err != nil {
+		return err
+	}
+	return settings.guest.Warm(ctx, cids,
+		ipfs.WithWarmWorkers(settings.workers),
+		ipfs.WithWarmEntries(settings.entries),
+	)
+}
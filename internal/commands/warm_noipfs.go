@@ -0,0 +1,15 @@
+//go:build noipfs
+
+package commands
+
+import "github.com/djdv/go-filesystem-utils/internal/command"
+
+func makeWarmIPFSCommand() command.Command {
+	return &unbuiltCommand{
+		name: "ipfs",
+		err: unbuiltError{
+			system: "IPFS",
+			tag:    "noipfs",
+		},
+	}
+}
@@ -6,13 +6,19 @@ import (
 	"sync/atomic"
 	"unsafe"
 
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	"github.com/djdv/go-filesystem-utils/internal/generic"
 	perrors "github.com/djdv/p9/errors"
 	"github.com/djdv/p9/fsimpl/templatefs"
 	"github.com/djdv/p9/p9"
 )
 
-const parentWName = ".."
+const (
+	parentWName = ".."
+	// dotEntCount is the number of entries which
+	// precede a directory's own entries (self and parent).
+	dotEntCount = 2
+)
 
 type (
 	// Embeddable alias with a more apt name.
@@ -240,10 +246,65 @@ func (dir *Directory) Mkdir(name string, permissions p9.FileMode, uid p9.UID, gi
 	return qid, err
 }
 
+// Readdir lists the directory's entries,
+// preceded by its self and parent entries
+// (see [filesystem.DotEntries]).
 func (dir *Directory) Readdir(offset uint64, count uint32) (p9.Dirents, error) {
 	const entrySize = unsafe.Sizeof(p9.Dirent{})
 	countDecimal := count / uint32(entrySize) // Bytes -> index.
-	return dir.to9Ents(offset, countDecimal)
+	ents, err := dir.dotEnts(offset, countDecimal)
+	if err != nil {
+		return nil, err
+	}
+	remain := countDecimal - uint32(len(ents))
+	if offset < dotEntCount {
+		offset = 0
+	} else {
+		offset -= dotEntCount
+	}
+	tableEnts, err := dir.to9Ents(offset, remain)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tableEnts {
+		tableEnts[i].Offset += dotEntCount
+	}
+	return append(ents, tableEnts...), nil
+}
+
+// dotEnts returns the self and parent entries
+// which follow `offset` (up to `count`).
+// The root is considered to be its own parent.
+func (dir *Directory) dotEnts(offset uint64, count uint32) (p9.Dirents, error) {
+	if offset >= dotEntCount || count == 0 {
+		return nil, nil
+	}
+	var (
+		attrMaskNone p9.AttrMask
+		files        = [dotEntCount]p9.File{dir, dir.parent}
+		names        = [dotEntCount]string{
+			filesystem.SelfName,
+			filesystem.ParentName,
+		}
+		end  = generic.Min(dotEntCount, offset+uint64(count))
+		ents = make(p9.Dirents, 0, end-offset)
+	)
+	if dirIsRoot := files[1] == nil; dirIsRoot {
+		files[1] = dir
+	}
+	for i := offset; i < end; i++ {
+		qid, _, _, err := files[i].GetAttr(attrMaskNone)
+		if err != nil {
+			return nil, err
+		}
+		ents = append(ents, p9.Dirent{
+			QID:    qid,
+			Offset: i + 1,
+			Type:   qid.Type,
+			Name:   names[i],
+		})
+	}
+	return ents, nil
 }
 
 func (dir *Directory) Rename(newDir p9.File, newName string) error {
@@ -0,0 +1,114 @@
+package p9_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/p9/p9"
+)
+
+func TestDirectoryDots(t *testing.T) {
+	t.Parallel()
+	const childName = "child"
+	rootQID, root, err := p9fs.NewDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	childQID, err := root.Mkdir(childName, 0o755, p9.NoUID, p9.NoGID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Run("root", func(t *testing.T) {
+		t.Parallel()
+		got := readdirAt(t, root, nil, 0)
+		direntsMatch(t, got, []p9.Dirent{
+			{Name: filesystem.SelfName, QID: rootQID, Offset: 1},
+			{Name: filesystem.ParentName, QID: rootQID, Offset: 2},
+			{Name: childName, QID: childQID, Offset: 3},
+		})
+	})
+	t.Run("child", func(t *testing.T) {
+		t.Parallel()
+		got := readdirAt(t, root, []string{childName}, 0)
+		direntsMatch(t, got, []p9.Dirent{
+			{Name: filesystem.SelfName, QID: childQID, Offset: 1},
+			{Name: filesystem.ParentName, QID: rootQID, Offset: 2},
+		})
+	})
+	t.Run("offset", func(t *testing.T) {
+		t.Parallel()
+		got := readdirAt(t, root, nil, 1)
+		direntsMatch(t, got, []p9.Dirent{
+			{Name: filesystem.ParentName, QID: rootQID, Offset: 2},
+			{Name: childName, QID: childQID, Offset: 3},
+		})
+		if got := readdirAt(t, root, nil, 3); len(got) != 0 {
+			t.Errorf("expected no entries past the end, got: %v", got)
+		}
+	})
+	t.Run("ReadDir", func(t *testing.T) {
+		t.Parallel()
+		_, clone, err := root.Walk(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer clone.Close()
+		got, err := p9fs.ReadDir(clone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		direntsMatch(t, got, []p9.Dirent{
+			{Name: childName, QID: childQID, Offset: 3},
+		})
+	})
+}
+
+func readdirAt(t *testing.T, root p9.File, names []string, offset uint64) p9.Dirents {
+	t.Helper()
+	_, directory, err := root.Walk(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := directory.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	if _, _, err := directory.Open(p9.ReadOnly); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := directory.Readdir(offset, math.MaxUint32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func direntsMatch(t *testing.T, got, want []p9.Dirent) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("entry count mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, want,
+		)
+	}
+	for i, entry := range got {
+		wantEntry := want[i]
+		wantEntry.Type = wantEntry.QID.Type
+		if entry != wantEntry {
+			t.Errorf("entry mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				entry, wantEntry,
+			)
+		}
+		if entry.QID.Type != p9.TypeDir {
+			t.Errorf("\"%s\" is not a directory, type: %v",
+				entry.Name, entry.QID.Type,
+			)
+		}
+	}
+}
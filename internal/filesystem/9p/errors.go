@@ -0,0 +1,65 @@
+package p9
+
+import (
+	"errors"
+	"io/fs"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	perrors "github.com/djdv/p9/errors"
+)
+
+// fsErrorsTable mirrors the table used by the FUSE host,
+// so that guest errors are reported consistently
+// regardless of which host exposes them.
+var fsErrorsTable = map[fserrors.Kind]perrors.Errno{
+	fserrors.Other:            perrors.EIO,
+	fserrors.InvalidItem:      perrors.EINVAL,
+	fserrors.InvalidOperation: perrors.ENOSYS,
+	fserrors.Permission:       perrors.EACCES,
+	fserrors.IO:               perrors.EIO,
+	fserrors.Exist:            perrors.EEXIST,
+	fserrors.NotExist:         perrors.ENOENT,
+	fserrors.IsDir:            perrors.EISDIR,
+	fserrors.NotDir:           perrors.ENOTDIR,
+	fserrors.NotEmpty:         perrors.ENOTEMPTY,
+	fserrors.ReadOnly:         perrors.EROFS,
+	fserrors.Closed:           perrors.EBADF,
+	fserrors.TooManyOpen:      perrors.EMFILE,
+}
+
+// p9ErrnoFromError joins err with the 9P errno
+// that best describes it.
+// Errors which already carry an errno are returned as-is.
+func p9ErrnoFromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var errno perrors.Errno
+	if errors.As(err, &errno) {
+		return err
+	}
+	return errors.Join(interpretError(err), err)
+}
+
+func interpretError(err error) perrors.Errno {
+	var fsErr *fserrors.Error
+	if errors.As(err, &fsErr) {
+		if errno, ok := fsErrorsTable[fsErr.Kind]; ok {
+			return errno
+		}
+		return perrors.EIO
+	}
+	// Standard errors, from file systems
+	// which don't use [fserrors].
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return perrors.ENOENT
+	case errors.Is(err, fs.ErrExist):
+		return perrors.EEXIST
+	case errors.Is(err, fs.ErrPermission):
+		return perrors.EACCES
+	case errors.Is(err, fs.ErrInvalid):
+		return perrors.EINVAL
+	}
+	return perrors.EIO
+}
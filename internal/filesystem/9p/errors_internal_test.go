@@ -0,0 +1,77 @@
+package p9
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	perrors "github.com/djdv/p9/errors"
+)
+
+func TestErrno(t *testing.T) {
+	t.Parallel()
+	t.Run("kinds", testErrnoKinds)
+	t.Run("standard", testErrnoStandard)
+	t.Run("passthrough", testErrnoPassthrough)
+}
+
+func testErrnoKinds(t *testing.T) {
+	t.Parallel()
+	const cause = generic.ConstError("guest error")
+	for kind := fserrors.Other; kind <= fserrors.TooManyOpen; kind++ {
+		want, ok := fsErrorsTable[kind]
+		if !ok {
+			t.Errorf("kind %d has no errno mapping", kind)
+			continue
+		}
+		err := p9ErrnoFromError(fserrors.New("op", "path", cause, kind))
+		checkErrno(t, err, want)
+		if !errors.Is(err, cause) {
+			t.Errorf("original error was discarded: %v", err)
+		}
+	}
+}
+
+func testErrnoStandard(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		err  error
+		want perrors.Errno
+	}{
+		{err: fs.ErrNotExist, want: perrors.ENOENT},
+		{err: fs.ErrExist, want: perrors.EEXIST},
+		{err: fs.ErrPermission, want: perrors.EACCES},
+		{err: fs.ErrInvalid, want: perrors.EINVAL},
+		{err: generic.ConstError("unclassified"), want: perrors.EIO},
+	} {
+		checkErrno(t, p9ErrnoFromError(test.err), test.want)
+	}
+}
+
+func testErrnoPassthrough(t *testing.T) {
+	t.Parallel()
+	if err := p9ErrnoFromError(nil); err != nil {
+		t.Errorf("expected nil error, got: %v", err)
+	}
+	err := errors.Join(perrors.EBUSY, generic.ConstError("busy"))
+	if got := p9ErrnoFromError(err); got != err {
+		t.Errorf("error with errno was modified"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, err,
+		)
+	}
+}
+
+func checkErrno(t *testing.T, err error, want perrors.Errno) {
+	t.Helper()
+	if got := perrors.ExtractErrno(err); got != want {
+		t.Errorf("errno mismatch for \"%v\""+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, got, want,
+		)
+	}
+}
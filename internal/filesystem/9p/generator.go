@@ -0,0 +1,125 @@
+package p9
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	perrors "github.com/djdv/p9/errors"
+	"github.com/djdv/p9/fsimpl/templatefs"
+	"github.com/djdv/p9/p9"
+)
+
+type (
+	// GeneratorFunc returns the contents
+	// of a [GeneratorFile].
+	GeneratorFunc func() ([]byte, error)
+	// GeneratorFile is a read-only file whose contents
+	// are produced by a function when the file is first
+	// inspected (via size request or open).
+	// Each fid receives its own snapshot of the data,
+	// so the reported size and reads are consistent.
+	GeneratorFile struct {
+		templatefs.NoopFile
+		*metadata
+		*linkSync
+		io.ReaderAt
+		generate GeneratorFunc
+		openFlags
+	}
+	GeneratorOption func(*fileSettings) error
+)
+
+func NewGeneratorFile(generate GeneratorFunc,
+	options ...GeneratorOption,
+) (p9.QID, *GeneratorFile, error) {
+	var settings fileSettings
+	settings.metadata.initialize(p9.ModeRegular)
+	if err := generic.ApplyOptions(&settings, options...); err != nil {
+		return p9.QID{}, nil, err
+	}
+	settings.metadata.fillDefaults()
+	settings.metadata.incrementPath()
+	return settings.QID, &GeneratorFile{
+		metadata: &settings.metadata,
+		linkSync: &settings.linkSync,
+		generate: generate,
+	}, nil
+}
+
+func (gf *GeneratorFile) Walk(names []string) ([]p9.QID, p9.File, error) {
+	if len(names) > 0 {
+		return nil, nil, perrors.ENOTDIR
+	}
+	if gf.opened() {
+		return nil, nil, fidOpenedErr
+	}
+	return nil, &GeneratorFile{
+		metadata: gf.metadata,
+		linkSync: gf.linkSync,
+		generate: gf.generate,
+	}, nil
+}
+
+func (gf *GeneratorFile) Open(mode p9.OpenFlags) (p9.QID, ioUnit, error) {
+	if gf.opened() {
+		return p9.QID{}, 0, perrors.EBADF
+	}
+	if mode.Mode() != p9.ReadOnly {
+		return p9.QID{}, 0, perrors.EINVAL
+	}
+	if _, err := gf.snapshot(); err != nil {
+		return p9.QID{}, 0, err
+	}
+	gf.openFlags = gf.withOpenedFlag(mode)
+	return gf.QID, 0, nil
+}
+
+func (gf *GeneratorFile) Close() error {
+	gf.openFlags = 0
+	gf.ReaderAt = nil
+	return nil
+}
+
+func (gf *GeneratorFile) ReadAt(p []byte, offset int64) (int, error) {
+	if !gf.canRead() {
+		return -1, perrors.EBADF
+	}
+	return gf.ReaderAt.ReadAt(p, offset)
+}
+
+func (gf *GeneratorFile) SetAttr(valid p9.SetAttrMask, attr p9.SetAttr) error {
+	return gf.metadata.SetAttr(valid, attr)
+}
+
+func (gf *GeneratorFile) GetAttr(req p9.AttrMask) (p9.QID, p9.AttrMask, p9.Attr, error) {
+	if req.Size {
+		reader, err := gf.snapshot()
+		if err != nil {
+			return p9.QID{}, p9.AttrMask{}, p9.Attr{}, err
+		}
+		gf.metadata.Size = uint64(reader.Size())
+	}
+	return gf.metadata.GetAttr(req)
+}
+
+func (gf *GeneratorFile) snapshot() (*bytes.Reader, error) {
+	if reader, ok := gf.ReaderAt.(*bytes.Reader); ok {
+		return reader, nil
+	}
+	data, err := gf.generate()
+	if err != nil {
+		return nil, err
+	}
+	reader := bytes.NewReader(data)
+	gf.ReaderAt = reader
+	return reader, nil
+}
+
+func (gf *GeneratorFile) Rename(newDir p9.File, newName string) error {
+	return gf.linkSync.rename(gf, newDir, newName)
+}
+
+func (gf *GeneratorFile) Renamed(newDir p9.File, newName string) {
+	gf.linkSync.Renamed(newDir, newName)
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 
 	"github.com/djdv/go-filesystem-utils/internal/generic"
-	perrors "github.com/djdv/p9/errors"
 	"github.com/djdv/p9/p9"
 )
 
@@ -67,7 +66,7 @@ func (gf *GuestFile) Mkdir(name string, permissions p9.FileMode, uid p9.UID, gid
 	qid, file, err := gf.makeMountPointFn(gf, name,
 		mode, uid, gid)
 	if err != nil {
-		return p9.QID{}, errors.Join(perrors.EACCES, err)
+		return p9.QID{}, p9ErrnoFromError(err)
 	}
 	return qid, gf.Link(file, name)
 }
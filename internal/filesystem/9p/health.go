@@ -0,0 +1,207 @@
+package p9
+
+import (
+	"context"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/djdv/p9/p9"
+)
+
+type (
+	// MountHealth describes the results of
+	// probing a mounted guest file system.
+	MountHealth struct {
+		MountInfo
+		LastProbe   time.Time
+		LastSuccess time.Time
+		// LastError is the error returned by
+		// the most recent probe (if it failed).
+		LastError error
+		// Failures is the amount of consecutive
+		// probes which have failed.
+		Failures int
+		// Handles is the amount of files held open
+		// by the host (if it reports them).
+		Handles int
+	}
+	// mountHealth is shared by each fid
+	// of a mount point file.
+	mountHealth struct {
+		fsys        fs.FS
+		handles     HandleCounter
+		lastProbe   time.Time
+		lastSuccess time.Time
+		lastErr     error
+		failures    int
+		probing     bool
+		mu          sync.Mutex
+	}
+	healthProber interface {
+		probeHealth() <-chan struct{}
+		healthStatus() (MountHealth, bool)
+	}
+)
+
+// errProbePending is recorded when a probe is
+// requested while a previous probe has not returned.
+const errProbePending = generic.ConstError("previous probe has not returned")
+
+// ProbeMounts checks the health of each mounted guest within mounts,
+// by calling [fs.Stat] on the guest's root.
+// Probes run concurrently; if the context is done before
+// a probe returns, ProbeMounts returns without waiting for it.
+// Results are retrieved with [MountHealths].
+func ProbeMounts(ctx context.Context, mounts p9.File) error {
+	var probes []<-chan struct{}
+	err := walkMountFiles(mounts, func(file p9.File) error {
+		if prober, ok := file.(healthProber); ok {
+			if done := prober.probeHealth(); done != nil {
+				probes = append(probes, done)
+			}
+		}
+		return nil
+	})
+	for _, done := range probes {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// MountHealths returns the health of each
+// mounted guest within mounts.
+// Mount points which are not mounted
+// (e.g. pending) are not included.
+func MountHealths(mounts p9.File, decodeTargetFn DecodeTargetFunc) ([]MountHealth, error) {
+	var healths []MountHealth
+	err := walkMountFiles(mounts, func(file p9.File) error {
+		prober, ok := file.(healthProber)
+		if !ok {
+			return nil
+		}
+		health, mounted := prober.healthStatus()
+		if !mounted {
+			return nil
+		}
+		info, err := parseMountInfo(file, decodeTargetFn)
+		if err != nil {
+			return err
+		}
+		health.MountInfo = info
+		healths = append(healths, health)
+		return nil
+	})
+	return healths, err
+}
+
+// OpenHandles returns the amount of files held open
+// by the hosts of mounted guests within mounts.
+// Hosts which do not count their handles
+// (see [HandleCounter]) are not included.
+func OpenHandles(mounts p9.File) (int, error) {
+	var handles int
+	err := walkMountFiles(mounts, func(file p9.File) error {
+		prober, ok := file.(healthProber)
+		if !ok {
+			return nil
+		}
+		if health, mounted := prober.healthStatus(); mounted {
+			handles += health.Handles
+		}
+		return nil
+	})
+	return handles, err
+}
+
+func (mf *MountPointFile[MP]) probeHealth() <-chan struct{} {
+	return mf.health.probe()
+}
+
+func (mf *MountPointFile[MP]) healthStatus() (MountHealth, bool) {
+	return mf.health.status()
+}
+
+// reset associates the health with fsys (and the
+// host's handles), clearing the results of previous probes.
+// If fsys is nil, the health is cleared
+// and probes do nothing.
+func (mh *mountHealth) reset(fsys fs.FS, handles HandleCounter) {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+	mh.fsys = fsys
+	mh.handles = handles
+	mh.lastProbe = time.Time{}
+	mh.lastSuccess = time.Time{}
+	mh.lastErr = nil
+	mh.failures = 0
+	mh.probing = false
+}
+
+// probe starts a probe (if the guest is mounted), and
+// returns a channel which is closed when it returns.
+// If a previous probe has not returned, it's
+// recorded as a failure and nil is returned.
+func (mh *mountHealth) probe() <-chan struct{} {
+	mh.mu.Lock()
+	defer mh.mu.Unlock()
+	fsys := mh.fsys
+	if fsys == nil {
+		return nil
+	}
+	if mh.probing {
+		mh.recordLocked(time.Now(), errProbePending)
+		return nil
+	}
+	mh.probing = true
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := fs.Stat(fsys, ".")
+		mh.mu.Lock()
+		defer mh.mu.Unlock()
+		mh.probing = false
+		if mh.fsys != fsys {
+			return // Remounted or unmounted since.
+		}
+		mh.recordLocked(time.Now(), err)
+	}()
+	return done
+}
+
+func (mh *mountHealth) recordLocked(now time.Time, err error) {
+	mh.lastProbe = now
+	mh.lastErr = err
+	if err != nil {
+		mh.failures++
+		return
+	}
+	mh.lastSuccess = now
+	mh.failures = 0
+}
+
+func (mh *mountHealth) status() (MountHealth, bool) {
+	mh.mu.Lock()
+	var (
+		health = MountHealth{
+			LastProbe:   mh.lastProbe,
+			LastSuccess: mh.lastSuccess,
+			LastError:   mh.lastErr,
+			Failures:    mh.failures,
+		}
+		mounted = mh.fsys != nil
+		handles = mh.handles
+	)
+	mh.mu.Unlock()
+	// The host may block while counting;
+	// don't hold up probes in the meantime.
+	if handles != nil {
+		health.Handles = handles.OpenHandles()
+	}
+	return health, mounted
+}
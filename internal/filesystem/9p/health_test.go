@@ -0,0 +1,308 @@
+package p9_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/p9/p9"
+)
+
+type (
+	// probedMountPoint mounts the [failingFS]
+	// stored in [probedSystems] for its target.
+	probedMountPoint struct {
+		Target string `json:"target"`
+	}
+	// failingFS fails to open any file
+	// while its failing flag is set.
+	failingFS struct {
+		mapFS   fstest.MapFS
+		failing atomic.Bool
+	}
+	// handledMountPoint mounts its guest within a
+	// [handledSystem], which is stored in
+	// [handledSystems] for its target.
+	handledMountPoint struct {
+		Target string `json:"target"`
+	}
+	// handledSystem reports the handles
+	// of its file system to the mount point.
+	handledSystem struct {
+		*filesystem.HandleLimitFS
+	}
+)
+
+const (
+	probedHost  filesystem.Host = "probedHost"
+	probedGuest filesystem.ID   = "probedGuest"
+
+	handledHost  filesystem.Host = "handledHost"
+	handledGuest filesystem.ID   = "handledGuest"
+	handledFile                  = "file"
+
+	errGuestFailing = healthTestError("guest is failing")
+)
+
+type healthTestError string
+
+func (e healthTestError) Error() string { return string(e) }
+
+var probedSystems, handledSystems sync.Map
+
+func (*probedMountPoint) HostID() filesystem.Host { return probedHost }
+func (*probedMountPoint) GuestID() filesystem.ID  { return probedGuest }
+
+func (pm *probedMountPoint) MakeFS() (fs.FS, error) {
+	fsys, ok := probedSystems.Load(pm.Target)
+	if !ok {
+		return nil, fmt.Errorf(`no file system for "%s"`, pm.Target)
+	}
+	return fsys.(*failingFS), nil
+}
+
+func (*probedMountPoint) Mount(fs.FS) (io.Closer, error) {
+	return nopCloser{}, nil
+}
+
+func (*handledMountPoint) HostID() filesystem.Host { return handledHost }
+func (*handledMountPoint) GuestID() filesystem.ID  { return handledGuest }
+
+func (*handledMountPoint) MakeFS() (fs.FS, error) {
+	return fstest.MapFS{handledFile: {}}, nil
+}
+
+func (hm *handledMountPoint) Mount(fsys fs.FS) (io.Closer, error) {
+	const limit = 1
+	limited, err := filesystem.NewHandleLimitFS(fsys, limit)
+	if err != nil {
+		return nil, err
+	}
+	system := handledSystem{HandleLimitFS: limited}
+	handledSystems.Store(hm.Target, system)
+	return system, nil
+}
+
+func (hs handledSystem) OpenHandles() int { return hs.Handles() }
+
+func (ff *failingFS) Open(name string) (fs.File, error) {
+	if ff.failing.Load() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errGuestFailing}
+	}
+	return ff.mapFS.Open(name)
+}
+
+func TestMountHealth(t *testing.T) {
+	t.Parallel()
+	const (
+		permissions = 0o751
+		uid         = p9.NoUID
+		gid         = p9.NoGID
+		failures    = 3
+	)
+	var (
+		target   = t.Name()
+		guestFS  = &failingFS{mapFS: fstest.MapFS{}}
+		mounts   = newTestMounter[*probedMountPoint](t)
+		decodeFn = func(_ filesystem.Host, _ filesystem.ID, data []byte) (string, error) {
+			var point probedMountPoint
+			err := json.Unmarshal(data, &point)
+			return point.Target, err
+		}
+		probe = func(t *testing.T) p9fs.MountHealth {
+			t.Helper()
+			if err := p9fs.ProbeMounts(context.Background(), mounts); err != nil {
+				t.Fatal(err)
+			}
+			healths, err := p9fs.MountHealths(mounts, decodeFn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(healths) != 1 {
+				t.Fatalf("expected 1 mount health, got %d", len(healths))
+			}
+			return healths[0]
+		}
+	)
+	probedSystems.Store(target, guestFS)
+	defer probedSystems.Delete(target)
+	healths, err := p9fs.MountHealths(mounts, decodeFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(healths) != 0 {
+		t.Fatalf("health reported without mounts: %v", healths)
+	}
+	guests, err := p9fs.MkdirAll(mounts,
+		[]string{string(probedHost), string(probedGuest)},
+		permissions, uid, gid,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mountFile, _, _, err := guests.Create("mountpoint", p9.WriteOnly, permissions, uid, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := guests.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"target":"` + target + `"}`)
+	if _, err := mountFile.WriteAt(data, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := mountFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+	healthy := probe(t)
+	if healthy.Target != target {
+		t.Errorf("target mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			healthy.Target, target,
+		)
+	}
+	if healthy.LastError != nil ||
+		healthy.Failures != 0 ||
+		healthy.LastSuccess.IsZero() {
+		t.Fatalf("healthy guest reported as failing: %+v", healthy)
+	}
+	guestFS.failing.Store(true)
+	var failing p9fs.MountHealth
+	for i := 1; i <= failures; i++ {
+		if failing = probe(t); failing.Failures != i {
+			t.Fatalf("failure count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				failing.Failures, i,
+			)
+		}
+	}
+	if !errors.Is(failing.LastError, errGuestFailing) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			failing.LastError, errGuestFailing,
+		)
+	}
+	if !failing.LastSuccess.Equal(healthy.LastSuccess) {
+		t.Errorf("last success changed while failing"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			failing.LastSuccess, healthy.LastSuccess,
+		)
+	}
+	if failing.LastProbe.Before(failing.LastSuccess) {
+		t.Errorf("last probe (%v) precedes last success (%v)",
+			failing.LastProbe, failing.LastSuccess,
+		)
+	}
+	guestFS.failing.Store(false)
+	if recovered := probe(t); recovered.Failures != 0 ||
+		recovered.LastError != nil {
+		t.Errorf("recovered guest reported as failing: %+v", recovered)
+	}
+	if err := p9fs.UnmountAll(mounts); err != nil {
+		t.Fatal(err)
+	}
+	if healths, err = p9fs.MountHealths(mounts, decodeFn); err != nil {
+		t.Fatal(err)
+	}
+	if len(healths) != 0 {
+		t.Errorf("health reported after unmount: %v", healths)
+	}
+}
+
+func TestMountHandles(t *testing.T) {
+	t.Parallel()
+	const (
+		permissions = 0o751
+		uid         = p9.NoUID
+		gid         = p9.NoGID
+	)
+	var (
+		target   = t.Name()
+		mounts   = newTestMounter[*handledMountPoint](t)
+		decodeFn = func(_ filesystem.Host, _ filesystem.ID, data []byte) (string, error) {
+			var point handledMountPoint
+			err := json.Unmarshal(data, &point)
+			return point.Target, err
+		}
+		handlesMatch = func(t *testing.T, want int) {
+			t.Helper()
+			healths, err := p9fs.MountHealths(mounts, decodeFn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(healths) != 1 {
+				t.Fatalf("expected 1 mount health, got %d", len(healths))
+			}
+			if got := healths[0].Handles; got != want {
+				t.Errorf("handle count mismatch"+
+					"\ngot: %d"+
+					"\nwant: %d",
+					got, want,
+				)
+			}
+			total, err := p9fs.OpenHandles(mounts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != want {
+				t.Errorf("open handle total mismatch"+
+					"\ngot: %d"+
+					"\nwant: %d",
+					total, want,
+				)
+			}
+		}
+	)
+	defer handledSystems.Delete(target)
+	guests, err := p9fs.MkdirAll(mounts,
+		[]string{string(handledHost), string(handledGuest)},
+		permissions, uid, gid,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mountFile, _, _, err := guests.Create("mountpoint", p9.WriteOnly, permissions, uid, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := guests.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"target":"` + target + `"}`)
+	if _, err := mountFile.WriteAt(data, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := mountFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+	system, ok := handledSystems.Load(target)
+	if !ok {
+		t.Fatal("guest was not mounted")
+	}
+	handlesMatch(t, 0)
+	file, err := system.(handledSystem).Open(handledFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlesMatch(t, 1)
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	handlesMatch(t, 0)
+	if err := p9fs.UnmountAll(mounts); err != nil {
+		t.Fatal(err)
+	}
+}
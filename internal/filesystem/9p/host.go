@@ -1,11 +1,8 @@
 package p9
 
 import (
-	"errors"
-
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	"github.com/djdv/go-filesystem-utils/internal/generic"
-	perrors "github.com/djdv/p9/errors"
 	"github.com/djdv/p9/p9"
 )
 
@@ -64,7 +61,7 @@ func (hd *HostFile) Mkdir(name string, permissions p9.FileMode, uid p9.UID, gid
 	qid, file, err := hd.makeGuestFn(hd, filesystem.ID(name),
 		mode, uid, gid)
 	if err != nil {
-		return p9.QID{}, errors.Join(perrors.EACCES, err)
+		return p9.QID{}, p9ErrnoFromError(err)
 	}
 	return qid, hd.Link(file, name)
 }
@@ -86,7 +83,7 @@ func (hf *HostFile) Mknod(name string, mode p9.FileMode,
 	qid, file, err := hf.makeGuestFn(hf, filesystem.ID(name),
 		mode, uid, gid)
 	if err != nil {
-		return p9.QID{}, errors.Join(perrors.EACCES, err)
+		return p9.QID{}, p9ErrnoFromError(err)
 	}
 	return qid, hf.Link(file, name)
 }
@@ -3,6 +3,7 @@ package p9
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/djdv/go-filesystem-utils/internal/generic"
 	p9net "github.com/djdv/go-filesystem-utils/internal/net/9p"
+	"github.com/djdv/go-filesystem-utils/internal/net/tunnel"
 	perrors "github.com/djdv/p9/errors"
 	"github.com/djdv/p9/fsimpl/templatefs"
 	"github.com/djdv/p9/p9"
@@ -33,12 +35,16 @@ type (
 		listenerShared
 	}
 	listenerSettings struct {
+		tlsConfig *tls.Config
 		directorySettings
 		channelSettings
 	}
 	ListenerOption func(*listenerSettings) error
 	listenerShared struct {
-		emitter        *chanEmitter[manet.Listener]
+		emitter *chanEmitter[manet.Listener]
+		// tlsConfig is used by listeners
+		// whose multiaddr ends with `/tls`.
+		tlsConfig      *tls.Config
 		path           ninePath
 		cleanupEmpties bool
 	}
@@ -56,6 +62,21 @@ type (
 		connDirMu  *sync.Mutex
 		connDirPtr **connDir
 		connIndex  *atomic.Uintptr
+		stats      *listenerStats
+	}
+	// listenerStats are accumulated by
+	// all connections of a listener.
+	listenerStats struct {
+		read, wrote atomic.Uint64
+		connections atomic.Int64
+	}
+	statsFile struct {
+		templatefs.NoopFile
+		*metadata
+		stats *listenerStats
+		io.ReaderAt
+		*linkSync
+		openFlags
 	}
 	listenerFile struct {
 		templatefs.NoopFile
@@ -72,6 +93,19 @@ type (
 	connTracker struct {
 		parent *valueDir
 		manet.Listener
+		// tlsConfig is set if connections
+		// must be secured when accepted.
+		tlsConfig *tls.Config
+		// tlsComponent is the `/tls` component
+		// removed from the listener's multiaddr.
+		tlsComponent *multiaddr.Component
+	}
+	// tlsConn is a connection secured by TLS,
+	// which reports the multiaddrs of the
+	// connection it's layered on.
+	tlsConn struct {
+		*tls.Conn
+		raw manet.Conn
 	}
 	connDir struct {
 		directory
@@ -94,18 +128,42 @@ type (
 		trackedConn
 		closeFn func() error
 	}
+	// countedConn adds the amount of bytes
+	// transferred to its listener's statistics.
+	countedConn struct {
+		trackedConn
+		stats *listenerStats
+	}
 	ConnInfo struct {
-		LastRead  time.Time           `json:"lastRead"`
-		LastWrite time.Time           `json:"lastWrite"`
-		Local     multiaddr.Multiaddr `json:"local"`
-		Remote    multiaddr.Multiaddr `json:"remote"`
-		ID        uintptr             `json:"#"`
+		LastRead    time.Time           `json:"lastRead"`
+		LastWrite   time.Time           `json:"lastWrite"`
+		Local       multiaddr.Multiaddr `json:"local"`
+		Remote      multiaddr.Multiaddr `json:"remote"`
+		Version     string              `json:"version,omitempty"`
+		MessageSize uint32              `json:"msize,omitempty"`
+		ID          uintptr             `json:"#"`
+	}
+	// ListenerStatistics is the format of a
+	// listener's statistics file. Byte counts are
+	// totals for all connections the listener has
+	// accepted, while the connection count only
+	// includes connections which are currently open.
+	ListenerStatistics struct {
+		BytesRead    uint64 `json:"bytesRead"`
+		BytesWritten uint64 `json:"bytesWritten"`
+		Connections  int64  `json:"connections"`
 	}
 )
 
 const (
 	listenerFileName    = "listener"
 	connectionsFileName = "connections"
+	statisticsFileName  = "statistics"
+
+	// tlsHandshakeTimeout bounds how long a
+	// client may take to complete the TLS handshake.
+	tlsHandshakeTimeout = 10 * time.Second
+	errTLSConfig        = generic.ConstError("TLS multiaddr requires a TLS config")
 )
 
 func NewListener(ctx context.Context, options ...ListenerOption) (p9.QID, *Listener, <-chan manet.Listener, error) {
@@ -130,6 +188,7 @@ func NewListener(ctx context.Context, options ...ListenerOption) (p9.QID, *Liste
 			listenerShared: listenerShared{
 				path:           settings.metadata.ninePath,
 				emitter:        emitter,
+				tlsConfig:      settings.tlsConfig,
 				cleanupEmpties: settings.cleanupElements,
 			},
 		}
@@ -137,6 +196,18 @@ func NewListener(ctx context.Context, options ...ListenerOption) (p9.QID, *Liste
 	return qid, listener, listeners, nil
 }
 
+// WithTLSConfig sets the configuration used by listeners
+// whose multiaddr ends with a `/tls` component.
+// Connections accepted by such listeners must complete
+// a TLS handshake before they are returned.
+// Other listeners are not affected.
+func WithTLSConfig(config *tls.Config) ListenerOption {
+	return func(settings *listenerSettings) error {
+		settings.tlsConfig = config
+		return nil
+	}
+}
+
 // TODO: [Ame] English.
 // Listen tries to listen on the provided [Multiaddr].
 // If successful, the [Multiaddr] is mapped as a directory,
@@ -160,6 +231,68 @@ func Listen(listener p9.File, maddr multiaddr.Multiaddr, permissions p9.FileMode
 	return err
 }
 
+// Unlisten closes the listener which was requested
+// for `maddr` (see [Listen]), and removes its file.
+// Connections already accepted by
+// the listener are not closed.
+func Unlisten(listener p9.File, maddr multiaddr.Multiaddr) error {
+	_, names := splitMaddr(maddr)
+	_, valueDir, err := listener.Walk(names)
+	if err != nil {
+		return err
+	}
+	const flags = 0
+	return errors.Join(
+		valueDir.UnlinkAt(listenerFileName, flags),
+		valueDir.Close(),
+	)
+}
+
+// GetListenerRequests returns the maddrs which were
+// requested (see [Listen]) for each active listener
+// contained within the `listener` file.
+// These may differ from the values returned by
+// [GetListeners]; e.g. when a port is assigned
+// by the system, this will contain the port as
+// requested (0) rather than the one assigned.
+func GetListenerRequests(listener p9.File) ([]multiaddr.Multiaddr, error) {
+	return getListenerRequests(listener, nil)
+}
+
+func getListenerRequests(dir p9.File, names []string) ([]multiaddr.Multiaddr, error) {
+	entries, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var maddrs []multiaddr.Multiaddr
+	for _, entry := range entries {
+		switch name := entry.Name; {
+		case name == listenerFileName:
+			maddr, err := multiaddr.NewMultiaddr("/" + strings.Join(names, "/"))
+			if err != nil {
+				return nil, err
+			}
+			maddrs = append(maddrs, maddr)
+		case entry.Type == p9.TypeDir &&
+			name != connectionsFileName:
+			child, err := walkEnt(dir, entry)
+			if err != nil {
+				return nil, err
+			}
+			childNames := append(names[:len(names):len(names)], name)
+			found, err := getListenerRequests(child, childNames)
+			if cErr := child.Close(); cErr != nil {
+				err = errors.Join(err, cErr)
+			}
+			if err != nil {
+				return nil, err
+			}
+			maddrs = append(maddrs, found...)
+		}
+	}
+	return maddrs, nil
+}
+
 // GetListeners returns a slice of maddrs that correspond to
 // active listeners contained within the `listener` file.
 func GetListeners(listener p9.File) ([]multiaddr.Multiaddr, error) {
@@ -395,6 +528,7 @@ func (pd *protocolDir) Mkdir(name string, permissions p9.FileMode, uid p9.UID, g
 		connDirMu:      new(sync.Mutex),
 		connDirPtr:     new(*connDir),
 		connIndex:      new(atomic.Uintptr),
+		stats:          new(listenerStats),
 	}
 	return qid, pd.directory.Link(newDir, name)
 }
@@ -410,6 +544,7 @@ func (vd *valueDir) Walk(names []string) ([]p9.QID, p9.File, error) {
 			connDirMu:      vd.connDirMu,
 			connDirPtr:     vd.connDirPtr,
 			connIndex:      vd.connIndex,
+			stats:          vd.stats,
 		}
 	}
 	return qids, file, err
@@ -425,7 +560,8 @@ func (vd *valueDir) Link(file p9.File, name string) error {
 		_, vOk = file.(*valueDir)
 		_, cOk = file.(*connDir)
 		_, fOk = file.(*listenerFile)
-		ok     = pOk || vOk || cOk || fOk
+		_, sOk = file.(*statsFile)
+		ok     = pOk || vOk || cOk || fOk || sOk
 	)
 	if !ok {
 		return fmt.Errorf("%w - unexpected file type", perrors.EACCES)
@@ -448,6 +584,7 @@ func (vd *valueDir) Mkdir(name string, permissions p9.FileMode, uid p9.UID, gid
 			connDirMu:      vd.connDirMu,
 			connDirPtr:     vd.connDirPtr,
 			connIndex:      vd.connIndex,
+			stats:          vd.stats,
 		}
 		return qid, vd.directory.Link(valueDir, name)
 	}
@@ -455,6 +592,9 @@ func (vd *valueDir) Mkdir(name string, permissions p9.FileMode, uid p9.UID, gid
 	if err != nil {
 		return p9.QID{}, fmt.Errorf("%w - %s", perrors.EIO, err)
 	}
+	if hasValue := protocol.Size != 0; !hasValue {
+		return vd.mkdirValueless(name, permissions, uid, gid)
+	}
 	qid, directory, link, err := vd.mkdir(vd,
 		name, permissions, uid, gid,
 	)
@@ -470,6 +610,33 @@ func (vd *valueDir) Mkdir(name string, permissions p9.FileMode, uid p9.UID, gid
 	return qid, vd.directory.Link(protoDir, name)
 }
 
+// mkdirValueless creates a value directory for
+// protocols which don't have values (E.g. `/http`),
+// since there's no value to make a directory for.
+func (vd *valueDir) mkdirValueless(name string, permissions p9.FileMode, uid p9.UID, gid p9.GID) (p9.QID, error) {
+	component, err := multiaddr.NewComponent(name, "")
+	if err != nil {
+		return p9.QID{}, err
+	}
+	qid, directory, link, err := vd.mkdir(vd,
+		name, permissions, uid, gid,
+	)
+	if err != nil {
+		return p9.QID{}, err
+	}
+	newDir := &valueDir{
+		listenerShared: vd.listenerShared,
+		linkSync:       link,
+		directory:      directory,
+		component:      component,
+		connDirMu:      new(sync.Mutex),
+		connDirPtr:     new(*connDir),
+		connIndex:      new(atomic.Uintptr),
+		stats:          new(listenerStats),
+	}
+	return qid, vd.directory.Link(newDir, name)
+}
+
 func (vd *valueDir) Create(name string, flags p9.OpenFlags,
 	permissions p9.FileMode, uid p9.UID, gid p9.GID,
 ) (p9.File, p9.QID, uint32, error) {
@@ -510,10 +677,17 @@ func (vd *valueDir) Mknod(name string, mode p9.FileMode,
 			},
 		}
 		qid, file = vd.newListenerFile(mode, uid, gid, fileListener)
+		stats     = vd.newStatsFile(uid, gid)
 	)
-	if err := vd.Link(file, name); err != nil {
+	if err := vd.Link(stats, statisticsFileName); err != nil {
 		return p9.QID{}, errors.Join(err, listener.Close())
 	}
+	if err := vd.Link(file, name); err != nil {
+		return p9.QID{}, errors.Join(err,
+			vd.directory.UnlinkAt(statisticsFileName, 0),
+			listener.Close(),
+		)
+	}
 	var (
 		link             = file.linkSync
 		unlinkerListener = &listenerCloser{
@@ -535,6 +709,17 @@ func (vd *valueDir) Mknod(name string, mode p9.FileMode,
 }
 
 func (vd *valueDir) listen(maddr multiaddr.Multiaddr, permissions p9.FileMode) (manet.Listener, error) {
+	var (
+		tlsConfig    *tls.Config
+		tlsComponent *multiaddr.Component
+	)
+	if base, last := multiaddr.SplitLast(maddr); base != nil &&
+		last != nil && last.Protocol().Code == multiaddr.P_TLS {
+		if tlsConfig = vd.tlsConfig; tlsConfig == nil {
+			return nil, errTLSConfig
+		}
+		maddr, tlsComponent = base, last
+	}
 	udsPath, err := maybeGetUDSPath(maddr)
 	if err != nil {
 		return nil, err
@@ -546,7 +731,7 @@ func (vd *valueDir) listen(maddr multiaddr.Multiaddr, permissions p9.FileMode) (
 			return nil, err
 		}
 	}
-	listener, err := manet.Listen(maddr)
+	listener, err := tunnel.Listen(maddr)
 	if err != nil {
 		if cleanup != nil {
 			return nil, errors.Join(err, cleanup())
@@ -569,6 +754,8 @@ func (vd *valueDir) listen(maddr multiaddr.Multiaddr, permissions p9.FileMode) (
 				Listener: listener,
 				closeFn:  closeFn,
 			},
+			tlsConfig:    tlsConfig,
+			tlsComponent: tlsComponent,
 		}
 	)
 	return trackingListener, nil
@@ -604,6 +791,28 @@ func (vd *valueDir) newListenerFile(
 	return metadata.QID, listenerFile
 }
 
+func (vd *valueDir) newStatsFile(uid p9.UID, gid p9.GID) *statsFile {
+	var metadata metadata
+	const permissions = ReadOther | ReadGroup | ReadUser
+	metadata.initialize(p9.ModeRegular | permissions)
+	metadata.ninePath = vd.path
+	metadata.UID, metadata.GID = uid, gid
+	statsFile := &statsFile{
+		metadata: &metadata,
+		stats:    vd.stats,
+		linkSync: &linkSync{
+			link: link{
+				parent: vd,
+				child:  statisticsFileName,
+			},
+			renameDisabled: true,
+		},
+	}
+	metadata.fillDefaults()
+	metadata.incrementPath()
+	return statsFile
+}
+
 func (vd *valueDir) UnlinkAt(name string, flags uint32) error {
 	directory := vd.directory
 	_, file, err := directory.Walk([]string{name})
@@ -613,6 +822,9 @@ func (vd *valueDir) UnlinkAt(name string, flags uint32) error {
 	// NOTE: non-fs errors are ignored in this operation.
 	if lFile, ok := file.(*listenerFile); ok {
 		lFile.Listener.Close()
+		// Statistics are only relevant
+		// while the listener exists.
+		directory.UnlinkAt(statisticsFileName, flags)
 	}
 	if _, ok := file.(*connDir); ok {
 		// HACK: we can't compare this file
@@ -898,11 +1110,55 @@ func splitMaddr(maddr multiaddr.Multiaddr) (components []*multiaddr.Component, n
 	return
 }
 
+// Multiaddr returns the listener's multiaddr,
+// including its `/tls` component (if any).
+func (ct *connTracker) Multiaddr() multiaddr.Multiaddr {
+	maddr := ct.Listener.Multiaddr()
+	if component := ct.tlsComponent; component != nil {
+		return maddr.Encapsulate(component)
+	}
+	return maddr
+}
+
 func (ct *connTracker) Accept() (manet.Conn, error) {
-	conn, err := ct.Listener.Accept()
-	if err != nil {
-		return nil, err
+	for {
+		conn, err := ct.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if ct.tlsConfig == nil {
+			return ct.track(conn)
+		}
+		secured, err := ct.handshake(conn)
+		if err != nil {
+			// Failed handshakes only concern that client;
+			// the listener should keep accepting.
+			continue
+		}
+		return ct.track(secured)
 	}
+}
+
+// handshake secures the connection with TLS.
+// The connection is closed if the handshake fails.
+func (ct *connTracker) handshake(conn manet.Conn) (manet.Conn, error) {
+	var (
+		secured     = tls.Server(conn, ct.tlsConfig)
+		ctx, cancel = context.WithTimeout(
+			context.Background(), tlsHandshakeTimeout,
+		)
+	)
+	defer cancel()
+	if err := secured.HandshakeContext(ctx); err != nil {
+		return nil, errors.Join(err, secured.Close())
+	}
+	return &tlsConn{
+		Conn: secured,
+		raw:  conn,
+	}, nil
+}
+
+func (ct *connTracker) track(conn manet.Conn) (manet.Conn, error) {
 	parent := ct.parent
 	connDir, err := parent.getConnDir()
 	if err != nil {
@@ -913,12 +1169,17 @@ func (ct *connTracker) Accept() (manet.Conn, error) {
 		unlinkOnce sync.Once
 		unlinked atomic.Bool
 		netErr   error
-		tracked  = p9net.NewTrackedConn(conn)
+		stats    = parent.stats
+		tracked  = &countedConn{
+			trackedConn: p9net.NewTrackedConn(conn),
+			stats:       stats,
+		}
 		fileConn = &connCloser{
 			trackedConn: tracked,
 			closeFn: func() error {
 				closeOnce.Do(func() {
 					unlinked.Store(true)
+					stats.connections.Add(-1)
 					netErr = tracked.Close()
 				})
 				return netErr
@@ -953,20 +1214,47 @@ func (ct *connTracker) Accept() (manet.Conn, error) {
 			},
 		}
 	)
+	stats.connections.Add(1)
 	if err := connDir.Close(); err != nil {
 		return nil, unwind(err, conn.Close, fileConn.Close)
 	}
 	return connUnlinker, nil
 }
 
+func (tc *tlsConn) LocalMultiaddr() multiaddr.Multiaddr {
+	return tc.raw.LocalMultiaddr()
+}
+
+func (tc *tlsConn) RemoteMultiaddr() multiaddr.Multiaddr {
+	return tc.raw.RemoteMultiaddr()
+}
+
+func (cc *countedConn) Read(b []byte) (int, error) {
+	read, err := cc.trackedConn.Read(b)
+	if read > 0 {
+		cc.stats.read.Add(uint64(read))
+	}
+	return read, err
+}
+
+func (cc *countedConn) Write(b []byte) (int, error) {
+	wrote, err := cc.trackedConn.Write(b)
+	if wrote > 0 {
+		cc.stats.wrote.Add(uint64(wrote))
+	}
+	return wrote, err
+}
+
 func (cf *connFile) marshal() ([]byte, error) {
 	tracked := cf.trackedConn
 	return json.Marshal(ConnInfo{
-		ID:        cf.connID,
-		Local:     tracked.LocalMultiaddr(),
-		Remote:    tracked.RemoteMultiaddr(),
-		LastRead:  tracked.LastRead(),
-		LastWrite: tracked.LastWrite(),
+		ID:          cf.connID,
+		Local:       tracked.LocalMultiaddr(),
+		Remote:      tracked.RemoteMultiaddr(),
+		LastRead:    tracked.LastRead(),
+		LastWrite:   tracked.LastWrite(),
+		Version:     tracked.Version(),
+		MessageSize: tracked.MessageSize(),
 	})
 }
 
@@ -1032,6 +1320,73 @@ func (cf *connFile) ReadAt(p []byte, offset int64) (int, error) {
 
 func (cc *connCloser) Close() error { return cc.closeFn() }
 
+func (ls *listenerStats) marshal() ([]byte, error) {
+	return json.Marshal(ListenerStatistics{
+		BytesRead:    ls.read.Load(),
+		BytesWritten: ls.wrote.Load(),
+		Connections:  ls.connections.Load(),
+	})
+}
+
+func (sf *statsFile) Walk(names []string) ([]p9.QID, p9.File, error) {
+	if len(names) > 0 {
+		return nil, nil, perrors.ENOTDIR
+	}
+	if sf.opened() {
+		return nil, nil, fidOpenedErr
+	}
+	return nil, &statsFile{
+		stats:    sf.stats,
+		metadata: sf.metadata,
+		linkSync: sf.linkSync,
+	}, nil
+}
+
+func (sf *statsFile) SetAttr(valid p9.SetAttrMask, attr p9.SetAttr) error {
+	return sf.metadata.SetAttr(valid, attr)
+}
+
+func (sf *statsFile) GetAttr(req p9.AttrMask) (p9.QID, p9.AttrMask, p9.Attr, error) {
+	if req.Size {
+		data, err := sf.stats.marshal()
+		if err != nil {
+			return p9.QID{}, p9.AttrMask{}, p9.Attr{}, err
+		}
+		sf.metadata.Size = uint64(len(data))
+	}
+	return sf.metadata.GetAttr(req)
+}
+
+func (sf *statsFile) Open(mode p9.OpenFlags) (p9.QID, ioUnit, error) {
+	if sf.opened() {
+		return p9.QID{}, 0, perrors.EBADF
+	}
+	sf.openFlags = sf.withOpenedFlag(mode)
+	return sf.QID, 0, nil
+}
+
+func (sf *statsFile) Close() error {
+	sf.openFlags = 0
+	sf.ReaderAt = nil
+	return nil
+}
+
+func (sf *statsFile) ReadAt(p []byte, offset int64) (int, error) {
+	reader := sf.ReaderAt
+	if reader == nil {
+		if !sf.canRead() {
+			return -1, perrors.EBADF
+		}
+		data, err := sf.stats.marshal()
+		if err != nil {
+			return -1, err
+		}
+		reader = bytes.NewReader(data)
+		sf.ReaderAt = reader
+	}
+	return reader.ReadAt(p, offset)
+}
+
 func (ci *ConnInfo) UnmarshalJSON(data []byte) error {
 	var maddrBuff struct {
 		Local  string `json:"local"`
@@ -1048,11 +1403,14 @@ func (ci *ConnInfo) UnmarshalJSON(data []byte) error {
 		return err
 	}
 	return json.Unmarshal(data, &struct {
-		ID        *uintptr   `json:"#"`
-		LastRead  *time.Time `json:"lastRead"`
-		LastWrite *time.Time `json:"lastWrite"`
+		ID          *uintptr   `json:"#"`
+		LastRead    *time.Time `json:"lastRead"`
+		LastWrite   *time.Time `json:"lastWrite"`
+		Version     *string    `json:"version"`
+		MessageSize *uint32    `json:"msize"`
 	}{
 		ID:       &ci.ID,
 		LastRead: &ci.LastRead, LastWrite: &ci.LastWrite,
+		Version: &ci.Version, MessageSize: &ci.MessageSize,
 	})
 }
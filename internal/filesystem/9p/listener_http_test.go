@@ -0,0 +1,31 @@
+//go:build !nohttp
+
+package p9_test
+
+import (
+	"context"
+	"testing"
+
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/multiformats/go-multiaddr"
+)
+
+func TestListenerHTTP(t *testing.T) {
+	t.Parallel()
+	const address = "127.0.0.1"
+	var (
+		maddr = newTCPMaddr(t, address).
+			Encapsulate(multiaddr.StringCast("/http"))
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer cancel()
+	_, listenerDir, listeners, lErr := p9fs.NewListener(ctx)
+	if lErr != nil {
+		t.Fatalf("could not create listener directory: %v", lErr)
+	}
+	listenerTCPServiceTest(t, listenerDir, listeners, maddr)
+	// Protocols without values (like `/http`)
+	// should still be represented in the tree.
+	names := maddrToNames(maddr)
+	mustWalkTo(t, listenerDir, names)
+}
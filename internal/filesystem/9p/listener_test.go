@@ -3,25 +3,40 @@ package p9_test
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"net"
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/go-filesystem-utils/internal/net/tunnel"
 	"github.com/djdv/p9/p9"
 	"github.com/multiformats/go-multiaddr"
 	manet "github.com/multiformats/go-multiaddr/net"
 )
 
-const listenerFileName = "listener"
+const (
+	listenerFileName   = "listener"
+	statisticsFileName = "statistics"
+)
 
 func TestListener(t *testing.T) {
 	t.Parallel()
 	t.Run("default", listenerDefault)
 	t.Run("options", listenerWithOptions)
+	t.Run("statistics", listenerStatistics)
+	t.Run("TLS", listenerTLS)
+	t.Run("unlisten", listenerUnlisten)
 }
 
 // best effort, not guaranteed to actually
@@ -120,6 +135,226 @@ func listenerWithOptions(t *testing.T) {
 	}
 }
 
+func listenerStatistics(t *testing.T) {
+	t.Parallel()
+	const address = "127.0.0.1"
+	var (
+		maddr       = newTCPMaddr(t, address)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer cancel()
+	_, listenerDir, listeners, lErr := p9fs.NewListener(ctx,
+		p9fs.UnlinkEmptyChildren[p9fs.ListenerOption](true),
+		p9fs.WithBuffer[p9fs.ListenerOption](1),
+	)
+	if lErr != nil {
+		t.Fatalf("could not create listener directory: %v", lErr)
+	}
+	const permissions = 0o751
+	if err := p9fs.Listen(listenerDir, maddr, permissions); err != nil {
+		t.Fatalf("could not listen on %v: %v", maddr, err)
+	}
+	listener := <-listeners
+	statsNames := append(maddrToNames(maddr), statisticsFileName)
+	statsMatch := func(want p9fs.ListenerStatistics) {
+		t.Helper()
+		statsFile, err := walkTo(listenerDir, statsNames)
+		if err != nil {
+			t.Fatalf("could not walk to statistics file: %v", err)
+		}
+		data, err := p9fs.ReadAll(statsFile)
+		if cErr := statsFile.Close(); cErr != nil {
+			err = errors.Join(err, cErr)
+		}
+		if err != nil {
+			t.Fatalf("could not read statistics file: %v", err)
+		}
+		var got p9fs.ListenerStatistics
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("statistics mismatch"+
+				"\ngot: %+v"+
+				"\nwant: %+v",
+				got, want,
+			)
+		}
+	}
+	statsMatch(p9fs.ListenerStatistics{})
+	var (
+		request  = []byte("request")
+		response = []byte("response data")
+		accepted = make(chan manet.Conn, 1)
+	)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Error(err)
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+	client, err := tunnel.Dial(maddr)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer client.Close()
+	server, ok := <-accepted
+	if !ok {
+		t.FailNow()
+	}
+	if _, err := client.Write(request); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Read(make([]byte, len(request))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Write(response); err != nil {
+		t.Fatal(err)
+	}
+	statsMatch(p9fs.ListenerStatistics{
+		BytesRead:    uint64(len(request)),
+		BytesWritten: uint64(len(response)),
+		Connections:  1,
+	})
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Totals persist after connections close.
+	statsMatch(p9fs.ListenerStatistics{
+		BytesRead:    uint64(len(request)),
+		BytesWritten: uint64(len(response)),
+	})
+	if err := listener.Close(); err != nil {
+		t.Fatalf("could not close listener: %v", err)
+	}
+	if _, err := walkTo(listenerDir, statsNames); err == nil {
+		t.Error("statistics file should not exist after listener is closed")
+	}
+}
+
+func listenerTLS(t *testing.T) {
+	t.Parallel()
+	const (
+		address     = "127.0.0.1"
+		permissions = 0o751
+	)
+	var (
+		serverConfig, clientConfig = newTLSConfigs(t, address)
+		tlsComponent               = multiaddr.StringCast("/tls")
+		ctx, cancel                = context.WithCancel(context.Background())
+	)
+	t.Cleanup(cancel)
+	t.Run("missing config", func(t *testing.T) {
+		t.Parallel()
+		_, listenerDir, _, err := p9fs.NewListener(ctx)
+		if err != nil {
+			t.Fatalf("could not create listener directory: %v", err)
+		}
+		maddr := newTCPMaddr(t, address).Encapsulate(tlsComponent)
+		if err := p9fs.Listen(listenerDir, maddr, permissions); err == nil {
+			t.Errorf("expected error listening on \"%s\" without a TLS config", maddr)
+		}
+	})
+	t.Run("handshake", func(t *testing.T) {
+		t.Parallel()
+		_, listenerDir, listeners, err := p9fs.NewListener(ctx,
+			p9fs.WithBuffer[p9fs.ListenerOption](1),
+			p9fs.WithTLSConfig(serverConfig),
+		)
+		if err != nil {
+			t.Fatalf("could not create listener directory: %v", err)
+		}
+		var (
+			tcpMaddr = newTCPMaddr(t, address)
+			maddr    = tcpMaddr.Encapsulate(tlsComponent)
+		)
+		if err := p9fs.Listen(listenerDir, maddr, permissions); err != nil {
+			t.Fatalf("could not listen on %v: %v", maddr, err)
+		}
+		listener := <-listeners
+		defer listener.Close()
+		if err := listenerMatches(listener, maddr); err != nil {
+			t.Error(err)
+		}
+		payload := []byte("arbitrary data")
+		serverErrs := listenerHostEchoTCP(listener, payload)
+		// A client which never completes the handshake
+		// must not prevent others from being accepted.
+		plain, err := manet.Dial(tcpMaddr)
+		if err != nil {
+			t.Fatalf("could not dial: %v", err)
+		}
+		if err := plain.Close(); err != nil {
+			t.Fatal(err)
+		}
+		_, tcpAddress, err := manet.DialArgs(tcpMaddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := tls.Dial("tcp", tcpAddress, clientConfig)
+		if err != nil {
+			t.Fatalf("could not dial TLS: %v", err)
+		}
+		if _, err := client.Write(payload); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Close(); err != nil {
+			t.Error(err)
+		}
+		for err := range serverErrs {
+			t.Error(err)
+		}
+	})
+}
+
+// newTLSConfigs returns configs for a server with a
+// self-signed certificate, and a client which trusts it.
+func newTLSConfigs(t *testing.T, address string) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		now      = time.Now()
+		template = x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			NotBefore:             now.Add(-time.Minute),
+			NotAfter:              now.Add(time.Hour),
+			IPAddresses:           []net.IP{net.ParseIP(address)},
+			KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+	)
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate)
+	server = &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{der},
+			PrivateKey:  key,
+		}},
+		MinVersion: tls.VersionTLS12,
+	}
+	client = &tls.Config{
+		RootCAs:    roots,
+		MinVersion: tls.VersionTLS12,
+	}
+	return server, client
+}
+
 func listenerTCPServiceTest(t *testing.T, listenerDir p9.File, listeners <-chan manet.Listener, maddr multiaddr.Multiaddr) {
 	var (
 		errs    = make(chan error)
@@ -203,7 +438,7 @@ func listenerHostEchoTCP(listener manet.Listener, expected []byte) <-chan error
 }
 
 func listenerClientEchoTCP(t *testing.T, maddr multiaddr.Multiaddr, payload []byte) {
-	conn, err := manet.Dial(maddr)
+	conn, err := tunnel.Dial(maddr)
 	if err != nil {
 		t.Fatalf("could not dial: %v", err)
 	}
@@ -279,3 +514,106 @@ func walkTo(root p9.File, names []string) (p9.File, error) {
 	_, file, err := root.Walk(names)
 	return file, err
 }
+
+func listenerUnlisten(t *testing.T) {
+	t.Parallel()
+	const (
+		address        = "127.0.0.1"
+		permissions    = 0o751
+		listenerBuffer = 2
+	)
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		fixed       = newTCPMaddr(t, address)
+		ephemeral   = multiaddr.StringCast("/ip4/" + address + "/tcp/0")
+	)
+	defer cancel()
+	_, listenerDir, listeners, err := p9fs.NewListener(ctx,
+		p9fs.WithBuffer[p9fs.ListenerOption](listenerBuffer),
+	)
+	if err != nil {
+		t.Fatalf("could not create listener directory: %v", err)
+	}
+	byMaddr := make(map[string]manet.Listener, listenerBuffer)
+	for _, maddr := range []multiaddr.Multiaddr{fixed, ephemeral} {
+		if err := p9fs.Listen(listenerDir, maddr, permissions); err != nil {
+			t.Fatalf("could not listen on %v: %v", maddr, err)
+		}
+		listener := <-listeners
+		t.Cleanup(func() { listener.Close() })
+		byMaddr[maddr.String()] = listener
+	}
+	requestsMatch := func(want ...multiaddr.Multiaddr) {
+		t.Helper()
+		requests, err := p9fs.GetListenerRequests(listenerDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]struct{}, len(requests))
+		for _, maddr := range requests {
+			got[maddr.String()] = struct{}{}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("listener request count mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				requests, want,
+			)
+		}
+		for _, maddr := range want {
+			if _, ok := got[maddr.String()]; !ok {
+				t.Errorf("missing listener request: %s", maddr)
+			}
+		}
+	}
+	// Requests are reported as requested,
+	// rather than as they were bound.
+	requestsMatch(fixed, ephemeral)
+	var (
+		listener = byMaddr[fixed.String()]
+		accepted = make(chan manet.Conn, 1)
+	)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Error(err)
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+	client, err := tunnel.Dial(fixed)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer client.Close()
+	server, ok := <-accepted
+	if !ok {
+		t.FailNow()
+	}
+	defer server.Close()
+	if err := p9fs.Unlisten(listenerDir, fixed); err != nil {
+		t.Fatal(err)
+	}
+	requestsMatch(ephemeral)
+	if _, err := tunnel.Dial(fixed); err == nil {
+		t.Error("dial succeeded after listener was removed")
+	}
+	// Connections which were already
+	// accepted must remain usable.
+	message := []byte("still connected")
+	if _, err := client.Write(message); err != nil {
+		t.Fatal(err)
+	}
+	received := make([]byte, len(message))
+	if _, err := server.Read(received); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(received, message) {
+		t.Errorf("message mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			received, message,
+		)
+	}
+}
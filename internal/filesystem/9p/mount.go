@@ -10,7 +10,6 @@ import (
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	"github.com/djdv/go-filesystem-utils/internal/generic"
-	perrors "github.com/djdv/p9/errors"
 	"github.com/djdv/p9/p9"
 )
 
@@ -30,6 +29,19 @@ type (
 	}
 	MounterOption func(*mounterSettings) error
 
+	// MountInfo describes a mount point file.
+	MountInfo struct {
+		Host   filesystem.Host
+		Guest  filesystem.ID
+		Target string
+		// Data is the mount point's encoded form.
+		// Writing it to a new mount point file
+		// (of the same host and guest) will
+		// recreate the mount.
+		Data    json.RawMessage
+		Pending bool
+	}
+
 	unmountError struct {
 		error
 		target string
@@ -88,7 +100,7 @@ func (mf *MountFile) Mkdir(name string, permissions p9.FileMode, uid p9.UID, gid
 	qid, file, err := mf.makeHostFn(mf, filesystem.Host(name),
 		mode, uid, gid)
 	if err != nil {
-		return p9.QID{}, errors.Join(perrors.EACCES, err)
+		return p9.QID{}, p9ErrnoFromError(err)
 	}
 	return qid, mf.Link(file, name)
 }
@@ -110,7 +122,7 @@ func (mf *MountFile) Mknod(name string, mode p9.FileMode,
 	qid, file, err := mf.makeHostFn(mf, filesystem.Host(name),
 		mode, uid, gid)
 	if err != nil {
-		return p9.QID{}, errors.Join(perrors.EACCES, err)
+		return p9.QID{}, p9ErrnoFromError(err)
 	}
 	return qid, mf.Link(file, name)
 }
@@ -215,6 +227,137 @@ func unmountTargetsPipeline(ctx context.Context,
 	}
 }
 
+// CountMounts returns the number of mount points
+// across all hosts and guests within mounts.
+func CountMounts(mounts p9.File) (int, error) {
+	var (
+		count       int
+		errs        []error
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer cancel()
+	for result := range flattenMounts(ctx, mounts) {
+		if err := result.error; err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		guestDir := result.value
+		ents, err := ReadDir(guestDir)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		count += len(ents)
+		if err := guestDir.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return count, errors.Join(errs...)
+}
+
+// ListMounts returns a description of each
+// mount point within mounts; including mounts
+// which are still pending.
+func ListMounts(mounts p9.File, decodeTargetFn DecodeTargetFunc) ([]MountInfo, error) {
+	var infos []MountInfo
+	err := walkMountFiles(mounts, func(file p9.File) error {
+		info, err := parseMountInfo(file, decodeTargetFn)
+		if err != nil {
+			return err
+		}
+		infos = append(infos, info)
+		return nil
+	})
+	return infos, err
+}
+
+// CancelMounts aborts pending mount operations
+// for each of the provided mount points.
+func CancelMounts(mounts p9.File,
+	mountPoints []string, decodeTargetFn DecodeTargetFunc,
+) error {
+	canceled := make([]string, 0, len(mountPoints))
+	err := walkMountFiles(mounts, func(file p9.File) error {
+		info, err := parseMountInfo(file, decodeTargetFn)
+		if err != nil {
+			return err
+		}
+		if !info.Pending {
+			return nil
+		}
+		for _, point := range mountPoints {
+			if point != info.Target {
+				continue
+			}
+			if err := cancelMountFile(file); err != nil {
+				return unmountError{target: point, error: err}
+			}
+			canceled = append(canceled, point)
+			return nil
+		}
+		return nil
+	})
+	var errs []error
+	if err != nil {
+		errs = []error{err}
+	}
+	if len(mountPoints) != len(canceled) ||
+		errs != nil {
+		return formatUnmountErr(mountPoints, canceled, errs)
+	}
+	return nil
+}
+
+func cancelMountFile(file p9.File) error {
+	_, clone, err := file.Walk(nil)
+	if err != nil {
+		return err
+	}
+	if _, _, err := clone.Open(p9.WriteOnly); err != nil {
+		return errors.Join(err, clone.Close())
+	}
+	const cancelKey = "cancel"
+	_, err = clone.WriteAt([]byte(cancelKey), 0)
+	return errors.Join(err, clone.Close())
+}
+
+// walkMountFiles calls fn with each
+// mount point file within mounts.
+func walkMountFiles(mounts p9.File, fn func(p9.File) error) error {
+	var (
+		errs        []error
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer cancel()
+	for result := range flattenMounts(ctx, mounts) {
+		if err := result.error; err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		guestDir := result.value
+		ents, err := ReadDir(guestDir)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, ent := range ents {
+			file, err := walkEnt(guestDir, ent)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if err := fn(file); err != nil {
+				errs = append(errs, err)
+			}
+			if err := file.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := guestDir.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // flattenMounts returns all guest directories
 // for all hosts within mounts.
 func flattenMounts(ctx context.Context, mounts p9.File) <-chan fileResult {
@@ -287,15 +430,30 @@ func unmountGuestEntry(ctx context.Context,
 }
 
 func parseMountFile(file p9.File, decodeFn DecodeTargetFunc) (string, error) {
+	info, err := parseMountInfo(file, decodeFn)
+	return info.Target, err
+}
+
+func parseMountInfo(file p9.File, decodeFn DecodeTargetFunc) (MountInfo, error) {
 	fileData, err := ReadAll(file)
 	if err != nil {
-		return "", err
+		return MountInfo{}, err
 	}
 	var point mountPointMarshal
 	if err := json.Unmarshal(fileData, &point); err != nil {
-		return "", err
+		return MountInfo{}, err
+	}
+	target, err := decodeFn(point.Host, point.ID, point.Data)
+	if err != nil {
+		return MountInfo{}, err
 	}
-	return decodeFn(point.Host, point.ID, point.Data)
+	return MountInfo{
+		Host:    point.Host,
+		Guest:   point.ID,
+		Target:  target,
+		Data:    point.Data,
+		Pending: point.Pending,
+	}, nil
 }
 
 func formatUnmountErr(mountPoints, unlinked []string, errs []error) error {
@@ -0,0 +1,374 @@
+package p9_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	perrors "github.com/djdv/p9/errors"
+	"github.com/djdv/p9/p9"
+)
+
+type (
+	// blockingMountPoint blocks in `MakeFS`
+	// until the channel stored in [blockingReleases]
+	// for its target is closed.
+	blockingMountPoint struct {
+		Target  string        `json:"target"`
+		Timeout time.Duration `json:"timeout,omitempty"`
+	}
+	nopCloser struct{}
+)
+
+const (
+	blockingHost  filesystem.Host = "blockingHost"
+	blockingGuest filesystem.ID   = "blockingGuest"
+)
+
+var blockingReleases sync.Map
+
+func (nopCloser) Close() error { return nil }
+
+func (*blockingMountPoint) HostID() filesystem.Host { return blockingHost }
+func (*blockingMountPoint) GuestID() filesystem.ID  { return blockingGuest }
+
+func (bm *blockingMountPoint) MountTimeout() time.Duration { return bm.Timeout }
+
+func (bm *blockingMountPoint) MakeFS() (fs.FS, error) {
+	release, ok := blockingReleases.Load(bm.Target)
+	if !ok {
+		return nil, fmt.Errorf(`no release channel for "%s"`, bm.Target)
+	}
+	<-release.(chan struct{})
+	return nil, nil
+}
+
+func (*blockingMountPoint) Mount(fs.FS) (io.Closer, error) {
+	return nopCloser{}, nil
+}
+
+func TestMountCancel(t *testing.T) {
+	t.Parallel()
+	const (
+		permissions = 0o751
+		uid         = p9.NoUID
+		gid         = p9.NoGID
+	)
+	var (
+		target    = t.Name()
+		release   = make(chan struct{})
+		mounts    = newBlockingMounter(t)
+		mountErrs = make(chan error, 1)
+		decodeFn  = func(_ filesystem.Host, _ filesystem.ID, data []byte) (string, error) {
+			var point blockingMountPoint
+			err := json.Unmarshal(data, &point)
+			return point.Target, err
+		}
+	)
+	blockingReleases.Store(target, release)
+	defer func() {
+		close(release)
+		blockingReleases.Delete(target)
+	}()
+	guests, err := p9fs.MkdirAll(mounts,
+		[]string{string(blockingHost), string(blockingGuest)},
+		permissions, uid, gid,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mountFile, _, _, err := guests.Create("mountpoint", p9.WriteOnly, permissions, uid, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := guests.Close(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		data := []byte(`{"target":"` + target + `"}`)
+		if _, err := mountFile.WriteAt(data, 0); err != nil {
+			mountErrs <- err
+			return
+		}
+		mountErrs <- mountFile.Close()
+	}()
+	mountWaitForPending(t, mounts, decodeFn, target)
+	if err := p9fs.CancelMounts(mounts, []string{target}, decodeFn); err != nil {
+		t.Fatal(err)
+	}
+	const timeout = 5 * time.Second
+	select {
+	case err := <-mountErrs:
+		if !errors.Is(err, perrors.ECANCELED) {
+			t.Errorf("mount error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, perrors.ECANCELED,
+			)
+		}
+	case <-time.After(timeout):
+		t.Fatal("pending mount was not canceled")
+	}
+	infos, err := p9fs.ListMounts(mounts, decodeFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 0 {
+		t.Errorf("mount point remained after cancel: %v", infos)
+	}
+	if err := p9fs.CancelMounts(mounts, []string{target}, decodeFn); err == nil {
+		t.Error("expected error when canceling non-existent mount")
+	}
+}
+
+func TestMountTimeout(t *testing.T) {
+	t.Parallel()
+	const (
+		permissions  = 0o751
+		uid          = p9.NoUID
+		gid          = p9.NoGID
+		mountTimeout = 10 * time.Millisecond
+	)
+	var (
+		target   = t.Name()
+		release  = make(chan struct{})
+		mounts   = newBlockingMounter(t)
+		decodeFn = func(_ filesystem.Host, _ filesystem.ID, data []byte) (string, error) {
+			var point blockingMountPoint
+			err := json.Unmarshal(data, &point)
+			return point.Target, err
+		}
+	)
+	blockingReleases.Store(target, release)
+	defer func() {
+		close(release)
+		blockingReleases.Delete(target)
+	}()
+	guests, err := p9fs.MkdirAll(mounts,
+		[]string{string(blockingHost), string(blockingGuest)},
+		permissions, uid, gid,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mountFile, _, _, err := guests.Create("mountpoint", p9.WriteOnly, permissions, uid, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := guests.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(blockingMountPoint{
+		Target:  target,
+		Timeout: mountTimeout,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mountFile.WriteAt(data, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := mountFile.Close(); !errors.Is(err, perrors.ETIMEDOUT) {
+		t.Errorf("mount error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, perrors.ETIMEDOUT,
+		)
+	}
+	infos, err := p9fs.ListMounts(mounts, decodeFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 0 {
+		t.Errorf("mount point remained after timeout: %v", infos)
+	}
+}
+
+func TestMountRestore(t *testing.T) {
+	t.Parallel()
+	const (
+		permissions = 0o751
+		uid         = p9.NoUID
+		gid         = p9.NoGID
+	)
+	var (
+		mounts   = newBlockingMounter(t)
+		decodeFn = func(_ filesystem.Host, _ filesystem.ID, data []byte) (string, error) {
+			var point blockingMountPoint
+			err := json.Unmarshal(data, &point)
+			return point.Target, err
+		}
+		mountAll = func(t *testing.T, data [][]byte) {
+			t.Helper()
+			guests, err := p9fs.MkdirAll(mounts,
+				[]string{string(blockingHost), string(blockingGuest)},
+				permissions, uid, gid,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer guests.Close()
+			for i, datum := range data {
+				name := fmt.Sprintf("mountpoint%d", i)
+				mountFile, _, _, err := guests.Create(name, p9.WriteOnly, permissions, uid, gid)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := mountFile.WriteAt(datum, 0); err != nil {
+					t.Fatal(err)
+				}
+				if err := mountFile.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		listSorted = func(t *testing.T) []p9fs.MountInfo {
+			t.Helper()
+			infos, err := p9fs.ListMounts(mounts, decodeFn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(infos, func(i, j int) bool {
+				return infos[i].Target < infos[j].Target
+			})
+			return infos
+		}
+		released = make(chan struct{})
+		data     = make([][]byte, 3)
+	)
+	close(released)
+	for i := range data {
+		target := fmt.Sprintf("%s/%d", t.Name(), i)
+		blockingReleases.Store(target, released)
+		defer blockingReleases.Delete(target)
+		datum, err := json.Marshal(blockingMountPoint{
+			Target:  target,
+			Timeout: time.Duration(i) * time.Hour,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		data[i] = datum
+	}
+	mountAll(t, data)
+	dumped := listSorted(t)
+	if got, want := len(dumped), len(data); got != want {
+		t.Fatalf("mount count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+	if err := p9fs.UnmountAll(mounts); err != nil {
+		t.Fatal(err)
+	}
+	dump := make([][]byte, len(dumped))
+	for i, info := range dumped {
+		dump[i] = info.Data
+	}
+	mountAll(t, dump)
+	restored := listSorted(t)
+	if got, want := len(restored), len(dumped); got != want {
+		t.Fatalf("restored mount count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+	for i, want := range dumped {
+		got := restored[i]
+		if got.Host != want.Host ||
+			got.Guest != want.Guest ||
+			got.Target != want.Target ||
+			!bytes.Equal(got.Data, want.Data) {
+			t.Errorf("restored mount mismatch"+
+				"\ngot: %+v"+
+				"\nwant: %+v",
+				got, want,
+			)
+		}
+	}
+}
+
+func newBlockingMounter(t *testing.T) p9.File {
+	t.Helper()
+	return newTestMounter[*blockingMountPoint](t)
+}
+
+func newTestMounter[
+	MP interface {
+		*T
+		p9fs.MountPoint
+	},
+	T any,
+](t *testing.T,
+) p9.File {
+	t.Helper()
+	makeMountPointFn := func(parent p9.File, name string,
+		mode p9.FileMode, uid p9.UID, gid p9.GID,
+	) (p9.QID, p9.File, error) {
+		return p9fs.NewMountPoint[MP](
+			p9fs.WithParent[p9fs.MountPointOption](parent, name),
+			p9fs.WithUID[p9fs.MountPointOption](uid),
+			p9fs.WithGID[p9fs.MountPointOption](gid),
+			p9fs.WithPermissions[p9fs.MountPointOption](mode.Permissions()),
+		)
+	}
+	makeGuestFn := func(parent p9.File, guest filesystem.ID,
+		mode p9.FileMode, uid p9.UID, gid p9.GID,
+	) (p9.QID, p9.File, error) {
+		return p9fs.NewGuestFile(
+			makeMountPointFn,
+			p9fs.WithParent[p9fs.GuestOption](parent, string(guest)),
+			p9fs.WithUID[p9fs.GuestOption](uid),
+			p9fs.WithGID[p9fs.GuestOption](gid),
+			p9fs.WithPermissions[p9fs.GuestOption](mode.Permissions()),
+		)
+	}
+	makeHostFn := func(parent p9.File, host filesystem.Host,
+		mode p9.FileMode, uid p9.UID, gid p9.GID,
+	) (p9.QID, p9.File, error) {
+		return p9fs.NewHostFile(
+			makeGuestFn,
+			p9fs.WithParent[p9fs.HosterOption](parent, string(host)),
+			p9fs.WithUID[p9fs.HosterOption](uid),
+			p9fs.WithGID[p9fs.HosterOption](gid),
+			p9fs.WithPermissions[p9fs.HosterOption](mode.Permissions()),
+		)
+	}
+	_, mounts, err := p9fs.NewMounter(makeHostFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mounts
+}
+
+func mountWaitForPending(t *testing.T, mounts p9.File,
+	decodeFn p9fs.DecodeTargetFunc, target string,
+) {
+	t.Helper()
+	const (
+		timeout  = 5 * time.Second
+		interval = time.Millisecond
+	)
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(interval) {
+		// NOTE: The file may not be populated yet,
+		// so decoding errors are not fatal here.
+		infos, _ := p9fs.ListMounts(mounts, decodeFn)
+		for _, info := range infos {
+			if info.Target == target && info.Pending {
+				return
+			}
+		}
+	}
+	t.Fatalf("mount for \"%s\" never became pending", target)
+}
@@ -2,6 +2,7 @@ package p9
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"io/fs"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	"github.com/djdv/go-filesystem-utils/internal/generic"
@@ -37,6 +39,19 @@ type (
 	Mounter interface {
 		Mount(fs.FS) (io.Closer, error)
 	}
+	// MountTimeouter may be implemented by mount points
+	// which bound the duration of their mount operation.
+	// If the duration is exceeded, the mount is aborted.
+	// Values <= 0 do not bound the operation.
+	MountTimeouter interface {
+		MountTimeout() time.Duration
+	}
+	// HandleCounter may be implemented by the [io.Closer]
+	// returned from [Mounter.Mount], to report the
+	// amount of files the host is holding open.
+	HandleCounter interface {
+		OpenHandles() int
+	}
 	mountPointTag struct {
 		filesystem.Host `json:"host"`
 		filesystem.ID   `json:"guest"`
@@ -44,6 +59,7 @@ type (
 	mountPointMarshal struct {
 		mountPointTag `json:"tag"`
 		Data          json.RawMessage `json:"data"`
+		Pending       bool            `json:"pending,omitempty"`
 	}
 	HostIdentifier interface {
 		HostID() filesystem.Host
@@ -79,10 +95,36 @@ type (
 	detachFunc     = func() error
 	mountPointHost struct {
 		unmountFn *detachFunc
+		pending   *pendingMount
+		health    *mountHealth
+	}
+	// pendingMount tracks a mount operation
+	// which has not yet returned, so that it
+	// may be canceled by other fids.
+	pendingMount struct {
+		cancel context.CancelCauseFunc
+		mu     sync.Mutex
+	}
+	mountResult struct {
+		io.Closer
+		fsys              fs.FS
+		makeErr, mountErr error
 	}
 	MountPointOption func(*fileSettings) error
 )
 
+const (
+	// errMountCanceled is the cause used when
+	// a pending mount is canceled via keyword.
+	errMountCanceled = generic.ConstError("mount canceled")
+	// errMountDetached is the cause used when
+	// a pending mount's file is unlinked.
+	errMountDetached = generic.ConstError("mount point detached")
+	// errMountTimeout is returned when a mount
+	// exceeds its [MountTimeouter.MountTimeout].
+	errMountTimeout = generic.ConstError("mount timed out")
+)
+
 func (fe FieldError) Error() string {
 	// Format:
 	// unexpected key: "${key}", want one of: $QuotedCSV(${tried})
@@ -151,6 +193,8 @@ func NewMountPoint[
 		},
 		mountPointHost: mountPointHost{
 			unmountFn: new(detachFunc),
+			pending:   new(pendingMount),
+			health:    new(mountHealth),
 		},
 	}
 	settings.metadata.fillDefaults()
@@ -159,10 +203,14 @@ func NewMountPoint[
 }
 
 func (mf *MountPointFile[MP]) SetAttr(valid p9.SetAttrMask, attr p9.SetAttr) error {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
 	return mf.metadata.SetAttr(valid, attr)
 }
 
 func (mf *MountPointFile[MP]) GetAttr(req p9.AttrMask) (p9.QID, p9.AttrMask, p9.Attr, error) {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
 	return mf.metadata.GetAttr(req)
 }
 
@@ -177,6 +225,8 @@ func (mf *MountPointFile[MP]) Walk(names []string) ([]p9.QID, p9.File, error) {
 		mountPointFile: mf.mountPointFile,
 		mountPointHost: mountPointHost{
 			unmountFn: mf.unmountFn,
+			pending:   mf.pending,
+			health:    mf.health,
 		},
 		mountPoint: mf.mountPoint,
 	}, nil
@@ -228,6 +278,9 @@ func (mf *MountPointFile[MP]) parseFieldsLocked(b []byte) error {
 	for _, fields := range tokenize(b) {
 		switch fields.typ() {
 		case keyAndValue:
+			if mf.pending.active() {
+				return fmt.Errorf("%w - mount is pending", perrors.EBUSY)
+			}
 			parser, ok := any(mf.mountPoint).(FieldParser)
 			if !ok {
 				// TODO: [Go 1.21] use [errors.ErrUnsupported].
@@ -261,7 +314,8 @@ func (mf *MountPointFile[MP]) serializeLocked() ([]byte, error) {
 		return nil, err
 	}
 	return json.Marshal(mountPointMarshal{
-		Data: json.RawMessage(mb),
+		Data:    json.RawMessage(mb),
+		Pending: mf.pending.active(),
 		mountPointTag: mountPointTag{
 			Host: mf.mountPoint.HostID(),
 			ID:   mf.mountPoint.GuestID(),
@@ -270,19 +324,31 @@ func (mf *MountPointFile[MP]) serializeLocked() ([]byte, error) {
 }
 
 func (mf *MountPointFile[MP]) parseKeyWordLocked(keyWord string) error {
-	const syncKey = "sync"
-	if keyWord == syncKey {
+	const (
+		syncKey   = "sync"
+		cancelKey = "cancel"
+	)
+	switch keyWord {
+	case syncKey:
 		return mf.syncLocked()
+	case cancelKey:
+		if !mf.pending.abort(errMountCanceled) {
+			return generic.ConstError("no mount is pending")
+		}
+		return nil
 	}
 	return FieldError{
 		Key:   keyWord,
-		Tried: []string{syncKey},
+		Tried: []string{syncKey, cancelKey},
 	}
 	// TODO: Expected one of: $...
 	// return fmt.Errorf("%w - invalid keyword: %s", perrors.EINVAL, keyWord)
 }
 
 func (mf *MountPointFile[MP]) bufferStructuredLocked(p []byte, offset int64) (int, error) {
+	if mf.pending.active() {
+		return -1, fmt.Errorf("%w - mount is pending", perrors.EBUSY)
+	}
 	buffer := mf.buffer
 	if buffer == nil {
 		buffer = new(bytes.Buffer)
@@ -313,15 +379,21 @@ func (mf *MountPointFile[MP]) syncLocked() error {
 		return err
 	}
 	mf.modified = false
+	if err := mf.refreshLocked(); err != nil {
+		return err
+	}
+	return mf.remountLocked()
+}
+
+// refreshLocked updates the file's size (and this fid's
+// reader) to match the mount point's current state.
+func (mf *MountPointFile[MP]) refreshLocked() error {
 	data, err := mf.serializeLocked()
 	if err != nil {
 		return err
 	}
 	mf.Size = uint64(len(data))
-	if err := mf.resetReaderLocked(data); err != nil {
-		return err
-	}
-	return mf.remountLocked()
+	return mf.resetReaderLocked(data)
 }
 
 func (mf *MountPointFile[MP]) resetReaderLocked(data []byte) error {
@@ -351,6 +423,7 @@ func (mf *MountPointFile[MP]) flushBufferLocked() error {
 
 func (mf *MountPointFile[MP]) remountLocked() error {
 	if unmount := *mf.unmountFn; unmount != nil {
+		mf.health.reset(nil, nil)
 		if err := unmount(); err != nil {
 			return err
 		}
@@ -358,26 +431,114 @@ func (mf *MountPointFile[MP]) remountLocked() error {
 	return mf.mountFileLocked()
 }
 
+// mountFileLocked calls the mount point's
+// potentially blocking methods without holding the
+// file's lock, so that other fids may inspect or
+// cancel the mount while it is pending.
 func (mf *MountPointFile[MP]) mountFileLocked() error {
-	goFS, err := mf.mountPoint.MakeFS()
+	ctx, err := mf.pending.begin()
 	if err != nil {
 		return err
 	}
-	closer, err := mf.mountPoint.Mount(goFS)
-	if err == nil {
-		*mf.unmountFn = closer.Close
-		return nil
+	if err := mf.refreshLocked(); err != nil {
+		mf.pending.end()
+		return err
 	}
-	if parent := mf.linkSync.parent; parent != nil {
-		const flags = 0
-		child := mf.linkSync.child
-		return errors.Join(
-			perrors.EIO,
-			err,
-			parent.UnlinkAt(child, flags),
+	var (
+		mountPoint = mf.mountPoint
+		results    = make(chan mountResult, 1)
+		timeout    <-chan time.Time
+	)
+	if timeouter, ok := any(mountPoint).(MountTimeouter); ok {
+		if duration := timeouter.MountTimeout(); duration > 0 {
+			timer := time.NewTimer(duration)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+	}
+	go func() {
+		goFS, err := mountPoint.MakeFS()
+		if err != nil {
+			results <- mountResult{makeErr: err}
+			return
+		}
+		closer, err := mountPoint.Mount(goFS)
+		results <- mountResult{Closer: closer, fsys: goFS, mountErr: err}
+	}()
+	mf.mu.Unlock()
+	var (
+		result             mountResult
+		canceled, timedOut bool
+	)
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		canceled = true
+		go closeAbandoned(results)
+	case <-timeout:
+		timedOut = true
+		go closeAbandoned(results)
+	}
+	mf.mu.Lock()
+	mf.pending.end()
+	abandoned := canceled || timedOut
+	if err := mf.refreshLocked(); err != nil {
+		if !abandoned && result.makeErr == nil && result.mountErr == nil {
+			err = errors.Join(err, result.Closer.Close())
+		}
+		return err
+	}
+	if timedOut {
+		return mf.unlinkFailedLocked(
+			errors.Join(perrors.ETIMEDOUT, errMountTimeout),
 		)
 	}
-	return errors.Join(perrors.EIO, err)
+	if canceled {
+		cause := context.Cause(ctx)
+		err := errors.Join(perrors.ECANCELED, cause)
+		if errors.Is(cause, errMountDetached) {
+			return err // Parent is already unlinking this file.
+		}
+		return mf.unlinkFailedLocked(err)
+	}
+	if err := result.makeErr; err != nil {
+		return p9ErrnoFromError(err)
+	}
+	if err := result.mountErr; err != nil {
+		return mf.unlinkFailedLocked(p9ErrnoFromError(err))
+	}
+	*mf.unmountFn = result.Closer.Close
+	handles, _ := result.Closer.(HandleCounter)
+	mf.health.reset(result.fsys, handles)
+	return nil
+}
+
+// unlinkFailedLocked removes the file from its parent.
+// The parent will access this file during the operation,
+// so the lock is released until it returns.
+func (mf *MountPointFile[MP]) unlinkFailedLocked(err error) error {
+	parent := mf.linkSync.parent
+	if parent == nil {
+		return err
+	}
+	const flags = 0
+	child := mf.linkSync.child
+	mf.mu.Unlock()
+	defer mf.mu.Lock()
+	return errors.Join(
+		err,
+		parent.UnlinkAt(child, flags),
+	)
+}
+
+// closeAbandoned waits for a canceled mount
+// operation to return, and closes the
+// resulting system if it succeeded.
+func closeAbandoned(results <-chan mountResult) {
+	if result := <-results; result.makeErr == nil &&
+		result.mountErr == nil {
+		result.Closer.Close()
+	}
 }
 
 func (mf *MountPointFile[MP]) ReadAt(p []byte, offset int64) (int, error) {
@@ -408,8 +569,48 @@ func (mf *MountPointFile[MP]) Close() error {
 }
 
 func (mf *MountPointFile[MP]) detach() error {
+	mf.pending.abort(errMountDetached)
+	mf.health.reset(nil, nil)
 	if detach := *mf.unmountFn; detach != nil {
 		return detach()
 	}
 	return nil
 }
+
+func (pm *pendingMount) begin() (context.Context, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.cancel != nil {
+		return nil, fmt.Errorf("%w - mount is pending", perrors.EBUSY)
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	pm.cancel = cancel
+	return ctx, nil
+}
+
+func (pm *pendingMount) end() {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if cancel := pm.cancel; cancel != nil {
+		cancel(nil)
+		pm.cancel = nil
+	}
+}
+
+func (pm *pendingMount) active() bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.cancel != nil
+}
+
+// abort cancels the pending mount (if any)
+// and reports whether one was pending.
+func (pm *pendingMount) abort(cause error) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if cancel := pm.cancel; cancel != nil {
+		cancel(cause)
+		return true
+	}
+	return false
+}
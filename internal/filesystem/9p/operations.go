@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	perrors "github.com/djdv/p9/errors"
 	"github.com/djdv/p9/p9"
 	"github.com/multiformats/go-multiaddr"
@@ -37,6 +38,11 @@ type (
 	fieldType  uint
 
 	openFlags p9.OpenFlags
+
+	// MkdirPermissionsFunc returns the permissions for a
+	// directory created by [MkdirAllFunc].
+	// `depth` is the index of `name` within `names`.
+	MkdirPermissionsFunc func(depth int, name string) p9.FileMode
 )
 
 const (
@@ -155,16 +161,43 @@ func createViaMknod(fsys p9.File, name string, flags p9.OpenFlags,
 	return file, qid, ioUnit, nil
 }
 
+// MkdirAll creates each directory in `names` (if it does not exist),
+// with the same permissions, and returns the last one.
 func MkdirAll(root p9.File, names []string,
 	permissions p9.FileMode, uid p9.UID, gid p9.GID,
+) (p9.File, error) {
+	return MkdirAllFunc(root, names,
+		func(int, string) p9.FileMode { return permissions },
+		uid, gid,
+	)
+}
+
+// LeafPermissions returns a [MkdirPermissionsFunc] which
+// uses the `leaf` permissions for the last of the `count`
+// directories, and the `intermediate` permissions for the others.
+func LeafPermissions(count int, intermediate, leaf p9.FileMode) MkdirPermissionsFunc {
+	last := count - 1
+	return func(depth int, _ string) p9.FileMode {
+		if depth == last {
+			return leaf
+		}
+		return intermediate
+	}
+}
+
+// MkdirAllFunc is like [MkdirAll], but calls `permissions`
+// for each directory it creates.
+// Directories which already exist are not modified.
+func MkdirAllFunc(root p9.File, names []string,
+	permissions MkdirPermissionsFunc, uid p9.UID, gid p9.GID,
 ) (p9.File, error) {
 	_, current, err := root.Walk(nil)
 	if err != nil {
 		return nil, err
 	}
-	for _, name := range names {
+	for depth, name := range names {
 		var (
-			next, err = mkdirAndWalk(current, name, permissions, uid, gid)
+			next, err = mkdirAndWalk(current, name, permissions(depth, name), uid, gid)
 			cErr      = current.Close()
 		)
 		if err != nil {
@@ -242,6 +275,9 @@ func getDirentsPipeline(ctx context.Context, dir p9.File, wg *sync.WaitGroup, re
 			return
 		}
 		for _, entry := range entires {
+			if filesystem.IsDotName(entry.Name) {
+				continue // Callers only expect children.
+			}
 			if !sendResult(ctx, results, direntResult{value: entry}) {
 				return
 			}
@@ -0,0 +1,71 @@
+package p9_test
+
+import (
+	"testing"
+
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/p9/p9"
+)
+
+func TestMkdirAllFunc(t *testing.T) {
+	t.Parallel()
+	const (
+		intermediate p9.FileMode = 0o711
+		leaf         p9.FileMode = 0o750
+	)
+	var (
+		names        = []string{"a", "b", "c"}
+		_, root, err = p9fs.NewDirectory()
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	permissions := p9fs.LeafPermissions(len(names), intermediate, leaf)
+	directory, err := p9fs.MkdirAllFunc(root, names,
+		permissions, p9.NoUID, p9.NoGID,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := directory.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for depth := range names {
+		want := intermediate
+		if depth == len(names)-1 {
+			want = leaf
+		}
+		var (
+			wnames = names[:depth+1]
+			got    = getPermissions(t, root, wnames)
+		)
+		if got != want {
+			t.Errorf("permissions mismatch for %v"+
+				"\ngot: %#o"+
+				"\nwant: %#o",
+				wnames, got, want,
+			)
+		}
+	}
+}
+
+func getPermissions(t *testing.T, root p9.File, names []string) p9.FileMode {
+	t.Helper()
+	_, file, err := root.Walk(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+	_, valid, attr, err := file.GetAttr(p9.AttrMask{Mode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid.Mode {
+		t.Fatal("file mode was not returned")
+	}
+	return attr.Mode.Permissions()
+}
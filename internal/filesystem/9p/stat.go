@@ -58,7 +58,7 @@ func (md *metadata) setPermissions(permissions p9.FileMode) {
 func (md *metadata) initialize(mode p9.FileMode) {
 	var (
 		now       = time.Now()
-		sec, nano = uint64(now.Unix()), uint64(now.UnixNano())
+		sec, nano = uint64(now.Unix()), uint64(now.Nanosecond())
 	)
 	md.Attr = p9.Attr{
 		Mode: mode,
@@ -141,32 +141,34 @@ func (md *metadata) incrementPath() {
 	md.QID.Path = md.ninePath.Add(1)
 }
 
+// SetAttr applies the attributes in the mask.
+// Times which are valid but not flagged as
+// "not system time" are set to the current time.
 func (md *metadata) SetAttr(valid p9.SetAttrMask, attr p9.SetAttr) error {
 	var (
-		ourAttr  = md.Attr
-		ourAtime = !valid.ATimeNotSystemTime
-		ourMtime = !valid.MTimeNotSystemTime
+		nowAtime = valid.ATime && !valid.ATimeNotSystemTime
+		nowMtime = valid.MTime && !valid.MTimeNotSystemTime
 		cTime    = valid.CTime
 	)
-	if usingClock := ourAtime || ourMtime || cTime; usingClock {
+	if usingClock := nowAtime || nowMtime || cTime; usingClock {
 		var (
 			now  = time.Now()
 			sec  = uint64(now.Unix())
-			nano = uint64(now.UnixNano())
+			nano = uint64(now.Nanosecond())
 		)
-		if ourAtime {
+		if nowAtime {
 			valid.ATime = false
-			ourAttr.ATimeSeconds, ourAttr.ATimeNanoSeconds = sec, nano
+			md.ATimeSeconds, md.ATimeNanoSeconds = sec, nano
 		}
-		if ourMtime {
+		if nowMtime {
 			valid.MTime = false
-			ourAttr.MTimeSeconds, ourAttr.MTimeNanoSeconds = sec, nano
+			md.MTimeSeconds, md.MTimeNanoSeconds = sec, nano
 		}
 		if cTime {
-			ourAttr.CTimeSeconds, ourAttr.CTimeNanoSeconds = sec, nano
+			md.CTimeSeconds, md.CTimeNanoSeconds = sec, nano
 		}
 	}
-	ourAttr.Apply(valid, attr)
+	md.Attr.Apply(valid, attr)
 	return nil
 }
 
@@ -198,19 +200,23 @@ func validAttrs(req *p9.AttrMask, attr *p9.Attr) {
 		req.RDev = attr.RDev != 0
 	}
 	if req.ATime {
-		req.ATime = attr.ATimeNanoSeconds != 0
+		req.ATime = attr.ATimeSeconds != 0 ||
+			attr.ATimeNanoSeconds != 0
 	}
 	if req.MTime {
-		req.MTime = attr.MTimeNanoSeconds != 0
+		req.MTime = attr.MTimeSeconds != 0 ||
+			attr.MTimeNanoSeconds != 0
 	}
 	if req.CTime {
-		req.CTime = attr.CTimeNanoSeconds != 0
+		req.CTime = attr.CTimeSeconds != 0 ||
+			attr.CTimeNanoSeconds != 0
 	}
 	if req.Blocks {
 		req.Blocks = attr.Blocks != 0
 	}
 	if req.BTime {
-		req.BTime = attr.BTimeNanoSeconds != 0
+		req.BTime = attr.BTimeSeconds != 0 ||
+			attr.BTimeNanoSeconds != 0
 	}
 	if req.Gen {
 		req.Gen = attr.Gen != 0
@@ -0,0 +1,135 @@
+package p9_test
+
+import (
+	"testing"
+	"time"
+
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/p9/p9"
+)
+
+func TestSetAttrTimes(t *testing.T) {
+	t.Parallel()
+	t.Run("explicit", testSetAttrTimesExplicit)
+	t.Run("now", testSetAttrTimesNow)
+	t.Run("unrequested", testSetAttrTimesUnrequested)
+}
+
+func testSetAttrTimesExplicit(t *testing.T) {
+	t.Parallel()
+	var (
+		file  = newAttrFile(t)
+		atime = time.Unix(1_000_000, 500)
+		mtime = time.Unix(2_000_000, 0)
+	)
+	if err := file.SetAttr(p9.SetAttrMask{
+		ATime: true, ATimeNotSystemTime: true,
+		MTime: true, MTimeNotSystemTime: true,
+	}, p9.SetAttr{
+		ATimeSeconds:     uint64(atime.Unix()),
+		ATimeNanoSeconds: uint64(atime.Nanosecond()),
+		MTimeSeconds:     uint64(mtime.Unix()),
+		MTimeNanoSeconds: uint64(mtime.Nanosecond()),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	gotAtime, gotMtime := getAttrTimes(t, file)
+	timeMatch(t, "atime", gotAtime, atime)
+	timeMatch(t, "mtime", gotMtime, mtime)
+}
+
+func testSetAttrTimesNow(t *testing.T) {
+	t.Parallel()
+	file := newAttrFile(t)
+	const past = 1_000_000
+	if err := file.SetAttr(p9.SetAttrMask{
+		MTime: true, MTimeNotSystemTime: true,
+	}, p9.SetAttr{MTimeSeconds: past}); err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	// The provided time must be ignored
+	// when "not system time" is not set.
+	if err := file.SetAttr(p9.SetAttrMask{
+		MTime: true,
+	}, p9.SetAttr{MTimeSeconds: past}); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now()
+	_, mtime := getAttrTimes(t, file)
+	if mtime.Before(before.Truncate(time.Second)) ||
+		mtime.After(after) {
+		t.Errorf("mtime was not set to the current time"+
+			"\ngot: %s"+
+			"\nwant: [%s, %s]",
+			mtime, before, after,
+		)
+	}
+}
+
+func testSetAttrTimesUnrequested(t *testing.T) {
+	t.Parallel()
+	file := newAttrFile(t)
+	atime, mtime := getAttrTimes(t, file)
+	if err := file.SetAttr(p9.SetAttrMask{
+		Permissions: true,
+	}, p9.SetAttr{Permissions: 0o700}); err != nil {
+		t.Fatal(err)
+	}
+	gotAtime, gotMtime := getAttrTimes(t, file)
+	timeMatch(t, "atime", gotAtime, atime)
+	timeMatch(t, "mtime", gotMtime, mtime)
+	_, valid, attr, err := file.GetAttr(p9.AttrMask{Mode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid.Mode {
+		t.Fatal("mode was not returned")
+	}
+	if got, want := attr.Mode.Permissions(), p9.FileMode(0o700); got != want {
+		t.Errorf("permissions mismatch"+
+			"\ngot: %#o"+
+			"\nwant: %#o",
+			got, want,
+		)
+	}
+}
+
+func newAttrFile(t *testing.T) p9.File {
+	t.Helper()
+	_, file, err := p9fs.NewDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func getAttrTimes(t *testing.T, file p9.File) (atime, mtime time.Time) {
+	t.Helper()
+	want := p9.AttrMask{ATime: true, MTime: true}
+	_, valid, attr, err := file.GetAttr(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid.ATime || !valid.MTime {
+		t.Fatalf("times were not returned"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			valid, want,
+		)
+	}
+	atime = time.Unix(int64(attr.ATimeSeconds), int64(attr.ATimeNanoSeconds))
+	mtime = time.Unix(int64(attr.MTimeSeconds), int64(attr.MTimeNanoSeconds))
+	return atime, mtime
+}
+
+func timeMatch(t *testing.T, name string, got, want time.Time) {
+	t.Helper()
+	if !got.Equal(want) {
+		t.Errorf("%s mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			name, got, want,
+		)
+	}
+}
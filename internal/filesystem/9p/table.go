@@ -60,7 +60,8 @@ func (ft *fileTableSync) flatten(offset uint64, count uint32) ([]string, []p9.Fi
 		i++
 	}
 	sort.Strings(names)
-	names = names[offset : offset+uint64(generic.Min(len(names), int(count)))]
+	end := generic.Min(uint64(len(names)), offset+uint64(count))
+	names = names[offset:end]
 
 	files := make([]p9.File, len(names))
 	for i, name := range names {
@@ -0,0 +1,82 @@
+package p9
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/djdv/p9/p9"
+)
+
+// TreeEntry describes a file
+// within a directory tree.
+type TreeEntry struct {
+	Name     string
+	Children []TreeEntry
+	Size     uint64
+	Type     p9.QIDType
+}
+
+// ReadTree returns the entries within `dir`, sorted by name.
+// Directories are descended into until `depth`
+// layers have been read. If depth is < 1,
+// the whole tree is read.
+func ReadTree(ctx context.Context, dir p9.File, depth int) ([]TreeEntry, error) {
+	// NOTE: Opened files may not be walked,
+	// so entries are read from a clone.
+	_, clone, err := dir.Walk(nil)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		entries           []TreeEntry
+		direntCtx, cancel = context.WithCancel(ctx)
+	)
+	defer cancel()
+	for result := range getDirents(direntCtx, clone) {
+		if err := result.error; err != nil {
+			return nil, errors.Join(err, clone.Close())
+		}
+		entry, err := readTreeEntry(ctx, dir, result.value, depth)
+		if err != nil {
+			return nil, errors.Join(err, clone.Close())
+		}
+		entries = append(entries, entry)
+	}
+	if err := clone.Close(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+func readTreeEntry(ctx context.Context, parent p9.File, dirent p9.Dirent, depth int) (TreeEntry, error) {
+	file, err := walkEnt(parent, dirent)
+	if err != nil {
+		return TreeEntry{}, err
+	}
+	want := p9.AttrMask{Size: true}
+	_, valid, attr, err := file.GetAttr(want)
+	if err == nil && !valid.Contains(want) {
+		err = attrErr(valid, want)
+	}
+	if err != nil {
+		return TreeEntry{}, errors.Join(err, file.Close())
+	}
+	entry := TreeEntry{
+		Name: dirent.Name,
+		Type: dirent.Type,
+		Size: attr.Size,
+	}
+	if dirent.Type == p9.TypeDir && depth != 1 {
+		if entry.Children, err = ReadTree(ctx, file, depth-1); err != nil {
+			return TreeEntry{}, errors.Join(err, file.Close())
+		}
+	}
+	return entry, file.Close()
+}
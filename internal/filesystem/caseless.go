@@ -0,0 +1,294 @@
+package filesystem
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+)
+
+// CaseInsensitiveFS wraps a file system and retries
+// lookups which do not exist, by matching the requested
+// name against its parent directory's entries
+// without regard to case.
+//
+// A miss scans the name's parent directory;
+// ancestors are only scanned if the parent
+// does not exist with the requested spelling.
+// Components which match multiple entries are
+// considered ambiguous and are not resolved.
+// Operations which create files match
+// the parent directory of the new name.
+type CaseInsensitiveFS struct{ fsys fs.FS }
+
+// NewCaseInsensitiveFS wraps `fsys`
+// with case-insensitive lookups.
+func NewCaseInsensitiveFS(fsys fs.FS) *CaseInsensitiveFS {
+	return &CaseInsensitiveFS{fsys: fsys}
+}
+
+// Unwrap returns the wrapped file system.
+func (cfs *CaseInsensitiveFS) Unwrap() fs.FS { return cfs.fsys }
+
+// ID returns the ID of the wrapped file system
+// (if it has one).
+func (cfs *CaseInsensitiveFS) ID() ID {
+	if idFS, ok := cfs.fsys.(IDFS); ok {
+		return idFS.ID()
+	}
+	return ""
+}
+
+func (cfs *CaseInsensitiveFS) Open(name string) (fs.File, error) {
+	return foldCall(cfs.fold, name, cfs.fsys.Open)
+}
+
+func (cfs *CaseInsensitiveFS) Stat(name string) (fs.FileInfo, error) {
+	return foldCall(cfs.fold, name, func(name string) (fs.FileInfo, error) {
+		return fs.Stat(cfs.fsys, name)
+	})
+}
+
+func (cfs *CaseInsensitiveFS) OpenFile(name string, flag int, perm fs.FileMode) (fs.File, error) {
+	const op = "open"
+	opener, ok := Extension[OpenFileFS](cfs.fsys)
+	if !ok {
+		return nil, unsupportedOp(op, name)
+	}
+	fold := cfs.fold
+	if flag&os.O_CREATE != 0 {
+		fold = cfs.foldNew
+	}
+	return foldCall(fold, name, func(name string) (fs.File, error) {
+		return opener.OpenFile(name, flag, perm)
+	})
+}
+
+func (cfs *CaseInsensitiveFS) CreateFile(name string) (fs.File, error) {
+	const op = "create"
+	creator, ok := Extension[CreateFileFS](cfs.fsys)
+	if !ok {
+		return nil, unsupportedOp(op, name)
+	}
+	return foldCall(cfs.foldNew, name, creator.CreateFile)
+}
+
+func (cfs *CaseInsensitiveFS) Remove(name string) error {
+	const op = "remove"
+	remover, ok := Extension[RemoveFS](cfs.fsys)
+	if !ok {
+		return unsupportedOp(op, name)
+	}
+	return foldDo(cfs.fold, name, remover.Remove)
+}
+
+func (cfs *CaseInsensitiveFS) Readlink(name string) (string, error) {
+	const op = "readlink"
+	linker, ok := Extension[ReadlinkFS](cfs.fsys)
+	if !ok {
+		return "", unsupportedOp(op, name)
+	}
+	return foldCall(cfs.fold, name, linker.Readlink)
+}
+
+// Symlink creates `newname`, and does not
+// modify the link's target (`oldname`).
+func (cfs *CaseInsensitiveFS) Symlink(oldname, newname string) error {
+	const op = "symlink"
+	linker, ok := Extension[SymlinkFS](cfs.fsys)
+	if !ok {
+		return unsupportedOp(op, newname)
+	}
+	return foldDo(cfs.foldNew, newname, func(newname string) error {
+		return linker.Symlink(oldname, newname)
+	})
+}
+
+func (cfs *CaseInsensitiveFS) Rename(oldName, newName string) error {
+	const op = "rename"
+	renamer, ok := Extension[RenameFS](cfs.fsys)
+	if !ok {
+		return unsupportedOp(op, oldName)
+	}
+	err := renamer.Rename(oldName, newName)
+	if err == nil || !isNotExist(err) {
+		return err
+	}
+	foldedOld, oldOk := cfs.fold(oldName)
+	if !oldOk {
+		foldedOld = oldName
+	}
+	foldedNew, newOk := cfs.foldNew(newName)
+	if !newOk {
+		foldedNew = newName
+	}
+	if !oldOk && !newOk {
+		return err
+	}
+	return renamer.Rename(foldedOld, foldedNew)
+}
+
+func (cfs *CaseInsensitiveFS) Truncate(name string, size int64) error {
+	const op = "truncate"
+	truncater, ok := Extension[TruncateFileFS](cfs.fsys)
+	if !ok {
+		return unsupportedOp(op, name)
+	}
+	return foldDo(cfs.fold, name, func(name string) error {
+		return truncater.Truncate(name, size)
+	})
+}
+
+func (cfs *CaseInsensitiveFS) Mkdir(name string, perm fs.FileMode) error {
+	const op = "mkdir"
+	maker, ok := Extension[MkdirFS](cfs.fsys)
+	if !ok {
+		return unsupportedOp(op, name)
+	}
+	return foldDo(cfs.foldNew, name, func(name string) error {
+		return maker.Mkdir(name, perm)
+	})
+}
+
+func (cfs *CaseInsensitiveFS) Chown(name string, uid, gid int) error {
+	const op = "chown"
+	chowner, ok := Extension[ChownFS](cfs.fsys)
+	if !ok {
+		return unsupportedOp(op, name)
+	}
+	return foldDo(cfs.fold, name, func(name string) error {
+		return chowner.Chown(name, uid, gid)
+	})
+}
+
+// Close closes the wrapped file system
+// (if it implements [io.Closer]).
+func (cfs *CaseInsensitiveFS) Close() error {
+	if closer, ok := cfs.fsys.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// fold returns the name of the existing file
+// which matches `name` (see: [MatchFold]).
+func (cfs *CaseInsensitiveFS) fold(name string) (string, bool) {
+	return MatchFold(cfs.fsys, name)
+}
+
+// foldNew is like fold, but if `name` does not
+// match an existing file, its parent directory
+// is matched instead.
+func (cfs *CaseInsensitiveFS) foldNew(name string) (string, bool) {
+	var (
+		fsys       = cfs.fsys
+		parent, ok = matchParent(fsys, name)
+		base       = path.Base(name)
+	)
+	if !ok {
+		return "", false
+	}
+	if actual, ok := matchEntry(fsys, parent, base); ok {
+		return path.Join(parent, actual), true
+	}
+	return path.Join(parent, base), true
+}
+
+// foldCall calls `fn` with `name`, and calls it again
+// with the folded name if `name` does not exist.
+func foldCall[T any](fold func(string) (string, bool), name string, fn func(string) (T, error)) (T, error) {
+	value, err := fn(name)
+	if err == nil || !isNotExist(err) {
+		return value, err
+	}
+	actual, ok := fold(name)
+	if !ok || actual == name {
+		return value, err
+	}
+	return fn(actual)
+}
+
+// foldDo is like [foldCall]
+// for functions which only return an error.
+func foldDo(fold func(string) (string, bool), name string, fn func(string) error) error {
+	_, err := foldCall(fold, name, func(name string) (struct{}, error) {
+		return struct{}{}, fn(name)
+	})
+	return err
+}
+
+// MatchFold returns the name of the file which
+// matches `name`, ignoring case.
+// The last component is matched against the entries
+// of its parent directory. If the parent does not
+// exist as spelled, it is matched the same way first.
+// Exact matches take precedence over folded ones.
+// (See: [CaseInsensitiveFS].)
+func MatchFold(fsys fs.FS, name string) (string, bool) {
+	if name == Root {
+		return "", false
+	}
+	parent, ok := matchParent(fsys, name)
+	if !ok {
+		return "", false
+	}
+	actual, ok := matchEntry(fsys, parent, path.Base(name))
+	if !ok {
+		return "", false
+	}
+	return path.Join(parent, actual), true
+}
+
+// matchParent returns the parent directory of `name`
+// as spelled if it exists, otherwise its folded match.
+func matchParent(fsys fs.FS, name string) (string, bool) {
+	parent := path.Dir(name)
+	if parent == Root {
+		return Root, true
+	}
+	if info, err := fs.Stat(fsys, parent); err == nil && info.IsDir() {
+		return parent, true
+	}
+	return MatchFold(fsys, parent)
+}
+
+// matchEntry returns the name of the entry in `directory`
+// which is `base` exactly, or else the single entry
+// which matches `base` without regard to case.
+func matchEntry(fsys fs.FS, directory, base string) (string, bool) {
+	entries, err := fs.ReadDir(fsys, directory)
+	if err != nil {
+		return "", false
+	}
+	var (
+		actual string
+		found  bool
+	)
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entryName == base {
+			return entryName, true
+		}
+		if !strings.EqualFold(entryName, base) {
+			continue
+		}
+		if found {
+			actual = "" // Ambiguous, unless an exact match follows.
+			continue
+		}
+		actual, found = entryName, true
+	}
+	return actual, actual != ""
+}
+
+func isNotExist(err error) bool {
+	var fsErr *fserrors.Error
+	if errors.As(err, &fsErr) {
+		return fsErr.Kind == fserrors.NotExist
+	}
+	return errors.Is(err, fs.ErrNotExist)
+}
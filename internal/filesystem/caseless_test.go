@@ -0,0 +1,203 @@
+package filesystem_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+)
+
+// linkMapFS supports reading links (stored as file data),
+// and removing files; but not creating links.
+type linkMapFS struct{ fstest.MapFS }
+
+func (lfs linkMapFS) Readlink(name string) (string, error) {
+	file, ok := lfs.MapFS[name]
+	if !ok {
+		return "", fs.ErrNotExist
+	}
+	if file.Mode&fs.ModeSymlink == 0 {
+		return "", fs.ErrInvalid
+	}
+	return string(file.Data), nil
+}
+
+func (lfs linkMapFS) Remove(name string) error {
+	if _, ok := lfs.MapFS[name]; !ok {
+		return fs.ErrNotExist
+	}
+	delete(lfs.MapFS, name)
+	return nil
+}
+
+// scanCountFS counts directory scans.
+type scanCountFS struct {
+	fstest.MapFS
+	scans int
+}
+
+func (sfs *scanCountFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	sfs.scans++
+	return sfs.MapFS.ReadDir(name)
+}
+
+func TestCaseInsensitiveFSScans(t *testing.T) {
+	t.Parallel()
+	const name = "A/B/C/File"
+	for _, test := range []struct {
+		name, path string
+		scans      int
+	}{
+		{name: "base", path: "A/B/C/file", scans: 1},
+		{name: "parent", path: "A/B/c/file", scans: 2},
+		{name: "all", path: "a/b/c/file", scans: 4},
+	} {
+		var (
+			path  = test.path
+			scans = test.scans
+		)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			memfs := &scanCountFS{MapFS: fstest.MapFS{
+				name: new(fstest.MapFile),
+			}}
+			got, ok := filesystem.MatchFold(memfs, path)
+			if !ok || got != name {
+				t.Fatalf("match mismatch for \"%s\""+
+					"\ngot: %q (%t)"+
+					"\nwant: %q",
+					path, got, ok, name,
+				)
+			}
+			if memfs.scans != scans {
+				t.Errorf("scan count mismatch for \"%s\""+
+					"\ngot: %d"+
+					"\nwant: %d",
+					path, memfs.scans, scans,
+				)
+			}
+		})
+	}
+}
+
+func TestCaseInsensitiveFSExtensions(t *testing.T) {
+	t.Parallel()
+	const target = "Directory/File"
+	var (
+		memfs = linkMapFS{MapFS: fstest.MapFS{
+			"Directory/File": new(fstest.MapFile),
+			"Directory/Link": {
+				Data: []byte(target),
+				Mode: fs.ModeSymlink,
+			},
+		}}
+		fsys = filesystem.NewCaseInsensitiveFS(memfs)
+	)
+	if _, ok := filesystem.Extension[filesystem.SymlinkFS](fsys); ok {
+		t.Error("wrapper reported extension which the wrapped system lacks")
+	}
+	linker, ok := filesystem.Extension[filesystem.ReadlinkFS](fsys)
+	if !ok {
+		t.Fatal("wrapper did not forward extension")
+	}
+	got, err := linker.Readlink("directory/link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != target {
+		t.Errorf("link target mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, target,
+		)
+	}
+	if err := fsys.Remove("DIRECTORY/FILE"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := memfs.MapFS[target]; ok {
+		t.Errorf(`"%s" was not removed`, target)
+	}
+	if err := fsys.Mkdir("directory/new", 0o755); !errors.Is(err, fserrors.ErrUnsupported) {
+		t.Errorf("error mismatch for unsupported operation"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, fserrors.ErrUnsupported,
+		)
+	}
+}
+
+func TestCaseInsensitiveFS(t *testing.T) {
+	t.Parallel()
+	const payload = "arbitrary data"
+	var (
+		memfs = fstest.MapFS{
+			"File.txt":             {Data: []byte(payload)},
+			"Directory/Nested.TXT": {Data: []byte(payload)},
+			"ambiguous":            {Data: []byte("lower")},
+			"AMBIGUOUS":            {Data: []byte("upper")},
+		}
+		fsys = filesystem.NewCaseInsensitiveFS(memfs)
+	)
+	for _, test := range []struct {
+		name, path, want string
+	}{
+		{name: "exact", path: "File.txt", want: payload},
+		{name: "lower", path: "file.txt", want: payload},
+		{name: "upper", path: "FILE.TXT", want: payload},
+		{name: "nested", path: "Directory/nested.txt", want: payload},
+		{name: "parent case", path: "directory/Nested.TXT", want: payload},
+		{name: "ancestors case", path: "DIRECTORY/NESTED.txt", want: payload},
+		{name: "exact ambiguous", path: "ambiguous", want: "lower"},
+	} {
+		var (
+			path = test.path
+			want = test.want
+		)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("data mismatch for \"%s\""+
+					"\ngot: %q"+
+					"\nwant: %q",
+					path, got, want,
+				)
+			}
+			if _, err := fsys.Stat(path); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	for _, test := range []struct {
+		name, path string
+	}{
+		{name: "missing", path: "missing.txt"},
+		{name: "ambiguous", path: "Ambiguous"},
+		{name: "ambiguous parent", path: "Ambiguous/file"},
+	} {
+		path := test.path
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := fsys.Open(path); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("error mismatch for \"%s\""+
+					"\ngot: %v"+
+					"\nwant: %v",
+					path, err, fs.ErrNotExist,
+				)
+			}
+			if _, err := fsys.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("stat error mismatch for \"%s\""+
+					"\ngot: %v"+
+					"\nwant: %v",
+					path, err, fs.ErrNotExist,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package cgofuse
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// writableMapFS claims write support, so that
+// access checks fall through to the mode bits.
+type writableMapFS struct{ fstest.MapFS }
+
+func (wfs writableMapFS) OpenFile(name string, _ int, _ fs.FileMode) (fs.File, error) {
+	return wfs.MapFS.Open(name)
+}
+
+func TestAccess(t *testing.T) {
+	t.Parallel()
+	const (
+		file       = "/file"
+		executable = "/executable"
+		missing    = "/missing"
+		userID     = 1000
+		groupID    = 1001
+		otherID    = 1002
+	)
+	newMapFS := func() fstest.MapFS {
+		return fstest.MapFS{
+			file[1:]:       &fstest.MapFile{Mode: 0o640},
+			executable[1:]: &fstest.MapFile{Mode: 0o750},
+		}
+	}
+	var (
+		owner = &fuseContext{uid: userID, gid: groupID}
+		user  = *owner
+		group = fuseContext{uid: otherID, gid: groupID}
+		other = fuseContext{uid: otherID, gid: otherID}
+		root  = fuseContext{}
+	)
+	type accessTest struct {
+		name   string
+		path   string
+		caller fuseContext
+		mask   uint32
+		want   errNo
+	}
+	run := func(t *testing.T, fsys *goWrapper, tests []accessTest) {
+		t.Helper()
+		for _, test := range tests {
+			got := fsys.access(test.path, test.mask, test.caller)
+			if got != test.want {
+				t.Errorf("%s: Access(%s, %#o) mismatch"+
+					"\ngot: %d"+
+					"\nwant: %d",
+					test.name, test.path, test.mask, got, test.want,
+				)
+			}
+		}
+	}
+	t.Run("read-only", func(t *testing.T) {
+		t.Parallel()
+		fsys := &goWrapper{
+			FS:    newMapFS(),
+			owner: owner,
+		}
+		run(t, fsys, []accessTest{
+			{"exists", file, other, fuse.F_OK, operationSuccess},
+			{"missing", missing, user, fuse.F_OK, -fuse.ENOENT},
+			{"user read", file, user, fuse.R_OK, operationSuccess},
+			{"user write", file, user, fuse.W_OK, -fuse.EACCES},
+			{"user read write", file, user, fuse.R_OK | fuse.W_OK, -fuse.EACCES},
+			{"root write", file, root, fuse.W_OK, -fuse.EACCES},
+			{"group read", file, group, fuse.R_OK, operationSuccess},
+			{"other read", file, other, fuse.R_OK, -fuse.EACCES},
+			{"user execute", file, user, fuse.X_OK, -fuse.EACCES},
+			{"user execute binary", executable, user, fuse.X_OK, operationSuccess},
+			{"group execute binary", executable, group, fuse.X_OK, operationSuccess},
+			{"other execute binary", executable, other, fuse.X_OK, -fuse.EACCES},
+			{"root read", file, root, fuse.R_OK, operationSuccess},
+			{"root execute", file, root, fuse.X_OK, -fuse.EACCES},
+			{"root execute binary", executable, root, fuse.X_OK, operationSuccess},
+		})
+	})
+	t.Run("writable", func(t *testing.T) {
+		t.Parallel()
+		fsys := &goWrapper{
+			FS:    writableMapFS{MapFS: newMapFS()},
+			owner: owner,
+		}
+		run(t, fsys, []accessTest{
+			{"user write", file, user, fuse.W_OK, operationSuccess},
+			{"group write", file, group, fuse.W_OK, -fuse.EACCES},
+			{"other write", file, other, fuse.W_OK, -fuse.EACCES},
+			{"root write", file, root, fuse.W_OK, operationSuccess},
+		})
+	})
+	t.Run("caller owned", func(t *testing.T) {
+		t.Parallel()
+		// Without an ID mapping, files are
+		// reported as owned by the caller.
+		fsys := &goWrapper{FS: newMapFS()}
+		run(t, fsys, []accessTest{
+			{"other read", file, other, fuse.R_OK, operationSuccess},
+			{"other execute", executable, other, fuse.X_OK, operationSuccess},
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package cgofuse
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttrCacheTimeout(t *testing.T) {
+	t.Parallel()
+	t.Run("arguments", testAttrCacheArgs)
+	t.Run("fields", testAttrCacheFields)
+}
+
+func testAttrCacheArgs(t *testing.T) {
+	t.Parallel()
+	const fsID = "test"
+	var (
+		disabled  time.Duration
+		low       = 250 * time.Millisecond
+		optionsOf = func(timeout *time.Duration) string {
+			t.Helper()
+			host := &Host{AttrCacheTimeout: timeout}
+			_, args := makeFuseArgs(fsID, host)
+			if len(args) < 2 {
+				t.Fatalf("unexpected arguments: %v", args)
+			}
+			return args[1]
+		}
+	)
+	if cacheOptionPre(nil) != "" {
+		t.Error("nil timeout produced cache options")
+	}
+	for _, timeout := range []*time.Duration{&disabled, &low} {
+		var (
+			options = optionsOf(timeout)
+			want    = cacheOptionPre(timeout)
+		)
+		if want == "" || !strings.Contains(options, want) {
+			t.Errorf("timeout %v not in options"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				*timeout, options, want,
+			)
+		}
+	}
+}
+
+func testAttrCacheFields(t *testing.T) {
+	t.Parallel()
+	var host Host
+	if err := host.ParseField("attrcachetimeout", "0s"); err != nil {
+		t.Fatal(err)
+	}
+	if host.AttrCacheTimeout == nil ||
+		*host.AttrCacheTimeout != 0 {
+		t.Fatalf("timeout was not set: %v", host.AttrCacheTimeout)
+	}
+	// An explicit 0 must survive encoding,
+	// as it differs from the default (nil).
+	encoded, err := json.Marshal(&host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Host
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.AttrCacheTimeout == nil ||
+		*decoded.AttrCacheTimeout != 0 {
+		t.Errorf("timeout was not preserved: %s", encoded)
+	}
+	if err := host.ParseField("attrcachetimeout", "soon"); err == nil {
+		t.Error("expected error for invalid duration, got nil")
+	}
+}
@@ -0,0 +1,77 @@
+package cgofuse
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/u-root/uio/ulog"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+func TestCaseFold(t *testing.T) {
+	t.Parallel()
+	newWrapper := func(caseFold bool) *goWrapper {
+		return &goWrapper{
+			FS: fstest.MapFS{
+				"about":     &fstest.MapFile{Data: []byte("about")},
+				"dir/File":  &fstest.MapFile{Data: []byte("file")},
+				"same":      new(fstest.MapFile),
+				"SAME":      new(fstest.MapFile),
+				"different": new(fstest.MapFile),
+			},
+			log:       ulog.Null,
+			fileTable: newFileTable(),
+			caseFold:  caseFold,
+		}
+	}
+	t.Run("folded", func(t *testing.T) {
+		t.Parallel()
+		fsys := newWrapper(true)
+		for _, test := range []struct {
+			path, want string
+		}{
+			{"/ABOUT", "about"},
+			{"/dir/file", "File"},
+			{"/DIR/file", "File"},
+			{"/same", "same"},
+		} {
+			info, err := fsys.infoFromPath(test.path)
+			if err != nil {
+				t.Errorf(`stat "%s": %v`, test.path, err)
+				continue
+			}
+			if got := info.Name(); got != test.want {
+				t.Errorf(`"%s" name mismatch`+
+					"\ngot: %s"+
+					"\nwant: %s",
+					test.path, got, test.want,
+				)
+			}
+		}
+		errNo, fh := fsys.Open("/About", fuse.O_RDONLY)
+		if errNo != operationSuccess {
+			t.Fatalf("open failed: %s", fuse.Error(errNo))
+		}
+		if errNo := fsys.Release("/About", fh); errNo != operationSuccess {
+			t.Errorf("release failed: %s", fuse.Error(errNo))
+		}
+		// Ambiguous names are not resolved.
+		if _, err := fsys.infoFromPath("/Same"); interpretError(err) != -fuse.ENOENT {
+			t.Errorf("expected not-exist for ambiguous name, got: %v", err)
+		}
+	})
+	t.Run("exact", func(t *testing.T) {
+		t.Parallel()
+		fsys := newWrapper(false)
+		if _, err := fsys.infoFromPath("/ABOUT"); interpretError(err) != -fuse.ENOENT {
+			t.Errorf("expected not-exist without folding, got: %v", err)
+		}
+		if errNo, _ := fsys.Open("/ABOUT", fuse.O_RDONLY); errNo != -fuse.ENOENT {
+			t.Errorf("open without folding"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				fuse.Error(errNo), fuse.Error(-fuse.ENOENT),
+			)
+		}
+	})
+}
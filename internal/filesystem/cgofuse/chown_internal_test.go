@@ -0,0 +1,90 @@
+package cgofuse
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/u-root/uio/ulog"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+type (
+	owner      struct{ uid, gid int }
+	ownerMapFS struct {
+		fstest.MapFS
+		owners map[string]owner
+	}
+)
+
+func (ofs *ownerMapFS) Chown(name string, uid, gid int) error {
+	if _, err := fs.Stat(ofs.MapFS, name); err != nil {
+		return err
+	}
+	ofs.owners[name] = owner{uid: uid, gid: gid}
+	return nil
+}
+
+func TestChown(t *testing.T) {
+	t.Parallel()
+	const (
+		file    = "/file"
+		missing = "/missing"
+		uid     = 1000
+		gid     = 1001
+	)
+	newMapFS := func() fstest.MapFS {
+		return fstest.MapFS{file[1:]: new(fstest.MapFile)}
+	}
+	t.Run("stored", func(t *testing.T) {
+		t.Parallel()
+		var (
+			ownerFS = &ownerMapFS{
+				MapFS:  newMapFS(),
+				owners: make(map[string]owner),
+			}
+			fsys = &goWrapper{FS: ownerFS, log: ulog.Null}
+		)
+		if errNo := fsys.Chown(file, uid, gid); errNo != operationSuccess {
+			t.Fatalf("chown failed: %s", fuse.Error(errNo))
+		}
+		const unchanged = ^uint32(0)
+		if errNo := fsys.Chown(file, unchanged, gid+1); errNo != operationSuccess {
+			t.Fatalf("chown failed: %s", fuse.Error(errNo))
+		}
+		want := owner{uid: -1, gid: gid + 1}
+		if got := ownerFS.owners[file[1:]]; got != want {
+			t.Errorf("owner mismatch"+
+				"\ngot: %#v"+
+				"\nwant: %#v",
+				got, want,
+			)
+		}
+	})
+	t.Run("strict", func(t *testing.T) {
+		t.Parallel()
+		fsys := &goWrapper{FS: newMapFS(), log: ulog.Null}
+		testChownErrNo(t, fsys, file, -fuse.EROFS)
+	})
+	t.Run("ignored", func(t *testing.T) {
+		t.Parallel()
+		fsys := &goWrapper{
+			FS:          newMapFS(),
+			ignoreChown: true,
+			log:         ulog.Null,
+		}
+		testChownErrNo(t, fsys, file, operationSuccess)
+		testChownErrNo(t, fsys, missing, -fuse.ENOENT)
+	})
+}
+
+func testChownErrNo(t *testing.T, fsys *goWrapper, path string, want errNo) {
+	t.Helper()
+	if got := fsys.Chown(path, 0, 0); got != want {
+		t.Errorf(`chown "%s" error mismatch`+
+			"\ngot: %s"+
+			"\nwant: %s",
+			path, fuse.Error(got), fuse.Error(want),
+		)
+	}
+}
@@ -2,6 +2,8 @@ package cgofuse
 
 import (
 	"context"
+	"errors"
+	"io"
 	"io/fs"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
@@ -23,21 +25,43 @@ type (
 		// and thus miss an error value. So it may not be better.
 		// Needs consideration.
 		entries <-chan filesystem.StreamDirEntry
+		// names is set instead of entries,
+		// when only names are needed by the host,
+		// and the directory can provide them.
+		names filesystem.ReadDirNamesFile
+		// pendingNames (or pendingEntry) were read from
+		// the directory, but did not fit within the last
+		// fill buffer. They precede the next read.
+		pendingNames []string
+		pendingEntry filesystem.StreamDirEntry
+		// pendingErr was returned alongside `pendingNames`.
+		pendingErr error
 		context.Context
 		context.CancelFunc
 		fuseContext
+		// dots are the directory's self and parent
+		// entries, which precede the guest's entries.
+		dots     []filesystem.StreamDirEntry
 		position int64
+		// placeholder is passed to [placeholderSize]
+		// for entries within readdir-plus responses.
+		placeholder int64
+		// path is the path the directory was opened with,
+		// which differs from the requested path if its
+		// case was folded.
+		path string
 	}
 )
 
 const (
+	dirBatchSize          = 16 // Arbitrary buffer size.
 	errNotReadDirFile     = generic.ConstError("file does not implement ReadDirFile")
 	errDirStreamNotOpened = generic.ConstError("directory stream not opened")
 )
 
 func (gw *goWrapper) Mkdir(path string, mode uint32) errNo {
 	defer gw.systemLock.CreateOrDelete(path)()
-	if maker, ok := gw.FS.(filesystem.MkdirFS); ok {
+	if maker, ok := filesystem.Extension[filesystem.MkdirFS](gw.FS); ok {
 		goPath, err := fuseToGo(path)
 		if err != nil {
 			gw.logError(path, err)
@@ -56,6 +80,18 @@ func (gw *goWrapper) Opendir(path string) (errNo, fileDescriptor) {
 	defer gw.systemLock.Access(path)()
 	directory, err := openDir(gw.FS, path)
 	if err != nil {
+		if folded, ok := gw.foldPath(path, err); ok {
+			path = folded
+			directory, err = openDir(gw.FS, path)
+		}
+		if err != nil {
+			gw.logError(path, err)
+			return interpretError(err), errorHandle
+		}
+	}
+	dots, err := dotEntries(gw.FS, path, directory)
+	if err != nil {
+		err = errors.Join(err, directory.Close())
 		gw.logError(path, err)
 		return interpretError(err), errorHandle
 	}
@@ -73,8 +109,11 @@ func (gw *goWrapper) Opendir(path string) (errNo, fileDescriptor) {
 		dirStream   = newStreamDir(directory, fuseContext{
 			uid: uid,
 			gid: gid,
-		})
+		}, !gw.readdirPlus)
 	)
+	dirStream.dots = dots
+	dirStream.placeholder = gw.placeholder
+	dirStream.path = path
 	handle, err := gw.fileTable.add(dirStream)
 	if err != nil {
 		gw.logError(path, err)
@@ -85,6 +124,20 @@ func (gw *goWrapper) Opendir(path string) (errNo, fileDescriptor) {
 	return operationSuccess, handle
 }
 
+// foldPath is like [goWrapper.foldName]
+// but operates on FUSE paths.
+func (gw *goWrapper) foldPath(path string, err error) (string, bool) {
+	goPath, pErr := fuseToGo(path)
+	if pErr != nil {
+		return "", false
+	}
+	folded, ok := gw.foldName(goPath, err)
+	if !ok {
+		return "", false
+	}
+	return posixRoot + folded, true
+}
+
 func openDir(fsys fs.FS, path string) (fs.ReadDirFile, error) {
 	goPath, err := fuseToGo(path)
 	if err != nil {
@@ -108,6 +161,20 @@ func openDir(fsys fs.FS, path string) (fs.ReadDirFile, error) {
 	return directory, nil
 }
 
+// dotEntries returns the self and parent entries
+// of the directory; guests do not provide them.
+func dotEntries(fsys fs.FS, path string, directory fs.ReadDirFile) ([]filesystem.StreamDirEntry, error) {
+	goPath, err := fuseToGo(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := directory.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return filesystem.DotEntries(fsys, goPath, info), nil
+}
+
 func (gw *goWrapper) Readdir(path string, fill fillFunc, ofst int64, fh fileDescriptor) errNo {
 	defer gw.systemLock.Access(path)()
 	if fh == errorHandle {
@@ -130,6 +197,9 @@ func (gw *goWrapper) Readdir(path string, fill fillFunc, ofst int64, fh fileDesc
 		return errNo
 	}
 	if ofst == 0 && stream.position != 0 {
+		if stream.path != "" {
+			path = stream.path
+		}
 		if errorCode, err := rewinddir(gw.FS, stream, path); err != nil {
 			gw.logError(path, err)
 			return errorCode
@@ -142,16 +212,23 @@ func (gw *goWrapper) Readdir(path string, fill fillFunc, ofst int64, fh fileDesc
 	return ret
 }
 
-func newStreamDir(directory fs.ReadDirFile, fCtx fuseContext) *directoryStream {
+// newStreamDir reads entry names only, if `namesOnly`
+// is set and the directory implements [filesystem.ReadDirNamesFile].
+// Otherwise, entries (and their metadata) are read.
+func newStreamDir(directory fs.ReadDirFile, fCtx fuseContext, namesOnly bool) *directoryStream {
 	ctx, cancel := context.WithCancel(context.Background())
-	const count = 16 // Arbitrary buffer size.
-	return &directoryStream{
+	stream := &directoryStream{
 		Context:     ctx,
 		CancelFunc:  cancel,
 		ReadDirFile: directory,
-		entries:     filesystem.StreamDir(ctx, count, directory),
 		fuseContext: fCtx,
 	}
+	if namer, ok := filesystem.FileExtension[filesystem.ReadDirNamesFile](directory); ok && namesOnly {
+		stream.names = namer
+		return stream
+	}
+	stream.entries = filesystem.StreamDir(ctx, dirBatchSize, directory)
+	return stream
 }
 
 // NOTE: See SUSv4;BSi7 `rewinddir`.
@@ -168,11 +245,30 @@ func rewinddir(fsys fs.FS, stream *directoryStream, path string) (errNo, error)
 	if err != nil {
 		return interpretError(err), err
 	}
-	*stream = *newStreamDir(directory, stream.fuseContext)
+	var (
+		dots        = stream.dots
+		placeholder = stream.placeholder
+		openedPath  = stream.path
+	)
+	*stream = *newStreamDir(directory, stream.fuseContext, stream.names != nil)
+	stream.dots = dots
+	stream.placeholder = placeholder
+	stream.path = openedPath
 	return operationSuccess, nil
 }
 
 func fillDir(stream *directoryStream, fill fillFunc) (errNo, error) {
+	namesOnly := stream.names != nil
+	full, err := fillDots(stream, fill, namesOnly)
+	if err != nil {
+		return -fuse.EIO, err
+	}
+	if full {
+		return operationSuccess, nil
+	}
+	if namesOnly {
+		return fillDirNames(stream, fill)
+	}
 	var (
 		ctx     = stream.Context
 		entries = stream.entries
@@ -180,6 +276,29 @@ func fillDir(stream *directoryStream, fill fillFunc) (errNo, error) {
 		fCtx    = stream.fuseContext
 	)
 	defer func() { stream.position = offset }()
+	fillEntry := func(entry filesystem.StreamDirEntry) (bool, errNo, error) {
+		if err := entry.Error(); err != nil {
+			offset++
+			return false, -fuse.ENOENT, err
+		}
+		entStat, err := dirStat(entry, fCtx, stream.placeholder)
+		if err != nil {
+			offset++
+			return false, -fuse.EIO, err
+		}
+		if !fill(entry.Name(), entStat, offset+1) {
+			stream.pendingEntry = entry
+			return false, operationSuccess, nil
+		}
+		offset++
+		return true, operationSuccess, nil
+	}
+	if entry := stream.pendingEntry; entry != nil {
+		stream.pendingEntry = nil
+		if filled, errNo, err := fillEntry(entry); !filled {
+			return errNo, err
+		}
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -188,18 +307,116 @@ func fillDir(stream *directoryStream, fill fillFunc) (errNo, error) {
 			if !ok {
 				return operationSuccess, nil
 			}
-			offset++
-			if err := entry.Error(); err != nil {
-				return -fuse.ENOENT, err
+			if entry.Error() == nil &&
+				filesystem.IsDotName(entry.Name()) {
+				continue // Synthesized by [fillDots].
 			}
-			entStat, err := dirStat(entry, fCtx)
-			if err != nil {
-				return -fuse.EIO, err
+			if filled, errNo, err := fillEntry(entry); !filled {
+				return errNo, err
 			}
-			if !fill(entry.Name(), entStat, offset) {
-				return operationSuccess, nil
+		}
+	}
+}
+
+// entryInfo returns the entry itself, if it also
+// implements [fs.FileInfo]. Such entries already hold
+// their metadata, so it's cheap to include it within
+// `readdir`; unlike entries whose `Info` method may
+// need to look up the file (which can be slow).
+// Only entries from directories read via [filesystem.StreamDir]
+// are checked; names-only streams have no entries.
+// (See: [newStreamDir].)
+func entryInfo(ent fs.DirEntry) (fs.FileInfo, bool) {
+	info, ok := ent.(fs.FileInfo)
+	return info, ok
+}
+
+func newDirStat(info fs.FileInfo, fCtx fuseContext, placeholder int64) *fuse.Stat_t {
+	stat := new(fuse.Stat_t)
+	goToFuseStat(info, fCtx, stat)
+	placeholderSize(info, placeholder, stat)
+	return stat
+}
+
+// fillDots fills the directory's self and parent
+// entries, if they have not been filled yet,
+// and reports if the fill buffer became full.
+func fillDots(stream *directoryStream, fill fillFunc, namesOnly bool) (bool, error) {
+	dots := stream.dots
+	for stream.position < int64(len(dots)) {
+		var (
+			entry = dots[stream.position]
+			stat  *fuse.Stat_t
+		)
+		if !namesOnly {
+			var err error
+			if stat, err = dirStat(entry, stream.fuseContext, stream.placeholder); err != nil {
+				return false, err
 			}
 		}
+		if !fill(entry.Name(), stat, stream.position+1) {
+			return true, nil
+		}
+		stream.position++
+	}
+	return false, nil
+}
+
+// fillDirNames is like [fillDir] but only
+// provides entry names (with no metadata).
+func fillDirNames(stream *directoryStream, fill fillFunc) (errNo, error) {
+	var (
+		ctx       = stream.Context
+		directory = stream.names
+		offset    = stream.position
+	)
+	defer func() { stream.position = offset }()
+	// fillNames reports whether all of
+	// the names fit within the buffer.
+	// Names which did not are kept for
+	// the next call.
+	fillNames := func(names []string) bool {
+		for i, name := range names {
+			if filesystem.IsDotName(name) {
+				continue // Synthesized by [fillDots].
+			}
+			if !fill(name, nil, offset+1) {
+				stream.pendingNames = names[i:]
+				return false
+			}
+			offset++
+		}
+		return true
+	}
+	readErr := func(err error) (errNo, error) {
+		if errors.Is(err, io.EOF) {
+			return operationSuccess, nil
+		}
+		return -fuse.ENOENT, err
+	}
+	if pending := stream.pendingNames; pending != nil {
+		err := stream.pendingErr
+		stream.pendingNames, stream.pendingErr = nil, nil
+		if !fillNames(pending) {
+			stream.pendingErr = err
+			return operationSuccess, nil
+		}
+		if err != nil {
+			return readErr(err)
+		}
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return -fuse.EBADF, err
+		}
+		names, err := directory.ReadDirNames(dirBatchSize)
+		if !fillNames(names) {
+			stream.pendingErr = err
+			return operationSuccess, nil
+		}
+		if err != nil {
+			return readErr(err)
+		}
 	}
 }
 
@@ -218,7 +435,7 @@ func (gw *goWrapper) Releasedir(path string, fh fileDescriptor) errNo {
 
 func (gw *goWrapper) Rmdir(path string) errNo {
 	defer gw.systemLock.CreateOrDelete(path)()
-	if remover, ok := gw.FS.(filesystem.RemoveFS); ok {
+	if remover, ok := filesystem.Extension[filesystem.RemoveFS](gw.FS); ok {
 		goPath, err := fuseToGo(path)
 		if err != nil {
 			return interpretError(err)
@@ -0,0 +1,78 @@
+package cgofuse
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type (
+	// infoEntry holds its own metadata.
+	infoEntry struct{ fs.FileInfo }
+	// lookupEntry must look up its metadata.
+	lookupEntry struct {
+		fs.DirEntry
+		lookups *int
+	}
+)
+
+func (ie infoEntry) Type() fs.FileMode          { return ie.Mode().Type() }
+func (ie infoEntry) Info() (fs.FileInfo, error) { return ie, nil }
+
+func (le lookupEntry) Info() (fs.FileInfo, error) {
+	*le.lookups++
+	return le.DirEntry.Info()
+}
+
+func TestDirStat(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"dir":  &fstest.MapFile{Mode: fs.ModeDir | 0o755, ModTime: time.Unix(1, 0)},
+		"file": &fstest.MapFile{Data: []byte("data")},
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cheap := infoEntry{FileInfo: info}
+		got, ok := entryInfo(cheap)
+		if !ok {
+			t.Fatalf(`"%s" was not used as its own info`, entry.Name())
+		}
+		if got.Mode() != info.Mode() || got.Size() != info.Size() {
+			t.Errorf(`"%s" info mismatch`+
+				"\ngot: %v %d"+
+				"\nwant: %v %d",
+				entry.Name(), got.Mode(), got.Size(), info.Mode(), info.Size(),
+			)
+		}
+		stat, err := dirStat(cheap, fuseContext{}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stat == nil {
+			t.Fatalf(`"%s" had no stat`, entry.Name())
+		}
+		mode := info.Mode()
+		if want := goToFuseFileType(mode) | goToFusePermissions(mode); stat.Mode != want {
+			t.Errorf(`"%s" mode mismatch`+
+				"\ngot: %#o"+
+				"\nwant: %#o",
+				entry.Name(), stat.Mode, want,
+			)
+		}
+		var lookups int
+		if _, ok := entryInfo(lookupEntry{DirEntry: entry, lookups: &lookups}); ok {
+			t.Errorf(`"%s" was treated as its own info`, entry.Name())
+		}
+		if lookups != 0 {
+			t.Errorf(`"%s" was looked up %d times`, entry.Name(), lookups)
+		}
+	}
+}
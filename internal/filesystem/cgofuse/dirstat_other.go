@@ -11,6 +11,17 @@ import (
 // [2022.11.15] readdir-plus in cgofuse is only supported on Windows.
 // If support for a system is added in cgofuse,
 // metadata should be returned within `readdir` in this project as well.
-// This function is a no-op since FUSE will use `getattr` instead
-// to retrieve metadata on systems without the readdir-plus capability.
-func dirStat(fs.DirEntry, fuseContext) (*fuse.Stat_t, error) { return nil, nil }
+// Until then, FUSE will use `getattr` to retrieve metadata, so we only
+// provide it here when it's already available (see: [entryInfo]).
+// Which still lets FUSE report the entry's type without a `getattr`.
+// NOTE: Directories which implement [filesystem.ReadDirNamesFile]
+// (such as IPFS directories) are read by name only on these systems,
+// since fetching names alone is cheaper than fetching entries;
+// dirStat is not called for them.
+func dirStat(ent fs.DirEntry, fCtx fuseContext, placeholder int64) (*fuse.Stat_t, error) {
+	info, ok := entryInfo(ent)
+	if !ok {
+		return nil, nil
+	}
+	return newDirStat(info, fCtx, placeholder), nil
+}
@@ -6,12 +6,13 @@ import (
 	"github.com/winfsp/cgofuse/fuse"
 )
 
-func dirStat(ent fs.DirEntry, fCtx fuseContext) (*fuse.Stat_t, error) {
-	info, err := ent.Info()
-	if err != nil {
-		return nil, err
+func dirStat(ent fs.DirEntry, fCtx fuseContext, placeholder int64) (*fuse.Stat_t, error) {
+	info, ok := entryInfo(ent)
+	if !ok {
+		var err error
+		if info, err = ent.Info(); err != nil {
+			return nil, err
+		}
 	}
-	stat := new(fuse.Stat_t)
-	goToFuseStat(info, fCtx, stat)
-	return stat, nil
+	return newDirStat(info, fCtx, placeholder), nil
 }
@@ -0,0 +1,142 @@
+package cgofuse
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+type (
+	// dotsDirectory emits `.` and `..` entries
+	// with its first batch, which guests should not do.
+	dotsDirectory struct {
+		fs.ReadDirFile
+		dots []filesystem.StreamDirEntry
+	}
+	filledEntry struct {
+		name   string
+		offset int64
+	}
+)
+
+func (dd *dotsDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
+	entries, err := dd.ReadDirFile.ReadDir(count)
+	dots := dd.dots
+	if dots == nil {
+		return entries, err
+	}
+	dd.dots = nil
+	prefixed := make([]fs.DirEntry, 0, len(dots)+len(entries))
+	for _, dot := range dots {
+		prefixed = append(prefixed, dot)
+	}
+	return append(prefixed, entries...), err
+}
+
+func (dd *dotsDirectory) ReadDirNames(count int) ([]string, error) {
+	entries, err := dd.ReadDir(count)
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names, err
+}
+
+func TestDirectoryDots(t *testing.T) {
+	t.Parallel()
+	memfs := fstest.MapFS{
+		"directory/a": {},
+		"directory/b": {},
+	}
+	want := []filledEntry{
+		{name: filesystem.SelfName, offset: 1},
+		{name: filesystem.ParentName, offset: 2},
+		{name: "a", offset: 3},
+		{name: "b", offset: 4},
+	}
+	for _, test := range []struct {
+		name      string
+		namesOnly bool
+	}{
+		{name: "entries", namesOnly: false},
+		{name: "names", namesOnly: true},
+	} {
+		namesOnly := test.namesOnly
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			stream := openDotsStream(t, memfs, namesOnly)
+			defer stream.Close()
+			got := fillAll(t, stream, 0)
+			filledMatch(t, got, want)
+		})
+		t.Run(test.name+" resumed", func(t *testing.T) {
+			t.Parallel()
+			stream := openDotsStream(t, memfs, namesOnly)
+			defer stream.Close()
+			// Buffer fills after the first entry.
+			got := fillAll(t, stream, 1)
+			got = append(got, fillAll(t, stream, 0)...)
+			filledMatch(t, got, want)
+		})
+	}
+}
+
+func openDotsStream(t *testing.T, fsys fs.FS, namesOnly bool) *directoryStream {
+	t.Helper()
+	const path = "/directory"
+	directory, err := openDir(fsys, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dots, err := dotEntries(fsys, path, directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := newStreamDir(
+		&dotsDirectory{ReadDirFile: directory, dots: dots},
+		fuseContext{}, namesOnly,
+	)
+	stream.dots = dots
+	return stream
+}
+
+// fillAll fills the directory's entries;
+// up to `limit` entries if > 0.
+func fillAll(t *testing.T, stream *directoryStream, limit int) []filledEntry {
+	t.Helper()
+	var filled []filledEntry
+	fill := func(name string, _ *fuse.Stat_t, offset int64) bool {
+		if limit > 0 && len(filled) == limit {
+			return false
+		}
+		filled = append(filled, filledEntry{name: name, offset: offset})
+		return true
+	}
+	if errNo, err := fillDir(stream, fill); errNo != operationSuccess {
+		t.Fatalf("fill failed: %d %v", errNo, err)
+	}
+	return filled
+}
+
+func filledMatch(t *testing.T, got, want []filledEntry) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("entry count mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, want,
+		)
+	}
+	for i, entry := range got {
+		if entry != want[i] {
+			t.Errorf("entry mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				entry, want[i],
+			)
+		}
+	}
+}
@@ -57,7 +57,7 @@ func (gw *goWrapper) Mknod(path string, mode uint32, dev uint64) errNo {
 	if gw.exists(name) {
 		return -fuse.EEXIST
 	}
-	if creator, ok := gw.FS.(filesystem.CreateFileFS); ok {
+	if creator, ok := filesystem.Extension[filesystem.CreateFileFS](gw.FS); ok {
 		file, err := creator.CreateFile(name)
 		if err != nil {
 			gw.logError(path, err)
@@ -72,6 +72,11 @@ func (gw *goWrapper) Mknod(path string, mode uint32, dev uint64) errNo {
 	return -fuse.ENOSYS
 }
 
+// Truncate changes the size of a file via its handle
+// (if it's open) or its path. Handles must implement
+// [filesystem.TruncateFile]. Paths are truncated by
+// [filesystem.Truncate]. The request fails with EROFS
+// if the file system can not modify files.
 func (gw *goWrapper) Truncate(path string, size int64, fh fileDescriptor) errNo {
 	defer gw.systemLock.Modify(path)()
 	if size < 0 {
@@ -79,6 +84,9 @@ func (gw *goWrapper) Truncate(path string, size int64, fh fileDescriptor) errNo
 		gw.logError(path, fuse.Error(errNo))
 		return errNo
 	}
+	if !gw.writable(0) {
+		return -fuse.EROFS
+	}
 	// TODO: [metadata] "Unless FUSE_CAP_HANDLE_KILLPRIV is disabled,
 	// this method is expected to reset the setuid and setgid bits."
 	var (
@@ -100,7 +108,7 @@ func (gw *goWrapper) Truncate(path string, size int64, fh fileDescriptor) errNo
 }
 
 func truncateFile(file fs.File, size int64) (errNo, error) {
-	truncater, ok := file.(filesystem.TruncateFile)
+	truncater, ok := filesystem.FileExtension[filesystem.TruncateFile](file)
 	if !ok {
 		return -fuse.ENOSYS, fmt.Errorf("%T does not implement truncate", file)
 	}
@@ -125,6 +133,9 @@ func truncatePath(fsys fs.FS, path string, size int64) (errNo, error) {
 		if errors.As(err, &fsErr) {
 			return fsErrorsTable[fsErr.Kind], err
 		}
+		if errNo := interpretError(err); errNo != -fuse.EIO {
+			return errNo, err
+		}
 		return -fuse.ENOSYS, err
 	}
 	return operationSuccess, nil
@@ -150,6 +161,9 @@ func (gw *goWrapper) Open(path string, flags int) (errNo, fileDescriptor) {
 	const permissions = 0
 	fsFlags := goFlagsFromFuse(flags)
 	file, err := filesystem.OpenFile(gw.FS, name, fsFlags, permissions)
+	if folded, ok := gw.foldName(name, err); ok {
+		file, err = filesystem.OpenFile(gw.FS, folded, fsFlags, permissions)
+	}
 	if err != nil {
 		gw.logError(path, err)
 		return interpretError(err), errorHandle
@@ -188,7 +202,7 @@ func writeFile(file fs.File, buff []byte, ofst int64) (errNo, error) {
 		return -fuse.EINVAL, fmt.Errorf("invalid offset %d", ofst)
 	}
 
-	writer, ok := file.(io.Writer)
+	writer, ok := filesystem.FileExtension[io.Writer](file)
 	if !ok { // Access should have been be checked during [Open] with `EROFS` returned.
 		return -fuse.EIO, fmt.Errorf("%T does not support writing", file)
 	}
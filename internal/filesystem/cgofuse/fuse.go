@@ -1,9 +1,11 @@
 package cgofuse
 
 import (
+	"errors"
 	"io"
 	"io/fs"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
@@ -38,6 +40,11 @@ type (
 	}
 
 	fillFunc = func(name string, stat *fuse.Stat_t, ofst int64) bool
+
+	xattr struct {
+		name  string
+		value []byte
+	}
 )
 
 const (
@@ -61,14 +68,31 @@ const (
 	readUser    = fuse.S_IRUSR
 
 	HostID filesystem.Host = "FUSE"
+
+	// xattrCID is the extended attribute name
+	// for a file's content identifier.
+	// E.g. `getfattr -n user.cid $file`.
+	xattrCID = "user.cid"
 )
 
 type goWrapper struct {
 	fs.FS
 	log ulog.Logger
 	*fileTable
+	// owner holds the IDs that files are mapped to
+	// (via mount options), if any.
+	// Otherwise files are owned by the caller.
+	owner        *fuseContext
 	systemLock   lock.PathLocker
 	activeMounts uint64
+	// placeholder is the size reported for files
+	// which do not know their size (if > 0).
+	placeholder int64
+	readdirPlus bool
+	ignoreChown bool
+	// caseFold retries lookups of names which
+	// do not exist, without regard to case.
+	caseFold bool
 }
 
 func (gw *goWrapper) Init() {
@@ -97,40 +121,277 @@ func (gw *goWrapper) Destroy() {
 	}
 }
 
+func (gw *goWrapper) openHandles() int {
+	defer gw.systemLock.Access(posixRoot)()
+	if table := gw.fileTable; table != nil {
+		return table.count()
+	}
+	return 0
+}
+
 func (gw *goWrapper) Flush(path string, fh fileDescriptor) errNo {
 	defer gw.systemLock.Modify(path)()
 	return -fuse.ENOSYS
 }
 
+// Access checks the file's permission bits against the
+// mask, for the calling process. Write access is
+// denied if the file system can not modify the file.
 func (gw *goWrapper) Access(path string, mask uint32) errNo {
 	defer gw.systemLock.Access(path)()
-	return -fuse.ENOSYS
+	var (
+		uid, gid, _ = fuse.Getcontext()
+		caller      = fuseContext{uid: uid, gid: gid}
+	)
+	return gw.access(path, mask, caller)
+}
+
+func (gw *goWrapper) access(path string, mask uint32, caller fuseContext) errNo {
+	info, err := gw.infoFromPath(path)
+	if err != nil {
+		errNo := interpretError(err)
+		if errNo != -fuse.ENOENT {
+			gw.logError(path, err)
+		}
+		return errNo
+	}
+	if mask == fuse.F_OK {
+		return operationSuccess
+	}
+	mode := info.Mode()
+	if mask&fuse.W_OK != 0 && !gw.writable(mode) {
+		return -fuse.EACCES
+	}
+	if !permitted(mode, mask, caller, gw.ownerOf(caller)) {
+		return -fuse.EACCES
+	}
+	return operationSuccess
+}
+
+// ownerOf returns the IDs which files
+// are owned by, from the caller's perspective.
+func (gw *goWrapper) ownerOf(caller fuseContext) fuseContext {
+	owner := caller
+	if mapped := gw.owner; mapped != nil {
+		// NOTE: -1 is interpreted by some
+		// implementations as "the mounting user".
+		// Which we assume is the caller.
+		const unmapped = ^id(0)
+		if mapped.uid != unmapped {
+			owner.uid = mapped.uid
+		}
+		if mapped.gid != unmapped {
+			owner.gid = mapped.gid
+		}
+	}
+	return owner
+}
+
+// foldName returns the name of the file which matches
+// `name` without regard to case, if case folding is
+// enabled, and `err` (from a lookup of `name`) is not-exist.
+func (gw *goWrapper) foldName(name string, err error) (string, bool) {
+	if !gw.caseFold || interpretError(err) != -fuse.ENOENT {
+		return "", false
+	}
+	return filesystem.MatchFold(gw.FS, name)
+}
+
+// writable reports whether the file system
+// can modify files (or directories) of this mode.
+func (gw *goWrapper) writable(mode fs.FileMode) bool {
+	fsys := gw.FS
+	if mode.IsDir() {
+		return supports[filesystem.CreateFileFS](fsys) ||
+			supports[filesystem.MkdirFS](fsys) ||
+			supports[filesystem.RemoveFS](fsys) ||
+			supports[filesystem.RenameFS](fsys)
+	}
+	return supports[filesystem.OpenFileFS](fsys) ||
+		supports[filesystem.TruncateFileFS](fsys)
+}
+
+// supports reports whether the file
+// system implements the extension `T`.
+// (See: [filesystem.Extension].)
+func supports[T any](fsys fs.FS) bool {
+	_, ok := filesystem.Extension[T](fsys)
+	return ok
 }
 
+// permitted reports whether the permission bits of
+// the mode grant the access requested by the mask.
+// The class of bits checked is determined by the caller's
+// relation to the owner (supplementary groups are not considered).
+// The super user is granted read and write access regardless,
+// and execute access if any execute bit is set (or it's a directory).
+func permitted(mode fs.FileMode, mask uint32, caller, owner fuseContext) bool {
+	const (
+		accessBits  = fuse.R_OK | fuse.W_OK | fuse.X_OK
+		executeBits = executeUser | executeGroup | executeOther
+		superUser   = 0
+		userShift   = 6
+		groupShift  = 3
+	)
+	var (
+		permissions = goToFusePermissions(mode)
+		requested   = mask & accessBits
+	)
+	if caller.uid == superUser {
+		return requested&fuse.X_OK == 0 ||
+			mode.IsDir() ||
+			permissions&executeBits != 0
+	}
+	var shift uint32
+	switch {
+	case caller.uid == owner.uid:
+		shift = userShift
+	case caller.gid == owner.gid:
+		shift = groupShift
+	}
+	granted := (permissions >> shift) & accessBits
+	return requested&^granted == 0
+}
+
+// Chown applies ownership changes if the file system
+// implements [filesystem.ChownFS].
+// Otherwise, ownership can not be stored;
+// the request succeeds without effect if
+// `ignoreChown` is set, and fails with EROFS if not.
 func (gw *goWrapper) Chown(path string, uid, gid uint32) errNo {
 	defer gw.systemLock.Modify(path)()
-	return -fuse.ENOSYS
+	goPath, err := fuseToGo(path)
+	if err != nil {
+		gw.logError(path, err)
+		return interpretError(err)
+	}
+	if chowner, ok := filesystem.Extension[filesystem.ChownFS](gw.FS); ok {
+		if err := chowner.Chown(goPath, fuseToGoID(uid), fuseToGoID(gid)); err != nil {
+			gw.logError(path, err)
+			return interpretError(err)
+		}
+		return operationSuccess
+	}
+	if !gw.ignoreChown {
+		return -fuse.EROFS
+	}
+	// Requests for files which don't exist
+	// should still fail, even if ignored.
+	if _, err := fs.Stat(gw.FS, goPath); err != nil {
+		gw.logError(path, err)
+		return interpretError(err)
+	}
+	return operationSuccess
 }
 
+// Rename moves a file if the file system implements
+// [filesystem.RenameFS]. Otherwise, the request fails with
+// EROFS if the file system is read-only, and ENOSYS if not.
+// Like POSIX `rename`, an existing destination is replaced
+// if it's the same type of file as the source (and empty
+// if it's a directory). If the file system refuses to
+// replace it, and implements [filesystem.RemoveFS],
+// the destination is removed before trying again.
 func (gw *goWrapper) Rename(oldpath, newpath string) errNo {
+	// NOTE: These are checked before locking, since
+	// the path locker can not lock a path for writing
+	// while also holding it as a parent of the other path.
+	if oldpath == posixRoot || newpath == posixRoot {
+		return -fuse.EBUSY
+	}
+	if strings.HasPrefix(newpath, oldpath+"/") {
+		return -fuse.EINVAL // Would be its own descendant.
+	}
 	if path.Dir(oldpath) == path.Dir(newpath) {
 		defer gw.systemLock.Rename(oldpath, newpath)()
 	} else {
 		defer gw.systemLock.Move(oldpath, newpath)()
 	}
-	if renamer, ok := gw.FS.(filesystem.RenameFS); ok {
-		goOldPath, goNewPath, err := fuseToGoPair(oldpath, newpath)
-		if err != nil {
-			gw.logError(oldpath+"->"+newpath, err)
-			return interpretError(err)
+	renamer, ok := filesystem.Extension[filesystem.RenameFS](gw.FS)
+	if !ok {
+		if gw.writable(fs.ModeDir) {
+			return -fuse.ENOSYS
 		}
-		if err := renamer.Rename(goOldPath, goNewPath); err != nil {
-			gw.logError(oldpath+"->"+newpath, err)
-			return interpretError(err)
+		return -fuse.EROFS
+	}
+	goOldPath, goNewPath, err := fuseToGoPair(oldpath, newpath)
+	if err != nil {
+		gw.logError(oldpath+"->"+newpath, err)
+		return interpretError(err)
+	}
+	replacing, errNo := gw.checkRename(goOldPath, goNewPath)
+	if errNo != operationSuccess ||
+		goOldPath == goNewPath {
+		return errNo
+	}
+	err = renamer.Rename(goOldPath, goNewPath)
+	if err != nil && replacing &&
+		interpretError(err) == -fuse.EEXIST {
+		if remover, ok := filesystem.Extension[filesystem.RemoveFS](gw.FS); ok {
+			if err = remover.Remove(goNewPath); err == nil {
+				err = renamer.Rename(goOldPath, goNewPath)
+			}
 		}
-		return operationSuccess
 	}
-	return -fuse.ENOSYS
+	if err != nil {
+		gw.logError(oldpath+"->"+newpath, err)
+		return interpretError(err)
+	}
+	return operationSuccess
+}
+
+// checkRename applies POSIX's restrictions
+// on renaming `oldName` to `newName`, and reports
+// whether `newName` exists (and would be replaced).
+func (gw *goWrapper) checkRename(oldName, newName string) (bool, errNo) {
+	oldInfo, err := fs.Stat(gw.FS, oldName)
+	if err != nil {
+		gw.logError(oldName, err)
+		return false, interpretError(err)
+	}
+	oldIsDir := oldInfo.IsDir()
+	newInfo, err := fs.Stat(gw.FS, newName)
+	if err != nil {
+		if interpretError(err) == -fuse.ENOENT {
+			return false, operationSuccess
+		}
+		gw.logError(newName, err)
+		return false, interpretError(err)
+	}
+	switch newIsDir := newInfo.IsDir(); {
+	case oldName == newName:
+		return true, operationSuccess
+	case oldIsDir && !newIsDir:
+		return true, -fuse.ENOTDIR
+	case !oldIsDir && newIsDir:
+		return true, -fuse.EISDIR
+	case newIsDir:
+		return true, gw.checkEmpty(newName)
+	}
+	return true, operationSuccess
+}
+
+func (gw *goWrapper) checkEmpty(name string) errNo {
+	file, err := gw.FS.Open(name)
+	if err != nil {
+		gw.logError(name, err)
+		return interpretError(err)
+	}
+	defer file.Close()
+	directory, ok := file.(fs.ReadDirFile)
+	if !ok {
+		return -fuse.ENOTDIR
+	}
+	const count = 1
+	entries, err := directory.ReadDir(count)
+	if err != nil && !errors.Is(err, io.EOF) {
+		gw.logError(name, err)
+		return interpretError(err)
+	}
+	if len(entries) != 0 {
+		return -fuse.ENOTEMPTY
+	}
+	return operationSuccess
 }
 
 func (gw *goWrapper) Link(oldpath, newpath string) errNo {
@@ -144,7 +405,7 @@ func (gw *goWrapper) Link(oldpath, newpath string) errNo {
 
 func (gw *goWrapper) Unlink(path string) errNo {
 	defer gw.systemLock.CreateOrDelete(path)()
-	if remover, ok := gw.FS.(filesystem.RemoveFS); ok {
+	if remover, ok := filesystem.Extension[filesystem.RemoveFS](gw.FS); ok {
 		goPath, err := fuseToGo(path)
 		if err != nil {
 			gw.logError(path, err)
@@ -159,21 +420,34 @@ func (gw *goWrapper) Unlink(path string) errNo {
 	return -fuse.ENOSYS
 }
 
+// Symlink creates a link if the file system implements
+// [filesystem.SymlinkFS]. Otherwise, the request fails with
+// EROFS if the file system is read-only, and ENOSYS if not.
+// See [fuseToGoLink] for how the target is interpreted.
 func (gw *goWrapper) Symlink(target, newpath string) errNo {
 	defer gw.systemLock.CreateOrDelete(newpath)()
-	if linker, ok := gw.FS.(filesystem.SymlinkFS); ok {
-		goTarget, goNewPath, err := fuseToGoPair(target, newpath)
-		if err != nil {
-			gw.logError(newpath+"->"+target, err)
-			return interpretError(err)
-		}
-		if err := linker.Symlink(goTarget, goNewPath); err != nil {
-			gw.logError(newpath+"->"+target, err)
-			return interpretError(err)
+	linker, ok := filesystem.Extension[filesystem.SymlinkFS](gw.FS)
+	if !ok {
+		if gw.writable(fs.ModeDir) {
+			return -fuse.ENOSYS
 		}
-		return operationSuccess
+		return -fuse.EROFS
 	}
-	return -fuse.ENOSYS
+	goNewPath, err := fuseToGo(newpath)
+	if err != nil {
+		gw.logError(newpath+"->"+target, err)
+		return interpretError(err)
+	}
+	goTarget, err := fuseToGoLink(target, goNewPath)
+	if err != nil {
+		gw.logError(newpath+"->"+target, err)
+		return interpretError(err)
+	}
+	if err := linker.Symlink(goTarget, goNewPath); err != nil {
+		gw.logError(newpath+"->"+target, err)
+		return interpretError(err)
+	}
+	return operationSuccess
 }
 
 func (gw *goWrapper) Readlink(path string) (errNo, string) {
@@ -184,7 +458,7 @@ func (gw *goWrapper) Readlink(path string) (errNo, string) {
 	case "":
 		return -fuse.ENOENT, ""
 	default:
-		if extractor, ok := gw.FS.(filesystem.SymlinkFS); ok {
+		if extractor, ok := filesystem.Extension[filesystem.ReadlinkFS](gw.FS); ok {
 			goPath, err := fuseToGo(path)
 			if err != nil {
 				gw.logError(path, err)
@@ -195,7 +469,10 @@ func (gw *goWrapper) Readlink(path string) (errNo, string) {
 				gw.logError(path, err)
 				return interpretError(err), ""
 			}
-			fuseLink := posixRoot + fsLink
+			fuseLink := posixRoot
+			if fsLink != goRoot {
+				fuseLink += fsLink
+			}
 			return operationSuccess, fuseLink
 		}
 		return -fuse.ENOSYS, ""
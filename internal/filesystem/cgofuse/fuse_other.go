@@ -3,7 +3,9 @@
 package cgofuse
 
 import (
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 )
@@ -32,12 +34,20 @@ func makeFuseArgs(fsid filesystem.ID, host *Host) (string, []string) {
 		options        strings.Builder
 		uString, uSize = idOptionPre(host.UID)
 		gString, gSize = idOptionPre(host.GID)
+		cacheString    = cacheOptionPre(host.AttrCacheTimeout)
 		size           = uSize + delimiterSize + gSize
 	)
+	if cacheString != "" {
+		size += delimiterSize + len(cacheString)
+	}
 	options.Grow(size)
 	idOption(&options, uString, 'u')
 	options.WriteRune(optionDelimiter)
 	idOption(&options, gString, 'g')
+	if cacheString != "" {
+		options.WriteRune(optionDelimiter)
+		options.WriteString(cacheString)
+	}
 	var (
 		fuseArgs = []string{"-o", options.String()}
 		target   = host.Point
@@ -45,4 +55,17 @@ func makeFuseArgs(fsid filesystem.ID, host *Host) (string, []string) {
 	return target, fuseArgs
 }
 
+// cacheOptionPre returns the libfuse options which
+// set the kernel's attribute and entry cache timeouts
+// (in seconds), or an empty string if timeout is nil.
+func cacheOptionPre(timeout *time.Duration) string {
+	if timeout == nil {
+		return ""
+	}
+	seconds := strconv.FormatFloat(timeout.Seconds(), 'f', -1, 64)
+	return "attr_timeout=" + seconds +
+		string(optionDelimiter) +
+		"entry_timeout=" + seconds
+}
+
 func getOSTarget(target string, _ []string) string { return target }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 )
@@ -16,6 +17,7 @@ const (
 	systemNameOpt = "FileSystemName="
 	volNameOpt    = "volname="
 	volumeOpt     = "--VolumePrefix="
+	fileInfoOpt   = "FileInfoTimeout="
 )
 
 func makeFuseArgs(fsid filesystem.ID, host *Host) (string, []string) {
@@ -25,12 +27,16 @@ func makeFuseArgs(fsid filesystem.ID, host *Host) (string, []string) {
 		uString, uSize = idOptionPre(host.UID)
 		gString, gSize = idOptionPre(host.GID)
 		nameSize       = nameOptionSize(fsid)
+		cacheString    = cacheOptionPre(host.AttrCacheTimeout)
 		size           = uSize + delimiterSize +
 			gSize + nameSize
 	)
 	if nameSize != 0 {
 		size += delimiterSize
 	}
+	if cacheString != "" {
+		size += delimiterSize + len(cacheString)
+	}
 	options.Grow(size)
 	idOption(&options, uString, 'u')
 	options.WriteRune(optionDelimiter)
@@ -39,6 +45,10 @@ func makeFuseArgs(fsid filesystem.ID, host *Host) (string, []string) {
 		options.WriteRune(optionDelimiter)
 		nameOption(&options, fsid)
 	}
+	if cacheString != "" {
+		options.WriteRune(optionDelimiter)
+		options.WriteString(cacheString)
+	}
 	fuseArgs := []string{"-o", options.String()}
 	// The UNC argument for cgo-fuse/WinFSP uses a single slash prefix.
 	// And a target should not be supplied in addition to the UNC argument.
@@ -74,6 +84,17 @@ func nameOption(b *strings.Builder, id filesystem.ID) {
 	b.WriteString(name)
 }
 
+// cacheOptionPre returns the WinFSP option which
+// sets the metadata cache timeout (in milliseconds),
+// or an empty string if timeout is nil.
+func cacheOptionPre(timeout *time.Duration) string {
+	if timeout == nil {
+		return ""
+	}
+	return fileInfoOpt +
+		strconv.FormatInt(timeout.Milliseconds(), 10)
+}
+
 func uncOption(target string) string {
 	var option strings.Builder
 	option.Grow(len(volumeOpt) + len(target) - 1)
@@ -1,11 +1,11 @@
 package cgofuse
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
-	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -31,7 +31,36 @@ type (
 		ReaddirPlus     bool     `json:"readdirPlus,omitempty"`
 		DeleteAccess    bool     `json:"deleteAccess,omitempty"`
 		CaseInsensitive bool     `json:"caseInsensitive,omitempty"`
-		sysquirks                // Platform specific behavior.
+		IgnoreChown     bool     `json:"ignoreChown,omitempty"`
+		// CaseFold retries lookups of names which do
+		// not exist, by matching them against their
+		// parent directory's entries without regard to case.
+		// (E.g. for hosts whose file systems are
+		// case insensitive, hosting guests which are not.)
+		CaseFold bool `json:"caseFold,omitempty"`
+		// AttrCacheTimeout (if not nil) sets how long the
+		// host's kernel may cache file attributes and
+		// directory entries. Longer timeouts reduce the
+		// amount of calls made to the file system, but
+		// changes made to mutable file systems may not
+		// be reflected in (e.g.) `Stat` until the timeout
+		// expires. A timeout of 0 disables caching.
+		// If nil, the FUSE library's default is used.
+		AttrCacheTimeout *time.Duration `json:"attrCacheTimeout,omitempty"`
+		// PlaceholderSize (if > 0) is reported as the size of
+		// regular files which do not know their size
+		// (see [filesystem.SizeInfo]), rather than 0.
+		// This is a compatibility shim for programs which
+		// skip (seemingly) empty files. The reported size
+		// is misleading; reads will still end at the
+		// file's actual length, which may differ.
+		PlaceholderSize int64 `json:"placeholderSize,omitempty"`
+		sysquirks             // Platform specific behavior.
+	}
+	// mountedHost is returned from [Host.Mount].
+	mountedHost struct {
+		system  *goWrapper
+		unmount func() error
 	}
 )
 
@@ -64,6 +93,10 @@ func (mh *Host) ParseField(key, value string) error {
 		readdirPlusKey     = "readdirplus"
 		deleteAccessKey    = "deleteaccess"
 		caseInsensitiveKey = "caseinsensitive"
+		ignoreChownKey     = "ignorechown"
+		caseFoldKey        = "casefold"
+		attrCacheKey       = "attrcachetimeout"
+		placeholderKey     = "placeholdersize"
 	)
 	var err error
 	switch key {
@@ -83,6 +116,20 @@ func (mh *Host) ParseField(key, value string) error {
 		err = mh.parseBoolFlag(value, &mh.DeleteAccess)
 	case caseInsensitiveKey:
 		err = mh.parseBoolFlag(value, &mh.CaseInsensitive)
+	case ignoreChownKey:
+		err = mh.parseBoolFlag(value, &mh.IgnoreChown)
+	case caseFoldKey:
+		err = mh.parseBoolFlag(value, &mh.CaseFold)
+	case attrCacheKey:
+		var timeout time.Duration
+		if timeout, err = time.ParseDuration(value); err == nil {
+			mh.AttrCacheTimeout = &timeout
+		}
+	case placeholderKey:
+		var size int64
+		if size, err = strconv.ParseInt(value, 0, 64); err == nil {
+			mh.PlaceholderSize = size
+		}
 	default:
 		err = p9fs.FieldError{
 			Key:   key,
@@ -143,8 +190,12 @@ func (mh *Host) Mount(fsys fs.FS) (io.Closer, error) {
 		fsID       filesystem.ID
 		mountPoint = mh.Point
 		fuseSys    = &goWrapper{
-			FS:  fsys,
-			log: sysLog,
+			FS:          fsys,
+			log:         sysLog,
+			readdirPlus: mh.ReaddirPlus,
+			ignoreChown: mh.IgnoreChown,
+			caseFold:    mh.CaseFold,
+			placeholder: mh.PlaceholderSize,
 		}
 		fuseHost = fuse.NewFileSystemHost(fuseSys)
 	)
@@ -163,22 +214,32 @@ func (mh *Host) Mount(fsys fs.FS) (io.Closer, error) {
 			fsID = idFS.ID()
 		}
 		target, args = makeFuseArgs(fsID, mh)
+		fuseSys.owner = &fuseContext{uid: mh.UID, gid: mh.GID}
 	}
 	if err := doMount(fuseHost, target, args); err != nil {
 		return nil, err
 	}
-	return generic.Closer(func() error {
-		if fuseHost.Unmount() {
-			mh.sysquirks.unmount()
-			return nil
-		}
-		return fmt.Errorf(
-			syscallFailedFmt,
-			"unmount", mountPoint,
-		)
-	}), nil
+	return &mountedHost{
+		system: fuseSys,
+		unmount: func() error {
+			if fuseHost.Unmount() {
+				mh.sysquirks.unmount()
+				return nil
+			}
+			return fmt.Errorf(
+				syscallFailedFmt,
+				"unmount", mountPoint,
+			)
+		},
+	}, nil
 }
 
+func (mh *mountedHost) Close() error { return mh.unmount() }
+
+// OpenHandles returns the amount of files
+// and directories held open by the host.
+func (mh *mountedHost) OpenHandles() int { return mh.system.openHandles() }
+
 func doMount(fuseSys *fuse.FileSystemHost, target string, args []string) error {
 	errs := make(chan error, 1)
 	go safeMount(fuseSys, target, args, errs)
@@ -223,54 +284,33 @@ func idOption(option *strings.Builder, id string, leader rune) {
 	option.WriteString(id)
 }
 
-func makeJitterFunc(initial time.Duration) func() time.Duration {
-	// Adapted from an inlined [net/http] closure.
-	const pollIntervalMax = 500 * time.Millisecond
-	return func() time.Duration {
-		// Add 10% jitter.
-		interval := initial +
-			time.Duration(rand.Intn(int(initial/10)))
-		// Double and clamp for next time.
-		initial *= 2
-		if initial > pollIntervalMax {
-			initial = pollIntervalMax
-		}
-		return interval
-	}
-}
-
 func pollMountpoint(target string, errs chan<- error) {
-	const deadlineDuration = 16 * time.Second // Arbitrary.
+	const (
+		deadlineDuration = 16 * time.Second // Arbitrary.
+		unbounded        = 0
+	)
 	var (
-		specialFile  = filepath.Join(target, mountedFilePath)
-		nextInterval = makeJitterFunc(time.Microsecond)
-		deadline     = time.NewTimer(deadlineDuration)
-		timer        = time.NewTimer(nextInterval())
+		specialFile = filepath.Join(target, mountedFilePath)
+		ctx, cancel = context.WithTimeout(context.Background(), deadlineDuration)
 	)
-	defer deadline.Stop()
-	for {
-		select {
-		case <-deadline.C:
-			timer.Stop()
-			errs <- fmt.Errorf(
-				"call to `Mount` did not respond in time (%v)",
-				deadlineDuration,
-			)
-			// NOTE: this does not mean the mount did not, or
-			// won't eventually succeed. We could try calling
-			// `Unmount`, but we just alert the operator and
-			// exit instead. They'll have more context from
-			// the operating system itself than we have here.
-			return
-		case <-timer.C:
-			// If we can access the special file,
-			// then the mount succeeded.
-			_, err := os.Lstat(specialFile)
-			if err == nil {
-				errs <- nil
-				return
-			}
-			timer.Reset(nextInterval())
-		}
+	defer cancel()
+	// If we can access the special file,
+	// then the mount succeeded.
+	if err := generic.RetryWithJitter(ctx,
+		unbounded, time.Microsecond,
+		func() error { _, err := os.Lstat(specialFile); return err },
+		nil,
+	); err != nil {
+		errs <- fmt.Errorf(
+			"call to `Mount` did not respond in time (%v)",
+			deadlineDuration,
+		)
+		// NOTE: this does not mean the mount did not, or
+		// won't eventually succeed. We could try calling
+		// `Unmount`, but we just alert the operator and
+		// exit instead. They'll have more context from
+		// the operating system itself than we have here.
+		return
 	}
+	errs <- nil
 }
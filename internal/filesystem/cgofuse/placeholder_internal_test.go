@@ -0,0 +1,117 @@
+package cgofuse
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+func TestPlaceholderSize(t *testing.T) {
+	t.Parallel()
+	t.Run("stat", testPlaceholderStat)
+	t.Run("fields", testPlaceholderFields)
+}
+
+func testPlaceholderStat(t *testing.T) {
+	t.Parallel()
+	const (
+		compressedName = "file.gz"
+		emptyName      = "empty"
+		payload        = "arbitrary data"
+		placeholder    = 4096
+	)
+	var (
+		buffer bytes.Buffer
+		writer = gzip.NewWriter(&buffer)
+	)
+	if _, err := writer.Write([]byte(payload)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	memfs := fstest.MapFS{
+		compressedName: {Data: buffer.Bytes()},
+		emptyName:      {},
+	}
+	statSize := func(t *testing.T, fsys fs.FS, name string, placeholder int64) int64 {
+		t.Helper()
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stat fuse.Stat_t
+		goToFuseStat(info, fuseContext{}, &stat)
+		placeholderSize(info, placeholder, &stat)
+		return stat.Size
+	}
+	unknown, err := filesystem.NewDecompressFS(memfs,
+		filesystem.WithUnknownSize(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	known, err := filesystem.NewDecompressFS(memfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		fsys        fs.FS
+		name        string
+		description string
+		placeholder int64
+		want        int64
+	}{
+		{
+			description: "unknown size",
+			fsys:        unknown, name: compressedName,
+			placeholder: placeholder, want: placeholder,
+		},
+		{
+			description: "disabled",
+			fsys:        unknown, name: compressedName,
+			placeholder: 0, want: 0,
+		},
+		{
+			description: "known size",
+			fsys:        known, name: compressedName,
+			placeholder: placeholder, want: int64(len(payload)),
+		},
+		{
+			description: "empty file",
+			fsys:        memfs, name: emptyName,
+			placeholder: placeholder, want: 0,
+		},
+	} {
+		if got := statSize(t, test.fsys, test.name, test.placeholder); got != test.want {
+			t.Errorf("size mismatch (%s)"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				test.description, got, test.want,
+			)
+		}
+	}
+}
+
+func testPlaceholderFields(t *testing.T) {
+	t.Parallel()
+	var host Host
+	if err := host.ParseField("placeholdersize", "4096"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := host.PlaceholderSize, int64(4096); got != want {
+		t.Errorf("placeholder size mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+	if err := host.ParseField("placeholdersize", "large"); err == nil {
+		t.Error("expected error for invalid size, got nil")
+	}
+}
@@ -0,0 +1,54 @@
+package cgofuse
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+)
+
+func TestReaddirResume(t *testing.T) {
+	t.Parallel()
+	const (
+		entryCount = dirBatchSize*2 + 3
+		// Not a multiple of the batch size, so that
+		// fills end partway through a batch.
+		fillLimit = dirBatchSize/2 + 1
+	)
+	var (
+		memfs = make(fstest.MapFS, entryCount)
+		want  = []filledEntry{
+			{name: filesystem.SelfName, offset: 1},
+			{name: filesystem.ParentName, offset: 2},
+		}
+	)
+	for i := 0; i < entryCount; i++ {
+		name := fmt.Sprintf("%03d", i)
+		memfs["directory/"+name] = new(fstest.MapFile)
+		want = append(want, filledEntry{name: name, offset: int64(len(want) + 1)})
+	}
+	for _, test := range []struct {
+		name      string
+		namesOnly bool
+	}{
+		{name: "entries", namesOnly: false},
+		{name: "names", namesOnly: true},
+	} {
+		namesOnly := test.namesOnly
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			stream := openDotsStream(t, memfs, namesOnly)
+			defer stream.Close()
+			var got []filledEntry
+			for {
+				filled := fillAll(t, stream, fillLimit)
+				if len(filled) == 0 {
+					break
+				}
+				got = append(got, filled...)
+			}
+			filledMatch(t, got, want)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package cgofuse
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/u-root/uio/ulog"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+type (
+	// renameMapFS moves files (and their descendants)
+	// within the map, but will not replace files.
+	renameMapFS struct{ removeMapFS }
+	// readOnlyRenameFS claims to rename files,
+	// but rejects every request.
+	readOnlyRenameFS struct{ fstest.MapFS }
+)
+
+func (rfs renameMapFS) Rename(oldName, newName string) error {
+	const op = "rename"
+	mapFS := rfs.MapFS
+	if _, err := fs.Stat(mapFS, oldName); err != nil {
+		return err
+	}
+	if _, err := fs.Stat(mapFS, path.Dir(newName)); err != nil {
+		return fserrors.New(op, newName, err, fserrors.NotExist)
+	}
+	if _, ok := mapFS[newName]; ok {
+		return fserrors.New(op, newName, fs.ErrExist, fserrors.Exist)
+	}
+	prefix := oldName + "/"
+	for name, file := range mapFS {
+		switch {
+		case name == oldName:
+			mapFS[newName] = file
+		case strings.HasPrefix(name, prefix):
+			mapFS[newName+"/"+strings.TrimPrefix(name, prefix)] = file
+		default:
+			continue
+		}
+		delete(mapFS, name)
+	}
+	return nil
+}
+
+func (readOnlyRenameFS) Rename(_, newName string) error {
+	const op = "rename"
+	return fserrors.New(op, newName, fs.ErrPermission, fserrors.ReadOnly)
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+	t.Run("writable", testRenameWritable)
+	t.Run("invalid", testRenameInvalid)
+	t.Run("unsupported", testRenameUnsupported)
+}
+
+func newRenameFS() (*goWrapper, fstest.MapFS) {
+	mapFS := fstest.MapFS{
+		"file":          &fstest.MapFile{Data: []byte("file")},
+		"other":         &fstest.MapFile{Data: []byte("other")},
+		"dir":           &fstest.MapFile{Mode: fs.ModeDir},
+		"dir/child":     &fstest.MapFile{Data: []byte("child")},
+		"empty":         &fstest.MapFile{Mode: fs.ModeDir},
+		"full":          &fstest.MapFile{Mode: fs.ModeDir},
+		"full/contents": new(fstest.MapFile),
+	}
+	return &goWrapper{
+		FS:  renameMapFS{removeMapFS{MapFS: mapFS}},
+		log: ulog.Null,
+	}, mapFS
+}
+
+func testRenameWritable(t *testing.T) {
+	t.Parallel()
+	fsys, mapFS := newRenameFS()
+	for _, test := range []struct {
+		oldpath, newpath string
+		want             string
+	}{
+		{"/file", "/file", "file"},
+		{"/file", "/moved", "file"},
+		{"/moved", "/dir/moved", "file"},
+		{"/dir/moved", "/other", "file"}, // Replaced.
+		{"/dir", "/empty", ""},           // Replaced.
+	} {
+		if errNo := fsys.Rename(test.oldpath, test.newpath); errNo != operationSuccess {
+			t.Fatalf(`rename "%s" -> "%s" failed: %s`,
+				test.oldpath, test.newpath, fuse.Error(errNo),
+			)
+		}
+		newName := strings.TrimPrefix(test.newpath, "/")
+		file, ok := mapFS[newName]
+		if !ok {
+			t.Fatalf(`"%s" does not exist after rename`, newName)
+		}
+		if got := string(file.Data); got != test.want {
+			t.Errorf(`"%s" data mismatch`+
+				"\ngot: %s"+
+				"\nwant: %s",
+				newName, got, test.want,
+			)
+		}
+		if test.oldpath == test.newpath {
+			continue
+		}
+		if _, ok := mapFS[strings.TrimPrefix(test.oldpath, "/")]; ok {
+			t.Errorf(`"%s" still exists after rename`, test.oldpath)
+		}
+	}
+	if _, ok := mapFS["empty/child"]; !ok {
+		t.Error("directory's children were not moved")
+	}
+}
+
+func testRenameInvalid(t *testing.T) {
+	t.Parallel()
+	fsys, _ := newRenameFS()
+	for _, test := range []struct {
+		oldpath, newpath string
+		want             errNo
+	}{
+		{"/missing", "/new", -fuse.ENOENT},
+		{"/file", "/missing/new", -fuse.ENOENT},
+		{"/file", "/dir", -fuse.EISDIR},
+		{"/dir", "/file", -fuse.ENOTDIR},
+		{"/dir", "/full", -fuse.ENOTEMPTY},
+		{"/dir", "/dir/descendant", -fuse.EINVAL},
+		{"/", "/root", -fuse.EBUSY},
+	} {
+		if errNo := fsys.Rename(test.oldpath, test.newpath); errNo != test.want {
+			t.Errorf(`rename "%s" -> "%s" error mismatch`+
+				"\ngot: %s"+
+				"\nwant: %s",
+				test.oldpath, test.newpath,
+				fuse.Error(errNo), fuse.Error(test.want),
+			)
+		}
+	}
+}
+
+func testRenameUnsupported(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name string
+		fsys fs.FS
+		want errNo
+	}{
+		{"read-only", fstest.MapFS{"file": {}}, -fuse.EROFS},
+		{"writable", removeMapFS{MapFS: fstest.MapFS{"file": {}}}, -fuse.ENOSYS},
+		{"rejected", readOnlyRenameFS{MapFS: fstest.MapFS{"file": {}}}, -fuse.EROFS},
+	} {
+		fsys := &goWrapper{FS: test.fsys, log: ulog.Null}
+		if errNo := fsys.Rename("/file", "/new"); errNo != test.want {
+			t.Errorf("%s error mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				test.name, fuse.Error(errNo), fuse.Error(test.want),
+			)
+		}
+	}
+}
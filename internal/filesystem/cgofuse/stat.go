@@ -40,6 +40,7 @@ func (gw *goWrapper) Getattr(path string, stat *fuse.Stat_t, fh fileDescriptor)
 		fctx        = fuseContext{uid: uid, gid: gid}
 	)
 	goToFuseStat(info, fctx, stat)
+	placeholderSize(info, gw.placeholder, stat)
 	return operationSuccess
 }
 
@@ -56,7 +57,11 @@ func (gw *goWrapper) infoFromPath(path string) (fs.FileInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-	return fs.Stat(gw.FS, goPath)
+	info, err := fs.Stat(gw.FS, goPath)
+	if folded, ok := gw.foldName(goPath, err); ok {
+		return fs.Stat(gw.FS, folded)
+	}
+	return info, err
 }
 
 func (gw *goWrapper) Utimens(path string, tmsp []fuse.Timespec) errNo {
@@ -71,12 +76,49 @@ func (gw *goWrapper) Setxattr(path, name string, value []byte, flags int) errNo
 
 func (gw *goWrapper) Listxattr(path string, fill func(name string) bool) errNo {
 	defer gw.systemLock.Access(path)()
-	return -fuse.ENOSYS
+	attributes, errNo := gw.xattrs(path)
+	if errNo != operationSuccess {
+		return errNo
+	}
+	for _, attribute := range attributes {
+		if !fill(attribute.name) {
+			return -fuse.ERANGE
+		}
+	}
+	return operationSuccess
 }
 
 func (gw *goWrapper) Getxattr(path, name string) (errNo, []byte) {
 	defer gw.systemLock.Access(path)()
-	return -fuse.ENOSYS, nil
+	attributes, errNo := gw.xattrs(path)
+	if errNo != operationSuccess {
+		return errNo, nil
+	}
+	for _, attribute := range attributes {
+		if attribute.name == name {
+			return operationSuccess, attribute.value
+		}
+	}
+	return -fuse.ENOATTR, nil
+}
+
+// xattrs returns the (read-only) extended attributes
+// derived from the file's metadata.
+// If the file has none, ENOTSUP is returned.
+func (gw *goWrapper) xattrs(path string) ([]xattr, errNo) {
+	info, err := gw.infoFromPath(path)
+	if err != nil {
+		errNo := interpretError(err)
+		if errNo != -fuse.ENOENT {
+			gw.logError(path, err)
+		}
+		return nil, errNo
+	}
+	attributes := goToFuseXattrs(info)
+	if len(attributes) == 0 {
+		return nil, -fuse.ENOTSUP
+	}
+	return attributes, operationSuccess
 }
 
 func (gw *goWrapper) Removexattr(path, name string) errNo {
@@ -0,0 +1,45 @@
+//go:build darwin || windows
+
+package cgofuse
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+type creationInfo struct {
+	fs.FileInfo
+	creationTime time.Time
+}
+
+func (ci *creationInfo) CreationTime() time.Time { return ci.creationTime }
+
+func TestBirthTime(t *testing.T) {
+	t.Parallel()
+	const name = "file"
+	var (
+		want = time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+		fsys = fstest.MapFS{
+			name: &fstest.MapFile{
+				Sys: &creationInfo{creationTime: want},
+			},
+		}
+	)
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stat fuse.Stat_t
+	goToFuseStat(info, fuseContext{}, &stat)
+	if got := stat.Birthtim.Time(); !got.Equal(want) {
+		t.Errorf("birth time mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, want,
+		)
+	}
+}
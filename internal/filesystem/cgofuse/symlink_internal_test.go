@@ -0,0 +1,135 @@
+package cgofuse
+
+import (
+	"io/fs"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/u-root/uio/ulog"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+type (
+	// linkMapFS stores links as files
+	// which contain their target.
+	linkMapFS struct{ fstest.MapFS }
+	// removeMapFS is writable,
+	// but can not store links.
+	removeMapFS struct{ fstest.MapFS }
+)
+
+func (rfs removeMapFS) Remove(name string) error {
+	delete(rfs.MapFS, name)
+	return nil
+}
+
+func (lfs linkMapFS) Symlink(oldname, newname string) error {
+	const op = "symlink"
+	if _, err := fs.Stat(lfs.MapFS, path.Dir(newname)); err != nil {
+		return err
+	}
+	if _, ok := lfs.MapFS[newname]; ok {
+		return &fs.PathError{Op: op, Path: newname, Err: fs.ErrExist}
+	}
+	lfs.MapFS[newname] = &fstest.MapFile{
+		Data: []byte(oldname),
+		Mode: fs.ModeSymlink,
+	}
+	return nil
+}
+
+func (lfs linkMapFS) Readlink(name string) (string, error) {
+	file, ok := lfs.MapFS[name]
+	if !ok || file.Mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return string(file.Data), nil
+}
+
+func TestSymlink(t *testing.T) {
+	t.Parallel()
+	t.Run("writable", testSymlinkWritable)
+	t.Run("unsupported", testSymlinkUnsupported)
+}
+
+func testSymlinkWritable(t *testing.T) {
+	t.Parallel()
+	fsys := &goWrapper{
+		FS: linkMapFS{
+			MapFS: fstest.MapFS{
+				"dir":      &fstest.MapFile{Mode: fs.ModeDir},
+				"dir/file": new(fstest.MapFile),
+			},
+		},
+		log: ulog.Null,
+	}
+	for _, test := range []struct {
+		target, link, want string
+	}{
+		{"/dir/file", "/absolute", "/dir/file"},
+		{"file", "/dir/relative", "/dir/file"},
+		{"../dir/./file", "/dir/parent", "/dir/file"},
+		{"/", "/root", "/"},
+	} {
+		if errNo := fsys.Symlink(test.target, test.link); errNo != operationSuccess {
+			t.Errorf(`symlink "%s" -> "%s" failed: %s`,
+				test.link, test.target, fuse.Error(errNo),
+			)
+			continue
+		}
+		errNo, got := fsys.Readlink(test.link)
+		if errNo != operationSuccess {
+			t.Errorf(`readlink "%s" failed: %s`,
+				test.link, fuse.Error(errNo),
+			)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("link target mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				got, test.want,
+			)
+		}
+	}
+	for _, test := range []struct {
+		target, link string
+		want         errNo
+	}{
+		{"/dir/file", "/absolute", -fuse.EEXIST},
+		{"../escape", "/escape", -fuse.EINVAL},
+		{"", "/empty", -fuse.EINVAL},
+		{"/dir/file", "/missing/link", -fuse.ENOENT},
+	} {
+		if errNo := fsys.Symlink(test.target, test.link); errNo != test.want {
+			t.Errorf(`symlink "%s" -> "%s" error mismatch`+
+				"\ngot: %s"+
+				"\nwant: %s",
+				test.link, test.target,
+				fuse.Error(errNo), fuse.Error(test.want),
+			)
+		}
+	}
+}
+
+func testSymlinkUnsupported(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name string
+		fsys fs.FS
+		want errNo
+	}{
+		{"read-only", fstest.MapFS{}, -fuse.EROFS},
+		{"writable", removeMapFS{MapFS: fstest.MapFS{}}, -fuse.ENOSYS},
+	} {
+		fsys := &goWrapper{FS: test.fsys, log: ulog.Null}
+		if errNo := fsys.Symlink("/target", "/link"); errNo != test.want {
+			t.Errorf("%s error mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				test.name, fuse.Error(errNo), fuse.Error(test.want),
+			)
+		}
+	}
+}
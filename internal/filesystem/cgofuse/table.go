@@ -116,6 +116,19 @@ func (ft *fileTable) add(f fs.File) (fileDescriptor, error) {
 	return fileDescriptor(index), nil
 }
 
+// count returns the amount of open handles.
+func (ft *fileTable) count() int {
+	ft.RLock()
+	defer ft.RUnlock()
+	var count int
+	for _, handle := range ft.files {
+		if handle != nil {
+			count++
+		}
+	}
+	return count
+}
+
 func (ft *fileTable) validLocked(fh fileDescriptor) error {
 	var (
 		files           = ft.files
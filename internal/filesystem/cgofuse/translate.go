@@ -4,6 +4,8 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"path"
+	"strings"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
@@ -49,6 +51,33 @@ func fuseToGoPair(path1, path2 string) (string, string, error) {
 	return new1, new2, nil
 }
 
+// fuseToGoLink converts a FUSE link target to an [fs.FS] name.
+// Links are stored as names within the file system,
+// so relative targets are resolved against the directory
+// of the link (`goLink`), and absolute targets are
+// considered to be relative to the file system's root.
+// Targets which are not valid names
+// (or leave the file system) are rejected.
+func fuseToGoLink(target, goLink string) (string, error) {
+	const op = "symlink"
+	var name string
+	switch {
+	case target == "":
+		return "", fserrors.New(op, target, errEmptyPath, fserrors.InvalidItem)
+	case strings.HasPrefix(target, posixRoot):
+		name = path.Clean(target)[1:]
+		if name == "" {
+			name = goRoot
+		}
+	default:
+		name = path.Join(path.Dir(goLink), target)
+	}
+	if !fs.ValidPath(name) {
+		return "", fserrors.New(op, target, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	return name, nil
+}
+
 func goToFuseStat(info fs.FileInfo, fctx fuseContext, stat *fuse.Stat_t) {
 	var (
 		goMode          = info.Mode()
@@ -74,11 +103,34 @@ func goToFuseStat(info fs.FileInfo, fctx fuseContext, stat *fuse.Stat_t) {
 		stat.Ctim = fuseModTime
 	}
 	// TODO: Block size + others.
-	if crtimer, ok := info.(filesystem.CreationTimeInfo); ok {
+	if crtimer, ok := creationTimeInfo(info); ok {
 		stat.Birthtim = fuse.NewTimespec(crtimer.CreationTime())
 	}
 }
 
+// placeholderSize substitutes the size of regular files
+// which do not know their size, if the placeholder is > 0.
+func placeholderSize(info fs.FileInfo, placeholder int64, stat *fuse.Stat_t) {
+	if placeholder <= 0 || !info.Mode().IsRegular() {
+		return
+	}
+	if sizer, ok := info.(filesystem.SizeInfo); ok {
+		if _, known := sizer.KnownSize(); !known {
+			stat.Size = placeholder
+		}
+	}
+}
+
+// creationTimeInfo checks the info (and its [fs.FileInfo.Sys] value)
+// for a birth time. Only some platforms expose this field.
+func creationTimeInfo(info fs.FileInfo) (filesystem.CreationTimeInfo, bool) {
+	if crtimer, ok := info.(filesystem.CreationTimeInfo); ok {
+		return crtimer, true
+	}
+	crtimer, ok := info.Sys().(filesystem.CreationTimeInfo)
+	return crtimer, ok
+}
+
 // [FileMode] to FUSE mode bits.
 func goToFuseFileType(m fs.FileMode) fileType {
 	switch m.Type() {
@@ -128,6 +180,9 @@ var (
 		fserrors.IsDir:            -fuse.EISDIR,
 		fserrors.NotDir:           -fuse.ENOTDIR,
 		fserrors.NotEmpty:         -fuse.ENOTEMPTY,
+		fserrors.ReadOnly:         -fuse.EROFS,
+		fserrors.Closed:           -fuse.EBADF,
+		fserrors.TooManyOpen:      -fuse.EMFILE,
 	}
 )
 
@@ -145,6 +200,16 @@ func goToFusePermissions(m fs.FileMode) filePermissions {
 	return fusePermissions
 }
 
+// fuseToGoID converts a FUSE owner ID to
+// its Go equivalent, where -1 means "unchanged".
+func fuseToGoID(id uint32) int {
+	const unchanged = ^uint32(0)
+	if id == unchanged {
+		return -1
+	}
+	return int(id)
+}
+
 func fuseToGoPermissions(m filePermissions) fs.FileMode {
 	var fsPermissions fs.FileMode
 	for _, bit := range goToFusePermissionsTable {
@@ -180,5 +245,36 @@ func interpretError(err error) errNo {
 	if errors.As(err, &fsErr) {
 		return fsErrorsTable[fsErr.Kind]
 	}
+	// Standard errors, from file systems
+	// which don't use [fserrors].
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return -fuse.ENOENT
+	case errors.Is(err, fs.ErrExist):
+		return -fuse.EEXIST
+	case errors.Is(err, fs.ErrPermission):
+		return -fuse.EACCES
+	case errors.Is(err, fs.ErrInvalid):
+		return -fuse.EINVAL
+	}
 	return -fuse.EIO
 }
+
+// goToFuseXattrs exposes metadata from the file's
+// info (or its [fs.FileInfo.Sys] value),
+// as extended attributes within the "user" namespace.
+func goToFuseXattrs(info fs.FileInfo) []xattr {
+	cidInfo, ok := info.(filesystem.CIDInfo)
+	if !ok {
+		if cidInfo, ok = info.Sys().(filesystem.CIDInfo); !ok {
+			return nil
+		}
+	}
+	if cid := cidInfo.CID(); cid.Defined() {
+		return []xattr{{
+			name:  xattrCID,
+			value: []byte(cid.String()),
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cgofuse
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/u-root/uio/ulog"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// truncateMapFS can change the size of its files.
+type truncateMapFS struct{ fstest.MapFS }
+
+func (tfs truncateMapFS) Truncate(name string, size int64) error {
+	const op = "truncate"
+	file, ok := tfs.MapFS[name]
+	if !ok {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	data := make([]byte, size)
+	copy(data, file.Data)
+	file.Data = data
+	return nil
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+	const file = "/file"
+	newMapFS := func() fstest.MapFS {
+		return fstest.MapFS{file[1:]: &fstest.MapFile{Data: []byte("file data")}}
+	}
+	t.Run("writable", func(t *testing.T) {
+		t.Parallel()
+		fsys := &goWrapper{
+			FS:        truncateMapFS{MapFS: newMapFS()},
+			log:       ulog.Null,
+			fileTable: newFileTable(),
+		}
+		for _, size := range []int64{4, 16, 0} {
+			if errNo := fsys.Truncate(file, size, errorHandle); errNo != operationSuccess {
+				t.Fatalf("truncate to %d failed: %s", size, fuse.Error(errNo))
+			}
+			info, err := fs.Stat(fsys.FS, file[1:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Size(); got != size {
+				t.Errorf("size mismatch"+
+					"\ngot: %d"+
+					"\nwant: %d",
+					got, size,
+				)
+			}
+		}
+		testTruncateErrNo(t, fsys, "/missing", 0, -fuse.ENOENT)
+		testTruncateErrNo(t, fsys, file, -1, -fuse.EINVAL)
+	})
+	t.Run("read-only", func(t *testing.T) {
+		t.Parallel()
+		fsys := &goWrapper{
+			FS:        newMapFS(),
+			log:       ulog.Null,
+			fileTable: newFileTable(),
+		}
+		testTruncateErrNo(t, fsys, file, 0, -fuse.EROFS)
+	})
+}
+
+func testTruncateErrNo(t *testing.T, fsys *goWrapper, path string, size int64, want errNo) {
+	t.Helper()
+	if got := fsys.Truncate(path, size, errorHandle); got != want {
+		t.Errorf(`truncate "%s" to %d`+
+			"\ngot: %s"+
+			"\nwant: %s",
+			path, size, fuse.Error(got), fuse.Error(want),
+		)
+	}
+}
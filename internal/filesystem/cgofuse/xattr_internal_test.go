@@ -0,0 +1,97 @@
+package cgofuse
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+type cidInfo struct {
+	fs.FileInfo
+	cid cid.Cid
+}
+
+func (ci *cidInfo) CID() cid.Cid { return ci.cid }
+
+func TestXattr(t *testing.T) {
+	t.Parallel()
+	const (
+		cidFile   = "/cid"
+		plainFile = "/plain"
+	)
+	digest, err := multihash.Sum([]byte(t.Name()), multihash.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		want = cid.NewCidV1(cid.Raw, digest)
+		fsys = &goWrapper{
+			FS: fstest.MapFS{
+				cidFile[1:]: &fstest.MapFile{
+					Sys: &cidInfo{cid: want},
+				},
+				plainFile[1:]: new(fstest.MapFile),
+			},
+		}
+	)
+	t.Run("get", func(t *testing.T) {
+		t.Parallel()
+		errNo, value := fsys.Getxattr(cidFile, xattrCID)
+		if errNo != operationSuccess {
+			t.Fatalf("Getxattr returned: %s", fuse.Error(errNo))
+		}
+		if got, want := string(value), want.String(); got != want {
+			t.Errorf("xattr value mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				got, want,
+			)
+		}
+	})
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+		var names []string
+		errNo := fsys.Listxattr(cidFile, func(name string) bool {
+			names = append(names, name)
+			return true
+		})
+		if errNo != operationSuccess {
+			t.Fatalf("Listxattr returned: %s", fuse.Error(errNo))
+		}
+		if len(names) != 1 || names[0] != xattrCID {
+			t.Errorf("xattr list mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				names, []string{xattrCID},
+			)
+		}
+	})
+	for _, test := range []struct {
+		name, path, attribute string
+		want                  errNo
+	}{
+		{name: "unknown", path: cidFile, attribute: "user.unknown", want: -fuse.ENOATTR},
+		{name: "unsupported", path: plainFile, attribute: xattrCID, want: -fuse.ENOTSUP},
+		{name: "missing", path: "/missing", attribute: xattrCID, want: -fuse.ENOENT},
+	} {
+		var (
+			path      = test.path
+			attribute = test.attribute
+			want      = test.want
+		)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			if got, _ := fsys.Getxattr(path, attribute); got != want {
+				t.Errorf("Getxattr error mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					fuse.Error(got), fuse.Error(want),
+				)
+			}
+		})
+	}
+}
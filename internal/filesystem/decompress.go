@@ -0,0 +1,474 @@
+package filesystem
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/klauspost/compress/zstd"
+)
+
+type (
+	// DecoderFunc returns a reader which
+	// decompresses the data read from `r`.
+	DecoderFunc func(r io.Reader) (io.ReadCloser, error)
+
+	// DecompressFS wraps a file system and presents
+	// compressed files as their decompressed content.
+	// Names are not altered; `archive.gz` is read
+	// as the data it contains.
+	//
+	// Files are read-only, and seeking backwards
+	// within a file restarts decompression
+	// from the beginning of the stream.
+	DecompressFS struct {
+		fsys        fs.FS
+		decoders    []decoder
+		detectMagic bool
+		unknownSize bool
+	}
+	DecompressOption func(*DecompressFS) error
+
+	decoder struct {
+		decode    DecoderFunc
+		extension string
+		magic     []byte
+	}
+	decompressedFile struct {
+		fs.File
+		stream   io.ReadCloser
+		info     *decompressedInfo
+		fsys     fs.FS
+		decoder  *decoder
+		name     string
+		position int64
+		offset   int64
+		length   int64
+		ended    bool // Set once `length` is known.
+	}
+	decompressedInfo struct {
+		fs.FileInfo
+		sizeFn      func() (int64, error)
+		once        sync.Once
+		size        int64
+		unknownSize bool
+	}
+	decompressedDirectory struct {
+		fs.ReadDirFile
+		fsys *DecompressFS
+		name string
+	}
+	decompressedEntry struct {
+		fs.DirEntry
+		fsys *DecompressFS
+		name string
+	}
+)
+
+const (
+	DecompressID ID = "Decompress"
+
+	// GzipExtension is the file extension
+	// associated with the built-in gzip decoder.
+	GzipExtension = ".gz"
+	// ZstdExtension is the file extension
+	// associated with the built-in Zstandard decoder.
+	ZstdExtension = ".zst"
+
+	errSeekNegative = generic.ConstError("seek to negative position")
+	errSeekWhence   = generic.ConstError("invalid whence")
+)
+
+// NewDecompressFS wraps `fsys`, decompressing
+// files that match a registered decoder.
+// Gzip and Zstandard decoders are registered by default.
+func NewDecompressFS(fsys fs.FS, options ...DecompressOption) (*DecompressFS, error) {
+	dfs := &DecompressFS{
+		fsys: fsys,
+		decoders: []decoder{
+			{
+				extension: GzipExtension,
+				magic:     []byte{0x1f, 0x8b},
+				decode:    decodeGzip,
+			},
+			{
+				extension: ZstdExtension,
+				magic:     []byte{0x28, 0xb5, 0x2f, 0xfd},
+				decode:    decodeZstd,
+			},
+		},
+	}
+	if err := generic.ApplyOptions(dfs, options...); err != nil {
+		return nil, err
+	}
+	return dfs, nil
+}
+
+// WithDecoder registers a decoder for files with the
+// `extension` suffix (or data prefixed with `magic` if
+// magic detection is enabled).
+// A decoder registered for an existing extension
+// replaces the previous decoder.
+func WithDecoder(extension string, magic []byte, decode DecoderFunc) DecompressOption {
+	return func(dfs *DecompressFS) error {
+		if decode == nil {
+			return generic.ConstError("decoder function is nil")
+		}
+		entry := decoder{
+			extension: extension,
+			magic:     magic,
+			decode:    decode,
+		}
+		for i, existing := range dfs.decoders {
+			if existing.extension == extension {
+				dfs.decoders[i] = entry
+				return nil
+			}
+		}
+		dfs.decoders = append(dfs.decoders, entry)
+		return nil
+	}
+}
+
+// WithMagicDetection enables inspection of file headers
+// for files that do not match a registered extension.
+// Detection requires an extra open of each such file.
+func WithMagicDetection(detect bool) DecompressOption {
+	return func(dfs *DecompressFS) error {
+		dfs.detectMagic = detect
+		return nil
+	}
+}
+
+// WithUnknownSize causes decompressed files to report
+// a size of 0, rather than decompressing the file
+// to determine its length. Callers are expected to
+// read such files until [io.EOF].
+// Seeking relative to the end of such a file
+// decompresses it to its end first.
+func WithUnknownSize(unknown bool) DecompressOption {
+	return func(dfs *DecompressFS) error {
+		dfs.unknownSize = unknown
+		return nil
+	}
+}
+
+func decodeGzip(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+func decodeZstd(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
+
+func (*DecompressFS) ID() ID { return DecompressID }
+
+func (dfs *DecompressFS) Open(name string) (fs.File, error) {
+	const op = "open"
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	file, err := dfs.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+	if info.IsDir() {
+		if directory, ok := file.(fs.ReadDirFile); ok {
+			return &decompressedDirectory{
+				ReadDirFile: directory,
+				fsys:        dfs,
+				name:        name,
+			}, nil
+		}
+		return file, nil
+	}
+	if !info.Mode().IsRegular() {
+		return file, nil
+	}
+	decoder, err := dfs.decoderFor(name)
+	if err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+	if decoder == nil {
+		return file, nil
+	}
+	return &decompressedFile{
+		File:    file,
+		fsys:    dfs.fsys,
+		name:    name,
+		decoder: decoder,
+		info:    dfs.wrapInfo(name, info, decoder),
+	}, nil
+}
+
+func (dfs *DecompressFS) decoderFor(name string) (*decoder, error) {
+	for i := range dfs.decoders {
+		if decoder := &dfs.decoders[i]; decoder.extension != "" &&
+			strings.HasSuffix(name, decoder.extension) {
+			return decoder, nil
+		}
+	}
+	if !dfs.detectMagic {
+		return nil, nil
+	}
+	return dfs.sniffDecoder(name)
+}
+
+func (dfs *DecompressFS) sniffDecoder(name string) (*decoder, error) {
+	var longest int
+	for _, decoder := range dfs.decoders {
+		if size := len(decoder.magic); size > longest {
+			longest = size
+		}
+	}
+	if longest == 0 {
+		return nil, nil
+	}
+	file, err := dfs.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		header     = make([]byte, longest)
+		read, rErr = io.ReadFull(file, header)
+		cErr       = file.Close()
+	)
+	if rErr != nil &&
+		!errors.Is(rErr, io.EOF) &&
+		!errors.Is(rErr, io.ErrUnexpectedEOF) {
+		return nil, errors.Join(rErr, cErr)
+	}
+	if cErr != nil {
+		return nil, cErr
+	}
+	header = header[:read]
+	for i := range dfs.decoders {
+		if decoder := &dfs.decoders[i]; len(decoder.magic) != 0 &&
+			bytes.HasPrefix(header, decoder.magic) {
+			return decoder, nil
+		}
+	}
+	return nil, nil
+}
+
+func (dfs *DecompressFS) wrapInfo(name string, info fs.FileInfo, decoder *decoder) *decompressedInfo {
+	sizeFn := func() (int64, error) {
+		if dfs.unknownSize {
+			return 0, nil
+		}
+		return decompressedSize(dfs.fsys, name, decoder)
+	}
+	return &decompressedInfo{
+		FileInfo:    info,
+		sizeFn:      sizeFn,
+		unknownSize: dfs.unknownSize,
+	}
+}
+
+func decompressedSize(fsys fs.FS, name string, decoder *decoder) (int64, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	stream, err := decoder.decode(file)
+	if err != nil {
+		return 0, errors.Join(err, file.Close())
+	}
+	size, err := io.Copy(io.Discard, stream)
+	return size, errors.Join(err, stream.Close(), file.Close())
+}
+
+// Size returns the length of the decompressed data.
+// The value is computed on first call.
+// If the data cannot be decompressed,
+// the size of the compressed file is returned.
+func (di *decompressedInfo) Size() int64 {
+	di.once.Do(func() {
+		size, err := di.sizeFn()
+		if err != nil {
+			size = di.FileInfo.Size()
+		}
+		di.size = size
+	})
+	return di.size
+}
+
+// KnownSize reports the size of the decompressed data,
+// unless the file system was constructed with [WithUnknownSize].
+func (di *decompressedInfo) KnownSize() (int64, bool) {
+	if di.unknownSize {
+		return 0, false
+	}
+	return di.Size(), true
+}
+
+func (di *decompressedInfo) Mode() fs.FileMode {
+	const writeAll = WriteUser | WriteGroup | WriteOther
+	return di.FileInfo.Mode() &^ writeAll
+}
+
+func (di *decompressedInfo) Sys() any { return di.FileInfo.Sys() }
+
+func (df *decompressedFile) Stat() (fs.FileInfo, error) { return df.info, nil }
+
+func (df *decompressedFile) Read(b []byte) (int, error) {
+	if err := df.sync(); err != nil {
+		return 0, err
+	}
+	read, err := df.stream.Read(b)
+	df.position += int64(read)
+	df.offset = df.position
+	return read, err
+}
+
+// sync positions the decompressed stream at the
+// file's offset. Backwards seeks restart the stream.
+func (df *decompressedFile) sync() error {
+	if df.stream != nil &&
+		df.offset < df.position {
+		if err := df.rewind(); err != nil {
+			return err
+		}
+	}
+	if df.stream == nil {
+		stream, err := df.decoder.decode(df.File)
+		if err != nil {
+			return err
+		}
+		df.stream = stream
+		df.position = 0
+	}
+	if skip := df.offset - df.position; skip > 0 {
+		skipped, err := io.CopyN(io.Discard, df.stream, skip)
+		df.position += skipped
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (df *decompressedFile) rewind() error {
+	err := df.stream.Close()
+	df.stream = nil
+	if err != nil {
+		return err
+	}
+	if seeker, ok := df.File.(io.Seeker); ok {
+		_, err := seeker.Seek(0, io.SeekStart)
+		return err
+	}
+	file, err := df.fsys.Open(df.name)
+	if err != nil {
+		return err
+	}
+	if err := df.File.Close(); err != nil {
+		return errors.Join(err, file.Close())
+	}
+	df.File = file
+	return nil
+}
+
+func (df *decompressedFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += df.offset
+	case io.SeekEnd:
+		length, err := df.end()
+		if err != nil {
+			return df.offset, err
+		}
+		offset += length
+	default:
+		return df.offset, errSeekWhence
+	}
+	if offset < 0 {
+		return df.offset, errSeekNegative
+	}
+	df.offset = offset
+	return offset, nil
+}
+
+// end returns the length of the decompressed data.
+// If the size is not known (see [WithUnknownSize]),
+// the stream is decompressed to its end
+// (once) to determine it.
+func (df *decompressedFile) end() (int64, error) {
+	if size, ok := df.info.KnownSize(); ok {
+		return size, nil
+	}
+	if df.ended {
+		return df.length, nil
+	}
+	if df.stream == nil {
+		if err := df.sync(); err != nil {
+			return 0, err
+		}
+	}
+	skipped, err := io.Copy(io.Discard, df.stream)
+	df.position += skipped
+	if err != nil {
+		return 0, err
+	}
+	df.length = df.position
+	df.ended = true
+	return df.length, nil
+}
+
+func (df *decompressedFile) Close() error {
+	var err error
+	if stream := df.stream; stream != nil {
+		err = stream.Close()
+		df.stream = nil
+	}
+	return errors.Join(err, df.File.Close())
+}
+
+func (dd *decompressedDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
+	entries, err := dd.ReadDirFile.ReadDir(count)
+	for i, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		entries[i] = &decompressedEntry{
+			DirEntry: entry,
+			fsys:     dd.fsys,
+			name:     path.Join(dd.name, entry.Name()),
+		}
+	}
+	return entries, err
+}
+
+func (de *decompressedEntry) Info() (fs.FileInfo, error) {
+	info, err := de.DirEntry.Info()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		fsys = de.fsys
+		name = de.name
+	)
+	decoder, err := fsys.decoderFor(name)
+	if err != nil {
+		return nil, err
+	}
+	if decoder == nil {
+		return info, nil
+	}
+	return fsys.wrapInfo(name, info, decoder), nil
+}
@@ -0,0 +1,237 @@
+package filesystem_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestDecompressFS(t *testing.T) {
+	t.Parallel()
+	const (
+		compressedName = "archive.txt.gz"
+		zstdName       = "archive.txt.zst"
+		magicName      = "archive"
+		zstdMagicName  = "archive-zstd"
+		plainName      = "plain.txt"
+		payload        = "arbitrary data that is compressed"
+		sys            = "underlying system data"
+	)
+	var (
+		compressed = gzipData(t, payload)
+		zstandard  = zstdData(t, payload)
+		memfs      = fstest.MapFS{
+			compressedName:           {Data: compressed, Mode: 0o644, Sys: sys},
+			zstdName:                 {Data: zstandard, Mode: 0o644},
+			magicName:                {Data: compressed, Mode: 0o644},
+			zstdMagicName:            {Data: zstandard, Mode: 0o644},
+			plainName:                {Data: []byte(payload), Mode: 0o644},
+			"directory/" + plainName: {Data: []byte(payload), Mode: 0o644},
+		}
+	)
+	t.Run("extension", func(t *testing.T) {
+		t.Parallel()
+		fsys := newDecompressFS(t, memfs)
+		decompressRead(t, fsys, compressedName, payload)
+		decompressRead(t, fsys, plainName, payload)
+		decompressRead(t, fsys, magicName, string(compressed))
+		decompressStat(t, fsys, compressedName, len(payload))
+		decompressKnownSize(t, fsys, compressedName, true)
+		decompressSys(t, fsys, compressedName, sys)
+	})
+	t.Run("magic", func(t *testing.T) {
+		t.Parallel()
+		fsys := newDecompressFS(t, memfs,
+			filesystem.WithMagicDetection(true),
+		)
+		decompressRead(t, fsys, magicName, payload)
+		decompressRead(t, fsys, zstdMagicName, payload)
+		decompressRead(t, fsys, plainName, payload)
+	})
+	t.Run("zstd", func(t *testing.T) {
+		t.Parallel()
+		fsys := newDecompressFS(t, memfs)
+		decompressRead(t, fsys, zstdName, payload)
+		decompressStat(t, fsys, zstdName, len(payload))
+		decompressSeek(t, fsys, zstdName, payload)
+	})
+	t.Run("unknown size", func(t *testing.T) {
+		t.Parallel()
+		fsys := newDecompressFS(t, memfs,
+			filesystem.WithUnknownSize(true),
+		)
+		decompressRead(t, fsys, compressedName, payload)
+		decompressStat(t, fsys, compressedName, 0)
+		decompressKnownSize(t, fsys, compressedName, false)
+		decompressSeek(t, fsys, compressedName, payload)
+	})
+	t.Run("seek", func(t *testing.T) {
+		t.Parallel()
+		fsys := newDecompressFS(t, memfs)
+		decompressSeek(t, fsys, compressedName, payload)
+	})
+	t.Run("fstest", func(t *testing.T) {
+		t.Parallel()
+		fsys := newDecompressFS(t, memfs)
+		if err := fstest.TestFS(fsys,
+			compressedName, zstdName,
+			magicName, zstdMagicName, plainName,
+			"directory/"+plainName,
+		); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+	var (
+		buffer bytes.Buffer
+		writer = gzip.NewWriter(&buffer)
+	)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func zstdData(t *testing.T, data string) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer, err := zstd.NewWriter(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func newDecompressFS(t *testing.T, fsys fs.FS, options ...filesystem.DecompressOption) fs.FS {
+	t.Helper()
+	dfs, err := filesystem.NewDecompressFS(fsys, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dfs
+}
+
+func decompressRead(t *testing.T, fsys fs.FS, name, want string) {
+	t.Helper()
+	got, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("data mismatch for \"%s\""+
+			"\ngot: %q"+
+			"\nwant: %q",
+			name, got, want,
+		)
+	}
+}
+
+func decompressStat(t *testing.T, fsys fs.FS, name string, want int) {
+	t.Helper()
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Size(); got != int64(want) {
+		t.Errorf("size mismatch for \"%s\""+
+			"\ngot: %d"+
+			"\nwant: %d",
+			name, got, want,
+		)
+	}
+	if info.Mode()&0o222 != 0 {
+		t.Errorf("expected read-only mode, got: %v", info.Mode())
+	}
+}
+
+func decompressKnownSize(t *testing.T, fsys fs.FS, name string, want bool) {
+	t.Helper()
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizer, ok := info.(filesystem.SizeInfo)
+	if !ok {
+		t.Fatalf("%T does not implement %T", info, sizer)
+	}
+	if _, got := sizer.KnownSize(); got != want {
+		t.Errorf("known size mismatch for \"%s\""+
+			"\ngot: %t"+
+			"\nwant: %t",
+			name, got, want,
+		)
+	}
+}
+
+func decompressSys(t *testing.T, fsys fs.FS, name string, want any) {
+	t.Helper()
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Sys(); got != want {
+		t.Errorf("sys mismatch for \"%s\""+
+			"\ngot: %v"+
+			"\nwant: %v",
+			name, got, want,
+		)
+	}
+}
+
+func decompressSeek(t *testing.T, fsys fs.FS, name, payload string) {
+	t.Helper()
+	file, err := fsys.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	seeker, ok := file.(io.ReadSeeker)
+	if !ok {
+		t.Fatalf("%T does not implement %T", file, seeker)
+	}
+	const offset = 4
+	for _, test := range []struct {
+		offset int64
+		whence int
+		want   string
+	}{
+		{offset: offset, whence: io.SeekStart, want: payload[offset:]},
+		{offset: 0, whence: io.SeekStart, want: payload},
+		{offset: -offset, whence: io.SeekEnd, want: payload[len(payload)-offset:]},
+		{offset: 0, whence: io.SeekEnd, want: ""},
+		{offset: offset, whence: io.SeekStart, want: payload[offset:]},
+	} {
+		if _, err := seeker.Seek(test.offset, test.whence); err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(seeker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.want {
+			t.Errorf("data mismatch after seek (%d, %d)"+
+				"\ngot: %q"+
+				"\nwant: %q",
+				test.offset, test.whence, got, test.want,
+			)
+		}
+	}
+}
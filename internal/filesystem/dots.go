@@ -0,0 +1,52 @@
+package filesystem
+
+import (
+	"io/fs"
+	"path"
+)
+
+// dotEntry is a directory entry which describes
+// a directory under its self or parent name.
+type dotEntry struct {
+	fs.FileInfo
+	name string
+}
+
+const (
+	// SelfName and ParentName are the names of
+	// a directory's entries for itself and its parent.
+	// Guests do not include them when listing entries;
+	// hosts which require them synthesize them
+	// (see [DotEntries]).
+	SelfName   = "."
+	ParentName = ".."
+)
+
+// IsDotName reports whether `name` is
+// either [SelfName] or [ParentName].
+func IsDotName(name string) bool {
+	return name == SelfName || name == ParentName
+}
+
+// DotEntries returns the self and parent entries of the
+// directory `name` within `fsys`, which is described by `self`.
+// The root is considered to be its own parent.
+// If the parent can not be accessed, `self` is
+// used to describe it instead.
+func DotEntries(fsys fs.FS, name string, self fs.FileInfo) []StreamDirEntry {
+	parent := self
+	if name != Root {
+		if info, err := fs.Stat(fsys, path.Dir(name)); err == nil {
+			parent = info
+		}
+	}
+	return []StreamDirEntry{
+		&dotEntry{FileInfo: self, name: SelfName},
+		&dotEntry{FileInfo: parent, name: ParentName},
+	}
+}
+
+func (de *dotEntry) Name() string               { return de.name }
+func (de *dotEntry) Type() fs.FileMode          { return de.Mode().Type() }
+func (de *dotEntry) Info() (fs.FileInfo, error) { return de, nil }
+func (*dotEntry) Error() error                  { return nil }
@@ -0,0 +1,102 @@
+package filesystem_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+)
+
+func TestDotEntries(t *testing.T) {
+	t.Parallel()
+	const (
+		rootMode   = fs.ModeDir | 0o755
+		parentMode = fs.ModeDir | 0o750
+		childMode  = fs.ModeDir | 0o700
+	)
+	memfs := fstest.MapFS{
+		".":            {Mode: rootMode},
+		"parent":       {Mode: parentMode},
+		"parent/child": {Mode: childMode},
+	}
+	for _, test := range []struct {
+		name                 string
+		selfMode, parentMode fs.FileMode
+	}{
+		{name: filesystem.Root, selfMode: rootMode, parentMode: rootMode},
+		{name: "parent", selfMode: parentMode, parentMode: rootMode},
+		{name: "parent/child", selfMode: childMode, parentMode: parentMode},
+	} {
+		info, err := fs.Stat(memfs, test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries := filesystem.DotEntries(memfs, test.name, info)
+		if len(entries) != 2 {
+			t.Fatalf("entry count mismatch for \"%s\""+
+				"\ngot: %d"+
+				"\nwant: %d",
+				test.name, len(entries), 2,
+			)
+		}
+		for i, want := range []struct {
+			name string
+			mode fs.FileMode
+		}{
+			{name: filesystem.SelfName, mode: test.selfMode},
+			{name: filesystem.ParentName, mode: test.parentMode},
+		} {
+			dotEntryMatches(t, entries[i], want.name, want.mode)
+		}
+	}
+	for name, want := range map[string]bool{
+		filesystem.SelfName:   true,
+		filesystem.ParentName: true,
+		"...":                 false,
+		".hidden":             false,
+	} {
+		if got := filesystem.IsDotName(name); got != want {
+			t.Errorf("dot name mismatch for \"%s\""+
+				"\ngot: %t"+
+				"\nwant: %t",
+				name, got, want,
+			)
+		}
+	}
+}
+
+func dotEntryMatches(t *testing.T, entry filesystem.StreamDirEntry, name string, mode fs.FileMode) {
+	t.Helper()
+	if err := entry.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if got := entry.Name(); got != name {
+		t.Errorf("entry name mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, name,
+		)
+	}
+	if !entry.IsDir() || entry.Type() != fs.ModeDir {
+		t.Errorf("\"%s\" is not a directory, type: %s", name, entry.Type())
+	}
+	info, err := entry.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Name(); got != name {
+		t.Errorf("info name mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, name,
+		)
+	}
+	if got := info.Mode(); got != mode {
+		t.Errorf("mode mismatch for \"%s\""+
+			"\ngot: %s"+
+			"\nwant: %s",
+			name, got, mode,
+		)
+	}
+}
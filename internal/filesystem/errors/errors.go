@@ -27,6 +27,8 @@ const (
 	NotDir                       // Item is not a directory.
 	NotEmpty                     // Directory not empty.
 	ReadOnly                     // File system has no modification capabilities.
+	Closed                       // Item was used after being closed.
+	TooManyOpen                  // Too many items are open.
 )
 
 func (e *Error) Unwrap() error { return &e.PathError }
@@ -19,11 +19,13 @@ func _() {
 	_ = x[NotDir-8]
 	_ = x[NotEmpty-9]
 	_ = x[ReadOnly-10]
+	_ = x[Closed-11]
+	_ = x[TooManyOpen-12]
 }
 
-const _Kind_name = "OtherInvalidItemInvalidOperationPermissionIOExistNotExistIsDirNotDirNotEmptyReadOnly"
+const _Kind_name = "OtherInvalidItemInvalidOperationPermissionIOExistNotExistIsDirNotDirNotEmptyReadOnlyClosedTooManyOpen"
 
-var _Kind_index = [...]uint8{0, 5, 16, 32, 42, 44, 49, 57, 62, 68, 76, 84}
+var _Kind_index = [...]uint8{0, 5, 16, 32, 42, 44, 49, 57, 62, 68, 76, 84, 90, 101}
 
 func (i Kind) String() string {
 	if i >= Kind(len(_Kind_index)-1) {
@@ -0,0 +1,224 @@
+package filesystem
+
+import (
+	"io"
+	"io/fs"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+)
+
+type (
+	// WrapperFS is implemented by file systems which
+	// wrap another, and forward its extensions
+	// (such as [OpenFileFS], [SymlinkFS], etc.).
+	// Wrappers implement every extension they forward,
+	// regardless of whether the wrapped system does;
+	// see [Extension].
+	WrapperFS interface {
+		fs.FS
+		Unwrap() fs.FS
+	}
+	// WrapperFile is the file equivalent of [WrapperFS].
+	// See [FileExtension].
+	WrapperFile interface {
+		fs.File
+		Unwrap() fs.File
+	}
+	// forwardFS implements the extensions which
+	// do not return files, by forwarding them
+	// to the wrapped file system (as-is).
+	// Wrappers may embed it, and override
+	// the methods they need to intercept.
+	forwardFS struct{ fsys fs.FS }
+	// forwardFile is the file equivalent of [forwardFS].
+	forwardFile struct {
+		fs.File
+		name string
+	}
+	// forwardDirectory is the directory
+	// equivalent of [forwardFS].
+	forwardDirectory struct {
+		fs.ReadDirFile
+		name string
+	}
+)
+
+// Extension returns `fsys` as `T` if it implements `T`,
+// and so does every file system it wraps (if any).
+// Extension should be used instead of a type assertion,
+// when checking if a file system supports an operation.
+func Extension[T any](fsys fs.FS) (T, bool) {
+	return unwrapExtension[T](fsys)
+}
+
+// FileExtension returns `file` as `T` if it implements `T`,
+// and so does every file it wraps (if any).
+// FileExtension should be used instead of a type assertion,
+// when checking if a file supports an operation.
+func FileExtension[T any](file fs.File) (T, bool) {
+	return unwrapExtension[T](file)
+}
+
+func unwrapExtension[T, W any](value W) (T, bool) {
+	extension, ok := any(value).(T)
+	if !ok {
+		return extension, false
+	}
+	for {
+		wrapper, ok := any(value).(interface{ Unwrap() W })
+		if !ok {
+			return extension, true
+		}
+		value = wrapper.Unwrap()
+		if _, ok := any(value).(T); !ok {
+			var zero T
+			return zero, false
+		}
+	}
+}
+
+// unsupportedOp is returned by wrappers when the
+// system they wrap does not implement an extension.
+func unsupportedOp(op, name string) error {
+	return fserrors.New(op, name, fserrors.ErrUnsupported, fserrors.InvalidOperation)
+}
+
+// Unwrap returns the wrapped file system.
+func (ffs forwardFS) Unwrap() fs.FS { return ffs.fsys }
+
+// ID returns the ID of the wrapped file system
+// (if it has one).
+func (ffs forwardFS) ID() ID {
+	if idFS, ok := ffs.fsys.(IDFS); ok {
+		return idFS.ID()
+	}
+	return ""
+}
+
+func (ffs forwardFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(ffs.fsys, name)
+}
+
+func (ffs forwardFS) Remove(name string) error {
+	const op = "remove"
+	remover, ok := Extension[RemoveFS](ffs.fsys)
+	if !ok {
+		return unsupportedOp(op, name)
+	}
+	return remover.Remove(name)
+}
+
+func (ffs forwardFS) Readlink(name string) (string, error) {
+	const op = "readlink"
+	linker, ok := Extension[ReadlinkFS](ffs.fsys)
+	if !ok {
+		return "", unsupportedOp(op, name)
+	}
+	return linker.Readlink(name)
+}
+
+func (ffs forwardFS) Symlink(oldname, newname string) error {
+	const op = "symlink"
+	linker, ok := Extension[SymlinkFS](ffs.fsys)
+	if !ok {
+		return unsupportedOp(op, newname)
+	}
+	return linker.Symlink(oldname, newname)
+}
+
+func (ffs forwardFS) Rename(oldName, newName string) error {
+	const op = "rename"
+	renamer, ok := Extension[RenameFS](ffs.fsys)
+	if !ok {
+		return unsupportedOp(op, oldName)
+	}
+	return renamer.Rename(oldName, newName)
+}
+
+func (ffs forwardFS) Truncate(name string, size int64) error {
+	const op = "truncate"
+	truncater, ok := Extension[TruncateFileFS](ffs.fsys)
+	if !ok {
+		return unsupportedOp(op, name)
+	}
+	return truncater.Truncate(name, size)
+}
+
+func (ffs forwardFS) Mkdir(name string, perm fs.FileMode) error {
+	const op = "mkdir"
+	maker, ok := Extension[MkdirFS](ffs.fsys)
+	if !ok {
+		return unsupportedOp(op, name)
+	}
+	return maker.Mkdir(name, perm)
+}
+
+func (ffs forwardFS) Chown(name string, uid, gid int) error {
+	const op = "chown"
+	chowner, ok := Extension[ChownFS](ffs.fsys)
+	if !ok {
+		return unsupportedOp(op, name)
+	}
+	return chowner.Chown(name, uid, gid)
+}
+
+// Close closes the wrapped file system
+// (if it implements [io.Closer]).
+func (ffs forwardFS) Close() error {
+	if closer, ok := ffs.fsys.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Unwrap returns the wrapped file.
+func (ff forwardFile) Unwrap() fs.File { return ff.File }
+
+func (ff forwardFile) ReadAt(b []byte, offset int64) (int, error) {
+	const op = "readat"
+	readerAt, ok := FileExtension[io.ReaderAt](ff.File)
+	if !ok {
+		return 0, unsupportedOp(op, ff.name)
+	}
+	return readerAt.ReadAt(b, offset)
+}
+
+func (ff forwardFile) Write(b []byte) (int, error) {
+	const op = "write"
+	writer, ok := FileExtension[io.Writer](ff.File)
+	if !ok {
+		return 0, unsupportedOp(op, ff.name)
+	}
+	return writer.Write(b)
+}
+
+func (ff forwardFile) Truncate(size int64) error {
+	const op = "truncate"
+	truncater, ok := FileExtension[TruncateFile](ff.File)
+	if !ok {
+		return unsupportedOp(op, ff.name)
+	}
+	return truncater.Truncate(size)
+}
+
+// Unwrap returns the wrapped directory.
+func (fd forwardDirectory) Unwrap() fs.File { return fd.ReadDirFile }
+
+// ReadDirNames is forwarded via [ReadDirNames],
+// which reads entries if the wrapped directory
+// does not implement [ReadDirNamesFile].
+func (fd forwardDirectory) ReadDirNames(count int) ([]string, error) {
+	return ReadDirNames(fd.ReadDirFile, count)
+}
+
+func (fd forwardDirectory) StreamDir() <-chan StreamDirEntry {
+	const op = "streamdir"
+	streamer, ok := FileExtension[StreamDirFile](fd.ReadDirFile)
+	if !ok {
+		entries := make(chan StreamDirEntry, 1)
+		entries <- dirEntryWrapper{error: unsupportedOp(op, fd.name)}
+		close(entries)
+		return entries
+	}
+	return streamer.StreamDir()
+}
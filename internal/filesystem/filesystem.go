@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/ipfs/go-cid"
 )
 
 type (
@@ -36,11 +37,16 @@ type (
 		fs.FS
 		Remove(name string) error
 	}
-	SymlinkFS interface {
+	// ReadlinkFS is a file system which
+	// can report the target of a symbolic link.
+	ReadlinkFS interface {
 		fs.FS
-		Symlink(oldname, newname string) error
 		Readlink(name string) (string, error)
 	}
+	SymlinkFS interface {
+		ReadlinkFS
+		Symlink(oldname, newname string) error
+	}
 	RenameFS interface {
 		fs.FS
 		Rename(oldName, newName string) error
@@ -53,6 +59,14 @@ type (
 		fs.FS
 		Mkdir(name string, perm fs.FileMode) error
 	}
+	// A ChownFS is a file system which
+	// stores file ownership.
+	// Like [os.Chown], an ID of -1
+	// leaves that value unchanged.
+	ChownFS interface {
+		fs.FS
+		Chown(name string, uid, gid int) error
+	}
 
 	// A StreamDirFile is a directory file whose entries
 	// can be received with the StreamDir method.
@@ -63,6 +77,15 @@ type (
 		// is sent or the directory is closed.
 		StreamDir() <-chan StreamDirEntry
 	}
+	// A ReadDirNamesFile is a directory file which
+	// can list the names of its entries, without
+	// constructing an [fs.DirEntry] for each.
+	ReadDirNamesFile interface {
+		fs.ReadDirFile
+		// ReadDirNames behaves like [fs.ReadDirFile.ReadDir]
+		// but only returns the names of entries.
+		ReadDirNames(count int) ([]string, error)
+	}
 	TruncateFile interface {
 		fs.File
 		Truncate(size int64) error
@@ -85,6 +108,34 @@ type (
 		fs.FileInfo
 		CreationTime() time.Time
 	}
+	// CIDInfo is implemented by files which
+	// are addressed by a content identifier.
+	CIDInfo interface {
+		fs.FileInfo
+		CID() cid.Cid
+	}
+	// ContentTypeInfo is implemented by files
+	// which know their media type. E.g. "text/plain".
+	ContentTypeInfo interface {
+		fs.FileInfo
+		ContentType() string
+	}
+	// EntryCountInfo is implemented by directories
+	// which may know how many entries they contain,
+	// without being read. If the count is not known,
+	// `ok` will be false.
+	EntryCountInfo interface {
+		fs.FileInfo
+		EntryCount() (count int, ok bool)
+	}
+	// SizeInfo is implemented by files which
+	// may not know their size, without being read.
+	// If the size is not known, `ok` will be false
+	// and [fs.FileInfo.Size] should not be relied on.
+	SizeInfo interface {
+		fs.FileInfo
+		KnownSize() (size int64, ok bool)
+	}
 
 	dirEntryWrapper struct {
 		fs.DirEntry
@@ -117,8 +168,19 @@ const (
 
 func (dw dirEntryWrapper) Error() error { return dw.error }
 
+// NormalizeRoot returns [Root] for the empty string,
+// and returns other names as-is.
+// NOTE: "/" is not normalized, since [fs.FS]
+// implementations must reject it as an invalid path.
+func NormalizeRoot(name string) string {
+	if name == "" {
+		return Root
+	}
+	return name
+}
+
 func OpenFile(fsys fs.FS, name string, flag int, perm fs.FileMode) (fs.File, error) {
-	if fsys, ok := fsys.(OpenFileFS); ok {
+	if fsys, ok := Extension[OpenFileFS](fsys); ok {
 		return fsys.OpenFile(name, flag, perm)
 	}
 	if flag == os.O_RDONLY {
@@ -127,12 +189,21 @@ func OpenFile(fsys fs.FS, name string, flag int, perm fs.FileMode) (fs.File, err
 	return nil, fmt.Errorf(`open "%s": operation not supported`, name)
 }
 
+// Truncate changes the size of the named file.
+//
+// If `fsys` implements [TruncateFileFS],
+// Truncate calls `fsys.Truncate`.
+// Otherwise, the file is opened for writing
+// and must implement [TruncateFile].
 func Truncate(fsys fs.FS, name string, size int64) error {
+	if fsys, ok := Extension[TruncateFileFS](fsys); ok {
+		return fsys.Truncate(name, size)
+	}
 	file, err := OpenFile(fsys, name, os.O_WRONLY|os.O_CREATE, 0o666)
 	if err != nil {
 		return err
 	}
-	truncater, ok := file.(TruncateFile)
+	truncater, ok := FileExtension[TruncateFile](file)
 	if !ok {
 		return errors.Join(
 			fmt.Errorf(`truncate "%s": operation not supported`, name),
@@ -145,6 +216,28 @@ func Truncate(fsys fs.FS, name string, size int64) error {
 	)
 }
 
+// ReadDirNames reads the names of
+// the directory's entries.
+//
+// If `directory` implements [ReadDirNamesFile],
+// ReadDirNames calls `directory.ReadDirNames`.
+// Otherwise, ReadDirNames calls `directory.ReadDir`
+// and returns the names of the entries.
+func ReadDirNames(directory fs.ReadDirFile, count int) ([]string, error) {
+	if namer, ok := FileExtension[ReadDirNamesFile](directory); ok {
+		return namer.ReadDirNames(count)
+	}
+	entries, err := directory.ReadDir(count)
+	if len(entries) == 0 {
+		return nil, err
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names, err
+}
+
 // StreamDir reads the directory
 // and returns a channel of directory entry results.
 //
@@ -154,7 +247,7 @@ func Truncate(fsys fs.FS, name string, size int64) error {
 // repeatedly with `count` until the entire directory
 // is read, an error is encountered, or the context is done.
 func StreamDir(ctx context.Context, count int, directory fs.ReadDirFile) <-chan StreamDirEntry {
-	if dirStreamer, ok := directory.(StreamDirFile); ok {
+	if dirStreamer, ok := FileExtension[StreamDirFile](directory); ok {
 		return dirStreamer.StreamDir()
 	}
 	var (
@@ -0,0 +1,188 @@
+package filesystem
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	// FilterFS wraps a file system and hides
+	// paths which are not permitted by its patterns.
+	// Hidden paths do not exist; they can not be opened,
+	// and are omitted from directory listings.
+	//
+	// Patterns use [path.Match] syntax, and are matched
+	// against each component of a path.
+	// A path is hidden if it (or any of its parents)
+	// matches a deny pattern.
+	// If allow patterns are provided, a path is only
+	// visible if it (or any of its parents) matches one,
+	// or if it's a parent of paths which could match one.
+	// E.g. allowing "docs/*.md" keeps "docs" visible.
+	FilterFS struct {
+		fsys        fs.FS
+		allow, deny [][]string
+	}
+	filteredDirectory struct {
+		fs.ReadDirFile
+		fsys *FilterFS
+		name string
+	}
+)
+
+// NewFilterFS wraps `fsys`, with the
+// provided allow and deny patterns.
+func NewFilterFS(fsys fs.FS, allow, deny []string) (*FilterFS, error) {
+	allowed, err := splitPatterns(allow)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := splitPatterns(deny)
+	if err != nil {
+		return nil, err
+	}
+	return &FilterFS{
+		fsys:  fsys,
+		allow: allowed,
+		deny:  denied,
+	}, nil
+}
+
+// splitPatterns validates the patterns,
+// and splits them into their components.
+func splitPatterns(patterns []string) ([][]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	split := make([][]string, len(patterns))
+	for i, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Join(
+				err,
+				errors.New(`invalid pattern: "`+pattern+`"`),
+			)
+		}
+		split[i] = strings.Split(pattern, "/")
+	}
+	return split, nil
+}
+
+// ID returns the ID of the wrapped file system
+// (if it has one).
+func (ffs *FilterFS) ID() ID {
+	if idFS, ok := ffs.fsys.(IDFS); ok {
+		return idFS.ID()
+	}
+	return ""
+}
+
+func (ffs *FilterFS) Open(name string) (fs.File, error) {
+	const op = "open"
+	if err := ffs.check(op, name); err != nil {
+		return nil, err
+	}
+	file, err := ffs.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if directory, ok := file.(fs.ReadDirFile); ok {
+		return &filteredDirectory{
+			ReadDirFile: directory,
+			fsys:        ffs,
+			name:        name,
+		}, nil
+	}
+	return file, nil
+}
+
+func (ffs *FilterFS) Stat(name string) (fs.FileInfo, error) {
+	const op = "stat"
+	if err := ffs.check(op, name); err != nil {
+		return nil, err
+	}
+	return fs.Stat(ffs.fsys, name)
+}
+
+// Close closes the wrapped file system
+// (if it implements [io.Closer]).
+func (ffs *FilterFS) Close() error {
+	if closer, ok := ffs.fsys.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (ffs *FilterFS) check(op, name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if !ffs.visible(name) {
+		return fserrors.New(op, name, ErrNotFound, fserrors.NotExist)
+	}
+	return nil
+}
+
+func (ffs *FilterFS) visible(name string) bool {
+	if name == Root {
+		return true
+	}
+	components := strings.Split(name, "/")
+	for _, pattern := range ffs.deny {
+		if len(pattern) <= len(components) &&
+			matchComponents(pattern, components) {
+			return false
+		}
+	}
+	if len(ffs.allow) == 0 {
+		return true
+	}
+	for _, pattern := range ffs.allow {
+		if matchComponents(pattern, components) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchComponents reports whether the leading components
+// of the name and pattern match each other.
+// I.e. the name is either a parent of a path which
+// could match the pattern, or a descendant of
+// a path which does match it.
+func matchComponents(pattern, components []string) bool {
+	count := generic.Min(len(pattern), len(components))
+	for i := 0; i < count; i++ {
+		// NOTE: Patterns were validated
+		// during construction.
+		if matched, _ := path.Match(pattern[i], components[i]); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadDir omits hidden entries.
+// If `count` > 0, reads are repeated until
+// at least 1 visible entry is found
+// (or an error is encountered).
+func (fd *filteredDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
+	for {
+		entries, err := fd.ReadDirFile.ReadDir(count)
+		visible := entries[:0]
+		for _, entry := range entries {
+			if fd.fsys.visible(path.Join(fd.name, entry.Name())) {
+				visible = append(visible, entry)
+			}
+		}
+		if len(visible) != 0 || err != nil ||
+			count <= 0 || len(entries) == 0 {
+			return visible, err
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package filesystem_test
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+)
+
+func TestFilterFS(t *testing.T) {
+	t.Parallel()
+	memfs := fstest.MapFS{
+		"public.txt":          new(fstest.MapFile),
+		"secret.key":          new(fstest.MapFile),
+		"docs/readme.md":      new(fstest.MapFile),
+		"docs/notes.txt":      new(fstest.MapFile),
+		"private/data.txt":    new(fstest.MapFile),
+		"private/nested/file": new(fstest.MapFile),
+	}
+	t.Run("deny", func(t *testing.T) {
+		t.Parallel()
+		fsys, err := filesystem.NewFilterFS(memfs, nil,
+			[]string{"*.key", "private"},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testFilterFS(t, fsys,
+			map[string][]string{
+				filesystem.Root: {"docs", "public.txt"},
+				"docs":          {"notes.txt", "readme.md"},
+			},
+			[]string{
+				"secret.key",
+				"private",
+				"private/data.txt",
+				"private/nested/file",
+			},
+		)
+	})
+	t.Run("allow", func(t *testing.T) {
+		t.Parallel()
+		fsys, err := filesystem.NewFilterFS(memfs,
+			[]string{"docs/*.md", "public.txt"},
+			[]string{"docs/notes*"},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testFilterFS(t, fsys,
+			map[string][]string{
+				filesystem.Root: {"docs", "public.txt"},
+				"docs":          {"readme.md"},
+			},
+			[]string{
+				"secret.key",
+				"docs/notes.txt",
+				"private",
+				"private/data.txt",
+			},
+		)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		if _, err := filesystem.NewFilterFS(memfs,
+			nil, []string{"["},
+		); !errors.Is(err, path.ErrBadPattern) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, path.ErrBadPattern,
+			)
+		}
+	})
+}
+
+func testFilterFS(t *testing.T, fsys fs.FS,
+	listings map[string][]string, hidden []string,
+) {
+	t.Helper()
+	for directory, want := range listings {
+		entries, err := fs.ReadDir(fsys, directory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(entries))
+		for i, entry := range entries {
+			got[i] = entry.Name()
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("listing mismatch for \"%s\""+
+				"\ngot: %v"+
+				"\nwant: %v",
+				directory, got, want,
+			)
+		}
+		for _, name := range want {
+			name := path.Join(directory, name)
+			if _, err := fs.Stat(fsys, name); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+	for _, name := range hidden {
+		if _, err := fsys.Open(name); !isFSNotExist(err) {
+			t.Errorf("expected \"%s\" to not exist, got: %v", name, err)
+		}
+		if _, err := fs.Stat(fsys, name); !isFSNotExist(err) {
+			t.Errorf("expected \"%s\" to not exist, got: %v", name, err)
+		}
+	}
+}
+
+func isFSNotExist(err error) bool {
+	var fsErr *fserrors.Error
+	return errors.As(err, &fsErr) &&
+		fsErr.Kind == fserrors.NotExist
+}
@@ -0,0 +1,81 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	// MakeGuestFunc should decode the guest's
+	// settings from `data` and construct the
+	// file system they describe.
+	MakeGuestFunc func(data []byte) (fs.FS, error)
+
+	guestRegistry struct {
+		makers map[ID]MakeGuestFunc
+		mu     sync.RWMutex
+	}
+	// guestJSON is the subset of a mount point's
+	// encoded form, used to construct its guest.
+	guestJSON struct {
+		Tag struct {
+			Guest ID `json:"guest"`
+		} `json:"tag"`
+		Data struct {
+			Guest json.RawMessage `json:"guest"`
+		} `json:"data"`
+	}
+)
+
+const errGuestUnknown = generic.ConstError("guest is not registered")
+
+var guests = guestRegistry{
+	makers: make(map[ID]MakeGuestFunc),
+}
+
+// RegisterGuest associates `id` with the function
+// which constructs the guest file system.
+// Registering the same ID twice is an error.
+func RegisterGuest(id ID, makeFn MakeGuestFunc) error {
+	guests.mu.Lock()
+	defer guests.mu.Unlock()
+	if _, exists := guests.makers[id]; exists {
+		return fmt.Errorf(`guest "%s" already registered`, id)
+	}
+	guests.makers[id] = makeFn
+	return nil
+}
+
+// GuestFromJSON constructs a guest file system from
+// the encoded form of a mount point, as read from
+// a mount point file. The guest constructor is
+// selected by the guest ID within the data,
+// and must have been registered via [RegisterGuest].
+func GuestFromJSON(data []byte) (fs.FS, error) {
+	var point guestJSON
+	if err := json.Unmarshal(data, &point); err != nil {
+		return nil, err
+	}
+	settings := point.Data.Guest
+	if len(settings) == 0 {
+		settings = json.RawMessage("{}")
+	}
+	return MakeGuest(point.Tag.Guest, settings)
+}
+
+// MakeGuest constructs a guest file system from
+// its encoded settings, via the constructor
+// registered for `id` (see [RegisterGuest]).
+func MakeGuest(id ID, data []byte) (fs.FS, error) {
+	guests.mu.RLock()
+	makeFn, ok := guests.makers[id]
+	guests.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf(`%w: "%s"`, errGuestUnknown, id)
+	}
+	return makeFn(data)
+}
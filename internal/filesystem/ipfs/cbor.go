@@ -5,33 +5,82 @@ import (
 	"io"
 	"io/fs"
 
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
 	cbor "github.com/ipfs/go-ipld-cbor"
 )
 
 type cborFile struct {
-	reader io.ReadSeeker
+	reader *bytes.Reader
 	node   *cbor.Node
 	info   nodeInfo
 }
 
-func (cio *cborFile) Close() error { return nil }
+func (cio *cborFile) Close() error {
+	const op = "close"
+	if cio.reader == nil {
+		return cio.closedErr(op)
+	}
+	cio.reader = nil
+	return nil
+}
+
+func (cio *cborFile) closedErr(op string) error {
+	return fserrors.New(op, cio.info.name, filesystem.ErrNotOpen, fserrors.Closed)
+}
+
+func (cio *cborFile) Stat() (fs.FileInfo, error) { return &cio.info, nil }
 
-func (cio *cborFile) Stat() (fs.FileInfo, error)    { return &cio.info, nil }
-func (cio *cborFile) Read(buff []byte) (int, error) { return cio.reader.Read(buff) }
+func (cio *cborFile) Read(buff []byte) (int, error) {
+	const op = "read"
+	if cio.reader == nil {
+		return 0, cio.closedErr(op)
+	}
+	return cio.reader.Read(buff)
+}
 
+// Seek follows [io.Seeker] conventions, with the exception
+// that offsets beyond the end of the data are clamped
+// to the end offset.
 func (cio *cborFile) Seek(offset int64, whence int) (int64, error) {
-	return cio.reader.Seek(offset, whence)
+	const op = "seek"
+	reader := cio.reader
+	if reader == nil {
+		return 0, cio.closedErr(op)
+	}
+	var (
+		end      = reader.Size()
+		pos, err = reader.Seek(offset, whence)
+	)
+	if err != nil {
+		return pos, err
+	}
+	if pos > end {
+		return reader.Seek(end, io.SeekStart)
+	}
+	return pos, nil
 }
 
 func openCborFile(cborNode *cbor.Node, info *nodeInfo) *cborFile {
 	return &cborFile{
 		node:   cborNode,
-		reader: bytes.NewReader(cborNode.RawData()),
+		reader: bytes.NewReader(cborData(cborNode)),
 		info:   *info,
 	}
 }
 
+func cborData(node *cbor.Node) []byte {
+	if node == nil {
+		return nil
+	}
+	return node.RawData()
+}
+
 func statCbor(node *cbor.Node, info *nodeInfo) error {
+	if len(cborData(node)) == 0 {
+		info.size = 0
+		return nil
+	}
 	size, err := node.Size()
 	if err != nil {
 		return err
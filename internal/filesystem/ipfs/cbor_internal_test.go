@@ -0,0 +1,124 @@
+package ipfs
+
+import (
+	"io"
+	"io/fs"
+	"testing"
+
+	cbor "github.com/ipfs/go-ipld-cbor"
+	mh "github.com/multiformats/go-multihash"
+)
+
+var (
+	_ fs.File   = (*cborFile)(nil)
+	_ io.Seeker = (*cborFile)(nil)
+)
+
+func TestCbor(t *testing.T) {
+	t.Parallel()
+	t.Run("empty", testCborEmpty)
+	t.Run("seek", testCborSeek)
+}
+
+func testCborEmpty(t *testing.T) {
+	t.Parallel()
+	var (
+		node = new(cbor.Node)
+		info nodeInfo
+	)
+	if err := statCbor(node, &info); err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Size(); got != 0 {
+		t.Errorf("unexpected size for empty node"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, 0,
+		)
+	}
+	file := openCborFile(node, &info)
+	for _, whence := range []int{io.SeekStart, io.SeekCurrent, io.SeekEnd} {
+		pos, err := file.Seek(1, whence)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pos != 0 {
+			t.Errorf("seek on empty node did not clamp"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				pos, 0,
+			)
+		}
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("expected no data from empty node, got: %v", data)
+	}
+}
+
+func testCborSeek(t *testing.T) {
+	t.Parallel()
+	node, err := cbor.WrapObject("arbitrary data", mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info nodeInfo
+	if err := statCbor(node, &info); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		file = openCborFile(node, &info)
+		raw  = node.RawData()
+		end  = int64(len(raw))
+	)
+	if got := info.Size(); got != end {
+		t.Errorf("size mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, end,
+		)
+	}
+	for _, test := range []struct {
+		name   string
+		offset int64
+		whence int
+		want   int64
+	}{
+		{name: "start", offset: 1, whence: io.SeekStart, want: 1},
+		{name: "end negative", offset: -2, whence: io.SeekEnd, want: end - 2},
+		{name: "start past end", offset: end * 2, whence: io.SeekStart, want: end},
+		{name: "current past end", offset: 1, whence: io.SeekCurrent, want: end},
+	} {
+		pos, err := file.Seek(test.offset, test.whence)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if pos != test.want {
+			t.Errorf("%s: offset mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				test.name, pos, test.want,
+			)
+		}
+	}
+	if _, err := file.Seek(-1, io.SeekStart); err == nil {
+		t.Error("expected error when seeking to negative position")
+	}
+	if _, err := file.Seek(-2, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := raw[end-2:]; string(data) != string(want) {
+		t.Errorf("data mismatch after seek"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			data, want,
+		)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"runtime/debug"
 	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/generic"
@@ -13,13 +14,44 @@ import (
 	manet "github.com/multiformats/go-multiaddr/net"
 )
 
-const errCantResolveAPI = generic.ConstError("non-resolvable API endpoint")
+// userAgentTransport identifies
+// the client within each request.
+type userAgentTransport struct {
+	http.RoundTripper
+	userAgent string
+}
+
+const (
+	errCantResolveAPI = generic.ConstError("non-resolvable API endpoint")
+	userAgentProduct  = "go-filesystem-utils"
+)
 
-func newIPFSClient(apiMaddr multiaddr.Multiaddr) (*rpc.HttpApi, error) {
+// DefaultUserAgent returns the User-Agent
+// used when communicating with IPFS APIs
+// (if one was not provided).
+func DefaultUserAgent() string {
+	version := "devel"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if mainVersion := info.Main.Version; mainVersion != "" &&
+			mainVersion != "(devel)" {
+			version = mainVersion
+		}
+	}
+	return userAgentProduct + "/" + version
+}
+
+func newIPFSClient(apiMaddr multiaddr.Multiaddr, userAgent string) (*rpc.HttpApi, error) {
 	address, client, err := newHTTPClient(apiMaddr)
 	if err != nil {
 		return nil, err
 	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent()
+	}
+	client.Transport = &userAgentTransport{
+		RoundTripper: client.Transport,
+		userAgent:    userAgent,
+	}
 	return rpc.NewURLApiWithClient(address, client)
 }
 
@@ -75,6 +107,14 @@ func udsHTTPClient(address string) (string, *http.Client) {
 	return fakeAddress, client
 }
 
+func (ut *userAgentTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	// NOTE: [http.RoundTripper] implementations
+	// must not modify the caller's request.
+	request = request.Clone(request.Context())
+	request.Header.Set("User-Agent", ut.userAgent)
+	return ut.RoundTripper.RoundTrip(request)
+}
+
 func resolveMaddr(ctx context.Context, addr multiaddr.Multiaddr) (multiaddr.Multiaddr, error) {
 	addrs, err := madns.DefaultResolver.Resolve(ctx, addr)
 	if err != nil {
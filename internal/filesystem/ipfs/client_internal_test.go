@@ -0,0 +1,61 @@
+package ipfs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+func TestUserAgent(t *testing.T) {
+	t.Parallel()
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		testUserAgent(t, "", DefaultUserAgent())
+	})
+	t.Run("custom", func(t *testing.T) {
+		t.Parallel()
+		const userAgent = "test-agent/1.0"
+		testUserAgent(t, userAgent, userAgent)
+	})
+}
+
+func testUserAgent(t *testing.T, userAgent, want string) {
+	t.Helper()
+	userAgents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(_ http.ResponseWriter, request *http.Request) {
+			select {
+			case userAgents <- request.UserAgent():
+			default:
+			}
+		}),
+	)
+	t.Cleanup(server.Close)
+	serverMaddr, err := manet.FromNetAddr(server.Listener.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := newIPFSClient(serverMaddr, userAgent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The response is irrelevant,
+	// only the request is inspected.
+	_ = client.Request("version").
+		Exec(context.Background(), nil)
+	select {
+	case got := <-userAgents:
+		if got != want {
+			t.Errorf("User-Agent mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				got, want,
+			)
+		}
+	default:
+		t.Fatal("server did not receive a request")
+	}
+}
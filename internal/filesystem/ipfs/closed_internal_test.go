@@ -0,0 +1,124 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	files "github.com/ipfs/boxo/files"
+)
+
+func TestClosed(t *testing.T) {
+	t.Parallel()
+	newStream := func() *entryStream {
+		ctx, cancel := context.WithCancel(context.Background())
+		entries := make(chan filesystem.StreamDirEntry)
+		close(entries)
+		return &entryStream{
+			Context: ctx, CancelFunc: cancel,
+			ch: entries,
+		}
+	}
+	newInfo := func() *nodeInfo {
+		return &nodeInfo{name: t.Name()}
+	}
+	directories := []struct {
+		name string
+		fs.ReadDirFile
+	}{
+		{
+			name: "IPFS root",
+			ReadDirFile: &emptyRoot{
+				info: newInfo(),
+			},
+		},
+		{
+			name: "IPFS directory",
+			ReadDirFile: &ipfsDirectory{
+				info:   newInfo(),
+				stream: newStream(),
+			},
+		},
+		{
+			name: "PinFS directory",
+			ReadDirFile: &pinDirectory{
+				stream: newStream(),
+			},
+		},
+		{
+			name: "KeyFS directory",
+			ReadDirFile: &keyDirectory{
+				stream: newStream(),
+			},
+		},
+		{
+			name: "IPNS file",
+			ReadDirFile: &ipnsFile{
+				file:      &emptyRoot{info: newInfo()},
+				refreshFn: func() error { return nil },
+			},
+		},
+	}
+	for _, test := range directories {
+		directory := test.ReadDirFile
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			if err := directory.Close(); err != nil {
+				t.Fatal(err)
+			}
+			_, err := directory.ReadDir(-1)
+			closedErr(t, "ReadDir", err)
+			closedErr(t, "Close", directory.Close())
+		})
+	}
+	readers := []struct {
+		name string
+		fs.File
+	}{
+		{
+			name: "UFS file",
+			File: &ufsFile{
+				File:   files.NewBytesFile([]byte(t.Name())),
+				cancel: func() {},
+				info:   *newInfo(),
+			},
+		},
+		{
+			name: "CBOR file",
+			File: openCborFile(nil, newInfo()),
+		},
+	}
+	for _, test := range readers {
+		file := test.File
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			if err := file.Close(); err != nil {
+				t.Fatal(err)
+			}
+			_, err := file.Read(make([]byte, 1))
+			closedErr(t, "Read", err)
+			closedErr(t, "Close", file.Close())
+		})
+	}
+}
+
+func closedErr(t *testing.T, op string, err error) {
+	t.Helper()
+	var fsErr *fserrors.Error
+	if !errors.As(err, &fsErr) {
+		t.Errorf("%s: expected %T after close, got: %#v",
+			op, fsErr, err,
+		)
+		return
+	}
+	if got, want := fsErr.Kind, fserrors.Closed; got != want {
+		t.Errorf("%s: error kind mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			op, got, want,
+		)
+	}
+}
@@ -0,0 +1,489 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	coreiface "github.com/ipfs/boxo/coreiface"
+	coreoptions "github.com/ipfs/boxo/coreiface/options"
+	corepath "github.com/ipfs/boxo/coreiface/path"
+	dag "github.com/ipfs/boxo/ipld/merkledag"
+	mdtest "github.com/ipfs/boxo/ipld/merkledag/test"
+	"github.com/ipfs/boxo/ipld/unixfs"
+	uio "github.com/ipfs/boxo/ipld/unixfs/io"
+	unixpb "github.com/ipfs/boxo/ipld/unixfs/pb"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+)
+
+type (
+	// fixtureCore implements the methods
+	// the guests require, using an in-memory DAG.
+	fixtureCore struct {
+		coreiface.CoreAPI
+		dag fixtureDag
+		// names maps IPNS names to the root.
+		names map[string]cid.Cid
+	}
+	fixtureDag    struct{ ipld.DAGService }
+	fixtureUnixfs struct {
+		coreiface.UnixfsAPI
+		dag ipld.DAGService
+	}
+	fixturePins struct {
+		coreiface.PinAPI
+		pins []cid.Cid
+	}
+	fixturePin struct{ cid cid.Cid }
+	// fixture is a small, deterministic,
+	// UnixFS tree, rooted at `root`.
+	fixture struct {
+		core  *fixtureCore
+		root  cid.Cid
+		files []string
+	}
+)
+
+func (core *fixtureCore) Dag() coreiface.APIDagService { return core.dag }
+func (core *fixtureCore) Unixfs() coreiface.UnixfsAPI {
+	return fixtureUnixfs{dag: core.dag}
+}
+
+func (core *fixtureCore) ResolvePath(_ context.Context, path corepath.Path) (corepath.Resolved, error) {
+	if resolved, ok := path.(corepath.Resolved); ok {
+		return resolved, nil
+	}
+	const prefix = "/ipns/"
+	name, found := strings.CutPrefix(path.String(), prefix)
+	if !found {
+		return nil, fs.ErrInvalid
+	}
+	root, ok := core.names[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return corepath.IpfsPath(root), nil
+}
+
+func (fp fixturePins) Ls(ctx context.Context, _ ...coreoptions.PinLsOption) (<-chan coreiface.Pin, error) {
+	pins := make(chan coreiface.Pin)
+	go func() {
+		defer close(pins)
+		for _, pin := range fp.pins {
+			select {
+			case pins <- fixturePin{cid: pin}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return pins, nil
+}
+
+func (fp fixturePin) Path() corepath.Resolved { return corepath.IpfsPath(fp.cid) }
+func (fixturePin) Type() string               { return "recursive" }
+func (fixturePin) Err() error                 { return nil }
+
+func (fd fixtureDag) Pinning() ipld.NodeAdder { return fd.DAGService }
+
+func (ufs fixtureUnixfs) Ls(ctx context.Context, path corepath.Path,
+	_ ...coreoptions.UnixfsLsOption,
+) (<-chan coreiface.DirEntry, error) {
+	resolved, ok := path.(corepath.Resolved)
+	if !ok {
+		return nil, fs.ErrInvalid
+	}
+	node, err := ufs.dag.Get(ctx, resolved.Cid())
+	if err != nil {
+		return nil, err
+	}
+	if isShard(node) {
+		return ufs.lsShard(ctx, node)
+	}
+	entries := make(chan coreiface.DirEntry)
+	go func() {
+		defer close(entries)
+		for _, link := range node.Links() {
+			entry := ufs.makeEntry(ctx, link)
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return entries, nil
+}
+
+func isShard(node ipld.Node) bool {
+	protoNode, ok := node.(*dag.ProtoNode)
+	if !ok {
+		return false
+	}
+	fsNode, err := unixfs.ExtractFSNode(protoNode)
+	return err == nil && fsNode.Type() == unixpb.Data_HAMTShard
+}
+
+// lsShard lists the entries of a HAMT
+// sharded directory; fetching its shards
+// (sequentially) as they're reached.
+func (ufs fixtureUnixfs) lsShard(ctx context.Context, node ipld.Node) (<-chan coreiface.DirEntry, error) {
+	directory, err := uio.NewDirectoryFromNode(ufs.dag, node)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(chan coreiface.DirEntry)
+	go func() {
+		defer close(entries)
+		err := directory.ForEachLink(ctx, func(link *ipld.Link) error {
+			select {
+			case entries <- ufs.makeEntry(ctx, link):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			select {
+			case entries <- coreiface.DirEntry{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return entries, nil
+}
+
+func (ufs fixtureUnixfs) makeEntry(ctx context.Context, link *ipld.Link) coreiface.DirEntry {
+	entry := coreiface.DirEntry{
+		Name: link.Name,
+		Cid:  link.Cid,
+	}
+	node, err := ufs.dag.Get(ctx, link.Cid)
+	if err != nil {
+		entry.Err = err
+		return entry
+	}
+	switch typed := node.(type) {
+	case *dag.ProtoNode:
+		fsNode, err := unixfs.ExtractFSNode(typed)
+		if err != nil {
+			entry.Err = err
+			return entry
+		}
+		switch fsNode.Type() {
+		case unixpb.Data_Directory, unixpb.Data_HAMTShard:
+			// Like the RPC API, directories
+			// report their cumulative size.
+			entry.Type = coreiface.TDirectory
+			entry.Size = link.Size
+			return entry
+		case unixpb.Data_Symlink:
+			entry.Type = coreiface.TSymlink
+			entry.Target = string(fsNode.Data())
+		default:
+			entry.Type = coreiface.TFile
+		}
+		entry.Size = fsNode.FileSize()
+	default:
+		entry.Type = coreiface.TFile
+		entry.Size = uint64(len(node.RawData()))
+	}
+	return entry
+}
+
+// fixtureIPNSName is resolved
+// to the root of the fixture.
+const fixtureIPNSName = "fixture"
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	var (
+		ctx     = context.Background()
+		dagServ = mdtest.Mock()
+		add     = func(node ipld.Node) {
+			t.Helper()
+			if err := dagServ.Add(ctx, node); err != nil {
+				t.Fatal(err)
+			}
+		}
+		link = func(parent *dag.ProtoNode, name string, child ipld.Node) {
+			t.Helper()
+			if err := parent.AddNodeLink(name, child); err != nil {
+				t.Fatal(err)
+			}
+		}
+		empty  = dag.NodeWithData(unixfs.FilePBData(nil, 0))
+		text   = []byte("file data")
+		file   = dag.NodeWithData(unixfs.FilePBData(text, uint64(len(text))))
+		raw    = dag.NewRawNode([]byte("raw data"))
+		nested = unixfs.EmptyDirNode()
+		root   = unixfs.EmptyDirNode()
+	)
+	for _, node := range []ipld.Node{empty, file, raw} {
+		add(node)
+	}
+	link(nested, "file", file)
+	link(nested, "raw", raw)
+	add(nested)
+	link(root, "empty", empty)
+	link(root, "file", file)
+	link(root, "nested", nested)
+	link(root, "raw", raw)
+	add(root)
+	return &fixture{
+		core: &fixtureCore{
+			dag: fixtureDag{DAGService: dagServ},
+			names: map[string]cid.Cid{
+				fixtureIPNSName: root.Cid(),
+			},
+		},
+		root: root.Cid(),
+		files: []string{
+			"empty", "file", "raw",
+			"nested/file", "nested/raw",
+		},
+	}
+}
+
+// subFiles returns the fixture's files,
+// as they're named beneath `root`.
+func (fx *fixture) subFiles(root string) []string {
+	files := make([]string, len(fx.files)+1)
+	files[0] = root
+	for i, file := range fx.files {
+		files[i+1] = root + "/" + file
+	}
+	return files
+}
+
+func (fx *fixture) newIPFS(t *testing.T) *IPFS {
+	t.Helper()
+	fsys, err := NewIPFS(fx.core)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return fsys
+}
+
+func (fx *fixture) newIPNS(t *testing.T) *IPNS {
+	t.Helper()
+	fsys, err := NewIPNS(fx.core, fx.newIPFS(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return fsys
+}
+
+// testConformance runs [fstest.TestFS] on the
+// fixture's tree, which is located at `root`.
+func testConformance(t *testing.T, fsys fs.FS, root string, files []string) {
+	t.Helper()
+	sub, err := fs.Sub(fsys, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(sub, files...); err != nil {
+		t.Error(err)
+	}
+}
+
+func testIPFSConformance(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		fsys    = fixture.newIPFS(t)
+	)
+	testConformance(t, fsys, fixture.root.String(), fixture.files)
+}
+
+func testIPNSConformance(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		fsys    = fixture.newIPNS(t)
+	)
+	testConformance(t, fsys, fixtureIPNSName, fixture.files)
+}
+
+func (fx *fixture) newPinFS(t *testing.T) *PinFS {
+	t.Helper()
+	fsys, err := NewPinFS(
+		fixturePins{pins: []cid.Cid{fx.root}},
+		WithIPFS(fx.newIPFS(t)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return fsys
+}
+
+// newKeyFS constructs a KeyFS containing
+// a single key, which refers to the fixture.
+func (fx *fixture) newKeyFS(t *testing.T, keyName string) *KeyFS {
+	t.Helper()
+	keyAPI := &stubKeyAPI{
+		keys: []coreiface.Key{
+			&stubKey{name: keyName, ipnsName: fixtureIPNSName},
+		},
+	}
+	fsys, err := NewKeyFS(keyAPI,
+		WithIPNS(fx.newIPNS(t)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return fsys
+}
+
+func testPinFSConformance(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		fsys    = fixture.newPinFS(t)
+		root    = fixture.root.String()
+	)
+	if err := fstest.TestFS(fsys, fixture.subFiles(root)...); err != nil {
+		t.Error(err)
+	}
+}
+
+func testKeyFSConformance(t *testing.T) {
+	t.Parallel()
+	const keyName = "key"
+	var (
+		fixture = newFixture(t)
+		fsys    = fixture.newKeyFS(t, keyName)
+	)
+	if err := fstest.TestFS(fsys, fixture.subFiles(keyName)...); err != nil {
+		t.Error(err)
+	}
+}
+
+// testRootSpellings asserts that the empty path is handled
+// the same as [filesystem.Root], and that "/" is rejected
+// (as required by [fs.FS]) the same way by both Open and Stat.
+func testRootSpellings(t *testing.T, fsys fs.FS) {
+	t.Helper()
+	const slash = "/"
+	if _, err := fsys.Open(slash); !errors.Is(err, filesystem.ErrPath) {
+		t.Errorf(`Open("%s"): expected "%v", got: %v`,
+			slash, filesystem.ErrPath, err,
+		)
+	}
+	if _, err := fs.Stat(fsys, slash); !errors.Is(err, filesystem.ErrPath) {
+		t.Errorf(`Stat("%s"): expected "%v", got: %v`,
+			slash, filesystem.ErrPath, err,
+		)
+	}
+	wantInfo, err := fs.Stat(fsys, filesystem.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantNames, err := readDirNames(fsys, filesystem.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"", filesystem.Root} {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Errorf(`Stat("%s"): %v`, name, err)
+			continue
+		}
+		if got, want := info.Name(), wantInfo.Name(); got != want {
+			t.Errorf(`Stat("%s"): name mismatch`+
+				"\n\tgot: %s\n\twant: %s",
+				name, got, want,
+			)
+		}
+		if got, want := info.Mode(), wantInfo.Mode(); got != want {
+			t.Errorf(`Stat("%s"): mode mismatch`+
+				"\n\tgot: %s\n\twant: %s",
+				name, got, want,
+			)
+		}
+		names, err := readDirNames(fsys, name)
+		if err != nil {
+			t.Errorf(`ReadDir("%s"): %v`, name, err)
+			continue
+		}
+		if got, want := strings.Join(names, ", "),
+			strings.Join(wantNames, ", "); got != want {
+			t.Errorf(`ReadDir("%s"): entries mismatch`+
+				"\n\tgot: [%s]\n\twant: [%s]",
+				name, got, want,
+			)
+		}
+	}
+}
+
+// readDirNames reads the directory via [fs.FS.Open],
+// so that the guest's path handling is exercised
+// (rather than the [fs.ReadDir] wrapper's).
+func readDirNames(fsys fs.FS, name string) (names []string, err error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errors.Join(err, file.Close()) }()
+	directory, ok := file.(fs.ReadDirFile)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement ReadDir", file)
+	}
+	entries, err := directory.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	names = make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func testIPFSRootSpellings(t *testing.T) {
+	t.Parallel()
+	testRootSpellings(t, newFixture(t).newIPFS(t))
+}
+
+func testIPNSRootSpellings(t *testing.T) {
+	t.Parallel()
+	testRootSpellings(t, newFixture(t).newIPNS(t))
+}
+
+func testPinFSRootSpellings(t *testing.T) {
+	t.Parallel()
+	testRootSpellings(t, newFixture(t).newPinFS(t))
+}
+
+func testKeyFSRootSpellings(t *testing.T) {
+	t.Parallel()
+	testRootSpellings(t, newFixture(t).newKeyFS(t, "key"))
+}
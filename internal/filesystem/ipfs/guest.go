@@ -0,0 +1,40 @@
+package ipfs
+
+import (
+	"encoding/json"
+	"io/fs"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+)
+
+func init() {
+	for _, guest := range []struct {
+		makeFn filesystem.MakeGuestFunc
+		id     filesystem.ID
+	}{
+		{id: IPFSID, makeFn: makeGuest[IPFSGuest]},
+		{id: IPNSID, makeFn: makeGuest[IPNSGuest]},
+		{id: KeyFSID, makeFn: makeGuest[KeyFSGuest]},
+		{id: PinFSID, makeFn: makeGuest[PinFSGuest]},
+	} {
+		if err := filesystem.RegisterGuest(guest.id, guest.makeFn); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func makeGuest[
+	T any,
+	G interface {
+		*T
+		p9fs.SystemMaker
+	},
+](data []byte,
+) (fs.FS, error) {
+	guest := G(new(T))
+	if err := json.Unmarshal(data, guest); err != nil {
+		return nil, err
+	}
+	return guest.MakeFS()
+}
@@ -0,0 +1,131 @@
+package ipfs_test
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/ipfs"
+	"github.com/multiformats/go-multiaddr"
+)
+
+func TestGuestFromJSON(t *testing.T) {
+	t.Parallel()
+	ipfsGuest := ipfs.IPFSGuest{
+		APIMaddr:            multiaddr.StringCast("/ip4/127.0.0.1/tcp/5001"),
+		APITimeout:          time.Minute,
+		NodeCacheCount:      8,
+		DirectoryCacheCount: 4,
+		CaseInsensitive:     false,
+	}
+	ipnsGuest := ipfs.IPNSGuest{
+		IPFSGuest:  ipfsGuest,
+		NodeExpiry: time.Second,
+	}
+	for _, test := range []struct {
+		guest any
+		id    filesystem.ID
+	}{
+		{id: ipfs.IPFSID, guest: &ipfsGuest},
+		{id: ipfs.IPNSID, guest: &ipnsGuest},
+		{id: ipfs.KeyFSID, guest: &ipfs.KeyFSGuest{IPNSGuest: ipnsGuest}},
+		{
+			id: ipfs.PinFSID,
+			guest: &ipfs.PinFSGuest{
+				IPFSGuest:   ipfsGuest,
+				CacheExpiry: time.Second,
+			},
+		},
+	} {
+		var (
+			id    = test.id
+			guest = test.guest
+		)
+		t.Run(string(id), func(t *testing.T) {
+			t.Parallel()
+			guestRoundTrip(t, id, guest)
+		})
+	}
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+		data := makeGuestJSON(t, "not a guest", struct{}{})
+		if _, err := filesystem.GuestFromJSON(data); err == nil {
+			t.Error("expected error for unknown guest ID")
+		}
+	})
+}
+
+func makeGuestJSON(t *testing.T, id filesystem.ID, guest any) []byte {
+	t.Helper()
+	type (
+		tag struct {
+			Guest filesystem.ID `json:"guest"`
+		}
+		data struct {
+			Guest any `json:"guest"`
+		}
+	)
+	encoded, err := json.Marshal(struct {
+		Tag  tag  `json:"tag"`
+		Data data `json:"data"`
+	}{
+		Tag:  tag{Guest: id},
+		Data: data{Guest: guest},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func guestRoundTrip(t *testing.T, id filesystem.ID, guest any) {
+	t.Helper()
+	encoded := makeGuestJSON(t, id, guest)
+	fsys, err := filesystem.GuestFromJSON(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if closer, ok := fsys.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	idFS, ok := fsys.(filesystem.IDFS)
+	if !ok {
+		t.Fatalf("%T does not implement %T", fsys, idFS)
+	}
+	if got := idFS.ID(); got != id {
+		t.Errorf("guest ID mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, id,
+		)
+	}
+	var (
+		decoded = reflect.New(reflect.TypeOf(guest).Elem()).Interface()
+		wrapped = struct {
+			Data struct {
+				Guest any `json:"guest"`
+			} `json:"data"`
+		}{}
+	)
+	wrapped.Data.Guest = decoded
+	if err := json.Unmarshal(encoded, &wrapped); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, guest) {
+		t.Errorf("guest settings mismatch"+
+			"\ngot: %#v"+
+			"\nwant: %#v",
+			decoded, guest,
+		)
+	}
+	if _, err := filesystem.GuestFromJSON(encoded[:len(encoded)/2]); err == nil {
+		t.Error("expected error for truncated input")
+	}
+}
@@ -0,0 +1,100 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	dag "github.com/ipfs/boxo/ipld/merkledag"
+	"github.com/ipfs/boxo/ipld/unixfs"
+	unixpb "github.com/ipfs/boxo/ipld/unixfs/pb"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+)
+
+// WithHAMTPrefetch fetches the shards of HAMT sharded
+// directories when they're listed, using up to `workers`
+// concurrent requests. This allows the node to retrieve
+// shards ahead of the listing, rather than one at a time
+// as the listing reaches them.
+// Shards which fail to prefetch are retrieved by the
+// listing as usual.
+// If <= 0, shards are not prefetched.
+func WithHAMTPrefetch(workers int) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		ifs.hamtPrefetch = workers
+		return nil
+	}
+}
+
+// prefetchShards walks the shards of the directory
+// (if it's sharded) until all of them have been
+// fetched, or the context is done.
+// Errors are ignored; a shard which could not
+// be fetched (and its descendants) is skipped.
+func (fsys *IPFS) prefetchShards(ctx context.Context, cid cid.Cid) {
+	root, err := fsys.getNode(cid)
+	if err != nil {
+		return
+	}
+	var (
+		limit = make(chan struct{}, fsys.hamtPrefetch)
+		wg    sync.WaitGroup
+		walk  func(ipld.Node)
+	)
+	walk = func(node ipld.Node) {
+		for _, shard := range shardLinks(node) {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case limit <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(shard *ipld.Link) {
+				defer wg.Done()
+				child, err := fsys.fetchNodeContext(ctx, shard.Cid)
+				<-limit
+				if err != nil {
+					return
+				}
+				walk(child)
+			}(shard)
+		}
+	}
+	walk(root)
+	wg.Wait()
+}
+
+// shardLinks returns the links of a HAMT shard node
+// which refer to other shards (rather than entries).
+// If the node is not a shard, nil is returned.
+func shardLinks(node ipld.Node) []*ipld.Link {
+	protoNode, ok := node.(*dag.ProtoNode)
+	if !ok {
+		return nil
+	}
+	ufsNode, err := unixfs.ExtractFSNode(protoNode)
+	if err != nil || ufsNode.Type() != unixpb.Data_HAMTShard {
+		return nil
+	}
+	// Shard links are named by their index alone, while
+	// entry links are named by their index and entry name.
+	fanout := ufsNode.Fanout()
+	if fanout == 0 {
+		return nil
+	}
+	var (
+		padLength = len(fmt.Sprintf("%X", fanout-1))
+		links     = protoNode.Links()
+		shards    = make([]*ipld.Link, 0, len(links))
+	)
+	for _, link := range links {
+		if len(link.Name) == padLength {
+			shards = append(shards, link)
+		}
+	}
+	return shards
+}
@@ -0,0 +1,256 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sync"
+	"testing"
+	"time"
+
+	dag "github.com/ipfs/boxo/ipld/merkledag"
+	mdtest "github.com/ipfs/boxo/ipld/merkledag/test"
+	"github.com/ipfs/boxo/ipld/unixfs"
+	"github.com/ipfs/boxo/ipld/unixfs/hamt"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+)
+
+// shardDAG tracks which nodes were fetched,
+// and may delay (or fail) the first fetch of a node;
+// simulating a node which has to retrieve blocks
+// from the network before it has them locally.
+type shardDAG struct {
+	ipld.DAGService
+	fetched map[cid.Cid]int
+	flaky   map[cid.Cid]bool
+	delay   time.Duration
+	mu      sync.Mutex
+}
+
+const errFlaky = hamtTestError("flaky fetch")
+
+type hamtTestError string
+
+func (e hamtTestError) Error() string { return string(e) }
+
+func newShardDAG(dagServ ipld.DAGService) *shardDAG {
+	return &shardDAG{
+		DAGService: dagServ,
+		fetched:    make(map[cid.Cid]int),
+		flaky:      make(map[cid.Cid]bool),
+	}
+}
+
+func (sd *shardDAG) Get(ctx context.Context, c cid.Cid) (ipld.Node, error) {
+	sd.mu.Lock()
+	var (
+		count = sd.fetched[c]
+		flaky = sd.flaky[c]
+	)
+	sd.fetched[c]++
+	sd.mu.Unlock()
+	if count == 0 {
+		if flaky {
+			return nil, errFlaky
+		}
+		if delay := sd.delay; delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return sd.DAGService.Get(ctx, c)
+}
+
+func (sd *shardDAG) GetMany(ctx context.Context, cids []cid.Cid) <-chan *ipld.NodeOption {
+	results := make(chan *ipld.NodeOption, len(cids))
+	var wg sync.WaitGroup
+	for _, c := range cids {
+		wg.Add(1)
+		go func(c cid.Cid) {
+			defer wg.Done()
+			node, err := sd.Get(ctx, c)
+			results <- &ipld.NodeOption{Node: node, Err: err}
+		}(c)
+	}
+	go func() { wg.Wait(); close(results) }()
+	return results
+}
+
+func (sd *shardDAG) fetchCount(c cid.Cid) int {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	return sd.fetched[c]
+}
+
+// newShardedDirectory adds a HAMT sharded directory
+// to the DAG, returning its root and the
+// shards (beneath the root) it contains.
+func newShardedDirectory(tb testing.TB, dagServ ipld.DAGService, entryCount int) (ipld.Node, []cid.Cid) {
+	tb.Helper()
+	const fanout = 16 // Small, so that shards are nested.
+	ctx := context.Background()
+	shard, err := hamt.NewShard(dagServ, fanout)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	file := dag.NodeWithData(unixfs.FilePBData(nil, 0))
+	if err := dagServ.Add(ctx, file); err != nil {
+		tb.Fatal(err)
+	}
+	for i := 0; i < entryCount; i++ {
+		if err := shard.Set(ctx, fmt.Sprintf("file%d", i), file); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	root, err := shard.Node()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := dagServ.Add(ctx, root); err != nil {
+		tb.Fatal(err)
+	}
+	var (
+		shards []cid.Cid
+		walk   func(ipld.Node)
+	)
+	walk = func(node ipld.Node) {
+		for _, link := range shardLinks(node) {
+			child, err := dagServ.Get(ctx, link.Cid)
+			if err != nil {
+				tb.Fatal(err)
+			}
+			shards = append(shards, link.Cid)
+			walk(child)
+		}
+	}
+	walk(root)
+	if len(shards) == 0 {
+		tb.Fatal("directory was not sharded")
+	}
+	return root, shards
+}
+
+func newPrefetchIPFS(tb testing.TB, shardDAG *shardDAG, workers int) *IPFS {
+	tb.Helper()
+	core := &fixtureCore{
+		dag: fixtureDag{DAGService: shardDAG},
+	}
+	fsys, err := NewIPFS(core, WithHAMTPrefetch(workers))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			tb.Error(err)
+		}
+	})
+	return fsys
+}
+
+func testIPFSHAMTPrefetch(t *testing.T) {
+	t.Parallel()
+	const (
+		entryCount = 512
+		workers    = 4
+	)
+	t.Run("shards", func(t *testing.T) {
+		t.Parallel()
+		var (
+			shardDAG     = newShardDAG(mdtest.Mock())
+			root, shards = newShardedDirectory(t, shardDAG.DAGService, entryCount)
+			fsys         = newPrefetchIPFS(t, shardDAG, workers)
+		)
+		fsys.prefetchShards(context.Background(), root.Cid())
+		for _, shard := range shards {
+			if count := shardDAG.fetchCount(shard); count != 1 {
+				t.Errorf("shard %s was fetched %d times, want 1",
+					shard, count,
+				)
+			}
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+		var (
+			shardDAG     = newShardDAG(mdtest.Mock())
+			root, shards = newShardedDirectory(t, shardDAG.DAGService, entryCount)
+			fsys         = newPrefetchIPFS(t, shardDAG, workers)
+			ctx, cancel  = context.WithCancel(context.Background())
+		)
+		cancel()
+		fsys.prefetchShards(ctx, root.Cid())
+		for _, shard := range shards {
+			if count := shardDAG.fetchCount(shard); count != 0 {
+				t.Errorf("shard %s was fetched after cancellation", shard)
+			}
+		}
+	})
+	t.Run("partial failure", func(t *testing.T) {
+		t.Parallel()
+		var (
+			shardDAG     = newShardDAG(mdtest.Mock())
+			root, shards = newShardedDirectory(t, shardDAG.DAGService, entryCount)
+			fsys         = newPrefetchIPFS(t, shardDAG, workers)
+		)
+		// The prefetch fails to fetch this shard,
+		// the listing must fetch it again (successfully).
+		shardDAG.flaky[shards[0]] = true
+		fsys.prefetchShards(context.Background(), root.Cid())
+		entries, err := fs.ReadDir(fsys, root.Cid().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(entries); got != entryCount {
+			t.Errorf("entry count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				got, entryCount,
+			)
+		}
+	})
+}
+
+func BenchmarkIPFSHAMTPrefetch(b *testing.B) {
+	const (
+		entryCount = 4096
+		delay      = time.Millisecond
+	)
+	for _, workers := range []int{0, 8, 32} {
+		workers := workers
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			var (
+				dagServ = mdtest.Mock()
+				root, _ = newShardedDirectory(b, dagServ, entryCount)
+				name    = root.Cid().String()
+			)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				// Each iteration starts "cold",
+				// with no shards available locally.
+				shardDAG := newShardDAG(dagServ)
+				shardDAG.delay = delay
+				fsys := newPrefetchIPFS(b, shardDAG, workers)
+				b.StartTimer()
+				entries, err := fs.ReadDir(fsys, name)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(entries) != entryCount {
+					b.Fatalf("entry count mismatch"+
+						"\ngot: %d"+
+						"\nwant: %d",
+						len(entries), entryCount,
+					)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package ipfs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
+	"path"
 	"strings"
 	"time"
 
@@ -28,7 +30,18 @@ type (
 	}
 	ipfsNodeCache = lru.ARCCache[cid.Cid, ipfsRecord]
 	ipfsDirCache  = lru.ARCCache[cid.Cid, []filesystem.StreamDirEntry]
-	IPFS          struct {
+	// NodeCache holds IPLD nodes (and their metadata)
+	// and may be shared by multiple file systems.
+	NodeCache struct {
+		cache *ipfsNodeCache
+		count int
+	}
+	// DirectoryCache holds directory entry-lists
+	// and may be shared by multiple file systems.
+	DirectoryCache struct {
+		cache *ipfsDirCache
+	}
+	IPFS struct {
 		ctx         context.Context
 		cancel      context.CancelFunc
 		core        coreiface.CoreAPI
@@ -37,22 +50,68 @@ type (
 		dirCache    *ipfsDirCache
 		info        nodeInfo
 		nodeTimeout time.Duration
+		// root is the directory which names are
+		// relative to (if defined); otherwise the
+		// first element of a name must be a CID.
+		root cid.Cid
+		// readdirWorkers is the number of directory
+		// children resolved concurrently during listing.
+		readdirWorkers int
+		// hamtPrefetch is the number of HAMT
+		// shards fetched concurrently during listing.
+		hamtPrefetch int
+		// nodeCacheCount is the capacity
+		// of the node cache (if enabled).
+		nodeCacheCount int
+		// contentTypes enables media type
+		// detection when files are opened.
+		contentTypes bool
+		// maxLinkTarget is the length that
+		// symbolic link targets may not exceed.
+		maxLinkTarget int
+		// dagService stores the nodes of written
+		// files (if defined); otherwise the
+		// file system is read-only.
+		dagService ipld.DAGService
+		// ipldDirectories presents maps and lists
+		// within IPLD nodes as directories.
+		ipldDirectories bool
+		// retryAttempts is the number of times
+		// requests to the node are made before
+		// failing (see [WithRetry]).
+		retryAttempts int
+		retryBackoff  time.Duration
 	}
 	ipfsSettings struct {
 		*IPFS
+		pathPrefix string
 		defaultNodeCache,
 		defaultDirCache bool
 	}
 	IPFSOption    func(*ipfsSettings) error
 	ipfsDirectory struct {
+		// NOTE: The stream's channel is populated
+		// on first use; either with entries or names.
 		stream *entryStream
+		names  <-chan coreiface.DirEntry
+		fsys   *IPFS
 		info   *nodeInfo
 		err    error
 		cid    cid.Cid
 	}
 )
 
-const IPFSID filesystem.ID = "IPFS"
+const (
+	IPFSID filesystem.ID = "IPFS"
+
+	// DefaultMaxLinkTargetLen is the length
+	// symbolic link targets are limited to, if
+	// not set via [WithMaxLinkTargetLen].
+	// Matches the common POSIX `PATH_MAX`.
+	DefaultMaxLinkTargetLen = 4096
+
+	errLinkTargetLen = generic.ConstError("link target exceeds maximum length")
+)
 
 func NewIPFS(core coreiface.CoreAPI, options ...IPFSOption) (*IPFS, error) {
 	var (
@@ -63,8 +122,9 @@ func NewIPFS(core coreiface.CoreAPI, options ...IPFSOption) (*IPFS, error) {
 				mode: fs.ModeDir |
 					readAll | executeAll,
 			},
-			core:        core,
-			nodeTimeout: 1 * time.Minute,
+			core:          core,
+			nodeTimeout:   1 * time.Minute,
+			maxLinkTarget: DefaultMaxLinkTargetLen,
 		}
 		settings = ipfsSettings{
 			IPFS:             fsys,
@@ -80,9 +140,41 @@ func NewIPFS(core coreiface.CoreAPI, options ...IPFSOption) (*IPFS, error) {
 		return nil, err
 	}
 	fsys.resolver = newPathResolver(fsys.getNode)
+	if prefix := settings.pathPrefix; prefix != "" {
+		if err := fsys.setRoot(prefix); err != nil {
+			fsys.cancel()
+			return nil, err
+		}
+	}
 	return fsys, nil
 }
 
+// setRoot resolves the prefix and
+// uses it as the file system's root.
+func (fsys *IPFS) setRoot(prefix string) error {
+	const op = "root"
+	name := strings.Trim(
+		strings.TrimPrefix(prefix, "/ipfs/"),
+		"/",
+	)
+	if !fs.ValidPath(name) || name == filesystem.Root {
+		return fserrors.New(op, prefix, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	root, err := fsys.toCID(op, name)
+	if err != nil {
+		return err
+	}
+	info, err := fsys.getInfo(name, root)
+	if err != nil {
+		return fserrors.New(op, prefix, err, fserrors.IO)
+	}
+	if !info.IsDir() {
+		return fserrors.New(op, prefix, filesystem.ErrIsNotDir, fserrors.NotDir)
+	}
+	fsys.root = root
+	return nil
+}
+
 func (settings *ipfsSettings) fillInDefaults() error {
 	if fsys := settings.IPFS; fsys.ctx == nil {
 		fsys.ctx, fsys.cancel = context.WithCancel(context.Background())
@@ -102,23 +194,51 @@ func (settings *ipfsSettings) fillInDefaults() error {
 }
 
 func (settings *ipfsSettings) initNodeCache(count int) error {
-	nodeCache, err := lru.NewARC[cid.Cid, ipfsRecord](count)
+	nodeCache, err := NewNodeCache(count)
 	if err != nil {
 		return err
 	}
-	settings.nodeCache = nodeCache
+	settings.setNodeCache(nodeCache)
 	return nil
 }
 
+func (settings *ipfsSettings) setNodeCache(nodeCache *NodeCache) {
+	settings.nodeCache = nodeCache.cache
+	settings.nodeCacheCount = nodeCache.count
+}
+
 func (settings *ipfsSettings) initDirectoryCache(count int) error {
-	dirCache, err := lru.NewARC[cid.Cid, []filesystem.StreamDirEntry](count)
+	dirCache, err := NewDirectoryCache(count)
 	if err != nil {
 		return err
 	}
-	settings.dirCache = dirCache
+	settings.dirCache = dirCache.cache
 	return nil
 }
 
+// NewNodeCache constructs a cache which
+// holds up to `count` IPLD nodes.
+func NewNodeCache(count int) (*NodeCache, error) {
+	cache, err := lru.NewARC[cid.Cid, ipfsRecord](count)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeCache{
+		cache: cache,
+		count: count,
+	}, nil
+}
+
+// NewDirectoryCache constructs a cache which
+// holds up to `count` directory entry-lists.
+func NewDirectoryCache(count int) (*DirectoryCache, error) {
+	cache, err := lru.NewARC[cid.Cid, []filesystem.StreamDirEntry](count)
+	if err != nil {
+		return nil, err
+	}
+	return &DirectoryCache{cache: cache}, nil
+}
+
 // WithNodeCacheCount sets the number of IPLD nodes the
 // file system will hold in its cache.
 // If <= 0, caching of nodes is disabled.
@@ -145,6 +265,54 @@ func WithDirectoryCacheCount(cacheCount int) IPFSOption {
 	}
 }
 
+// WithSharedNodeCache uses the cache for IPLD nodes,
+// rather than constructing one for the file system.
+// Nodes fetched by any file system using the cache
+// are available to all of them. Closing a file system
+// does not remove its nodes from the cache.
+// If nil, caching of nodes is disabled.
+func WithSharedNodeCache(cache *NodeCache) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		ifs.defaultNodeCache = false
+		if cache == nil {
+			ifs.nodeCache = nil
+			return nil
+		}
+		ifs.setNodeCache(cache)
+		return nil
+	}
+}
+
+// WithSharedDirectoryCache is like [WithSharedNodeCache]
+// but for directory entry-lists.
+// If nil, caching of entries is disabled.
+func WithSharedDirectoryCache(cache *DirectoryCache) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		ifs.defaultDirCache = false
+		if cache == nil {
+			ifs.dirCache = nil
+			return nil
+		}
+		ifs.dirCache = cache.cache
+		return nil
+	}
+}
+
+// WithPathPrefix roots the file system at the directory
+// named by prefix (e.g. "Qm.../sub" or "/ipfs/Qm.../sub"),
+// rather than the empty root; similar to [fs.Sub].
+// The prefix is resolved once, when the file system is
+// constructed. Names are then resolved relative to it.
+// File systems with a prefix should not be used by
+// overlays (such as [PinFS]), which expect names to
+// begin with a CID.
+func WithPathPrefix(prefix string) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		ifs.pathPrefix = prefix
+		return nil
+	}
+}
+
 // WithNodeTimeout sets a timeout duration to use
 // when communicating with the IPFS API/node.
 // If <= 0, operations will not time out,
@@ -156,6 +324,94 @@ func WithNodeTimeout(duration time.Duration) IPFSOption {
 	}
 }
 
+// WithRetry makes up to `attempts` requests to the
+// IPFS node when fetching nodes and listing directories;
+// waiting `backoff` before the first retry, and doubling
+// the wait before each subsequent retry.
+// Each attempt is subject to the node timeout
+// (see [WithNodeTimeout]). Errors indicating that
+// the data does not exist are not retried.
+// If <= 1, requests are made once.
+func WithRetry(attempts int, backoff time.Duration) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		ifs.retryAttempts = attempts
+		ifs.retryBackoff = backoff
+		return nil
+	}
+}
+
+// WithConcurrentReaddir resolves the nodes of directory
+// entries as they are listed, using up to `workers`
+// concurrent requests. Resolved nodes are stored in the
+// node cache, so that subsequent operations on the entries
+// (such as `stat` calls from `ls -L`) do not each require
+// a request to the node.
+// Entries are still sent in their original order.
+// If <= 0 (or node caching is disabled), entries are
+// not resolved until they are used.
+func WithConcurrentReaddir(workers int) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		ifs.readdirWorkers = workers
+		return nil
+	}
+}
+
+// WithContentTypeDetection determines the media type
+// of files when they are opened, from either their
+// name's extension, or their leading bytes.
+// The type is exposed via the file's [fs.FileInfo]
+// (see: [filesystem.ContentTypeInfo]).
+func WithContentTypeDetection(detect bool) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		ifs.contentTypes = detect
+		return nil
+	}
+}
+
+// WithIPLDAsDirectory presents IPLD nodes (such as
+// DAG-CBOR and DAG-JSON) as directories.
+// Maps and lists become directories named by their
+// keys and indices, links become symbolic links to the
+// CID they refer to, and other values become files.
+// Strings and bytes are stored as-is,
+// while other values are encoded as JSON.
+// If disabled (the default), IPLD nodes are
+// presented as files containing their raw data.
+func WithIPLDAsDirectory(enabled bool) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		ifs.ipldDirectories = enabled
+		return nil
+	}
+}
+
+// WithMaxLinkTargetLen sets the maximum length of
+// symbolic link targets. Reading a link whose
+// target is longer returns an error of kind
+// [fserrors.InvalidItem].
+// The default is [DefaultMaxLinkTargetLen].
+func WithMaxLinkTargetLen(length int) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		if length < 1 {
+			return generic.ConstError("link target length must be positive")
+		}
+		ifs.maxLinkTarget = length
+		return nil
+	}
+}
+
+// WithDagService allows files to be written
+// (see: [IPFS.OpenFile]), storing their
+// nodes within the provided service.
+func WithDagService(service ipld.DAGService) IPFSOption {
+	return func(ifs *ipfsSettings) error {
+		if service == nil {
+			return generic.ConstError("DAG service must not be nil")
+		}
+		ifs.dagService = service
+		return nil
+	}
+}
+
 func (*IPFS) ID() filesystem.ID { return IPFSID }
 
 func (fsys *IPFS) setContext(ctx context.Context) {
@@ -173,9 +429,16 @@ func (fsys *IPFS) Close() error {
 
 func (fsys *IPFS) Stat(name string) (fs.FileInfo, error) {
 	const op = "stat"
+	name = filesystem.NormalizeRoot(name)
 	if name == filesystem.Root {
 		return &fsys.info, nil
 	}
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	if fsys.ipldDirectories {
+		return fsys.statIPLD(op, name)
+	}
 	cid, err := fsys.toCID(op, name)
 	if err != nil {
 		return nil, err
@@ -194,6 +457,14 @@ func (fsys *IPFS) toCID(op, goPath string) (cid.Cid, error) {
 	// we're getting hit frequently.
 	// As such, we use the local information we have
 	// and cache + make assumptions aggressively.
+	if root := fsys.root; root.Defined() {
+		nodeCID, err := fsys.resolvePath(root.String() + "/" + goPath)
+		if err != nil {
+			kind := resolveErrKind(err)
+			return cid.Cid{}, fserrors.New(op, goPath, err, kind)
+		}
+		return nodeCID, nil
+	}
 	var (
 		names        = strings.Split(goPath, "/")
 		rootCID, err = cid.Decode(names[0])
@@ -213,19 +484,44 @@ func (fsys *IPFS) toCID(op, goPath string) (cid.Cid, error) {
 	return nodeCID, nil
 }
 
+// getInfo returns the info of the node,
+// named after the last element of `name`.
 func (fsys *IPFS) getInfo(name string, cid cid.Cid) (*nodeInfo, error) {
+	name = path.Base(name)
 	cache := fsys.nodeCache
 	if cacheDisabled := cache == nil; cacheDisabled {
 		return fsys.fetchInfo(name, cid)
 	}
+	return fsys.getCachedInfo(fsys.ctx, name, cid)
+}
+
+func (fsys *IPFS) getCachedInfo(ctx context.Context, name string, cid cid.Cid) (*nodeInfo, error) {
+	cache := fsys.nodeCache
 	record, _ := cache.Get(cid)
 	if info := record.nodeInfo; info != nil {
+		var (
+			rootInfo    = fsys.info
+			permissions = rootInfo.mode.Perm()
+		)
+		if info.name != name ||
+			!info.modTime.Equal(rootInfo.modTime) ||
+			info.mode.Perm() != permissions {
+			// The same node may be linked
+			// to, under different names.
+			// Or cached by another file system
+			// (with its own metadata).
+			renamed := *info
+			renamed.name = name
+			renamed.modTime = rootInfo.modTime
+			renamed.mode = info.mode.Type() | permissions
+			return &renamed, nil
+		}
 		return info, nil
 	}
 	node := record.Node
 	if node == nil {
 		var err error
-		if node, err = fsys.fetchNode(cid); err != nil {
+		if node, err = fsys.fetchNodeContext(ctx, cid); err != nil {
 			return nil, err
 		}
 		record.Node = node
@@ -287,16 +583,20 @@ func (fsys *IPFS) getNode(cid cid.Cid) (ipld.Node, error) {
 }
 
 func (fsys *IPFS) fetchNode(cid cid.Cid) (ipld.Node, error) {
-	ctx, cancel := fsys.nodeContext()
-	defer cancel()
-	return fsys.core.Dag().Get(ctx, cid)
+	return fsys.fetchNodeContext(fsys.ctx, cid)
 }
 
-func (fsys *IPFS) nodeContext() (context.Context, context.CancelFunc) {
-	var (
-		ctx     = fsys.ctx
-		timeout = fsys.nodeTimeout
-	)
+func (fsys *IPFS) fetchNodeContext(ctx context.Context, cid cid.Cid) (ipld.Node, error) {
+	return withRetry(ctx, fsys.retryAttempts, fsys.retryBackoff,
+		func(ctx context.Context) (ipld.Node, error) {
+			ctx, cancel := fsys.nodeContext(ctx)
+			defer cancel()
+			return fsys.core.Dag().Get(ctx, cid)
+		})
+}
+
+func (fsys *IPFS) nodeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := fsys.nodeTimeout
 	if timeout <= 0 {
 		return context.WithCancel(ctx)
 	}
@@ -314,13 +614,20 @@ func (fsys *IPFS) resolvePath(goPath string) (cid.Cid, error) {
 }
 
 func (fsys *IPFS) Open(name string) (fs.File, error) {
+	name = filesystem.NormalizeRoot(name)
 	if name == filesystem.Root {
-		return emptyRoot{info: &fsys.info}, nil
+		if root := fsys.root; root.Defined() {
+			return fsys.openDir(root, &fsys.info)
+		}
+		return &emptyRoot{info: &fsys.info}, nil
 	}
 	const op = "open"
 	if !fs.ValidPath(name) {
 		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
 	}
+	if fsys.ipldDirectories {
+		return fsys.openIPLD(op, name)
+	}
 	cid, err := fsys.toCID(op, name)
 	if err != nil {
 		return nil, err
@@ -351,20 +658,13 @@ func (fsys *IPFS) openCid(name string, cid cid.Cid) (fs.File, error) {
 }
 
 func (fsys *IPFS) openDir(cid cid.Cid, info *nodeInfo) (fs.File, error) {
-	var (
-		dirCtx, cancel = context.WithCancel(fsys.ctx)
-		entries, err   = fsys.getEntries(dirCtx, cid, info)
-	)
-	if err != nil {
-		cancel()
-		return nil, err
-	}
+	dirCtx, cancel := context.WithCancel(fsys.ctx)
 	return &ipfsDirectory{
+		fsys: fsys,
 		cid:  cid,
 		info: info,
 		stream: &entryStream{
 			Context: dirCtx, CancelFunc: cancel,
-			ch: entries,
 		},
 	}, nil
 }
@@ -375,11 +675,63 @@ func (fsys *IPFS) getEntries(ctx context.Context, cid cid.Cid, info *nodeInfo) (
 		return fsys.fetchEntries(ctx, cid, info)
 	}
 	if entries, _ := cache.Get(cid); entries != nil {
+		entries = stampEntries(entries, info)
 		return generateEntryChan(ctx, entries), nil
 	}
 	return fsys.fetchAndCacheEntries(ctx, cid, info)
 }
 
+// stampEntries applies the directory's metadata to
+// entries which may have been cached by another
+// file system (which shares the directory cache).
+// The cached slice is returned if no entries differ.
+func stampEntries(entries []filesystem.StreamDirEntry, info *nodeInfo) []filesystem.StreamDirEntry {
+	var (
+		modTime     = info.modTime
+		permissions = info.mode.Perm()
+		stamped     []filesystem.StreamDirEntry
+	)
+	for i, entry := range entries {
+		coreEntry, ok := entry.(*coreDirEntry)
+		if !ok ||
+			(coreEntry.modTime.Equal(modTime) &&
+				coreEntry.permissions == permissions) {
+			if stamped != nil {
+				stamped[i] = entry
+			}
+			continue
+		}
+		if stamped == nil {
+			stamped = make([]filesystem.StreamDirEntry, len(entries))
+			copy(stamped, entries[:i])
+		}
+		stamped[i] = &coreDirEntry{
+			DirEntry:    coreEntry.DirEntry,
+			modTime:     modTime,
+			permissions: permissions,
+		}
+	}
+	if stamped == nil {
+		return entries
+	}
+	return stamped
+}
+
+// getNames is like getEntries, but does not
+// resolve, or construct, the entries themselves.
+func (fsys *IPFS) getNames(ctx context.Context, cid cid.Cid) (<-chan coreiface.DirEntry, error) {
+	if cache := fsys.dirCache; cache != nil {
+		if entries, _ := cache.Get(cid); entries != nil {
+			return generateNameChan(ctx, entries), nil
+		}
+	}
+	var (
+		api  = fsys.core.Unixfs()
+		path = corepath.IpfsPath(cid)
+	)
+	return api.Ls(ctx, path, coreoptions.Unixfs.ResolveChildren(false))
+}
+
 func (fsys *IPFS) fetchAndCacheEntries(ctx context.Context, cid cid.Cid, info *nodeInfo) (<-chan filesystem.StreamDirEntry, error) {
 	fetchCtx, cancel := context.WithCancel(fsys.ctx)
 	fetched, err := fsys.fetchEntries(fetchCtx, cid, info)
@@ -407,11 +759,23 @@ func (fsys *IPFS) fetchEntries(ctx context.Context, cid cid.Cid, info *nodeInfo)
 	var (
 		api          = fsys.core.Unixfs()
 		path         = corepath.IpfsPath(cid)
-		entries, err = api.Ls(ctx, path, coreoptions.Unixfs.ResolveChildren(true))
+		entries, err = withRetry(ctx, fsys.retryAttempts, fsys.retryBackoff,
+			func(ctx context.Context) (<-chan coreiface.DirEntry, error) {
+				return api.Ls(ctx, path,
+					coreoptions.Unixfs.ResolveChildren(true),
+					// File sizes, rather than DAG sizes;
+					// the same as [statNode].
+					coreoptions.Unixfs.UseCumulativeSize(false),
+				)
+			})
 	)
 	if err != nil {
 		return nil, err
 	}
+	prefetchCtx, stopPrefetch := context.WithCancel(ctx)
+	if fsys.hamtPrefetch > 0 {
+		go fsys.prefetchShards(prefetchCtx, cid)
+	}
 	var (
 		modTime     = info.modTime
 		permissions = info.mode.Perm()
@@ -419,6 +783,7 @@ func (fsys *IPFS) fetchEntries(ctx context.Context, cid cid.Cid, info *nodeInfo)
 	)
 	go func() {
 		defer close(converted)
+		defer stopPrefetch()
 		for {
 			select {
 			case entry, ok := <-entries:
@@ -441,9 +806,81 @@ func (fsys *IPFS) fetchEntries(ctx context.Context, cid cid.Cid, info *nodeInfo)
 			}
 		}
 	}()
+	if fsys.readdirWorkers > 0 &&
+		fsys.nodeCache != nil {
+		return fsys.resolveEntries(ctx, converted), nil
+	}
 	return converted, nil
 }
 
+// resolveEntries fetches the node of each entry
+// (using a bounded number of concurrent requests),
+// and relays the entries in their original order.
+func (fsys *IPFS) resolveEntries(ctx context.Context,
+	entries <-chan filesystem.StreamDirEntry,
+) <-chan filesystem.StreamDirEntry {
+	type slot = chan filesystem.StreamDirEntry
+	var (
+		workers  = fsys.readdirWorkers
+		limit    = make(chan struct{}, workers)
+		pending  = make(chan slot, workers)
+		resolved = newStreamChan(entries)
+	)
+	go func() {
+		defer close(pending)
+		for entry := range entries {
+			select {
+			case limit <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			result := make(slot, 1)
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				<-limit
+				return
+			}
+			go func(entry filesystem.StreamDirEntry) {
+				defer func() { <-limit }()
+				fsys.resolveEntry(ctx, entry)
+				result <- entry
+			}(entry)
+		}
+	}()
+	go func() {
+		defer close(resolved)
+		for result := range pending {
+			select {
+			case entry := <-result:
+				select {
+				case resolved <- entry:
+					continue
+				case <-ctx.Done():
+				}
+			case <-ctx.Done():
+			}
+			drainThenSendErr(resolved, ctx.Err())
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			drainThenSendErr(resolved, err) // Dispatch was canceled.
+		}
+	}()
+	return resolved
+}
+
+// resolveEntry stores the entry's node in the cache.
+// Errors are not fatal to the listing; the
+// entry will be resolved again when it is used.
+func (fsys *IPFS) resolveEntry(ctx context.Context, entry filesystem.StreamDirEntry) {
+	coreEntry, ok := entry.(*coreDirEntry)
+	if !ok || entry.Error() != nil {
+		return
+	}
+	fsys.getCachedInfo(ctx, coreEntry.Name(), coreEntry.Cid)
+}
+
 func (fsys *IPFS) openFile(cid cid.Cid, info *nodeInfo) (fs.File, error) {
 	ipldNode, err := fsys.getNode(cid)
 	if err != nil {
@@ -451,7 +888,11 @@ func (fsys *IPFS) openFile(cid cid.Cid, info *nodeInfo) (fs.File, error) {
 	}
 	switch typedNode := ipldNode.(type) {
 	case (*cbor.Node):
-		return openCborFile(typedNode, info), nil
+		file := openCborFile(typedNode, info)
+		if fsys.contentTypes {
+			file.info.contentType = dagCborContentType
+		}
+		return file, nil
 	default:
 		var (
 			ctx = fsys.ctx
@@ -463,6 +904,16 @@ func (fsys *IPFS) openFile(cid cid.Cid, info *nodeInfo) (fs.File, error) {
 			// But this only matters when debugging anyway.
 			return nil, fserrors.New("openFile", cid.String(), err, ufsOpenErr(err))
 		}
+		if fsys.contentTypes {
+			contentType, err := detectContentType(info.name, file)
+			if err != nil {
+				return nil, errors.Join(
+					fserrors.New("openFile", cid.String(), err, fserrors.IO),
+					file.Close(),
+				)
+			}
+			file.info.contentType = contentType
+		}
 		return file, nil
 	}
 }
@@ -476,17 +927,13 @@ func (id *ipfsDirectory) Read([]byte) (int, error) {
 
 func (id *ipfsDirectory) StreamDir() <-chan filesystem.StreamDirEntry {
 	const op = "streamdir"
-	stream := id.stream
-	if stream == nil {
+	entries, err := id.entries(op)
+	if err != nil {
 		errs := make(chan filesystem.StreamDirEntry, 1)
-		// TODO: We don't have an error kind
-		// that translates into EBADF
-		errs <- newErrorEntry(
-			fserrors.New(op, id.info.name, filesystem.ErrNotOpen, fserrors.IO),
-		)
+		errs <- newErrorEntry(err)
 		return errs
 	}
-	return stream.ch
+	return entries
 }
 
 func (id *ipfsDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
@@ -494,22 +941,68 @@ func (id *ipfsDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
 	if err := id.err; err != nil {
 		return nil, err
 	}
+	entryChan, err := id.entries(op)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readEntries(id.stream.Context, entryChan, count)
+	if err != nil {
+		err = readdirErr(op, id.info.name, err)
+		id.err = err
+	}
+	return entries, err
+}
+
+// ReadDirNames shares its position with [ipfsDirectory.ReadDir]
+// only if entries were read first. Otherwise, only names
+// are fetched and subsequent calls to ReadDir will fail.
+func (id *ipfsDirectory) ReadDirNames(count int) ([]string, error) {
+	const op = "readdirnames"
+	if err := id.err; err != nil {
+		return nil, err
+	}
 	stream := id.stream
 	if stream == nil {
-		// TODO: We don't have an error kind
-		// that translates into EBADF
-		return nil, fserrors.New(op, id.info.name, filesystem.ErrNotOpen, fserrors.IO)
+		return nil, fserrors.New(op, id.info.name, filesystem.ErrNotOpen, fserrors.Closed)
 	}
-	var (
-		ctx       = stream.Context
-		entryChan = stream.ch
-	)
-	entries, err := readEntries(ctx, entryChan, count)
+	if stream.ch != nil {
+		entries, err := id.ReadDir(count)
+		return entryNames(entries), err
+	}
+	if id.names == nil {
+		names, err := id.fsys.getNames(stream.Context, id.cid)
+		if err != nil {
+			return nil, fserrors.New(op, id.info.name, err, fserrors.IO)
+		}
+		id.names = names
+	}
+	names, err := readNames(stream.Context, id.names, count)
 	if err != nil {
 		err = readdirErr(op, id.info.name, err)
 		id.err = err
 	}
-	return entries, err
+	return names, err
+}
+
+// entries returns the directory's entry stream,
+// fetching the entries on first use.
+func (id *ipfsDirectory) entries(op string) (<-chan filesystem.StreamDirEntry, error) {
+	stream := id.stream
+	if stream == nil {
+		return nil, fserrors.New(op, id.info.name, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	if entries := stream.ch; entries != nil {
+		return entries, nil
+	}
+	if id.names != nil {
+		return nil, fserrors.New(op, id.info.name, errNamesOnly, fserrors.InvalidOperation)
+	}
+	entries, err := id.fsys.getEntries(stream.Context, id.cid, id.info)
+	if err != nil {
+		return nil, fserrors.New(op, id.info.name, err, fserrors.IO)
+	}
+	stream.ch = entries
+	return entries, nil
 }
 
 func (id *ipfsDirectory) Close() error {
@@ -519,5 +1012,5 @@ func (id *ipfsDirectory) Close() error {
 		id.stream = nil
 		return nil
 	}
-	return fserrors.New(op, id.info.name, filesystem.ErrNotOpen, fserrors.InvalidItem)
+	return fserrors.New(op, id.info.name, filesystem.ErrNotOpen, fserrors.Closed)
 }
@@ -1,11 +1,30 @@
 package ipfs
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	coreiface "github.com/ipfs/boxo/coreiface"
+	coreoptions "github.com/ipfs/boxo/coreiface/options"
+	corepath "github.com/ipfs/boxo/coreiface/path"
+	files "github.com/ipfs/boxo/files"
+	dag "github.com/ipfs/boxo/ipld/merkledag"
+	mdtest "github.com/ipfs/boxo/ipld/merkledag/test"
+	"github.com/ipfs/boxo/ipld/unixfs"
+	"github.com/ipfs/boxo/ipld/unixfs/hamt"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/multiformats/go-multihash"
 )
 
 var (
@@ -15,11 +34,96 @@ var (
 	_ fs.File                  = (*ipfsDirectory)(nil)
 	_ fs.ReadDirFile           = (*ipfsDirectory)(nil)
 	_ filesystem.StreamDirFile = (*ipfsDirectory)(nil)
+
+	_ filesystem.ReadDirNamesFile = (*ipfsDirectory)(nil)
+)
+
+type (
+	// lsCore implements only the methods
+	// required to list a directory.
+	lsCore struct {
+		coreiface.CoreAPI
+		unixfs lsUnixfs
+	}
+	lsUnixfs struct {
+		coreiface.UnixfsAPI
+		entries []coreiface.DirEntry
+	}
 )
 
+func newLsCore(t testing.TB, entryCount int) *lsCore {
+	t.Helper()
+	entries := make([]coreiface.DirEntry, entryCount)
+	for i := range entries {
+		name := fmt.Sprintf("entry %d", i)
+		digest, err := multihash.Sum([]byte(name), multihash.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[i] = coreiface.DirEntry{
+			Name: name,
+			Cid:  cid.NewCidV1(cid.Raw, digest),
+			Type: coreiface.TFile,
+		}
+	}
+	return &lsCore{unixfs: lsUnixfs{entries: entries}}
+}
+
+func (core *lsCore) Unixfs() coreiface.UnixfsAPI { return core.unixfs }
+
+func (ufs lsUnixfs) Ls(ctx context.Context, _ corepath.Path,
+	_ ...coreoptions.UnixfsLsOption,
+) (<-chan coreiface.DirEntry, error) {
+	entries := make(chan coreiface.DirEntry)
+	go func() {
+		defer close(entries)
+		for _, entry := range ufs.entries {
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return entries, nil
+}
+
+func newLsIPFS(t testing.TB, entryCount int) (*IPFS, *lsCore) {
+	t.Helper()
+	core := newLsCore(t, entryCount)
+	fsys, err := NewIPFS(core,
+		WithNodeCacheCount(0),
+		WithDirectoryCacheCount(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fsys, core
+}
+
+func openLsDir(t testing.TB, fsys *IPFS) *ipfsDirectory {
+	t.Helper()
+	directory, err := fsys.openDir(cid.Undef, &nodeInfo{mode: fs.ModeDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return directory.(*ipfsDirectory)
+}
+
 func TestIPFS(t *testing.T) {
 	t.Parallel()
 	t.Run("Options", testIPFSOptions)
+	t.Run("Concurrent readdir", testIPFSConcurrentReaddir)
+	t.Run("Readdir names", testIPFSReaddirNames)
+	t.Run("Content type", testIPFSContentType)
+	t.Run("Entry count", testIPFSEntryCount)
+	t.Run("Entry info", testIPFSEntryInfo)
+	t.Run("HAMT prefetch", testIPFSHAMTPrefetch)
+	t.Run("Shared cache", testIPFSSharedCache)
+	t.Run("Conformance", testIPFSConformance)
+	t.Run("Root spellings", testIPFSRootSpellings)
+	t.Run("Path prefix", testIPFSPathPrefix)
+	t.Run("Cancel", testIPFSCancel)
 }
 
 func testIPFSOptions(t *testing.T) {
@@ -32,3 +136,627 @@ func testIPFSOptions(t *testing.T) {
 		WithPermissions[IPFSOption](0),
 	)
 }
+
+func testIPFSConcurrentReaddir(t *testing.T) {
+	t.Parallel()
+	const (
+		entryCount = 32
+		workers    = 4
+	)
+	fsys, err := NewIPFS(nil,
+		WithConcurrentReaddir(workers),
+		WithNodeCacheCount(entryCount),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, entryCount)
+	newEntries := func() <-chan filesystem.StreamDirEntry {
+		entries := make(chan filesystem.StreamDirEntry, entryCount)
+		for i := range names {
+			name := fmt.Sprintf("entry %d", i)
+			digest, err := multihash.Sum([]byte(name), multihash.SHA2_256, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entryCID := cid.NewCidV1(cid.Raw, digest)
+			// NOTE: Records are pre-populated
+			// so that the (nil) core is never called.
+			fsys.nodeCache.Add(entryCID, ipfsRecord{
+				nodeInfo: &nodeInfo{name: name},
+			})
+			names[i] = name
+			entries <- &coreDirEntry{
+				DirEntry: coreiface.DirEntry{
+					Name: name,
+					Cid:  entryCID,
+				},
+			}
+		}
+		close(entries)
+		return entries
+	}
+	t.Run("order", func(t *testing.T) {
+		var (
+			ctx      = context.Background()
+			resolved = fsys.resolveEntries(ctx, newEntries())
+			index    int
+		)
+		for entry := range resolved {
+			if err := entry.Error(); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := entry.Name(), names[index]; got != want {
+				t.Errorf("entry order mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					got, want,
+				)
+			}
+			index++
+		}
+		if index != entryCount {
+			t.Errorf("entry count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				index, entryCount,
+			)
+		}
+	})
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var lastErr error
+		for entry := range fsys.resolveEntries(ctx, newEntries()) {
+			lastErr = entry.Error()
+		}
+		if !errors.Is(lastErr, context.Canceled) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				lastErr, context.Canceled,
+			)
+		}
+	})
+}
+
+func testIPFSReaddirNames(t *testing.T) {
+	t.Parallel()
+	const entryCount = 32
+	fsys, core := newLsIPFS(t, entryCount)
+	t.Run("names", func(t *testing.T) {
+		t.Parallel()
+		directory := openLsDir(t, fsys)
+		defer directory.Close()
+		var names []string
+		for {
+			batch, err := directory.ReadDirNames(entryCount / 4)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, batch...)
+		}
+		compareNames(t, names, core.unixfs.entries)
+		if _, err := directory.ReadDir(-1); err == nil {
+			t.Error("expected ReadDir to fail after ReadDirNames")
+		}
+	})
+	t.Run("entries first", func(t *testing.T) {
+		t.Parallel()
+		directory := openLsDir(t, fsys)
+		defer directory.Close()
+		const split = entryCount / 2
+		entries, err := directory.ReadDir(split)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := directory.ReadDirNames(-1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compareNames(t, append(entryNames(entries), names...), core.unixfs.entries)
+	})
+}
+
+func compareNames(t *testing.T, got []string, entries []coreiface.DirEntry) {
+	t.Helper()
+	if len(got) != len(entries) {
+		t.Fatalf("entry count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			len(got), len(entries),
+		)
+	}
+	for i, entry := range entries {
+		if got, want := got[i], entry.Name; got != want {
+			t.Errorf("entry name mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				got, want,
+			)
+		}
+	}
+}
+
+func BenchmarkIPFSReaddir(b *testing.B) {
+	const entryCount = 4096
+	fsys, _ := newLsIPFS(b, entryCount)
+	b.Run("ReadDir", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			directory := openLsDir(b, fsys)
+			if _, err := directory.ReadDir(-1); err != nil {
+				b.Fatal(err)
+			}
+			directory.Close()
+		}
+	})
+	b.Run("ReadDirNames", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			directory := openLsDir(b, fsys)
+			if _, err := directory.ReadDirNames(-1); err != nil {
+				b.Fatal(err)
+			}
+			directory.Close()
+		}
+	})
+}
+
+func testIPFSContentType(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name, file, want string
+		data             []byte
+	}{
+		{
+			name: "png",
+			file: "image",
+			data: []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR"),
+			want: "image/png",
+		},
+		{
+			name: "html",
+			file: "page",
+			data: []byte("<!DOCTYPE html><html><body></body></html>"),
+			want: "text/html; charset=utf-8",
+		},
+		{
+			name: "gzip",
+			file: "archive",
+			data: []byte("\x1F\x8B\x08\x00\x00\x00\x00\x00"),
+			want: "application/x-gzip",
+		},
+		{
+			name: "text",
+			file: "notes",
+			data: []byte(strings.Repeat("plain text ", 100)),
+			want: "text/plain; charset=utf-8",
+		},
+		{
+			name: "empty",
+			file: "empty",
+			want: "text/plain; charset=utf-8",
+		},
+		{
+			name: "extension",
+			file: "document.html",
+			data: []byte("extension takes precedence over content"),
+			want: "text/html; charset=utf-8",
+		},
+	} {
+		var (
+			fileName = test.file
+			data     = test.data
+			want     = test.want
+		)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			file := &ufsFile{
+				File:   files.NewBytesFile(data),
+				cancel: func() {},
+				info:   nodeInfo{name: fileName},
+			}
+			defer file.Close()
+			got, err := detectContentType(fileName, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("content type mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					got, want,
+				)
+			}
+			read, err := io.ReadAll(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(read, data) {
+				t.Errorf("data mismatch after detection"+
+					"\ngot: %q"+
+					"\nwant: %q",
+					read, data,
+				)
+			}
+		})
+	}
+	t.Run("cbor", func(t *testing.T) {
+		t.Parallel()
+		fsys, err := NewIPFS(nil,
+			WithContentTypeDetection(true),
+			WithNodeCacheCount(1),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node, err := cbor.WrapObject(
+			map[string]string{"key": "value"},
+			multihash.SHA2_256, -1,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys.nodeCache.Add(node.Cid(), ipfsRecord{Node: node})
+		file, err := fsys.openFile(node.Cid(), &nodeInfo{name: t.Name()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		typed, ok := info.Sys().(filesystem.ContentTypeInfo)
+		if !ok {
+			t.Fatalf("%T does not implement %T", info.Sys(), typed)
+		}
+		if got, want := typed.ContentType(), dagCborContentType; got != want {
+			t.Errorf("content type mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				got, want,
+			)
+		}
+	})
+}
+
+func testIPFSEntryCount(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		fsys    = fixture.newIPFS(t)
+		root    = fixture.root.String()
+	)
+	for _, name := range []string{
+		root,
+		root + "/nested",
+	} {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		countInfo, ok := info.(filesystem.EntryCountInfo)
+		if !ok {
+			t.Fatalf("%T does not implement %T", info, countInfo)
+		}
+		count, ok := countInfo.EntryCount()
+		if !ok {
+			t.Fatalf("entry count not reported for \"%s\"", name)
+		}
+		entries, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := len(entries); count != want {
+			t.Errorf("entry count mismatch for \"%s\""+
+				"\ngot: %d"+
+				"\nwant: %d",
+				name, count, want,
+			)
+		}
+	}
+	t.Run("sharded", func(t *testing.T) {
+		t.Parallel()
+		var (
+			ctx     = context.Background()
+			dagServ = fixture.core.dag.DAGService
+		)
+		shard, err := hamt.NewShard(dagServ, 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := dag.NodeWithData(nil)
+		if err := dagServ.Add(ctx, file); err != nil {
+			t.Fatal(err)
+		}
+		if err := shard.Set(ctx, "file", file); err != nil {
+			t.Fatal(err)
+		}
+		node, err := shard.Node()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := dagServ.Add(ctx, node); err != nil {
+			t.Fatal(err)
+		}
+		info, err := fs.Stat(fsys, node.Cid().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.IsDir() {
+			t.Fatalf("shard is not a directory: %s", info.Mode())
+		}
+		if _, ok := info.(filesystem.EntryCountInfo).EntryCount(); ok {
+			t.Error("sharded directory reported an entry count")
+		}
+	})
+}
+
+func testIPFSEntryInfo(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx     = context.Background()
+		fixture = newFixture(t)
+		dagServ = fixture.core.dag.DAGService
+		add     = func(node ipld.Node) {
+			t.Helper()
+			if err := dagServ.Add(ctx, node); err != nil {
+				t.Fatal(err)
+			}
+		}
+	)
+	tree, err := dagServ.Get(ctx, fixture.root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linkData, err := unixfs.SymlinkData("tree/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		link = dag.NodeWithData(linkData)
+		root = unixfs.EmptyDirNode()
+	)
+	add(link)
+	for name, node := range map[string]ipld.Node{
+		"link": link,
+		"tree": tree,
+	} {
+		if err := root.AddNodeLink(name, node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add(root)
+	fsys := fixture.newIPFS(t)
+	for _, dir := range []string{
+		root.Cid().String(),
+		root.Cid().String() + "/tree",
+		root.Cid().String() + "/tree/nested",
+	} {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			name := dir + "/" + entry.Name()
+			entryInfo, err := entry.Info()
+			if err != nil {
+				t.Fatal(err)
+			}
+			statInfo, err := fs.Stat(fsys, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := entryInfo.Size(), statInfo.Size(); got != want {
+				t.Errorf("size mismatch for \"%s\""+
+					"\ngot: %d"+
+					"\nwant: %d",
+					name, got, want,
+				)
+			}
+			if got, want := entryInfo.Mode(), statInfo.Mode(); got != want {
+				t.Errorf("mode mismatch for \"%s\""+
+					"\ngot: %s"+
+					"\nwant: %s",
+					name, got, want,
+				)
+			}
+		}
+	}
+}
+
+func testIPFSPathPrefix(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		root    = fixture.root.String()
+		nested  = root + "/nested"
+	)
+	for _, prefix := range []string{
+		nested,
+		"/ipfs/" + nested + "/",
+	} {
+		fsys, err := NewIPFS(fixture.core, WithPathPrefix(prefix))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := fs.ReadDir(fsys, filesystem.Root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var (
+			got  = make([]string, len(entries))
+			want = []string{"file", "raw"}
+		)
+		for i, entry := range entries {
+			got[i] = entry.Name()
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("root entries mismatch for \"%s\""+
+				"\ngot: %v"+
+				"\nwant: %v",
+				prefix, got, want,
+			)
+		}
+		if err := fstest.TestFS(fsys, want...); err != nil {
+			t.Error(err)
+		}
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+	for _, prefix := range []string{
+		root + "/file",
+		root + "/missing",
+		"/",
+	} {
+		fsys, err := NewIPFS(fixture.core, WithPathPrefix(prefix))
+		if err == nil {
+			fsys.Close()
+			t.Errorf("expected error for prefix \"%s\", got nil", prefix)
+		}
+	}
+}
+
+func testIPFSCancel(t *testing.T) {
+	t.Parallel()
+	const (
+		timeout  = 5 * time.Second
+		interval = time.Millisecond
+	)
+	var (
+		dagServ     = mdtest.Mock()
+		shardDAG    = newShardDAG(dagServ)
+		file        = dag.NodeWithData(unixfs.FilePBData(nil, 0))
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+	defer cancel()
+	if err := dagServ.Add(ctx, file); err != nil {
+		t.Fatal(err)
+	}
+	// NOTE: Longer than the test should ever take;
+	// only cancellation should unblock the fetch.
+	shardDAG.delay = time.Hour
+	fsys, err := NewIPFS(
+		&fixtureCore{dag: fixtureDag{DAGService: shardDAG}},
+		WithContext[IPFSOption](ctx),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fsys.Close()
+	opened := make(chan error, 1)
+	go func() {
+		file, err := fsys.Open(file.Cid().String())
+		if err == nil {
+			file.Close()
+		}
+		opened <- err
+	}()
+	deadline := time.Now().Add(timeout)
+	for shardDAG.fetchCount(file.Cid()) == 0 &&
+		time.Now().Before(deadline) {
+		time.Sleep(interval)
+	}
+	cancel()
+	select {
+	case err := <-opened:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, context.Canceled,
+			)
+		}
+	case <-time.After(timeout):
+		t.Fatal("open did not return after guest context was canceled")
+	}
+}
+
+func testIPFSSharedCache(t *testing.T) {
+	t.Parallel()
+	const cacheCount = 64
+	nodeCache, err := NewNodeCache(cacheCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirCache, err := NewDirectoryCache(cacheCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		fixture = newFixture(t)
+		root    = fixture.root
+		newFS   = func(permissions fs.FileMode) *IPFS {
+			t.Helper()
+			fsys, err := NewIPFS(fixture.core,
+				WithSharedNodeCache(nodeCache),
+				WithSharedDirectoryCache(dirCache),
+				WithPermissions[IPFSOption](permissions),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return fsys
+		}
+		first  = newFS(0o700)
+		second = newFS(0o555)
+	)
+	t.Cleanup(func() {
+		if err := second.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	if err := first.warm(context.Background(), root, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Entries cached by the first file system
+	// must remain after it's closed, and be
+	// presented with the second's metadata.
+	if _, ok := nodeCache.cache.Peek(root); !ok {
+		t.Fatal("node was not retained after close")
+	}
+	if _, ok := dirCache.cache.Peek(root); !ok {
+		t.Fatal("entries were not retained after close")
+	}
+	const wantPermissions = 0o555
+	entries, err := fs.ReadDir(second, root.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("expected entries from shared cache")
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != wantPermissions {
+			t.Errorf("entry \"%s\" permissions mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				entry.Name(), got, fs.FileMode(wantPermissions),
+			)
+		}
+	}
+	info, err := fs.Stat(second, root.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != wantPermissions {
+		t.Errorf("root permissions mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, fs.FileMode(wantPermissions),
+		)
+	}
+}
@@ -0,0 +1,381 @@
+package ipfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	dag "github.com/ipfs/boxo/ipld/merkledag"
+	"github.com/ipfs/boxo/ipld/unixfs"
+	unixpb "github.com/ipfs/boxo/ipld/unixfs/pb"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipfs/go-ipld-format"
+)
+
+type (
+	// ipldValue is the result of resolving
+	// a name through IPLD nodes.
+	ipldValue struct {
+		// value is only valid if `inNode` is set.
+		// Otherwise, the name referred to a node
+		// which is not navigated via IPLD (e.g. UnixFS).
+		value  any
+		node   cid.Cid
+		inNode bool
+	}
+	// ipldDirectory presents a map or list,
+	// within an IPLD node, as a directory.
+	ipldDirectory struct {
+		fsys   *IPFS
+		info   *nodeInfo
+		names  []string
+		values []any
+		closed bool
+	}
+	ipldDirEntry struct{ *nodeInfo }
+)
+
+const errNotLink = generic.ConstError("not a symbolic link")
+
+// isIPLDNode reports whether the node should be
+// navigated via its IPLD data model rather than UnixFS.
+func isIPLDNode(node ipld.Node) bool {
+	switch node.(type) {
+	case *dag.ProtoNode, *dag.RawNode:
+		return false
+	default:
+		return true
+	}
+}
+
+// resolveIPLD resolves the name through IPLD nodes,
+// following links within them.
+func (fsys *IPFS) resolveIPLD(op, goPath string) (ipldValue, error) {
+	var (
+		names   = strings.Split(goPath, "/")
+		nodeCID cid.Cid
+	)
+	if root := fsys.root; root.Defined() {
+		nodeCID = root
+	} else {
+		rootCID, err := cid.Decode(names[0])
+		if err != nil {
+			kind := cidErrKind(err)
+			return ipldValue{}, fserrors.New(op, goPath, err, kind)
+		}
+		nodeCID, names = rootCID, names[1:]
+	}
+	for {
+		node, err := fsys.getNode(nodeCID)
+		if err != nil {
+			return ipldValue{}, fserrors.New(op, goPath, err, fserrors.IO)
+		}
+		if !isIPLDNode(node) {
+			if len(names) != 0 {
+				remainder := nodeCID.String() + "/" + strings.Join(names, "/")
+				if nodeCID, err = fsys.resolvePath(remainder); err != nil {
+					kind := resolveErrKind(err)
+					return ipldValue{}, fserrors.New(op, goPath, err, kind)
+				}
+			}
+			return ipldValue{node: nodeCID}, nil
+		}
+		value, remainder, err := node.Resolve(names)
+		if err != nil {
+			kind := ipldErrKind(err)
+			return ipldValue{}, fserrors.New(op, goPath, err, kind)
+		}
+		if link, ok := value.(*ipld.Link); ok && len(remainder) != 0 {
+			nodeCID, names = link.Cid, remainder
+			continue
+		}
+		return ipldValue{
+			value:  value,
+			node:   nodeCID,
+			inNode: true,
+		}, nil
+	}
+}
+
+func ipldErrKind(err error) fserrors.Kind {
+	if errors.Is(err, cbor.ErrNoLinks) {
+		return fserrors.NotDir
+	}
+	return fserrors.NotExist
+}
+
+// ipldLink returns the target of the value
+// if it is a link.
+func ipldLink(value any) (cid.Cid, bool) {
+	switch typed := value.(type) {
+	case *ipld.Link:
+		return typed.Cid, true
+	case cid.Cid:
+		return typed, true
+	case map[string]any:
+		// DAG-JSON's link form: `{"/": "CID"}`.
+		if len(typed) != 1 {
+			break
+		}
+		if encoded, ok := typed["/"].(string); ok {
+			if target, err := cid.Decode(encoded); err == nil {
+				return target, true
+			}
+		}
+	}
+	return cid.Undef, false
+}
+
+// ipldChildren returns the sorted names of a map's keys
+// or a list's indices, along with their values.
+// Keys which are not valid file names are omitted.
+func ipldChildren(value any) ([]string, []any, bool) {
+	var fields map[string]any
+	switch typed := value.(type) {
+	case []any:
+		names := make([]string, len(typed))
+		for i := range typed {
+			names[i] = strconv.Itoa(i)
+		}
+		return names, typed, true
+	case map[string]any:
+		fields = typed
+	case map[any]any:
+		fields = make(map[string]any, len(typed))
+		for key, value := range typed {
+			if name, ok := key.(string); ok {
+				fields[name] = value
+			}
+		}
+	default:
+		return nil, nil, false
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if isIPLDName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	values := make([]any, len(names))
+	for i, name := range names {
+		values[i] = fields[name]
+	}
+	return names, values, true
+}
+
+func isIPLDName(name string) bool {
+	return name != "." &&
+		!strings.Contains(name, "/") &&
+		fs.ValidPath(name)
+}
+
+// ipldData returns the file contents of a scalar value.
+// Strings and bytes are returned as-is,
+// other values are encoded as JSON.
+func ipldData(value any) ([]byte, error) {
+	switch typed := value.(type) {
+	case string:
+		return []byte(typed), nil
+	case []byte:
+		return typed, nil
+	default:
+		return json.Marshal(value)
+	}
+}
+
+func (fsys *IPFS) ipldInfo(name string, value any) (*nodeInfo, error) {
+	info := &nodeInfo{
+		name:    name,
+		modTime: fsys.info.modTime,
+		mode:    fsys.info.mode.Perm(),
+	}
+	if target, ok := ipldLink(value); ok {
+		info.mode |= fs.ModeSymlink
+		info.cid = target
+		info.size = int64(len(target.String()))
+		return info, nil
+	}
+	if names, _, ok := ipldChildren(value); ok {
+		info.mode |= fs.ModeDir
+		info.entryCount = len(names)
+		info.entryCounted = true
+		return info, nil
+	}
+	data, err := ipldData(value)
+	if err != nil {
+		return nil, err
+	}
+	info.size = int64(len(data))
+	return info, nil
+}
+
+func (fsys *IPFS) statIPLD(op, name string) (fs.FileInfo, error) {
+	resolved, err := fsys.resolveIPLD(op, name)
+	if err != nil {
+		return nil, err
+	}
+	var info *nodeInfo
+	if resolved.inNode {
+		info, err = fsys.ipldInfo(path.Base(name), resolved.value)
+	} else {
+		info, err = fsys.getInfo(name, resolved.node)
+	}
+	if err != nil {
+		return nil, fserrors.New(op, name, err, fserrors.IO)
+	}
+	return info, nil
+}
+
+func (fsys *IPFS) openIPLD(op, name string) (fs.File, error) {
+	resolved, err := fsys.resolveIPLD(op, name)
+	if err != nil {
+		return nil, err
+	}
+	var file fs.File
+	if resolved.inNode {
+		file, err = fsys.openIPLDValue(path.Base(name), resolved.value)
+	} else {
+		file, err = fsys.openCid(name, resolved.node)
+	}
+	if err != nil {
+		return nil, fserrors.New(op, name, err, fserrors.IO)
+	}
+	return file, nil
+}
+
+func (fsys *IPFS) openIPLDValue(name string, value any) (fs.File, error) {
+	info, err := fsys.ipldInfo(name, value)
+	if err != nil {
+		return nil, err
+	}
+	switch typ := info.mode.Type(); typ {
+	case fs.FileMode(0):
+		data, err := ipldData(value)
+		if err != nil {
+			return nil, err
+		}
+		return &cborFile{
+			reader: bytes.NewReader(data),
+			info:   *info,
+		}, nil
+	case fs.ModeDir:
+		names, values, _ := ipldChildren(value)
+		return &ipldDirectory{
+			fsys:   fsys,
+			info:   info,
+			names:  names,
+			values: values,
+		}, nil
+	default:
+		return nil, fmt.Errorf(
+			"%w got: \"%s\" want: regular file or directory",
+			errUnexpectedType, fsTypeName(typ),
+		)
+	}
+}
+
+// Readlink returns the target of a symbolic link.
+// Links within IPLD nodes (see: [WithIPLDAsDirectory])
+// refer to the CID of the linked node.
+func (fsys *IPFS) Readlink(name string) (string, error) {
+	const op = "readlink"
+	name = filesystem.NormalizeRoot(name)
+	if name == filesystem.Root || !fs.ValidPath(name) {
+		return "", fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	var nodeCID cid.Cid
+	if fsys.ipldDirectories {
+		resolved, err := fsys.resolveIPLD(op, name)
+		if err != nil {
+			return "", err
+		}
+		if resolved.inNode {
+			if target, ok := ipldLink(resolved.value); ok {
+				return fsys.checkLinkTarget(op, name, target.String())
+			}
+			return "", fserrors.New(op, name, errNotLink, fserrors.InvalidItem)
+		}
+		nodeCID = resolved.node
+	} else {
+		var err error
+		if nodeCID, err = fsys.toCID(op, name); err != nil {
+			return "", err
+		}
+	}
+	node, err := fsys.getNode(nodeCID)
+	if err != nil {
+		return "", fserrors.New(op, name, err, fserrors.IO)
+	}
+	if protoNode, ok := node.(*dag.ProtoNode); ok {
+		ufsNode, err := unixfs.ExtractFSNode(protoNode)
+		if err == nil && ufsNode.Type() == unixpb.Data_Symlink {
+			return fsys.checkLinkTarget(op, name, string(ufsNode.Data()))
+		}
+	}
+	return "", fserrors.New(op, name, errNotLink, fserrors.InvalidItem)
+}
+
+// checkLinkTarget returns `target` if it
+// does not exceed the maximum length.
+func (fsys *IPFS) checkLinkTarget(op, name, target string) (string, error) {
+	if len(target) > fsys.maxLinkTarget {
+		return "", fserrors.New(op, name, errLinkTargetLen, fserrors.InvalidItem)
+	}
+	return target, nil
+}
+
+func (id *ipldDirectory) Stat() (fs.FileInfo, error) { return id.info, nil }
+
+func (id *ipldDirectory) Read([]byte) (int, error) {
+	const op = "read"
+	return -1, fserrors.New(op, id.info.name, filesystem.ErrIsDir, fserrors.IsDir)
+}
+
+func (id *ipldDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
+	const op = "readdir"
+	if id.closed {
+		return nil, fserrors.New(op, id.info.name, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	remaining := len(id.names)
+	if count <= 0 {
+		count = remaining
+	} else if remaining == 0 {
+		return nil, io.EOF
+	}
+	count = generic.Min(count, remaining)
+	entries := make([]fs.DirEntry, count)
+	for i := range entries {
+		info, err := id.fsys.ipldInfo(id.names[i], id.values[i])
+		if err != nil {
+			return nil, fserrors.New(op, id.info.name, err, fserrors.IO)
+		}
+		entries[i] = ipldDirEntry{nodeInfo: info}
+	}
+	id.names, id.values = id.names[count:], id.values[count:]
+	return entries, nil
+}
+
+func (id *ipldDirectory) Close() error {
+	const op = "close"
+	if id.closed {
+		return fserrors.New(op, id.info.name, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	id.closed = true
+	id.names, id.values = nil, nil
+	return nil
+}
+
+func (de ipldDirEntry) Info() (fs.FileInfo, error) { return de.nodeInfo, nil }
+func (de ipldDirEntry) Type() fs.FileMode          { return de.nodeInfo.Mode().Type() }
@@ -0,0 +1,297 @@
+package ipfs
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	dag "github.com/ipfs/boxo/ipld/merkledag"
+	"github.com/ipfs/boxo/ipld/unixfs"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/multiformats/go-multihash"
+)
+
+var (
+	_ fs.ReadDirFile        = (*ipldDirectory)(nil)
+	_ filesystem.ReadlinkFS = (*IPFS)(nil)
+)
+
+func TestIPLD(t *testing.T) {
+	t.Parallel()
+	var (
+		leaf = wrapIPLD(t, map[string]any{
+			"leaf": "value",
+		})
+		root = wrapIPLD(t, map[string]any{
+			"text":    "hello",
+			"number":  42,
+			"list":    []any{"a", true},
+			"nested":  map[string]any{"inner": "deep"},
+			"link":    leaf.Cid(),
+			"bad/key": "omitted",
+		})
+		newFS = func(t *testing.T, enabled bool) *IPFS {
+			t.Helper()
+			fsys, err := NewIPFS(nil,
+				WithIPLDAsDirectory(enabled),
+				WithNodeCacheCount(2),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, node := range []*cbor.Node{leaf, root} {
+				fsys.nodeCache.Add(node.Cid(), ipfsRecord{Node: node})
+			}
+			return fsys
+		}
+		rootName = root.Cid().String()
+	)
+	t.Run("entries", func(t *testing.T) {
+		t.Parallel()
+		testIPLDEntries(t, newFS(t, true), rootName)
+	})
+	t.Run("files", func(t *testing.T) {
+		t.Parallel()
+		testIPLDFiles(t, newFS(t, true), rootName)
+	})
+	t.Run("links", func(t *testing.T) {
+		t.Parallel()
+		testIPLDLinks(t, newFS(t, true), rootName, leaf.Cid())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		info, err := fs.Stat(newFS(t, false), rootName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.Mode().IsRegular() {
+			t.Errorf("node should be a file when disabled, got: %s", info.Mode())
+		}
+	})
+}
+
+func wrapIPLD(t *testing.T, obj any) *cbor.Node {
+	t.Helper()
+	node, err := cbor.WrapObject(obj, multihash.SHA2_256, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func testIPLDEntries(t *testing.T, fsys fs.FS, root string) {
+	for _, test := range []struct {
+		name string
+		want []string
+	}{
+		{name: root, want: []string{"link", "list", "nested", "number", "text"}},
+		{name: root + "/list", want: []string{"0", "1"}},
+		{name: root + "/nested", want: []string{"inner"}},
+	} {
+		entries, err := fs.ReadDir(fsys, test.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := entryNames(entries)
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("entries mismatch for \"%s\""+
+				"\ngot: %v"+
+				"\nwant: %v",
+				test.name, got, test.want,
+			)
+		}
+	}
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter, ok := info.(filesystem.EntryCountInfo)
+	if !ok {
+		t.Fatalf("%T does not implement %T", info, counter)
+	}
+	if count, counted := counter.EntryCount(); !counted || count != 5 {
+		t.Errorf("entry count mismatch"+
+			"\ngot: %d (counted: %t)"+
+			"\nwant: %d",
+			count, counted, 5,
+		)
+	}
+}
+
+func testIPLDFiles(t *testing.T, fsys fs.FS, root string) {
+	for _, test := range []struct {
+		name, want string
+	}{
+		{name: "text", want: "hello"},
+		{name: "number", want: "42"},
+		{name: "list/0", want: "a"},
+		{name: "list/1", want: "true"},
+		{name: "nested/inner", want: "deep"},
+		{name: "link/leaf", want: "value"},
+	} {
+		name := root + "/" + test.name
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != test.want {
+			t.Errorf("data mismatch for \"%s\""+
+				"\ngot: %s"+
+				"\nwant: %s",
+				test.name, got, test.want,
+			)
+		}
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := info.Size(), int64(len(test.want)); got != want {
+			t.Errorf("size mismatch for \"%s\""+
+				"\ngot: %d"+
+				"\nwant: %d",
+				test.name, got, want,
+			)
+		}
+	}
+	for _, name := range []string{
+		"missing",
+		"list/2",
+		"text/child",
+	} {
+		if _, err := fs.Stat(fsys, root+"/"+name); err == nil {
+			t.Errorf("expected error for \"%s\", got nil", name)
+		}
+	}
+	_, err := fs.Stat(fsys, root+"/missing")
+	var fsErr *fserrors.Error
+	if !errors.As(err, &fsErr) ||
+		fsErr.Kind != fserrors.NotExist {
+		t.Errorf("unexpected error for missing key"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, fserrors.NotExist,
+		)
+	}
+}
+
+func testIPLDLinks(t *testing.T, fsys *IPFS, root string, target cid.Cid) {
+	name := root + "/link"
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Type(); got != fs.ModeSymlink {
+		t.Errorf("link type mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, fs.ModeSymlink,
+		)
+	}
+	link, err := fsys.Readlink(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := target.String(); link != want {
+		t.Errorf("link target mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			link, want,
+		)
+	}
+	if _, err := fsys.Open(name); err == nil {
+		t.Error("expected error when opening link, got nil")
+	}
+	if _, err := fsys.Readlink(root + "/text"); err == nil {
+		t.Error("expected error when reading non-link, got nil")
+	}
+}
+
+func TestLinkTargetLength(t *testing.T) {
+	t.Parallel()
+	const targetLength = 64
+	var (
+		target      = strings.Repeat("a", targetLength)
+		symlinkData = func() []byte {
+			data, err := unixfs.SymlinkData(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return data
+		}()
+		symlink = dag.NodeWithData(symlinkData)
+		leaf    = wrapIPLD(t, map[string]any{"leaf": "value"})
+		root    = wrapIPLD(t, map[string]any{"link": leaf.Cid()})
+		links   = []struct {
+			name, target string
+			nodes        []ipld.Node
+			ipld         bool
+		}{
+			{
+				name:   symlink.Cid().String(),
+				target: target,
+				nodes:  []ipld.Node{symlink},
+			},
+			{
+				name:   root.Cid().String() + "/link",
+				target: leaf.Cid().String(),
+				nodes:  []ipld.Node{leaf, root},
+				ipld:   true,
+			},
+		}
+	)
+	for _, link := range links {
+		length := len(link.target)
+		for _, test := range []struct {
+			maximum int
+			valid   bool
+		}{
+			{maximum: length + 1, valid: true},
+			{maximum: length, valid: true},
+			{maximum: length - 1, valid: false},
+		} {
+			fsys, err := NewIPFS(nil,
+				WithMaxLinkTargetLen(test.maximum),
+				WithIPLDAsDirectory(link.ipld),
+				WithNodeCacheCount(len(link.nodes)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, node := range link.nodes {
+				fsys.nodeCache.Add(node.Cid(), ipfsRecord{Node: node})
+			}
+			got, err := fsys.Readlink(link.name)
+			if test.valid {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != link.target {
+					t.Errorf("link target mismatch"+
+						"\ngot: %s"+
+						"\nwant: %s",
+						got, link.target,
+					)
+				}
+				continue
+			}
+			var fsErr *fserrors.Error
+			if !errors.As(err, &fsErr) ||
+				fsErr.Kind != fserrors.InvalidItem {
+				t.Errorf("unexpected error for target length %d (maximum %d)"+
+					"\ngot: %v"+
+					"\nwant: %v",
+					length, test.maximum,
+					err, fserrors.InvalidItem,
+				)
+			}
+		}
+	}
+	if _, err := NewIPFS(nil, WithMaxLinkTargetLen(0)); err == nil {
+		t.Error("expected non-positive maximum to be rejected")
+	}
+}
@@ -46,6 +46,7 @@ type (
 	ipnsFile   struct {
 		file      fs.File
 		refreshFn func() error
+		name      string
 	}
 )
 
@@ -151,14 +152,41 @@ func (fsys *IPNS) Close() error {
 
 func (fsys *IPNS) Stat(name string) (fs.FileInfo, error) {
 	const op = "stat"
+	name = filesystem.NormalizeRoot(name)
 	if name == filesystem.Root {
 		return &fsys.info, nil
 	}
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
 	cid, err := fsys.toCID(op, name)
 	if err != nil {
 		return nil, err
 	}
-	return fs.Stat(fsys.ipfs, cid.String())
+	info, err := fs.Stat(fsys.ipfs, cid.String())
+	if err != nil {
+		return nil, err
+	}
+	return renameInfo(info, name), nil
+}
+
+// Readlink returns the target of a symbolic link.
+// (See: [IPFS.Readlink].)
+func (fsys *IPNS) Readlink(name string) (string, error) {
+	const op = "readlink"
+	name = filesystem.NormalizeRoot(name)
+	if name == filesystem.Root || !fs.ValidPath(name) {
+		return "", fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	cid, err := fsys.toCID(op, name)
+	if err != nil {
+		return "", err
+	}
+	linker, ok := fsys.ipfs.(filesystem.ReadlinkFS)
+	if !ok {
+		return "", fserrors.New(op, name, fserrors.ErrUnsupported, fserrors.InvalidOperation)
+	}
+	return linker.Readlink(cid.String())
 }
 
 func (fsys *IPNS) toCID(op, goPath string) (cid.Cid, error) {
@@ -178,7 +206,7 @@ func (fsys *IPNS) toCID(op, goPath string) (cid.Cid, error) {
 			ctx, cancel = fsys.nodeContext()
 		)
 		defer cancel()
-		if rootCID, err = fsys.fetchCID(ctx, goPath); err != nil {
+		if rootCID, err = fsys.fetchCID(ctx, root); err != nil {
 			kind := resolveErrKind(err)
 			return cid.Cid{}, fserrors.New(op, goPath, err, kind)
 		}
@@ -252,8 +280,9 @@ func (fsys *IPNS) fetchCID(ctx context.Context, goPath string) (cid.Cid, error)
 }
 
 func (fsys *IPNS) Open(name string) (fs.File, error) {
+	name = filesystem.NormalizeRoot(name)
 	if name == filesystem.Root {
-		return emptyRoot{info: &fsys.info}, nil
+		return &emptyRoot{info: &fsys.info}, nil
 	}
 	const op = "open"
 	if !fs.ValidPath(name) {
@@ -270,6 +299,7 @@ func (fsys *IPNS) Open(name string) (fs.File, error) {
 	}
 	nFile := ipnsFile{
 		file: file,
+		name: name,
 	}
 	nFile.refreshFn = func() error {
 		fetchedCID, err := fsys.toCID(op, name)
@@ -383,16 +413,40 @@ func formatSeekerErr(
 	return errors.New(b.String())
 }
 
-func (nf *ipnsFile) Close() error { return nf.file.Close() }
+func (nf *ipnsFile) Close() error {
+	const op = "close"
+	file := nf.file
+	if file == nil {
+		return fserrors.New(op, nf.name, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	nf.file = nil
+	return file.Close()
+}
+
+// refresh checks that the file is still open,
+// then updates it if the name's target changed.
+func (nf *ipnsFile) refresh(op string) error {
+	if nf.file == nil {
+		return fserrors.New(op, nf.name, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	return nf.refreshFn()
+}
+
 func (nf *ipnsFile) Stat() (fs.FileInfo, error) {
-	if err := nf.refreshFn(); err != nil {
+	const op = "stat"
+	if err := nf.refresh(op); err != nil {
+		return nil, err
+	}
+	info, err := nf.file.Stat()
+	if err != nil {
 		return nil, err
 	}
-	return nf.file.Stat()
+	return renameInfo(info, nf.name), nil
 }
 
 func (nf *ipnsFile) Seek(offset int64, whence int) (int64, error) {
-	if err := nf.refreshFn(); err != nil {
+	const op = "seek"
+	if err := nf.refresh(op); err != nil {
 		return 0, err
 	}
 	if seeker, ok := nf.file.(io.Seeker); ok {
@@ -402,14 +456,16 @@ func (nf *ipnsFile) Seek(offset int64, whence int) (int64, error) {
 }
 
 func (nf *ipnsFile) Read(b []byte) (int, error) {
-	if err := nf.refreshFn(); err != nil {
+	const op = "read"
+	if err := nf.refresh(op); err != nil {
 		return 0, err
 	}
 	return nf.file.Read(b)
 }
 
 func (nf *ipnsFile) ReadDir(count int) ([]fs.DirEntry, error) {
-	if err := nf.refreshFn(); err != nil {
+	const op = "readdir"
+	if err := nf.refresh(op); err != nil {
 		return nil, err
 	}
 	// TODO: these kinds of things should
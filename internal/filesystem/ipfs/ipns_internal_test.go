@@ -21,6 +21,8 @@ var (
 func TestIPNS(t *testing.T) {
 	t.Parallel()
 	t.Run("Options", testIPNSOptions)
+	t.Run("Conformance", testIPNSConformance)
+	t.Run("Root spellings", testIPNSRootSpellings)
 }
 
 func testIPNSOptions(t *testing.T) {
@@ -5,22 +5,31 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"os"
 	"path"
 	"strings"
 	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
 	coreiface "github.com/ipfs/boxo/coreiface"
+	"github.com/ipfs/go-cid"
 )
 
 type (
 	KeyFS struct {
-		keyAPI      coreiface.KeyAPI
-		ipns        fs.FS
-		ctx         context.Context
-		cancel      context.CancelFunc
+		keyAPI coreiface.KeyAPI
+		ipns   fs.FS
+		ctx    context.Context
+		cancel context.CancelFunc
+		// publisher publishes keys after their
+		// files are written to (if enabled).
+		publisher   *keyPublisher
 		permissions fs.FileMode
+		// proxyDisabled prevents names which are
+		// not keys, from being forwarded to IPNS.
+		proxyDisabled bool
 	}
 	KeyFSOption  func(*KeyFS) error
 	keyDirectory struct {
@@ -34,18 +43,72 @@ type (
 		ipns        fs.FS
 		permissions fs.FileMode
 	}
+	// keyTarget is the location of
+	// a write beneath a key.
+	keyTarget struct {
+		ipfs    *IPFS
+		keyName string
+		keyPath string
+		root    cid.Cid
+	}
+	// keyWriter schedules its key to
+	// be published when it's closed.
+	keyWriter struct {
+		*ipfsWriter
+		publisher *keyPublisher
+		keyName   string
+	}
+	// keyFile reports the name of the key
+	// it was opened by, rather than the
+	// name that the key resolved to.
+	keyFile struct {
+		fs.File
+		name string
+	}
 	keyInfo struct { // TODO: roll into keyDirEntry?
 		name string
 		mode fs.FileMode // Without the type, this is only really useful for move+delete permissions.
 	}
 )
 
-const KeyFSID filesystem.ID = "KeyFS"
+const (
+	KeyFSID filesystem.ID = "KeyFS"
+
+	errNotKey        = generic.ConstError("not a key")
+	errTargetEscapes = generic.ConstError("link target is outside of the key")
+)
 
 func WithIPNS(ipns fs.FS) KeyFSOption {
 	return func(ka *KeyFS) error { ka.ipns = ipns; return nil }
 }
 
+// WithProxyDisabled restricts the file system
+// to the node's keys. Paths whose first component
+// is not the name of a key will not be resolved
+// via IPNS, and are instead reported as not existing.
+func WithProxyDisabled(disabled bool) KeyFSOption {
+	return func(ka *KeyFS) error { ka.proxyDisabled = disabled; return nil }
+}
+
+// WithAutoPublish allows files beneath keys to be
+// written to (see: [KeyFS.OpenFile]). Once a key's files
+// have not been written to for `interval`, the key is
+// published with its new root via the name API.
+// Pending publishes are flushed when
+// the file system is closed.
+func WithAutoPublish(names coreiface.NameAPI, interval time.Duration) KeyFSOption {
+	return func(ka *KeyFS) error {
+		if names == nil {
+			return generic.ConstError("name API must not be nil")
+		}
+		if interval <= 0 {
+			return generic.ConstError("publish interval must be positive")
+		}
+		ka.publisher = newKeyPublisher(names, interval)
+		return nil
+	}
+}
+
 func NewKeyFS(core coreiface.KeyAPI, options ...KeyFSOption) (*KeyFS, error) {
 	fsys := &KeyFS{
 		permissions: readAll | executeAll,
@@ -59,6 +122,10 @@ func NewKeyFS(core coreiface.KeyAPI, options ...KeyFSOption) (*KeyFS, error) {
 	if fsys.ctx == nil {
 		fsys.ctx, fsys.cancel = context.WithCancel(context.Background())
 	}
+	if publisher := fsys.publisher; publisher != nil {
+		publisher.ctx = fsys.ctx
+		publisher.published = fsys.forgetRoot
+	}
 	return fsys, nil
 }
 
@@ -73,50 +140,83 @@ func (fsys *KeyFS) setPermissions(permissions fs.FileMode) {
 }
 
 func (ki *KeyFS) Close() error {
+	var err error
+	if publisher := ki.publisher; publisher != nil {
+		err = publisher.flush()
+	}
 	ki.cancel()
-	return nil
+	return err
 }
 
 // TODO: probably inefficient. Review.
 // TODO: deceptive name. This may translate the name.
 // but it won't if we don't have such a key
 // (which is fine for non-named IPNS paths).
-func (ki *KeyFS) translateName(name string) (string, error) {
+// The returned boolean reports whether
+// the name was translated.
+func (ki *KeyFS) translateName(name string) (string, bool, error) {
 	keys, err := ki.keyAPI.List(ki.ctx)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 	var (
 		components = strings.Split(name, "/")
 		keyName    = components[0]
+		isKey      bool
 	)
 	for _, key := range keys {
 		if key.Name() == keyName {
 			keyName = pathWithoutNamespace(key)
+			isKey = true
 			break
 		}
 	}
 	components = append([]string{keyName}, components[1:]...)
 	keyName = strings.Join(components, "/")
-	return keyName, nil
+	return keyName, isKey, nil
+}
+
+// resolveName translates the name and
+// returns the IPNS subsystem to forward it to.
+func (kfs *KeyFS) resolveName(op, name string) (fs.FS, string, error) {
+	translated, isKey, err := kfs.translateName(name)
+	if err != nil {
+		return nil, "", fserrors.New(op, name, err, fserrors.IO)
+	}
+	subsys := kfs.ipns
+	if subsys == nil ||
+		(kfs.proxyDisabled && !isKey) {
+		return nil, "", fserrors.New(op, name, filesystem.ErrNotFound, fserrors.NotExist)
+	}
+	return subsys, translated, nil
 }
 
 func (kfs *KeyFS) Stat(name string) (fs.FileInfo, error) {
 	const op = "stat"
+	name = filesystem.NormalizeRoot(name)
 	if name == filesystem.Root {
 		return &keyDirectory{
 			mode: fs.ModeDir | kfs.permissions,
 			ipns: kfs.ipns,
 		}, nil
 	}
-	if subsys := kfs.ipns; subsys != nil {
-		return fs.Stat(subsys, name)
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
 	}
-	return nil, fserrors.New(op, name, filesystem.ErrNotFound, fserrors.NotExist)
+	subsys, translated, err := kfs.resolveName(op, name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(subsys, translated)
+	if err != nil {
+		return nil, err
+	}
+	return renameInfo(info, name), nil
 }
 
 func (kfs *KeyFS) Open(name string) (fs.File, error) {
 	const op = "open"
+	name = filesystem.NormalizeRoot(name)
 	if name == filesystem.Root {
 		file, err := kfs.openRoot()
 		if err != nil {
@@ -124,14 +224,193 @@ func (kfs *KeyFS) Open(name string) (fs.File, error) {
 		}
 		return file, nil
 	}
-	translated, err := kfs.translateName(name)
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	subsys, translated, err := kfs.resolveName(op, name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := subsys.Open(translated)
+	if err != nil {
+		return nil, err
+	}
+	if path.Base(translated) == path.Base(name) {
+		return file, nil
+	}
+	return &keyFile{
+		File: file,
+		name: name,
+	}, nil
+}
+
+// OpenFile opens the named file with the specified flags.
+// Read-only opens are the same as [KeyFS.Open].
+// If auto-publishing is enabled (see: [WithAutoPublish]),
+// and the IPNS subsystem uses an [IPFS] which can write
+// (see: [WithDagService]), files beneath keys may be written
+// to, as with [IPFS.OpenFile]. When such a file is closed,
+// its key is scheduled to be published with the new root.
+// Writes build upon roots which are pending publication,
+// while reads resolve the key's published root.
+// Concurrent writes to the same key are not merged;
+// the file closed last determines the key's root.
+func (kfs *KeyFS) OpenFile(name string, flag int, perm fs.FileMode) (fs.File, error) {
+	if flag == os.O_RDONLY {
+		return kfs.Open(name)
+	}
+	const op = "open"
+	name = filesystem.NormalizeRoot(name)
+	if name == filesystem.Root {
+		return nil, fserrors.New(op, name, filesystem.ErrIsDir, fserrors.IsDir)
+	}
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	target, err := kfs.writeTarget(op, name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := target.ipfs.OpenFile(target.cidPath(), flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &keyWriter{
+		ipfsWriter: file.(*ipfsWriter),
+		publisher:  kfs.publisher,
+		keyName:    target.keyName,
+	}, nil
+}
+
+// Symlink creates a symbolic link beneath a key,
+// under the same conditions as [KeyFS.OpenFile].
+// Its key is then scheduled to be published.
+// The target must be a relative path, which
+// does not refer to anything outside of the key.
+// Absolute targets are rejected even if they name
+// something within the key, since their meaning
+// depends on where the system is mounted.
+func (kfs *KeyFS) Symlink(oldname, newname string) error {
+	const op = "symlink"
+	newname = filesystem.NormalizeRoot(newname)
+	if newname == filesystem.Root || !fs.ValidPath(newname) {
+		return fserrors.New(op, newname, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	target, err := kfs.writeTarget(op, newname)
+	if err != nil {
+		return err
+	}
+	if oldname == "" {
+		return fserrors.New(op, newname, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	if path.IsAbs(oldname) {
+		return fserrors.New(op, newname, errTargetEscapes, fserrors.InvalidItem)
+	}
+	if joined := path.Join(path.Dir(target.keyPath), oldname); joined == ".." ||
+		strings.HasPrefix(joined, "../") {
+		return fserrors.New(op, newname, errTargetEscapes, fserrors.InvalidItem)
+	}
+	root, err := target.ipfs.symlink(op, oldname, target.cidPath())
+	if err != nil {
+		return err
+	}
+	kfs.publisher.schedule(target.keyName, root)
+	return nil
+}
+
+// Readlink returns the target of a symbolic link.
+func (kfs *KeyFS) Readlink(name string) (string, error) {
+	const op = "readlink"
+	name = filesystem.NormalizeRoot(name)
+	if name == filesystem.Root || !fs.ValidPath(name) {
+		return "", fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	subsys, translated, err := kfs.resolveName(op, name)
+	if err != nil {
+		return "", err
+	}
+	linker, ok := subsys.(filesystem.ReadlinkFS)
+	if !ok {
+		return "", fserrors.New(op, name, fserrors.ErrUnsupported, fserrors.InvalidOperation)
+	}
+	return linker.Readlink(translated)
+}
+
+// writeTarget returns the location that a write
+// to `name` (which must be beneath a key) applies to.
+func (kfs *KeyFS) writeTarget(op, name string) (*keyTarget, error) {
+	subsys := kfs.writableIPFS()
+	if kfs.publisher == nil || subsys == nil {
+		return nil, fserrors.New(op, name, fserrors.ErrUnsupported, fserrors.ReadOnly)
+	}
+	keyName, keyPath, found := strings.Cut(name, "/")
+	if !found {
+		return nil, fserrors.New(op, name, errWriteRoot, fserrors.InvalidItem)
+	}
+	root, err := kfs.keyRoot(op, name, keyName)
 	if err != nil {
-		return nil, fserrors.New(op, name, err, fserrors.IO)
+		return nil, err
 	}
-	if subsys := kfs.ipns; subsys != nil {
-		return subsys.Open(translated)
+	return &keyTarget{
+		ipfs:    subsys,
+		keyName: keyName,
+		keyPath: keyPath,
+		root:    root,
+	}, nil
+}
+
+// writableIPFS returns the IPFS instance
+// used by the IPNS subsystem, if it can write.
+func (kfs *KeyFS) writableIPFS() *IPFS {
+	ipns, ok := kfs.ipns.(*IPNS)
+	if !ok {
+		return nil
 	}
-	return nil, fserrors.New(op, name, filesystem.ErrNotFound, fserrors.NotExist)
+	ipfs, ok := ipns.ipfs.(*IPFS)
+	if !ok || ipfs.dagService == nil {
+		return nil
+	}
+	return ipfs
+}
+
+// keyRoot returns the root which writes to the key
+// are based on; either the root pending publication,
+// or the key's current value.
+func (kfs *KeyFS) keyRoot(op, name, keyName string) (cid.Cid, error) {
+	if root, ok := kfs.publisher.pendingRoot(keyName); ok {
+		return root, nil
+	}
+	translated, isKey, err := kfs.translateName(keyName)
+	if err != nil {
+		return cid.Cid{}, fserrors.New(op, name, err, fserrors.IO)
+	}
+	if !isKey {
+		return cid.Cid{}, fserrors.New(op, name, errNotKey, fserrors.ReadOnly)
+	}
+	info, err := fs.Stat(kfs.ipns, translated)
+	if err != nil {
+		return cid.Cid{}, err
+	}
+	cidInfo, ok := info.(filesystem.CIDInfo)
+	if !ok {
+		err := fmt.Errorf("%w: %T does not report a CID", errUnexpectedType, info)
+		return cid.Cid{}, fserrors.New(op, name, err, fserrors.IO)
+	}
+	return cidInfo.CID(), nil
+}
+
+// forgetRoot removes the key's root from
+// the IPNS cache, so that it's resolved again.
+func (kfs *KeyFS) forgetRoot(keyName string) {
+	ipns, ok := kfs.ipns.(*IPNS)
+	if !ok || ipns.rootCache == nil {
+		return
+	}
+	translated, isKey, err := kfs.translateName(keyName)
+	if err != nil || !isKey {
+		return
+	}
+	ipns.rootCache.Remove(translated)
 }
 
 func (kfs *KeyFS) openRoot() (fs.ReadDirFile, error) {
@@ -192,7 +471,7 @@ func (kd *keyDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
 	}
 	stream := kd.stream
 	if stream == nil {
-		return nil, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.IO)
+		return nil, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
 	}
 	var (
 		ctx     = stream.Context
@@ -216,7 +495,7 @@ func (kd *keyDirectory) Close() error {
 		kd.stream = nil
 		return nil
 	}
-	return fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.InvalidItem)
+	return fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
 }
 
 func pathWithoutNamespace(key coreiface.Key) string {
@@ -231,7 +510,11 @@ func (ke *keyDirEntry) Name() string { return path.Base(ke.Key.Name()) }
 
 func (ke *keyDirEntry) Info() (fs.FileInfo, error) {
 	if subsys := ke.ipns; subsys != nil {
-		return fs.Stat(subsys, pathWithoutNamespace(ke.Key))
+		info, err := fs.Stat(subsys, pathWithoutNamespace(ke.Key))
+		if err != nil {
+			return nil, err
+		}
+		return renameInfo(info, ke.Name()), nil
 	}
 	return &keyInfo{
 		name: ke.Key.Name(),
@@ -250,6 +533,43 @@ func (ke *keyDirEntry) Type() fs.FileMode {
 func (ke *keyDirEntry) IsDir() bool { return ke.Type()&fs.ModeDir != 0 }
 func (*keyDirEntry) Error() error   { return nil }
 
+// cidPath returns the target's
+// path, relative to its root CID.
+func (kt *keyTarget) cidPath() string {
+	return kt.root.String() + "/" + kt.keyPath
+}
+
+func (kw *keyWriter) Close() error {
+	if err := kw.ipfsWriter.Close(); err != nil {
+		return err
+	}
+	kw.publisher.schedule(kw.keyName, kw.Synced())
+	return nil
+}
+
+func (kf *keyFile) Stat() (fs.FileInfo, error) {
+	info, err := kf.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return renameInfo(info, kf.name), nil
+}
+
+func (kf *keyFile) Seek(offset int64, whence int) (int64, error) {
+	if seeker, ok := kf.File.(io.Seeker); ok {
+		return seeker.Seek(offset, whence)
+	}
+	return 0, fserrors.ErrUnsupported
+}
+
+func (kf *keyFile) ReadDir(count int) ([]fs.DirEntry, error) {
+	if directory, ok := kf.File.(fs.ReadDirFile); ok {
+		return directory.ReadDir(count)
+	}
+	const op = "readdir"
+	return nil, fserrors.New(op, kf.name, filesystem.ErrIsNotDir, fserrors.NotDir)
+}
+
 func (ki *keyInfo) Name() string       { return ki.name }
 func (*keyInfo) Size() int64           { return 0 } // Unknown without IPNS subsystem.
 func (ki *keyInfo) Mode() fs.FileMode  { return ki.mode }
@@ -2,23 +2,73 @@ package ipfs
 
 import (
 	"context"
+	"errors"
 	"io/fs"
+	"os"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	coreiface "github.com/ipfs/boxo/coreiface"
+	coreoptions "github.com/ipfs/boxo/coreiface/options"
+	corepath "github.com/ipfs/boxo/coreiface/path"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
+type (
+	stubKeyAPI struct {
+		coreiface.KeyAPI
+		keys []coreiface.Key
+	}
+	stubKey     struct{ name, ipnsName string }
+	stubNameAPI struct {
+		coreiface.NameAPI
+		published chan stubPublish
+	}
+	stubPublish struct {
+		keyName string
+		path    corepath.Path
+	}
+)
+
+func (ka *stubKeyAPI) List(context.Context) ([]coreiface.Key, error) {
+	return ka.keys, nil
+}
+
+func (sk *stubKey) Name() string        { return sk.name }
+func (sk *stubKey) Path() corepath.Path { return corepath.New("/ipns/" + sk.ipnsName) }
+func (*stubKey) ID() peer.ID            { return "" }
+
+func (na *stubNameAPI) Publish(_ context.Context, path corepath.Path, opts ...coreoptions.NamePublishOption) (coreiface.IpnsEntry, error) {
+	settings, err := coreoptions.NamePublishOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	na.published <- stubPublish{keyName: settings.Key, path: path}
+	return nil, nil
+}
+
 var (
 	_ fs.FS           = (*KeyFS)(nil)
 	_ fs.StatFS       = (*KeyFS)(nil)
 	_ filesystem.IDFS = (*KeyFS)(nil)
 	_ fs.File         = (*keyDirectory)(nil)
 	_ fs.ReadDirFile  = (*keyDirectory)(nil)
+
+	_ filesystem.SymlinkFS = (*KeyFS)(nil)
 )
 
 func TestKeyFS(t *testing.T) {
 	t.Parallel()
 	t.Run("Options", testKeyFSOptions)
+	t.Run("Proxy", testKeyFSProxy)
+	t.Run("Conformance", testKeyFSConformance)
+	t.Run("Root spellings", testKeyFSRootSpellings)
+	t.Run("Auto publish", testKeyFSAutoPublish)
+	t.Run("Symlink", testKeyFSSymlink)
 }
 
 func testKeyFSOptions(t *testing.T) {
@@ -30,4 +80,318 @@ func testKeyFSOptions(t *testing.T) {
 		WithContext[KeyFSOption](context.Background()),
 		WithPermissions[KeyFSOption](0),
 	)
+	for _, option := range []KeyFSOption{
+		WithAutoPublish(nil, time.Second),
+		WithAutoPublish(new(stubNameAPI), 0),
+	} {
+		if _, err := NewKeyFS(nil, option); err == nil {
+			t.Error("expected invalid auto publish option to be rejected")
+		}
+	}
+}
+
+func testKeyFSProxy(t *testing.T) {
+	t.Parallel()
+	const (
+		keyName  = "key"
+		keyIPNS  = "k51key"
+		foreign  = "k51foreign"
+		fileName = "file"
+	)
+	var (
+		keyAPI = &stubKeyAPI{
+			keys: []coreiface.Key{
+				&stubKey{name: keyName, ipnsName: keyIPNS},
+			},
+		}
+		ipns = fstest.MapFS{
+			keyIPNS + "/" + fileName: new(fstest.MapFile),
+			foreign + "/" + fileName: new(fstest.MapFile),
+		}
+	)
+	for _, test := range []struct {
+		name     string
+		disabled bool
+	}{
+		{name: "enabled"},
+		{name: "disabled", disabled: true},
+	} {
+		disabled := test.disabled
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			fsys, err := NewKeyFS(keyAPI,
+				WithIPNS(ipns),
+				WithProxyDisabled(disabled),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer fsys.Close()
+			for _, name := range []string{
+				keyName,
+				keyName + "/" + fileName,
+			} {
+				if _, err := fs.Stat(fsys, name); err != nil {
+					t.Errorf("key path \"%s\" did not resolve: %v", name, err)
+				}
+				file, err := fsys.Open(name)
+				if err != nil {
+					t.Errorf("key path \"%s\" did not open: %v", name, err)
+					continue
+				}
+				if err := file.Close(); err != nil {
+					t.Error(err)
+				}
+			}
+			for _, name := range []string{
+				foreign,
+				foreign + "/" + fileName,
+			} {
+				_, statErr := fs.Stat(fsys, name)
+				file, openErr := fsys.Open(name)
+				if openErr == nil {
+					if err := file.Close(); err != nil {
+						t.Error(err)
+					}
+				}
+				for _, err := range []error{statErr, openErr} {
+					if disabled {
+						var fsErr *fserrors.Error
+						if !errors.As(err, &fsErr) ||
+							fsErr.Kind != fserrors.NotExist {
+							t.Errorf("proxied path \"%s\" was resolved while disabled"+
+								"\ngot: %v"+
+								"\nwant: %v",
+								name, err, fserrors.NotExist,
+							)
+						}
+						continue
+					}
+					if err != nil {
+						t.Errorf("proxied path \"%s\" did not resolve: %v", name, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// newPublishingKeyFS constructs a KeyFS containing
+// a single key (which refers to the fixture),
+// that publishes writes to the returned name API.
+func (fx *fixture) newPublishingKeyFS(t *testing.T, keyName string, interval time.Duration) (*KeyFS, *stubNameAPI) {
+	t.Helper()
+	ipns, err := NewIPNS(fx.core, fx.newWritableIPFS(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := ipns.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	var (
+		names  = &stubNameAPI{published: make(chan stubPublish, 2)}
+		keyAPI = &stubKeyAPI{
+			keys: []coreiface.Key{
+				&stubKey{name: keyName, ipnsName: fixtureIPNSName},
+			},
+		}
+	)
+	fsys, err := NewKeyFS(keyAPI,
+		WithIPNS(ipns),
+		WithAutoPublish(names, interval),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fsys, names
+}
+
+// publishedKeyRoot returns the root CID
+// which was published for the key.
+func publishedKeyRoot(t *testing.T, publish stubPublish, keyName string) string {
+	t.Helper()
+	if got, want := publish.keyName, keyName; got != want {
+		t.Errorf("published key mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, want,
+		)
+	}
+	resolved, ok := publish.path.(corepath.Resolved)
+	if !ok {
+		t.Fatalf("published path \"%s\" is not resolved", publish.path)
+	}
+	return resolved.Cid().String()
+}
+
+func testKeyFSAutoPublish(t *testing.T) {
+	t.Parallel()
+	const keyName = "key"
+	var (
+		fixture = newFixture(t)
+		ipfs    = fixture.newIPFS(t)
+		newFS   = func(t *testing.T, interval time.Duration) (*KeyFS, *stubNameAPI) {
+			t.Helper()
+			return fixture.newPublishingKeyFS(t, keyName, interval)
+		}
+		publishedRoot = func(t *testing.T, publish stubPublish) string {
+			t.Helper()
+			return publishedKeyRoot(t, publish, keyName)
+		}
+	)
+	t.Run("debounce", func(t *testing.T) {
+		t.Parallel()
+		const interval = 100 * time.Millisecond
+		fsys, names := newFS(t, interval)
+		t.Cleanup(func() {
+			if err := fsys.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		writeFile(t, fsys, keyName+"/first", os.O_WRONLY|os.O_CREATE, "first")
+		writeFile(t, fsys, keyName+"/nested/second", os.O_WRONLY|os.O_CREATE, "second")
+		var root string
+		select {
+		case publish := <-names.published:
+			root = publishedRoot(t, publish)
+		case <-time.After(10 * time.Second):
+			t.Fatal("key was not published")
+		}
+		// Both writes should be in the same root,
+		// with the original contents preserved.
+		writeDataMatch(t, ipfs, root+"/first", "first")
+		writeDataMatch(t, ipfs, root+"/nested/second", "second")
+		writeDataMatch(t, ipfs, root+"/file", "file data")
+		select {
+		case publish := <-names.published:
+			t.Errorf("key was published more than once: %v", publish.path)
+		case <-time.After(2 * interval):
+		}
+	})
+	t.Run("flush", func(t *testing.T) {
+		t.Parallel()
+		fsys, names := newFS(t, time.Hour)
+		writeFile(t, fsys, keyName+"/file", os.O_WRONLY|os.O_TRUNC, "flushed")
+		if err := fsys.Close(); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case publish := <-names.published:
+			root := publishedRoot(t, publish)
+			writeDataMatch(t, ipfs, root+"/file", "flushed")
+		default:
+			t.Error("pending publish was not flushed when closed")
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		fsys, _ := newFS(t, time.Hour)
+		t.Cleanup(func() {
+			if err := fsys.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		for _, test := range []struct {
+			name string
+			kind fserrors.Kind
+		}{
+			{keyName, fserrors.InvalidItem},
+			{fixtureIPNSName + "/new", fserrors.ReadOnly},
+			{keyName + "/nested", fserrors.IsDir},
+			{filesystem.Root, fserrors.IsDir},
+		} {
+			file, err := fsys.OpenFile(test.name, os.O_WRONLY|os.O_CREATE, 0)
+			if err == nil {
+				t.Errorf("expected error for \"%s\"", test.name)
+				file.Close()
+				continue
+			}
+			writeKindMatch(t, err, test.kind)
+		}
+		readOnly := fixture.newKeyFS(t, keyName)
+		_, err := readOnly.OpenFile(keyName+"/new", os.O_WRONLY|os.O_CREATE, 0)
+		writeKindMatch(t, err, fserrors.ReadOnly)
+	})
+}
+
+func testKeyFSSymlink(t *testing.T) {
+	t.Parallel()
+	const (
+		keyName = "key"
+		link    = keyName + "/nested/link"
+		target  = "../file"
+	)
+	var (
+		fixture     = newFixture(t)
+		fsys, names = fixture.newPublishingKeyFS(t, keyName, time.Hour)
+	)
+	for _, test := range []struct {
+		oldname, newname string
+		kind             fserrors.Kind
+	}{
+		{"/file", keyName + "/absolute", fserrors.InvalidItem},
+		{"../file", keyName + "/parent", fserrors.InvalidItem},
+		{"../../file", keyName + "/nested/parent", fserrors.InvalidItem},
+		{"", keyName + "/empty", fserrors.InvalidItem},
+		{"file", keyName + "/file", fserrors.Exist},
+		{"file", keyName + "/missing/link", fserrors.NotExist},
+		{"file", fixtureIPNSName + "/link", fserrors.ReadOnly},
+		{"file", keyName, fserrors.InvalidItem},
+	} {
+		err := fsys.Symlink(test.oldname, test.newname)
+		if err == nil {
+			t.Errorf("expected error for \"%s\" -> \"%s\"",
+				test.newname, test.oldname,
+			)
+			continue
+		}
+		writeKindMatch(t, err, test.kind)
+	}
+	if err := fsys.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var root cid.Cid
+	select {
+	case publish := <-names.published:
+		var err error
+		if root, err = cid.Decode(publishedKeyRoot(t, publish, keyName)); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatal("symbolic link was not published")
+	}
+	// Nothing else is using this fixture
+	// so its names may be modified directly.
+	fixture.core.names[fixtureIPNSName] = root
+	reader := fixture.newKeyFS(t, keyName)
+	got, err := reader.Readlink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != target {
+		t.Errorf("link target mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, target,
+		)
+	}
+	info, err := fs.Stat(reader, link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode(); mode.Type() != fs.ModeSymlink {
+		t.Errorf("link mode mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			mode.Type(), fs.ModeSymlink,
+		)
+	}
+	if _, err := reader.Readlink(keyName + "/file"); err == nil {
+		t.Error("expected error reading link of regular file")
+	}
 }
@@ -0,0 +1,142 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	coreiface "github.com/ipfs/boxo/coreiface"
+	coreoptions "github.com/ipfs/boxo/coreiface/options"
+)
+
+type (
+	// KeyInfo describes a key stored by the IPFS node.
+	KeyInfo struct {
+		Name string `json:"name"`
+		ID   string `json:"id"`
+	}
+	keyGenerateSettings struct {
+		keyType string
+	}
+	KeyGenerateOption func(*keyGenerateSettings) error
+)
+
+const (
+	// SelfKeyName is the name of the node's own key.
+	SelfKeyName = "self"
+
+	errSelfKey = generic.ConstError("the self key can not be removed")
+	errKeyType = generic.ConstError("unsupported key type")
+)
+
+// WithKeyType sets the algorithm used to generate keys.
+// Supported types are "rsa" and "ed25519" (case-insensitive).
+// If not set, the node's default is used.
+func WithKeyType(keyType string) KeyGenerateOption {
+	return func(settings *keyGenerateSettings) error {
+		switch typ := strings.ToLower(keyType); typ {
+		case coreoptions.RSAKey, coreoptions.Ed25519Key:
+			settings.keyType = typ
+			return nil
+		default:
+			return fmt.Errorf(
+				"%w got: \"%s\" want: \"%s\" or \"%s\"",
+				errKeyType, keyType,
+				coreoptions.RSAKey, coreoptions.Ed25519Key,
+			)
+		}
+	}
+}
+
+// ListKeys returns the keys stored by the node,
+// sorted by name.
+func (kg *KeyFSGuest) ListKeys(ctx context.Context) ([]KeyInfo, error) {
+	keyAPI, err := kg.makeKeyAPI()
+	if err != nil {
+		return nil, err
+	}
+	return listKeys(ctx, keyAPI)
+}
+
+// GenerateKey creates a new key with the name.
+func (kg *KeyFSGuest) GenerateKey(ctx context.Context, name string, options ...KeyGenerateOption) (KeyInfo, error) {
+	keyAPI, err := kg.makeKeyAPI()
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return generateKey(ctx, keyAPI, name, options...)
+}
+
+// RemoveKey removes the key with the name.
+// The node's own key (see [SelfKeyName])
+// is never removed.
+func (kg *KeyFSGuest) RemoveKey(ctx context.Context, name string) (KeyInfo, error) {
+	keyAPI, err := kg.makeKeyAPI()
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return removeKey(ctx, keyAPI, name)
+}
+
+func (kg *KeyFSGuest) makeKeyAPI() (coreiface.KeyAPI, error) {
+	client, err := kg.makeCoreAPI()
+	if err != nil {
+		return nil, err
+	}
+	return client.Key(), nil
+}
+
+func listKeys(ctx context.Context, keyAPI coreiface.KeyAPI) ([]KeyInfo, error) {
+	keys, err := keyAPI.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]KeyInfo, len(keys))
+	for i, key := range keys {
+		infos[i] = makeKeyInfo(key)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+func generateKey(ctx context.Context, keyAPI coreiface.KeyAPI,
+	name string, options ...KeyGenerateOption,
+) (KeyInfo, error) {
+	var settings keyGenerateSettings
+	if err := generic.ApplyOptions(&settings, options...); err != nil {
+		return KeyInfo{}, err
+	}
+	var generateOptions []coreoptions.KeyGenerateOption
+	if keyType := settings.keyType; keyType != "" {
+		generateOptions = append(generateOptions,
+			coreoptions.Key.Type(keyType),
+		)
+	}
+	key, err := keyAPI.Generate(ctx, name, generateOptions...)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return makeKeyInfo(key), nil
+}
+
+func removeKey(ctx context.Context, keyAPI coreiface.KeyAPI, name string) (KeyInfo, error) {
+	if name == SelfKeyName {
+		return KeyInfo{}, errSelfKey
+	}
+	key, err := keyAPI.Remove(ctx, name)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	return makeKeyInfo(key), nil
+}
+
+func makeKeyInfo(key coreiface.Key) KeyInfo {
+	return KeyInfo{
+		Name: key.Name(),
+		ID:   key.ID().String(),
+	}
+}
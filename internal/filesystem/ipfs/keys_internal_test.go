@@ -0,0 +1,194 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	coreiface "github.com/ipfs/boxo/coreiface"
+	coreoptions "github.com/ipfs/boxo/coreiface/options"
+	corepath "github.com/ipfs/boxo/coreiface/path"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+type (
+	// mutableKeyAPI stores keys in memory,
+	// recording the type of generated keys.
+	mutableKeyAPI struct {
+		coreiface.KeyAPI
+		keys  map[string]peer.ID
+		types map[string]string
+	}
+	mutableKey struct {
+		name string
+		id   peer.ID
+	}
+)
+
+func newMutableKeyAPI(names ...string) *mutableKeyAPI {
+	keyAPI := &mutableKeyAPI{
+		keys:  make(map[string]peer.ID, len(names)),
+		types: make(map[string]string),
+	}
+	for _, name := range names {
+		keyAPI.keys[name] = peer.ID("id-" + name)
+	}
+	return keyAPI
+}
+
+func (ka *mutableKeyAPI) List(context.Context) ([]coreiface.Key, error) {
+	keys := make([]coreiface.Key, 0, len(ka.keys))
+	for name, id := range ka.keys {
+		keys = append(keys, &mutableKey{name: name, id: id})
+	}
+	return keys, nil
+}
+
+func (ka *mutableKeyAPI) Generate(_ context.Context, name string,
+	options ...coreoptions.KeyGenerateOption,
+) (coreiface.Key, error) {
+	settings, err := coreoptions.KeyGenerateOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := ka.keys[name]; exists {
+		return nil, fmt.Errorf("key \"%s\" already exists", name)
+	}
+	id := peer.ID("id-" + name)
+	ka.keys[name] = id
+	ka.types[name] = settings.Algorithm
+	return &mutableKey{name: name, id: id}, nil
+}
+
+func (ka *mutableKeyAPI) Remove(_ context.Context, name string) (coreiface.Key, error) {
+	id, exists := ka.keys[name]
+	if !exists {
+		return nil, fmt.Errorf("no key named \"%s\"", name)
+	}
+	delete(ka.keys, name)
+	return &mutableKey{name: name, id: id}, nil
+}
+
+func (mk *mutableKey) Name() string        { return mk.name }
+func (mk *mutableKey) Path() corepath.Path { return corepath.New("/ipns/" + mk.id.String()) }
+func (mk *mutableKey) ID() peer.ID         { return mk.id }
+
+func TestKeys(t *testing.T) {
+	t.Parallel()
+	t.Run("list", testKeysList)
+	t.Run("generate", testKeysGenerate)
+	t.Run("remove", testKeysRemove)
+}
+
+func testKeysList(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx    = context.Background()
+		keyAPI = newMutableKeyAPI("b", SelfKeyName, "a")
+	)
+	keys, err := listKeys(ctx, keyAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "b", SelfKeyName}
+	if len(keys) != len(want) {
+		t.Fatalf("key count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			len(keys), len(want),
+		)
+	}
+	for i, key := range keys {
+		if key.Name != want[i] {
+			t.Errorf("key order mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				key.Name, want[i],
+			)
+		}
+		if wantID := keyAPI.keys[key.Name].String(); key.ID != wantID {
+			t.Errorf("key ID mismatch for \"%s\""+
+				"\ngot: %s"+
+				"\nwant: %s",
+				key.Name, key.ID, wantID,
+			)
+		}
+	}
+}
+
+func testKeysGenerate(t *testing.T) {
+	t.Parallel()
+	var (
+		ctx    = context.Background()
+		keyAPI = newMutableKeyAPI(SelfKeyName)
+	)
+	for _, test := range []struct {
+		name, keyType, want string
+	}{
+		{name: "ed", keyType: "Ed25519", want: coreoptions.Ed25519Key},
+		{name: "rsa", keyType: "rsa", want: coreoptions.RSAKey},
+	} {
+		key, err := generateKey(ctx, keyAPI, test.name, WithKeyType(test.keyType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.Name != test.name {
+			t.Errorf("key name mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				key.Name, test.name,
+			)
+		}
+		if got := keyAPI.types[test.name]; got != test.want {
+			t.Errorf("key type mismatch"+
+				"\ngot: %s"+
+				"\nwant: %s",
+				got, test.want,
+			)
+		}
+	}
+	if _, err := generateKey(ctx, keyAPI, "dsa", WithKeyType("dsa")); !errors.Is(err, errKeyType) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, errKeyType,
+		)
+	}
+	if _, exists := keyAPI.keys["dsa"]; exists {
+		t.Error("key was generated with an unsupported type")
+	}
+}
+
+func testKeysRemove(t *testing.T) {
+	t.Parallel()
+	const keyName = "key"
+	var (
+		ctx    = context.Background()
+		keyAPI = newMutableKeyAPI(SelfKeyName, keyName)
+	)
+	if _, err := removeKey(ctx, keyAPI, SelfKeyName); !errors.Is(err, errSelfKey) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, errSelfKey,
+		)
+	}
+	if _, exists := keyAPI.keys[SelfKeyName]; !exists {
+		t.Error("self key was removed")
+	}
+	key, err := removeKey(ctx, keyAPI, keyName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Name != keyName {
+		t.Errorf("removed key mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			key.Name, keyName,
+		)
+	}
+	if _, exists := keyAPI.keys[keyName]; exists {
+		t.Error("key was not removed")
+	}
+}
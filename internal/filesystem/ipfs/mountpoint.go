@@ -9,6 +9,7 @@ import (
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
 	coreiface "github.com/ipfs/boxo/coreiface"
 	"github.com/multiformats/go-multiaddr"
 )
@@ -19,6 +20,29 @@ type (
 		APITimeout          time.Duration       `json:"apiTimeout,omitempty"`
 		NodeCacheCount      int                 `json:"nodeCacheCount,omitempty"`
 		DirectoryCacheCount int                 `json:"directoryCacheCount,omitempty"`
+		CaseInsensitive     bool                `json:"caseInsensitive,omitempty"`
+		ContentType         bool                `json:"contentType,omitempty"`
+		// IPLDDirectories presents IPLD nodes
+		// as directories (see [WithIPLDAsDirectory]).
+		IPLDDirectories bool `json:"ipldDirectories,omitempty"`
+		// UserAgent identifies the client to the API.
+		// If empty, [DefaultUserAgent] is used.
+		UserAgent string `json:"userAgent,omitempty"`
+		// PathPrefix (if not empty) roots the IPFS guest
+		// at the named directory (see [WithPathPrefix]).
+		// Overlay guests (e.g. IPNS) do not support it.
+		PathPrefix string `json:"pathPrefix,omitempty"`
+		// MaxLinkTargetLen (if not 0) limits the length
+		// of symbolic link targets (see [WithMaxLinkTargetLen]).
+		MaxLinkTargetLen int `json:"maxLinkTargetLength,omitempty"`
+		// ReaddirWorkers (if not 0) sets the number of
+		// directory entries resolved concurrently
+		// during listing (see [WithConcurrentReaddir]).
+		ReaddirWorkers int `json:"readdirWorkers,omitempty"`
+		// HandleLimit (if not 0) limits the amount of
+		// files which may be open at once
+		// (see [filesystem.NewHandleLimitFS]).
+		HandleLimit int `json:"handleLimit,omitempty"`
 	}
 	IPNSGuest struct {
 		IPFSGuest
@@ -27,8 +51,12 @@ type (
 	PinFSGuest struct {
 		IPFSGuest
 		CacheExpiry time.Duration `json:"cacheExpiry,omitempty"`
+		InitialPins bool          `json:"initialPins,omitempty"`
+	}
+	KeyFSGuest struct {
+		IPNSGuest
+		ProxyDisabled bool `json:"proxyDisabled,omitempty"`
 	}
-	KeyFSGuest struct{ IPNSGuest }
 )
 
 func (*IPFSGuest) GuestID() filesystem.ID { return IPFSID }
@@ -46,10 +74,26 @@ func (ig *IPFSGuest) UnmarshalJSON(b []byte) error {
 		APITimeout          *time.Duration `json:"apiTimeout,omitempty"`
 		NodeCacheCount      *int           `json:"nodeCacheCount,omitempty"`
 		DirectoryCacheCount *int           `json:"directoryCacheCount,omitempty"`
+		CaseInsensitive     *bool          `json:"caseInsensitive,omitempty"`
+		ContentType         *bool          `json:"contentType,omitempty"`
+		IPLDDirectories     *bool          `json:"ipldDirectories,omitempty"`
+		UserAgent           *string        `json:"userAgent,omitempty"`
+		PathPrefix          *string        `json:"pathPrefix,omitempty"`
+		MaxLinkTargetLen    *int           `json:"maxLinkTargetLength,omitempty"`
+		ReaddirWorkers      *int           `json:"readdirWorkers,omitempty"`
+		HandleLimit         *int           `json:"handleLimit,omitempty"`
 	}{
 		APITimeout:          &ig.APITimeout,
 		NodeCacheCount:      &ig.NodeCacheCount,
 		DirectoryCacheCount: &ig.DirectoryCacheCount,
+		CaseInsensitive:     &ig.CaseInsensitive,
+		ContentType:         &ig.ContentType,
+		IPLDDirectories:     &ig.IPLDDirectories,
+		UserAgent:           &ig.UserAgent,
+		PathPrefix:          &ig.PathPrefix,
+		MaxLinkTargetLen:    &ig.MaxLinkTargetLen,
+		ReaddirWorkers:      &ig.ReaddirWorkers,
+		HandleLimit:         &ig.HandleLimit,
 	})
 }
 
@@ -59,6 +103,14 @@ func (ig *IPFSGuest) ParseField(key, value string) error {
 		apiTimeoutKey     = "apiTimeout"
 		nodeCacheKey      = "nodeCacheCount"
 		directoryCacheKey = "directoryCacheCount"
+		caseKey           = "caseInsensitive"
+		contentTypeKey    = "contentType"
+		ipldKey           = "ipldDirectories"
+		userAgentKey      = "userAgent"
+		pathPrefixKey     = "pathPrefix"
+		linkTargetKey     = "maxLinkTargetLength"
+		readdirKey        = "readdirWorkers"
+		handleLimitKey    = "handleLimit"
 	)
 	var err error
 	switch key {
@@ -76,12 +128,50 @@ func (ig *IPFSGuest) ParseField(key, value string) error {
 		err = ig.parseCacheField(value, &ig.NodeCacheCount)
 	case directoryCacheKey:
 		err = ig.parseCacheField(value, &ig.DirectoryCacheCount)
+	case caseKey:
+		var insensitive bool
+		if insensitive, err = strconv.ParseBool(value); err == nil {
+			ig.CaseInsensitive = insensitive
+		}
+	case contentTypeKey:
+		var detect bool
+		if detect, err = strconv.ParseBool(value); err == nil {
+			ig.ContentType = detect
+		}
+	case ipldKey:
+		var enabled bool
+		if enabled, err = strconv.ParseBool(value); err == nil {
+			ig.IPLDDirectories = enabled
+		}
+	case userAgentKey:
+		ig.UserAgent = value
+	case pathPrefixKey:
+		ig.PathPrefix = value
+	case linkTargetKey:
+		var length int
+		if length, err = strconv.Atoi(value); err == nil {
+			ig.MaxLinkTargetLen = length
+		}
+	case readdirKey:
+		var workers int
+		if workers, err = strconv.Atoi(value); err == nil {
+			ig.ReaddirWorkers = workers
+		}
+	case handleLimitKey:
+		var limit int
+		if limit, err = strconv.Atoi(value); err == nil {
+			ig.HandleLimit = limit
+		}
 	default:
 		return p9fs.FieldError{
 			Key: key,
 			Tried: []string{
 				apiKey, apiTimeoutKey,
 				nodeCacheKey, directoryCacheKey,
+				caseKey, contentTypeKey, ipldKey,
+				userAgentKey, pathPrefixKey,
+				linkTargetKey, readdirKey,
+				handleLimitKey,
 			},
 		}
 	}
@@ -105,7 +195,10 @@ func (ig *IPFSGuest) parseCacheField(value string, target *int) error {
 }
 
 func (ig *IPFSGuest) makeCoreAPI() (coreiface.CoreAPI, error) {
-	return newIPFSClient(ig.APIMaddr)
+	if ig.APIMaddr == nil {
+		return nil, generic.ConstError("API multiaddr not provided")
+	}
+	return newIPFSClient(ig.APIMaddr, ig.UserAgent)
 }
 
 func (ig *IPFSGuest) MakeFS() (fs.FS, error) {
@@ -113,17 +206,62 @@ func (ig *IPFSGuest) MakeFS() (fs.FS, error) {
 	if err != nil {
 		return nil, err
 	}
-	return ig.makeFS(client)
+	var options []IPFSOption
+	if prefix := ig.PathPrefix; prefix != "" {
+		options = []IPFSOption{WithPathPrefix(prefix)}
+	}
+	fsys, err := ig.makeFS(client, options...)
+	if err != nil {
+		return nil, err
+	}
+	return ig.wrapFS(fsys)
 }
 
-func (ig *IPFSGuest) makeFS(api coreiface.CoreAPI) (fs.FS, error) {
-	var options []IPFSOption
+// wrapFS applies the guest's middleware
+// (if any) to the outermost file system.
+func (ig *IPFSGuest) wrapFS(fsys fs.FS) (fs.FS, error) {
+	if ig.CaseInsensitive {
+		fsys = filesystem.NewCaseInsensitiveFS(fsys)
+	}
+	if limit := ig.HandleLimit; limit != 0 {
+		limited, err := filesystem.NewHandleLimitFS(fsys, limit)
+		if err != nil {
+			return nil, errors.Join(err, closeFS(fsys))
+		}
+		fsys = limited
+	}
+	return fsys, nil
+}
+
+// makeOverlayFS constructs the IPFS file system
+// for overlay guests, which require names
+// to begin with a CID (rather than a prefix).
+func (ig *IPFSGuest) makeOverlayFS(api coreiface.CoreAPI, options ...IPFSOption) (fs.FS, error) {
+	if ig.PathPrefix != "" {
+		return nil, generic.ConstError("path prefixes are only supported by the IPFS guest")
+	}
+	return ig.makeFS(api, options...)
+}
+
+func (ig *IPFSGuest) makeFS(api coreiface.CoreAPI, options ...IPFSOption) (fs.FS, error) {
 	if count := ig.NodeCacheCount; count != 0 {
 		options = append(options, WithNodeCacheCount(count))
 	}
 	if count := ig.DirectoryCacheCount; count != 0 {
 		options = append(options, WithDirectoryCacheCount(count))
 	}
+	if ig.ContentType {
+		options = append(options, WithContentTypeDetection(true))
+	}
+	if ig.IPLDDirectories {
+		options = append(options, WithIPLDAsDirectory(true))
+	}
+	if length := ig.MaxLinkTargetLen; length != 0 {
+		options = append(options, WithMaxLinkTargetLen(length))
+	}
+	if workers := ig.ReaddirWorkers; workers != 0 {
+		options = append(options, WithConcurrentReaddir(workers))
+	}
 	return NewIPFS(api, options...)
 }
 
@@ -167,7 +305,7 @@ func (ng *IPNSGuest) MakeFS() (fs.FS, error) {
 	if err != nil {
 		return nil, err
 	}
-	ipfs, err := ng.IPFSGuest.makeFS(client)
+	ipfs, err := ng.IPFSGuest.makeOverlayFS(client)
 	if err != nil {
 		return nil, err
 	}
@@ -175,7 +313,11 @@ func (ng *IPNSGuest) MakeFS() (fs.FS, error) {
 	if expiry := ng.NodeExpiry; expiry != 0 {
 		options = []IPNSOption{CacheNodesFor(expiry)}
 	}
-	return NewIPNS(client, ipfs, options...)
+	ipnsFS, err := NewIPNS(client, ipfs, options...)
+	if err != nil {
+		return nil, err
+	}
+	return ng.wrapFS(ipnsFS)
 }
 
 func (*PinFSGuest) GuestID() filesystem.ID { return PinFSID }
@@ -185,8 +327,10 @@ func (pg *PinFSGuest) UnmarshalJSON(b []byte) error {
 	}
 	return json.Unmarshal(b, &struct {
 		CacheExpiry *time.Duration `json:"cacheExpiry,omitempty"`
+		InitialPins *bool          `json:"initialPins,omitempty"`
 	}{
 		CacheExpiry: &pg.CacheExpiry,
+		InitialPins: &pg.InitialPins,
 	})
 }
 
@@ -195,19 +339,27 @@ func (pg *PinFSGuest) MakeFS() (fs.FS, error) {
 	if err != nil {
 		return nil, err
 	}
-	ipfsFS, err := pg.IPFSGuest.makeFS(client)
+	ipfsFS, err := pg.IPFSGuest.makeOverlayFS(client)
 	if err != nil {
 		return nil, err
 	}
-	return NewPinFS(
+	pinFS, err := NewPinFS(
 		client.Pin(),
 		WithIPFS(ipfsFS),
 		CachePinsFor(pg.CacheExpiry),
+		WithInitialPins(pg.InitialPins),
 	)
+	if err != nil {
+		return nil, err
+	}
+	return pg.wrapFS(pinFS)
 }
 
 func (pg *PinFSGuest) ParseField(key, value string) error {
-	const cacheKey = "cacheExpiry"
+	const (
+		cacheKey   = "cacheExpiry"
+		initialKey = "initialPins"
+	)
 	switch key {
 	case cacheKey:
 		duration, err := time.ParseDuration(value)
@@ -216,11 +368,18 @@ func (pg *PinFSGuest) ParseField(key, value string) error {
 		}
 		pg.CacheExpiry = duration
 		return nil
+	case initialKey:
+		prefetch, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		pg.InitialPins = prefetch
+		return nil
 	default:
 		if err := pg.IPFSGuest.ParseField(key, value); err != nil {
 			var fErr p9fs.FieldError
 			if errors.As(err, &fErr) {
-				fErr.Tried = append(fErr.Tried, cacheKey)
+				fErr.Tried = append(fErr.Tried, cacheKey, initialKey)
 				return fErr
 			}
 			return err
@@ -230,13 +389,46 @@ func (pg *PinFSGuest) ParseField(key, value string) error {
 }
 
 func (*KeyFSGuest) GuestID() filesystem.ID { return KeyFSID }
+func (kg *KeyFSGuest) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &kg.IPNSGuest); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &struct {
+		ProxyDisabled *bool `json:"proxyDisabled,omitempty"`
+	}{
+		ProxyDisabled: &kg.ProxyDisabled,
+	})
+}
+
+func (kg *KeyFSGuest) ParseField(key, value string) error {
+	const proxyKey = "proxyDisabled"
+	switch key {
+	case proxyKey:
+		disabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		kg.ProxyDisabled = disabled
+		return nil
+	default:
+		if err := kg.IPNSGuest.ParseField(key, value); err != nil {
+			var fErr p9fs.FieldError
+			if errors.As(err, &fErr) {
+				fErr.Tried = append(fErr.Tried, proxyKey)
+				return fErr
+			}
+			return err
+		}
+		return nil
+	}
+}
 
 func (kg *KeyFSGuest) MakeFS() (fs.FS, error) {
 	client, err := kg.makeCoreAPI()
 	if err != nil {
 		return nil, err
 	}
-	ipfs, err := kg.IPFSGuest.makeFS(client)
+	ipfs, err := kg.IPFSGuest.makeOverlayFS(client)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +437,12 @@ func (kg *KeyFSGuest) MakeFS() (fs.FS, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewKeyFS(client.Key(),
+	keyFS, err := NewKeyFS(client.Key(),
 		WithIPNS(ipnsFS),
+		WithProxyDisabled(kg.ProxyDisabled),
 	)
+	if err != nil {
+		return nil, err
+	}
+	return kg.wrapFS(keyFS)
 }
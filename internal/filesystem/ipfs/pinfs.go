@@ -3,6 +3,7 @@ package ipfs
 import (
 	"context"
 	"io/fs"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -10,8 +11,11 @@ import (
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
 	"github.com/djdv/go-filesystem-utils/internal/generic"
+	lru "github.com/hashicorp/golang-lru/v2"
 	coreiface "github.com/ipfs/boxo/coreiface"
 	coreoptions "github.com/ipfs/boxo/coreiface/options"
+	corepath "github.com/ipfs/boxo/coreiface/path"
+	"github.com/ipfs/go-cid"
 )
 
 type (
@@ -28,10 +32,36 @@ type (
 		ctx    context.Context
 		cancel context.CancelFunc
 		statFn func(*pinDirEntry) error
+		// walkCache maps the paths walked by
+		// [PinFS.WalkCached] to their CIDs.
+		walkCache *pinWalkCache
 		pinShared
-		snapshot []filesystem.StreamDirEntry
-		expiry   time.Duration
-		cacheMu  sync.RWMutex
+		snapshot   []filesystem.StreamDirEntry
+		expiry     time.Duration
+		walkHits   atomic.Uint64
+		walkMisses atomic.Uint64
+		// batchSize is the number of pins
+		// fetched ahead of the reader.
+		batchSize int
+		// generation is incremented when a change to
+		// the node's pins is observed; invalidating
+		// snapshots which were fetched before it.
+		generation atomic.Uint64
+		// snapshotGeneration is the generation
+		// which `snapshot` was fetched in.
+		snapshotGeneration uint64
+		// snapshotPins holds the names
+		// of the pins within `snapshot`.
+		snapshotPins atomic.Pointer[map[string]struct{}]
+		cacheMu      sync.RWMutex
+		prefetch     bool
+	}
+	pinWalkCache = lru.ARCCache[string, cid.Cid]
+	// WalkCacheStats counts the path components
+	// which [PinFS.WalkCached] found in its cache (hits),
+	// and those it had to resolve (misses).
+	WalkCacheStats struct {
+		Hits, Misses uint64
 	}
 	pinDirectory struct {
 		*pinShared
@@ -47,7 +77,13 @@ type (
 	PinFSOption func(*PinFS) error
 )
 
-const PinFSID filesystem.ID = "PinFS"
+const (
+	PinFSID filesystem.ID = "PinFS"
+
+	pinWalkCacheCount   = 256 // Arbitrary.
+	pinBatchSizeDefault = 64  // Arbitrary.
+	errNotPinned        = generic.ConstError("not pinned")
+)
 
 func NewPinFS(pinAPI coreiface.PinAPI, options ...PinFSOption) (*PinFS, error) {
 	fsys := PinFS{
@@ -57,8 +93,14 @@ func NewPinFS(pinAPI coreiface.PinAPI, options ...PinFSOption) (*PinFS, error) {
 				permissions: readAll | executeAll,
 			},
 		},
+		batchSize: pinBatchSizeDefault,
 	}
 	fsys.info.modTime.Store(new(time.Time))
+	walkCache, err := lru.NewARC[string, cid.Cid](pinWalkCacheCount)
+	if err != nil {
+		return nil, err
+	}
+	fsys.walkCache = walkCache
 	for _, setter := range options {
 		if err := setter(&fsys); err != nil {
 			return nil, err
@@ -68,6 +110,9 @@ func NewPinFS(pinAPI coreiface.PinAPI, options ...PinFSOption) (*PinFS, error) {
 		fsys.ctx, fsys.cancel = context.WithCancel(context.Background())
 	}
 	fsys.initStatFunc()
+	if fsys.prefetch && fsys.expiry != 0 {
+		fsys.prefetchEntries()
+	}
 	return &fsys, nil
 }
 
@@ -112,6 +157,33 @@ func (pfs *PinFS) initStatFunc() {
 	}
 }
 
+// WithInitialPins fetches the pin set in the background
+// during construction, so that the first listing
+// of the root may be served from the cache.
+// Has no effect if the cache is disabled.
+// The cache is invalidated by its expiry, or if
+// [PinFS.WalkCached] finds that a pin was
+// removed from (or added to) the node.
+func WithInitialPins(prefetch bool) PinFSOption {
+	return func(pfs *PinFS) error { pfs.prefetch = prefetch; return nil }
+}
+
+// WithPinBatchSize sets the number of pins which
+// may be fetched ahead of the root directory's reader.
+// Pins are fetched as the directory is read (rather
+// than all at once), so that listing large pin sets
+// does not require holding every pin in memory;
+// unless the cache is enabled (see: [CachePinsFor]).
+func WithPinBatchSize(size int) PinFSOption {
+	return func(pfs *PinFS) error {
+		if size < 1 {
+			return generic.ConstError("pin batch size must be positive")
+		}
+		pfs.batchSize = size
+		return nil
+	}
+}
+
 func CachePinsFor(duration time.Duration) PinFSOption {
 	return func(pfs *PinFS) error {
 		pfs.expiry = duration
@@ -123,20 +195,162 @@ func (*PinFS) ID() filesystem.ID { return PinFSID }
 
 func (pfs *PinFS) Stat(name string) (fs.FileInfo, error) {
 	const op = "stat"
+	name = filesystem.NormalizeRoot(name)
 	if name == filesystem.Root {
 		return &pfs.info, nil
 	}
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
 	if subsys := pfs.ipfs; subsys != nil {
 		return fs.Stat(subsys, name)
 	}
 	return nil, fserrors.New(op, name, filesystem.ErrNotFound, fserrors.NotExist)
 }
 
+// WalkCached is like [PinFS.Stat], but remembers the CID
+// of each component along `name`. Later walks resume from
+// the deepest component which was already resolved,
+// rather than resolving every component again.
+// After each walk, the first component (the pin) is checked
+// against the node's pins. If it's no longer pinned (including
+// if it was unpinned during the walk), its components are
+// removed from the cache, and an error of kind
+// [fserrors.NotExist] is returned.
+func (pfs *PinFS) WalkCached(name string) (fs.FileInfo, error) {
+	const op = "walk"
+	name = filesystem.NormalizeRoot(name)
+	if name == filesystem.Root {
+		return &pfs.info, nil
+	}
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	subsys := pfs.ipfs
+	if subsys == nil {
+		return nil, fserrors.New(op, name, filesystem.ErrNotFound, fserrors.NotExist)
+	}
+	var (
+		components = strings.Split(name, "/")
+		pinName    = components[0]
+		pin, err   = cid.Decode(pinName)
+	)
+	if err != nil {
+		return nil, fserrors.New(op, name, err, cidErrKind(err))
+	}
+	info, err := pfs.walkComponents(subsys, components)
+	if err != nil {
+		return nil, err
+	}
+	_, pinned, err := pfs.api.IsPinned(pfs.ctx, corepath.IpfsPath(pin))
+	if err != nil {
+		return nil, fserrors.New(op, name, err, fserrors.IO)
+	}
+	if !pinned {
+		pfs.forgetWalk(pinName)
+		pfs.invalidateEntries()
+		return nil, fserrors.New(op, name, errNotPinned, fserrors.NotExist)
+	}
+	if !pfs.listedPin(pinName) {
+		pfs.invalidateEntries()
+	}
+	return info, nil
+}
+
+// walkComponents resolves the components that
+// are not in the cache, and adds them to it.
+func (pfs *PinFS) walkComponents(subsys fs.FS, components []string) (fs.FileInfo, error) {
+	var (
+		cache  = pfs.walkCache
+		parent cid.Cid
+		start  = len(components)
+	)
+	for ; start > 0; start-- {
+		prefix := strings.Join(components[:start], "/")
+		if nodeCID, ok := cache.Get(prefix); ok {
+			parent = nodeCID
+			break
+		}
+	}
+	pfs.walkHits.Add(uint64(start))
+	pfs.walkMisses.Add(uint64(len(components) - start))
+	if start == len(components) {
+		info, err := fs.Stat(subsys, parent.String())
+		if err != nil {
+			return nil, err
+		}
+		return renameInfo(info, components[start-1]), nil
+	}
+	var info fs.FileInfo
+	for i := start; i < len(components); i++ {
+		name := components[i]
+		if parent.Defined() {
+			name = parent.String() + "/" + name
+		}
+		var err error
+		if info, err = fs.Stat(subsys, name); err != nil {
+			return nil, err
+		}
+		cidInfo, ok := info.(filesystem.CIDInfo)
+		if !ok {
+			// Can't be cached; resolve the rest as-is.
+			return fs.Stat(subsys, strings.Join(components, "/"))
+		}
+		parent = cidInfo.CID()
+		cache.Add(strings.Join(components[:i+1], "/"), parent)
+	}
+	return info, nil
+}
+
+// forgetWalk removes the pin, and
+// the components beneath it, from the cache.
+func (pfs *PinFS) forgetWalk(pinName string) {
+	var (
+		cache  = pfs.walkCache
+		prefix = pinName + "/"
+	)
+	for _, key := range cache.Keys() {
+		if key == pinName || strings.HasPrefix(key, prefix) {
+			cache.Remove(key)
+		}
+	}
+}
+
+// invalidateEntries marks the cached pin set as stale,
+// including a set which is currently being fetched.
+func (pfs *PinFS) invalidateEntries() {
+	pfs.generation.Add(1)
+}
+
+// listedPin reports whether the pin is within the
+// cached pin set; or true if there is no cached set.
+func (pfs *PinFS) listedPin(pinName string) bool {
+	pins := pfs.snapshotPins.Load()
+	if pins == nil {
+		return true
+	}
+	_, ok := (*pins)[pinName]
+	return ok
+}
+
+// CacheStats returns the cache
+// statistics of [PinFS.WalkCached].
+func (pfs *PinFS) CacheStats() WalkCacheStats {
+	return WalkCacheStats{
+		Hits:   pfs.walkHits.Load(),
+		Misses: pfs.walkMisses.Load(),
+	}
+}
+
 func (pfs *PinFS) Open(name string) (fs.File, error) {
 	const op = "open"
+	name = filesystem.NormalizeRoot(name)
 	if name == filesystem.Root {
 		return pfs.openRoot()
 	}
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
 	if subsys := pfs.ipfs; subsys != nil {
 		return subsys.Open(name)
 	}
@@ -179,8 +393,15 @@ func (pfs *PinFS) validLocked() bool {
 	var (
 		expiry  = pfs.expiry
 		forever = expiry < 0
+		fetched = *pfs.info.modTime.Load()
 	)
-	if forever || time.Since(*pfs.info.modTime.Load()) < expiry {
+	if fetched.IsZero() {
+		return false // Not fetched yet.
+	}
+	if pfs.snapshotGeneration != pfs.generation.Load() {
+		return false // Pins changed since.
+	}
+	if forever || time.Since(fetched) < expiry {
 		return true
 	}
 	return false
@@ -204,7 +425,9 @@ func (pfs *PinFS) fetchEntries(ctx context.Context) (<-chan filesystem.StreamDir
 		return nil, err
 	}
 	var (
-		entries = newStreamChan(pins)
+		// NOTE: The buffer determines how many
+		// pins are fetched ahead of the reader.
+		entries = make(chan filesystem.StreamDirEntry, pfs.batchSize)
 		statFn  = pfs.statFn
 	)
 	go func() {
@@ -233,8 +456,11 @@ func (pfs *PinFS) fetchEntries(ctx context.Context) (<-chan filesystem.StreamDir
 }
 
 func (pfs *PinFS) fetchAndCacheThenUnlock(ctx context.Context) (<-chan filesystem.StreamDirEntry, error) {
-	fetchCtx, cancel := context.WithCancel(pfs.ctx)
-	fetched, err := pfs.fetchEntries(fetchCtx)
+	var (
+		generation       = pfs.generation.Load()
+		fetchCtx, cancel = context.WithCancel(pfs.ctx)
+		fetched, err     = pfs.fetchEntries(fetchCtx)
+	)
 	if err != nil {
 		pfs.cacheMu.Unlock()
 		cancel()
@@ -252,12 +478,35 @@ func (pfs *PinFS) fetchAndCacheThenUnlock(ctx context.Context) (<-chan filesyste
 			return // Caller must try to fetch again.
 		}
 		pfs.snapshot = generic.CompactSlice(snapshot)
+		pfs.snapshotGeneration = generation
+		pins := make(map[string]struct{}, len(pfs.snapshot))
+		for _, entry := range pfs.snapshot {
+			pins[entry.Name()] = struct{}{}
+		}
+		pfs.snapshotPins.Store(&pins)
 		now := time.Now()
 		pfs.info.modTime.Store(&now)
 	}()
 	return relay, nil
 }
 
+// prefetchEntries populates the cache.
+// The cache's lock is acquired before returning,
+// so listings made during the fetch wait for
+// its result rather than fetching again.
+func (pfs *PinFS) prefetchEntries() {
+	pfs.cacheMu.Lock()
+	go func() {
+		relay, err := pfs.fetchAndCacheThenUnlock(pfs.ctx)
+		if err != nil {
+			return // Listings will try again.
+		}
+		for range relay {
+			// Only the cache is needed.
+		}
+	}()
+}
+
 func (pfs *PinFS) Close() error {
 	pfs.cancel()
 	return nil
@@ -283,9 +532,7 @@ func (pd *pinDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
 	}
 	stream := pd.stream
 	if stream == nil {
-		// TODO: We don't have an error kind
-		// that translates into EBADF
-		return nil, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.IO)
+		return nil, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
 	}
 	var (
 		ctx       = stream.Context
@@ -304,10 +551,8 @@ func (pd *pinDirectory) StreamDir() <-chan filesystem.StreamDirEntry {
 	stream := pd.stream
 	if stream == nil {
 		errs := make(chan filesystem.StreamDirEntry, 1)
-		// TODO: We don't have an error kind
-		// that translates into EBADF
 		errs <- newErrorEntry(
-			fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.IO),
+			fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed),
 		)
 		return errs
 	}
@@ -321,9 +566,7 @@ func (pd *pinDirectory) Close() error {
 		pd.stream = nil
 		return nil
 	}
-	// TODO: We don't have an error kind
-	// that translates into EBADF
-	return fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.IO)
+	return fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
 }
 
 func (pe *pinDirEntry) Name() string {
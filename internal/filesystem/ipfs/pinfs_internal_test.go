@@ -2,10 +2,53 @@ package ipfs
 
 import (
 	"context"
+	"errors"
+	"io"
 	"io/fs"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	coreiface "github.com/ipfs/boxo/coreiface"
+	coreoptions "github.com/ipfs/boxo/coreiface/options"
+	corepath "github.com/ipfs/boxo/coreiface/path"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+type (
+	// countingPins counts calls to `Ls`,
+	// which block while `blocked` is set.
+	countingPins struct {
+		fixturePins
+		release chan struct{}
+		calls   atomic.Int32
+		blocked atomic.Bool
+	}
+	// sentPins counts the pins
+	// received from `Ls`.
+	sentPins struct {
+		fixturePins
+		sent atomic.Int32
+	}
+	// walkPins is a pin set
+	// which may change during a test.
+	walkPins struct {
+		coreiface.PinAPI
+		pins map[cid.Cid]struct{}
+		mu   sync.Mutex
+	}
+	// statHookFS calls `hook`
+	// before each call to `Stat`.
+	statHookFS struct {
+		fs.FS
+		hook func(name string)
+	}
 )
 
 var (
@@ -20,6 +63,12 @@ var (
 func TestPinFS(t *testing.T) {
 	t.Parallel()
 	t.Run("Options", testPinFSOptions)
+	t.Run("Conformance", testPinFSConformance)
+	t.Run("Root spellings", testPinFSRootSpellings)
+	t.Run("Initial pins", testPinFSInitialPins)
+	t.Run("Invalidation", testPinFSInvalidation)
+	t.Run("Walk cached", testPinFSWalkCached)
+	t.Run("Batches", testPinFSBatches)
 }
 
 func testPinFSOptions(t *testing.T) {
@@ -32,3 +81,386 @@ func testPinFSOptions(t *testing.T) {
 		WithPermissions[PinFSOption](0),
 	)
 }
+
+func (cp *countingPins) Ls(ctx context.Context, options ...coreoptions.PinLsOption) (<-chan coreiface.Pin, error) {
+	cp.calls.Add(1)
+	if cp.blocked.Load() {
+		select {
+		case <-cp.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return cp.fixturePins.Ls(ctx, options...)
+}
+
+func testPinFSInitialPins(t *testing.T) {
+	t.Parallel()
+	fixture := newFixture(t)
+	pins := &countingPins{
+		fixturePins: fixturePins{pins: []cid.Cid{fixture.root}},
+		release:     make(chan struct{}),
+	}
+	defer close(pins.release)
+	fsys, err := NewPinFS(pins,
+		CachePinsFor(-1),
+		WithInitialPins(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	// Wait for the prefetch to be cached.
+	const (
+		timeout  = 5 * time.Second
+		interval = time.Millisecond
+	)
+	for deadline := time.Now().Add(timeout); ; time.Sleep(interval) {
+		if !fsys.info.ModTime().IsZero() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("pins were not prefetched")
+		}
+	}
+	// Listings which reach the node
+	// will now block until the test ends.
+	pins.blocked.Store(true)
+	listing := make(chan []fs.DirEntry, 1)
+	go func() {
+		entries, err := fs.ReadDir(fsys, filesystem.Root)
+		if err != nil {
+			t.Error(err)
+		}
+		listing <- entries
+	}()
+	select {
+	case entries := <-listing:
+		if got, want := len(entries), len(pins.pins); got != want {
+			t.Errorf("entry count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				got, want,
+			)
+		}
+	case <-time.After(timeout):
+		t.Fatal("listing blocked on the node despite prefetch")
+	}
+	if calls := pins.calls.Load(); calls != 1 {
+		t.Errorf("expected 1 call to the pin API, got: %d", calls)
+	}
+}
+
+func (wp *walkPins) IsPinned(_ context.Context, path corepath.Path,
+	_ ...coreoptions.PinIsPinnedOption,
+) (string, bool, error) {
+	resolved, ok := path.(corepath.Resolved)
+	if !ok {
+		return "", false, fs.ErrInvalid
+	}
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	_, pinned := wp.pins[resolved.Cid()]
+	return "recursive", pinned, nil
+}
+
+func (wp *walkPins) Ls(ctx context.Context, options ...coreoptions.PinLsOption) (<-chan coreiface.Pin, error) {
+	wp.mu.Lock()
+	pins := make([]cid.Cid, 0, len(wp.pins))
+	for pin := range wp.pins {
+		pins = append(pins, pin)
+	}
+	wp.mu.Unlock()
+	return fixturePins{pins: pins}.Ls(ctx, options...)
+}
+
+func (wp *walkPins) setPinned(pin cid.Cid, pinned bool) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	if pinned {
+		wp.pins[pin] = struct{}{}
+	} else {
+		delete(wp.pins, pin)
+	}
+}
+
+func (sh *statHookFS) Stat(name string) (fs.FileInfo, error) {
+	sh.hook(name)
+	return fs.Stat(sh.FS, name)
+}
+
+func testPinFSInvalidation(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		root    = fixture.root
+		pins    = &walkPins{
+			pins: map[cid.Cid]struct{}{root: {}},
+		}
+	)
+	fsys, err := NewPinFS(pins,
+		WithIPFS(fixture.newIPFS(t)),
+		CachePinsFor(-1),
+		WithInitialPins(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	var (
+		name = root.String() + "/file"
+		list = func(t *testing.T, want int) {
+			t.Helper()
+			entries, err := fs.ReadDir(fsys, filesystem.Root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(entries); got != want {
+				t.Errorf("entry count mismatch"+
+					"\ngot: %d"+
+					"\nwant: %d",
+					got, want,
+				)
+			}
+		}
+	)
+	list(t, 1)
+	pins.setPinned(root, false)
+	list(t, 1) // Cached.
+	_, err = fsys.WalkCached(name)
+	writeKindMatch(t, err, fserrors.NotExist)
+	list(t, 0)
+	pins.setPinned(root, true)
+	list(t, 0) // Cached.
+	if _, err := fsys.WalkCached(name); err != nil {
+		t.Fatal(err)
+	}
+	list(t, 1)
+}
+
+func testPinFSWalkCached(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		root    = fixture.root
+		pins    = &walkPins{
+			pins: map[cid.Cid]struct{}{root: {}},
+		}
+		stats  int
+		count  = func(string) { stats++ }
+		hooked = &statHookFS{
+			FS:   fixture.newIPFS(t),
+			hook: count,
+		}
+	)
+	fsys, err := NewPinFS(pins, WithIPFS(hooked))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	walk := func(t *testing.T, name string, want WalkCacheStats, wantStats int) {
+		t.Helper()
+		var (
+			before = fsys.CacheStats()
+			path   = root.String() + "/" + name
+		)
+		stats = 0
+		info, err := fsys.WalkCached(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats != wantStats {
+			t.Errorf("stat count mismatch for \"%s\""+
+				"\ngot: %d"+
+				"\nwant: %d",
+				name, stats, wantStats,
+			)
+		}
+		expected, err := fs.Stat(hooked.FS, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Name() != expected.Name() || info.Size() != expected.Size() {
+			t.Errorf("info mismatch for \"%s\""+
+				"\ngot: %s (%d bytes)"+
+				"\nwant: %s (%d bytes)",
+				name, info.Name(), info.Size(),
+				expected.Name(), expected.Size(),
+			)
+		}
+		after := fsys.CacheStats()
+		got := WalkCacheStats{
+			Hits:   after.Hits - before.Hits,
+			Misses: after.Misses - before.Misses,
+		}
+		if got != want {
+			t.Errorf("cache stats mismatch for \"%s\""+
+				"\ngot: %+v"+
+				"\nwant: %+v",
+				name, got, want,
+			)
+		}
+	}
+	walk(t, "nested/file", WalkCacheStats{Misses: 3}, 3)
+	walk(t, "nested/file", WalkCacheStats{Hits: 3}, 1)
+	walk(t, "nested/raw", WalkCacheStats{Hits: 2, Misses: 1}, 1)
+	// Unpinned during the walk.
+	hooked.hook = func(string) { pins.setPinned(root, false) }
+	name := root.String() + "/file"
+	_, err = fsys.WalkCached(name)
+	writeKindMatch(t, err, fserrors.NotExist)
+	if cached := fsys.walkCache.Len(); cached != 0 {
+		t.Errorf("%d components remain cached after unpinning: %v",
+			cached, fsys.walkCache.Keys(),
+		)
+	}
+	hooked.hook = count
+	pins.setPinned(root, true)
+	walk(t, "nested/file", WalkCacheStats{Misses: 3}, 3)
+}
+
+func (sp *sentPins) Ls(ctx context.Context, options ...coreoptions.PinLsOption) (<-chan coreiface.Pin, error) {
+	pins, err := sp.fixturePins.Ls(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	relay := make(chan coreiface.Pin)
+	go func() {
+		defer close(relay)
+		for pin := range pins {
+			select {
+			case relay <- pin:
+				sp.sent.Add(1)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return relay, nil
+}
+
+func testPinFSBatches(t *testing.T) {
+	t.Parallel()
+	const (
+		pinCount  = 64
+		batchSize = 4
+		readCount = 3
+	)
+	var (
+		pinned = make([]cid.Cid, pinCount)
+		want   = make([]string, pinCount)
+	)
+	for i := range pinned {
+		digest, err := multihash.Sum([]byte(strconv.Itoa(i)), multihash.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pinned[i] = cid.NewCidV1(cid.Raw, digest)
+		want[i] = pinned[i].String()
+	}
+	for _, test := range []struct {
+		name    string
+		options []PinFSOption
+		lazy    bool
+	}{
+		{name: "uncached", lazy: true},
+		{name: "cached", options: []PinFSOption{CachePinsFor(-1)}},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			pins := &sentPins{fixturePins: fixturePins{pins: pinned}}
+			fsys, err := NewPinFS(pins,
+				append(test.options, WithPinBatchSize(batchSize))...,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				if err := fsys.Close(); err != nil {
+					t.Error(err)
+				}
+			})
+			if test.lazy {
+				root, err := fsys.Open(filesystem.Root)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := root.(fs.ReadDirFile).ReadDir(readCount); err != nil {
+					t.Fatal(err)
+				}
+				// Read, buffered, and held by the fetcher.
+				const limit = readCount + batchSize + 1
+				if sent := pins.sent.Load(); sent > limit {
+					t.Errorf("fetched %d pins after reading %d (limit %d)",
+						sent, readCount, limit,
+					)
+				}
+				if err := root.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Listings resumed from an offset
+			// must continue from the same entry.
+			for _, offset := range []int{0, readCount, pinCount / 2, pinCount - 1} {
+				got := readPinNames(t, fsys, offset, readCount)
+				if !reflect.DeepEqual(got, want[offset:]) {
+					t.Errorf("listing from offset %d mismatch"+
+						"\ngot: %v"+
+						"\nwant: %v",
+						offset, got, want[offset:],
+					)
+				}
+			}
+		})
+	}
+}
+
+// readPinNames skips `offset` entries of the root,
+// then reads the rest, `count` entries at a time.
+func readPinNames(t *testing.T, fsys *PinFS, offset, count int) []string {
+	t.Helper()
+	root, err := fsys.Open(filesystem.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	directory := root.(fs.ReadDirFile)
+	if offset > 0 {
+		skipped, err := directory.ReadDir(offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(skipped) != offset {
+			t.Fatalf("read %d entries, expected %d", len(skipped), offset)
+		}
+	}
+	var names []string
+	for {
+		entries, err := directory.ReadDir(count)
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := root.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
@@ -0,0 +1,165 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	coreiface "github.com/ipfs/boxo/coreiface"
+	coreoptions "github.com/ipfs/boxo/coreiface/options"
+	corepath "github.com/ipfs/boxo/coreiface/path"
+	"github.com/ipfs/go-cid"
+)
+
+type (
+	// keyPublisher debounces the publishing of keys;
+	// publishing a key's latest root after no changes
+	// have been made to it for `interval`.
+	keyPublisher struct {
+		ctx       context.Context
+		names     coreiface.NameAPI
+		pending   map[string]*pendingPublish
+		keys      map[string]*publishedKey
+		published func(keyName string)
+		errs      []error
+		interval  time.Duration
+		sequence  uint64
+		mu        sync.Mutex
+		inFlight  sync.WaitGroup
+	}
+	pendingPublish struct {
+		timer    *time.Timer
+		root     cid.Cid
+		sequence uint64
+	}
+	// publishedKey serializes publishes for a key,
+	// and tracks the sequence of the latest root
+	// that was published for it; so that a root
+	// which was scheduled before it is dropped
+	// rather than published over it.
+	publishedKey struct {
+		mu     sync.Mutex
+		latest uint64
+	}
+)
+
+// newKeyPublisher constructs a publisher.
+// Its context (and optional callback)
+// must be set before it's used.
+func newKeyPublisher(names coreiface.NameAPI, interval time.Duration) *keyPublisher {
+	return &keyPublisher{
+		names:    names,
+		interval: interval,
+		pending:  make(map[string]*pendingPublish),
+		keys:     make(map[string]*publishedKey),
+	}
+}
+
+// pendingRoot returns the root which is
+// waiting to be published for the key (if any).
+func (kp *keyPublisher) pendingRoot(keyName string) (cid.Cid, bool) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if pending, ok := kp.pending[keyName]; ok {
+		return pending.root, true
+	}
+	return cid.Cid{}, false
+}
+
+// schedule publishes the root for the key after
+// the interval; unless another root is scheduled
+// for the key before then (which replaces it).
+func (kp *keyPublisher) schedule(keyName string, root cid.Cid) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	kp.sequence++
+	if pending, ok := kp.pending[keyName]; ok {
+		pending.root = root
+		pending.sequence = kp.sequence
+		pending.timer.Reset(kp.interval)
+		return
+	}
+	kp.pending[keyName] = &pendingPublish{
+		root:     root,
+		sequence: kp.sequence,
+		timer: time.AfterFunc(kp.interval, func() {
+			kp.publishPending(keyName)
+		}),
+	}
+}
+
+func (kp *keyPublisher) publishPending(keyName string) {
+	kp.mu.Lock()
+	pending, ok := kp.pending[keyName]
+	if !ok {
+		kp.mu.Unlock()
+		return // Flushed.
+	}
+	delete(kp.pending, keyName)
+	kp.inFlight.Add(1)
+	kp.mu.Unlock()
+	defer kp.inFlight.Done()
+	if err := kp.publish(keyName, pending); err != nil {
+		kp.mu.Lock()
+		kp.errs = append(kp.errs, err)
+		kp.mu.Unlock()
+	}
+}
+
+// publish publishes the pending root for the key,
+// unless a root which was scheduled after it
+// has already been published.
+func (kp *keyPublisher) publish(keyName string, pending *pendingPublish) error {
+	key := kp.publishedKey(keyName)
+	key.mu.Lock()
+	defer key.mu.Unlock()
+	if pending.sequence <= key.latest {
+		return nil // Superseded.
+	}
+	key.latest = pending.sequence
+	if _, err := kp.names.Publish(kp.ctx,
+		corepath.IpfsPath(pending.root),
+		coreoptions.Name.Key(keyName),
+	); err != nil {
+		return fmt.Errorf(`publish "%s": %w`, keyName, err)
+	}
+	if published := kp.published; published != nil {
+		published(keyName)
+	}
+	return nil
+}
+
+func (kp *keyPublisher) publishedKey(keyName string) *publishedKey {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	key, ok := kp.keys[keyName]
+	if !ok {
+		key = new(publishedKey)
+		kp.keys[keyName] = key
+	}
+	return key
+}
+
+// flush publishes any pending roots immediately,
+// waits for publishes already in progress,
+// then returns any errors encountered
+// since the last flush.
+func (kp *keyPublisher) flush() error {
+	kp.mu.Lock()
+	pending := kp.pending
+	kp.pending = make(map[string]*pendingPublish)
+	kp.mu.Unlock()
+	errs := make([]error, 0, len(pending))
+	for keyName, publish := range pending {
+		publish.timer.Stop()
+		errs = append(errs, kp.publish(keyName, publish))
+	}
+	kp.inFlight.Wait()
+	kp.mu.Lock()
+	errs = append(errs, kp.errs...)
+	kp.errs = nil
+	kp.mu.Unlock()
+	return errors.Join(errs...)
+}
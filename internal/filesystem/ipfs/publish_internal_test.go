@@ -0,0 +1,93 @@
+package ipfs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coreiface "github.com/ipfs/boxo/coreiface"
+	coreoptions "github.com/ipfs/boxo/coreiface/options"
+	corepath "github.com/ipfs/boxo/coreiface/path"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+// blockingNameAPI records published roots,
+// blocking each publish until it's released.
+type blockingNameAPI struct {
+	coreiface.NameAPI
+	entered   chan corepath.Path
+	release   chan struct{}
+	published []string
+}
+
+func (na *blockingNameAPI) Publish(_ context.Context, path corepath.Path, _ ...coreoptions.NamePublishOption) (coreiface.IpnsEntry, error) {
+	na.entered <- path
+	<-na.release
+	na.published = append(na.published, path.(corepath.Resolved).Cid().String())
+	return nil, nil
+}
+
+func TestKeyPublisher(t *testing.T) {
+	t.Parallel()
+	t.Run("ordering", testKeyPublisherOrdering)
+}
+
+func testKeyPublisherOrdering(t *testing.T) {
+	t.Parallel()
+	const keyName = "key"
+	var (
+		names = &blockingNameAPI{
+			entered: make(chan corepath.Path),
+			release: make(chan struct{}),
+		}
+		publisher = newKeyPublisher(names, time.Hour)
+		newRoot   = func(data string) cid.Cid {
+			root, err := cid.NewPrefixV1(cid.Raw, multihash.SHA2_256).
+				Sum([]byte(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return root
+		}
+		older = newRoot("older")
+		newer = newRoot("newer")
+	)
+	publisher.ctx = context.Background()
+	// Take the older root out of the pending set
+	// (as its timer would), but don't publish it
+	// until the newer root has been published.
+	publisher.schedule(keyName, older)
+	publisher.mu.Lock()
+	stale := publisher.pending[keyName]
+	delete(publisher.pending, keyName)
+	publisher.mu.Unlock()
+	stale.timer.Stop()
+	publisher.schedule(keyName, newer)
+	flushed := make(chan error, 1)
+	go func() { flushed <- publisher.flush() }()
+	<-names.entered
+	names.release <- struct{}{}
+	if err := <-flushed; err != nil {
+		t.Fatal(err)
+	}
+	published := make(chan error, 1)
+	go func() { published <- publisher.publish(keyName, stale) }()
+	select {
+	case path := <-names.entered:
+		names.release <- struct{}{}
+		t.Errorf("older root was published after newer root: %s", path)
+	case err := <-published:
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := names.published, []string{newer.String()}; len(got) != len(want) ||
+		got[0] != want[0] {
+		t.Errorf("published roots mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, want,
+		)
+	}
+}
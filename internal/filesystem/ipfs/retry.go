@@ -0,0 +1,48 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	ipld "github.com/ipfs/go-ipld-format"
+)
+
+// withRetry calls `fn` up to `attempts` times,
+// waiting `backoff` before the first retry and
+// doubling the wait before each subsequent retry.
+// Errors which indicate the requested data does
+// not exist are returned without retrying.
+func withRetry[T any](ctx context.Context,
+	attempts int, backoff time.Duration,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	for attempt := 1; ; attempt++ {
+		value, err := fn(ctx)
+		if err == nil ||
+			attempt >= attempts ||
+			!retryable(err) {
+			return value, err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+			backoff *= 2
+		case <-ctx.Done():
+			timer.Stop()
+			return value, errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+func retryable(err error) bool {
+	if ipld.IsNotFound(err) {
+		return false
+	}
+	var fsErr *fserrors.Error
+	if errors.As(err, &fsErr) {
+		return fsErr.Kind != fserrors.NotExist
+	}
+	return resolveErrKind(err) != fserrors.NotExist
+}
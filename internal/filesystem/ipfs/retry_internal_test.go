@@ -0,0 +1,175 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	coreiface "github.com/ipfs/boxo/coreiface"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/multiformats/go-multihash"
+)
+
+type (
+	// flakyCore fails its first `failures`
+	// node requests with a transient error.
+	flakyCore struct {
+		*fixtureCore
+		dag flakyDag
+	}
+	flakyDag struct {
+		fixtureDag
+		failures *atomic.Int32
+		requests *atomic.Int32
+	}
+)
+
+func (core *flakyCore) Dag() coreiface.APIDagService { return core.dag }
+
+func (fd flakyDag) Get(ctx context.Context, c cid.Cid) (ipld.Node, error) {
+	fd.requests.Add(1)
+	if fd.failures.Add(-1) >= 0 {
+		return nil, errFlaky
+	}
+	return fd.fixtureDag.Get(ctx, c)
+}
+
+func newFlakyCore(fixture *fixture, failures int32) *flakyCore {
+	dag := flakyDag{
+		fixtureDag: fixture.core.dag,
+		failures:   new(atomic.Int32),
+		requests:   new(atomic.Int32),
+	}
+	dag.failures.Store(failures)
+	return &flakyCore{
+		fixtureCore: fixture.core,
+		dag:         dag,
+	}
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+	fixture := newFixture(t)
+	const (
+		attempts = 3
+		backoff  = time.Millisecond
+	)
+	newFS := func(t *testing.T, core coreiface.CoreAPI, options ...IPFSOption) *IPFS {
+		t.Helper()
+		fsys, err := NewIPFS(core, append(options,
+			WithNodeCacheCount(0),
+		)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := fsys.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		return fsys
+	}
+	name := fixture.root.String() + "/file"
+	t.Run("transient", func(t *testing.T) {
+		t.Parallel()
+		var (
+			core = newFlakyCore(fixture, attempts-1)
+			fsys = newFS(t, core, WithRetry(attempts, backoff))
+		)
+		if _, err := fs.ReadFile(fsys, name); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+		var (
+			core = newFlakyCore(fixture, attempts)
+			fsys = newFS(t, core, WithRetry(attempts, backoff))
+		)
+		_, err := fsys.fetchNode(fixture.root)
+		if !errors.Is(err, errFlaky) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, errFlaky,
+			)
+		}
+		if got := core.dag.requests.Load(); got != attempts {
+			t.Errorf("request count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				got, attempts,
+			)
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		var (
+			core = newFlakyCore(fixture, 1)
+			fsys = newFS(t, core)
+		)
+		if _, err := fsys.fetchNode(fixture.root); !errors.Is(err, errFlaky) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, errFlaky,
+			)
+		}
+	})
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		digest, err := multihash.Sum([]byte("missing"), multihash.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var (
+			core    = newFlakyCore(fixture, 0)
+			fsys    = newFS(t, core, WithRetry(attempts, time.Hour))
+			missing = cid.NewCidV1(cid.Raw, digest)
+		)
+		// Would block for the backoff duration
+		// if missing nodes were retried.
+		_, err = fsys.fetchNode(missing)
+		if !ipld.IsNotFound(err) {
+			t.Errorf("expected not found error, got: %v", err)
+		}
+		if got := core.dag.requests.Load(); got != 1 {
+			t.Errorf("missing node was requested %d times", got)
+		}
+	})
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		var (
+			core = newFlakyCore(fixture, attempts)
+			fsys = newFS(t, core,
+				WithContext[IPFSOption](ctx),
+				WithRetry(attempts, time.Hour),
+			)
+		)
+		cancel()
+		_, err := fsys.fetchNode(fixture.root)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, context.Canceled,
+			)
+		}
+	})
+	t.Run("kind", func(t *testing.T) {
+		t.Parallel()
+		notExist := fserrors.New("op", "name", errFlaky, fserrors.NotExist)
+		if retryable(notExist) {
+			t.Error("NotExist errors must not be retried")
+		}
+		if !retryable(errFlaky) {
+			t.Error("transient errors must be retried")
+		}
+	})
+}
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"io"
 	"io/fs"
+	"mime"
+	"net/http"
+	"path"
 	"strings"
 	"time"
 
@@ -16,6 +19,7 @@ import (
 	"github.com/ipfs/boxo/ipld/unixfs"
 	unixpb "github.com/ipfs/boxo/ipld/unixfs/pb"
 	"github.com/ipfs/boxo/path/resolver"
+	"github.com/ipfs/go-cid"
 	ipfscmds "github.com/ipfs/go-ipfs-cmds"
 	cbor "github.com/ipfs/go-ipld-cbor"
 	ipld "github.com/ipfs/go-ipld-format"
@@ -32,12 +36,28 @@ type (
 		setPermissions(fs.FileMode)
 	}
 	nodeInfo struct {
-		modTime time.Time
-		name    string
-		size    int64
-		mode    fs.FileMode
+		modTime     time.Time
+		cid         cid.Cid
+		name        string
+		contentType string
+		size        int64
+		// entryCount is only valid if
+		// entryCounted is set.
+		entryCount   int
+		entryCounted bool
+		mode         fs.FileMode
+	}
+	// renamedInfo reports the name its file
+	// was accessed by, rather than the name
+	// of the node it refers to.
+	renamedInfo struct {
+		fs.FileInfo
+		name string
+	}
+	emptyRoot struct {
+		info   *nodeInfo
+		closed bool
 	}
-	emptyRoot      struct{ info *nodeInfo }
 	ctxChan[T any] struct {
 		context.Context
 		context.CancelFunc
@@ -57,11 +77,21 @@ type (
 
 const (
 	errUnexpectedType = generic.ConstError("unexpected type")
-	executeAll        = filesystem.ExecuteUser | filesystem.ExecuteGroup | filesystem.ExecuteOther
-	readAll           = filesystem.ReadUser | filesystem.ReadGroup | filesystem.ReadOther
+	errNamesOnly      = generic.ConstError("directory is being read by name")
+	// See: [http.DetectContentType].
+	contentTypeSniffLength = 512
+	// See: https://www.iana.org/assignments/media-types/application/vnd.ipld.dag-cbor
+	dagCborContentType = "application/vnd.ipld.dag-cbor"
+	executeAll         = filesystem.ExecuteUser | filesystem.ExecuteGroup | filesystem.ExecuteOther
+	readAll            = filesystem.ReadUser | filesystem.ReadGroup | filesystem.ReadOther
 )
 
-var _ fs.FileInfo = (*nodeInfo)(nil)
+var (
+	_ filesystem.CIDInfo          = (*nodeInfo)(nil)
+	_ filesystem.ContentTypeInfo  = (*nodeInfo)(nil)
+	_ filesystem.EntryCountInfo   = (*nodeInfo)(nil)
+	_ filesystem.CreationTimeInfo = (*nodeInfo)(nil)
+)
 
 func (ee errorEntry) Error() error { return ee.error }
 
@@ -99,15 +129,60 @@ func (ni *nodeInfo) Mode() fs.FileMode  { return ni.mode }
 func (ni *nodeInfo) ModTime() time.Time { return ni.modTime }
 func (ni *nodeInfo) IsDir() bool        { return ni.Mode().IsDir() }
 func (ni *nodeInfo) Sys() any           { return ni }
+func (ni *nodeInfo) CID() cid.Cid       { return ni.cid }
+
+// CreationTime returns the time the file system was
+// initialized. Nodes are immutable, so this
+// is the same as their modification time.
+func (ni *nodeInfo) CreationTime() time.Time { return ni.modTime }
+
+// ContentType returns the media type of the file,
+// if it was detected when the file was opened.
+func (ni *nodeInfo) ContentType() string { return ni.contentType }
+
+// EntryCount returns the number of entries
+// within the directory, if it was known
+// without listing the directory.
+func (ni *nodeInfo) EntryCount() (int, bool) { return ni.entryCount, ni.entryCounted }
+
+func (ri *renamedInfo) Name() string { return ri.name }
+
+// renameInfo returns `info` with its name
+// replaced by the last element of `name`.
+func renameInfo(info fs.FileInfo, name string) fs.FileInfo {
+	name = path.Base(name)
+	if info.Name() == name {
+		return info
+	}
+	if nodeInfo, ok := info.(*nodeInfo); ok {
+		renamed := *nodeInfo
+		renamed.name = name
+		return &renamed
+	}
+	return &renamedInfo{
+		FileInfo: info,
+		name:     name,
+	}
+}
 
 func (cde *coreDirEntry) Name() string               { return cde.DirEntry.Name }
 func (cde *coreDirEntry) IsDir() bool                { return cde.Type().IsDir() }
 func (cde *coreDirEntry) Info() (fs.FileInfo, error) { return cde, nil }
-func (cde *coreDirEntry) Size() int64                { return int64(cde.DirEntry.Size) }
 func (cde *coreDirEntry) ModTime() time.Time         { return cde.modTime }
 func (cde *coreDirEntry) Mode() fs.FileMode          { return cde.Type() | cde.permissions }
 func (cde *coreDirEntry) Sys() any                   { return cde }
 func (cde *coreDirEntry) Error() error               { return cde.DirEntry.Err }
+
+// Size returns the UnixFS file size of the entry
+// (the same as [statNode]). Directories have no file size,
+// regardless of the (cumulative) size reported by the API.
+func (cde *coreDirEntry) Size() int64 {
+	if cde.DirEntry.Type == coreiface.TDirectory {
+		return 0
+	}
+	return int64(cde.DirEntry.Size)
+}
+
 func (cde *coreDirEntry) Type() fs.FileMode {
 	switch cde.DirEntry.Type {
 	case coreiface.TDirectory:
@@ -121,14 +196,30 @@ func (cde *coreDirEntry) Type() fs.FileMode {
 	}
 }
 
-func (er emptyRoot) Stat() (fs.FileInfo, error) { return er.info, nil }
-func (emptyRoot) Close() error                  { return nil }
-func (emptyRoot) Read([]byte) (int, error) {
+func (er *emptyRoot) Stat() (fs.FileInfo, error) { return er.info, nil }
+
+func (er *emptyRoot) Close() error {
+	const op = "emptyRoot.Close"
+	if er.closed {
+		return fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	er.closed = true
+	return nil
+}
+
+func (er *emptyRoot) Read([]byte) (int, error) {
 	const op = "emptyRoot.Read"
+	if er.closed {
+		return -1, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
+	}
 	return -1, fserrors.New(op, filesystem.Root, filesystem.ErrIsDir, fserrors.IsDir)
 }
 
-func (emptyRoot) ReadDir(count int) ([]fs.DirEntry, error) {
+func (er *emptyRoot) ReadDir(count int) ([]fs.DirEntry, error) {
+	if er.closed {
+		const op = "emptyRoot.ReadDir"
+		return nil, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
+	}
 	if count > 0 {
 		return nil, io.EOF
 	}
@@ -136,6 +227,7 @@ func (emptyRoot) ReadDir(count int) ([]fs.DirEntry, error) {
 }
 
 func statNode(node ipld.Node, info *nodeInfo) error {
+	info.cid = node.Cid()
 	switch typedNode := node.(type) {
 	case *dag.ProtoNode:
 		return statProto(typedNode, info)
@@ -153,7 +245,15 @@ func statProto(node *dag.ProtoNode, info *nodeInfo) error {
 	}
 	info.size = int64(ufsNode.FileSize())
 	switch ufsNode.Type() {
-	case unixpb.Data_Directory, unixpb.Data_HAMTShard:
+	case unixpb.Data_Directory:
+		info.mode |= fs.ModeDir
+		// Each link is an entry.
+		info.entryCount = len(node.Links())
+		info.entryCounted = true
+	case unixpb.Data_HAMTShard:
+		// Links may point to other shards
+		// rather than entries; the count
+		// is not known without listing.
 		info.mode |= fs.ModeDir
 	case unixpb.Data_Symlink:
 		info.mode |= fs.ModeSymlink
@@ -194,6 +294,79 @@ func generateEntryChan(ctx context.Context, values []filesystem.StreamDirEntry)
 	return out
 }
 
+func generateNameChan(ctx context.Context, values []filesystem.StreamDirEntry) <-chan coreiface.DirEntry {
+	out := make(chan coreiface.DirEntry, 1)
+	go func() {
+		defer close(out)
+		for _, value := range values {
+			select {
+			case out <- coreiface.DirEntry{Name: value.Name()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// readNames is like [readEntries]
+// but only returns entry names.
+func readNames(ctx context.Context,
+	entries <-chan coreiface.DirEntry, count int,
+) ([]string, error) {
+	var (
+		readAll = count <= 0
+		names   []string
+	)
+	if readAll {
+		names = make([]string, 0, cap(entries))
+	} else {
+		const upperBound = 16
+		names = make([]string, 0, generic.Min(count, upperBound))
+	}
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				if err := ctx.Err(); err != nil {
+					return readNamesErr(names, readAll, err)
+				}
+				if len(names) == 0 && !readAll {
+					return names, io.EOF
+				}
+				return names, nil
+			}
+			if err := entry.Err; err != nil {
+				return readNamesErr(names, readAll, err)
+			}
+			names = append(names, entry.Name)
+			if count--; count == 0 {
+				return names, nil
+			}
+		case <-ctx.Done():
+			return readNamesErr(names, readAll, ctx.Err())
+		}
+	}
+}
+
+func readNamesErr(names []string, readAll bool, err error) ([]string, error) {
+	if !readAll {
+		names = nil
+	}
+	return names, err
+}
+
+func entryNames(entries []fs.DirEntry) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	return names
+}
+
 // readEntries handles different behaviour expected by
 // [fs.ReadDirFile].
 // Specifically in regard to the returned values.
@@ -208,7 +381,15 @@ func readEntries(ctx context.Context,
 		requested = make([]fs.DirEntry, 0, generic.Min(count, upperBound))
 	}
 	requested, err = readEntriesCount(ctx, entries, requested, count)
-	if err != nil && !readAll {
+	if readAll {
+		// [fs.ReadDirFile] requires a nil error
+		// at the end of the directory (for counts <= 0).
+		if err == io.EOF {
+			err = nil
+		}
+		return
+	}
+	if err != nil {
 		requested = nil
 	}
 	return
@@ -392,3 +573,26 @@ func fsTypeName(mode fs.FileMode) string {
 		return "irregular"
 	}
 }
+
+// detectContentType determines the media type of a
+// file from its name's extension, or (if not known)
+// by sniffing its leading bytes.
+// The file's offset is restored to the start.
+func detectContentType(name string, file io.ReadSeeker) (string, error) {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType, nil
+	}
+	var (
+		buffer    = make([]byte, contentTypeSniffLength)
+		read, err = io.ReadFull(file, buffer)
+	)
+	if err != nil &&
+		!errors.Is(err, io.EOF) &&
+		!errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(buffer[:read]), nil
+}
@@ -0,0 +1,145 @@
+package ipfs
+
+import (
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/ipfs/go-cid"
+)
+
+// SnapshotFS is a read-only view of a directory
+// (or file), fixed to the version it had when
+// the snapshot was taken.
+// Later changes to names which lead to the
+// snapshot (e.g. IPNS records) are not observed.
+type SnapshotFS struct {
+	fsys fs.FS
+	// immutable serves the snapshot's content;
+	// relative to `root`.
+	immutable fs.FS
+	root      string
+	cid       cid.Cid
+}
+
+const (
+	errSnapshotCID         = generic.ConstError("file does not have a CID")
+	errSnapshotUnsupported = generic.ConstError("file system does not support snapshots")
+)
+
+// NewSnapshotFS resolves `name` within `fsys` to a CID,
+// and serves the content of that CID for the lifetime
+// of the returned file system.
+// [IPNS] and [KeyFS] names are resolved once, here.
+// Systems whose names are already immutable
+// ([IPFS] and [PinFS]) are passed through to.
+func NewSnapshotFS(fsys fs.FS, name string) (*SnapshotFS, error) {
+	const op = "snapshot"
+	name = filesystem.NormalizeRoot(name)
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	snapshotCID, err := statCID(op, fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	immutable, root, err := immutableFS(op, fsys, name, snapshotCID)
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotFS{
+		fsys:      fsys,
+		immutable: immutable,
+		root:      root,
+		cid:       snapshotCID,
+	}, nil
+}
+
+// statCID returns the CID of `name`.
+func statCID(op string, fsys fs.FS, name string) (cid.Cid, error) {
+	if ipfs, ok := fsys.(*IPFS); ok &&
+		name == filesystem.Root && ipfs.root.Defined() {
+		return ipfs.root, nil
+	}
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return cid.Cid{}, err
+	}
+	if cidInfo, ok := info.(filesystem.CIDInfo); ok {
+		if infoCID := cidInfo.CID(); infoCID.Defined() {
+			return infoCID, nil
+		}
+	}
+	return cid.Cid{}, fserrors.New(op, name, errSnapshotCID, fserrors.InvalidItem)
+}
+
+// immutableFS returns the system which serves
+// the snapshot, and the snapshot's name within it.
+func immutableFS(op string, fsys fs.FS, name string, snapshotCID cid.Cid) (fs.FS, string, error) {
+	switch typed := fsys.(type) {
+	case *IPFS, *PinFS:
+		return fsys, name, nil
+	case *IPNS:
+		return typed.ipfs, snapshotCID.String(), nil
+	case *KeyFS:
+		if ipns := typed.ipns; ipns != nil {
+			return immutableFS(op, ipns, name, snapshotCID)
+		}
+	}
+	return nil, "", fserrors.New(op, name, errSnapshotUnsupported, fserrors.InvalidOperation)
+}
+
+// CID returns the CID that
+// the snapshot was taken of.
+func (sfs *SnapshotFS) CID() cid.Cid { return sfs.cid }
+
+// ID returns the ID of the system
+// the snapshot was taken from.
+func (sfs *SnapshotFS) ID() filesystem.ID {
+	if idFS, ok := sfs.fsys.(filesystem.IDFS); ok {
+		return idFS.ID()
+	}
+	return ""
+}
+
+func (sfs *SnapshotFS) Open(name string) (fs.File, error) {
+	const op = "open"
+	fullName, err := sfs.fullName(op, name)
+	if err != nil {
+		return nil, err
+	}
+	return sfs.immutable.Open(fullName)
+}
+
+func (sfs *SnapshotFS) Stat(name string) (fs.FileInfo, error) {
+	const op = "stat"
+	fullName, err := sfs.fullName(op, name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(sfs.immutable, fullName)
+	if err != nil {
+		return nil, err
+	}
+	return renameInfo(info, name), nil
+}
+
+func (sfs *SnapshotFS) fullName(op, name string) (string, error) {
+	name = filesystem.NormalizeRoot(name)
+	if !fs.ValidPath(name) {
+		return "", fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	return path.Join(sfs.root, name), nil
+}
+
+// Close closes the system the snapshot
+// was taken from (if it implements [io.Closer]).
+func (sfs *SnapshotFS) Close() error {
+	if closer, ok := sfs.fsys.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package ipfs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/ipfs/go-cid"
+)
+
+var (
+	_ fs.StatFS       = (*SnapshotFS)(nil)
+	_ filesystem.IDFS = (*SnapshotFS)(nil)
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+	t.Run("IPNS", testSnapshotIPNS)
+	t.Run("IPFS", testSnapshotIPFS)
+	t.Run("invalid", testSnapshotInvalid)
+}
+
+func testSnapshotIPNS(t *testing.T) {
+	t.Parallel()
+	fixture := newFixture(t)
+	ipns, err := NewIPNS(fixture.core, fixture.newIPFS(t),
+		CacheNodesFor(time.Nanosecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := NewSnapshotFS(ipns, fixtureIPNSName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Close()
+	snapshotCIDMatch(t, snapshot, fixture.root)
+	// Publish the nested directory under the name.
+	nested, err := fs.Stat(ipns, fixtureIPNSName+"/nested")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture.core.names[fixtureIPNSName] = nested.(filesystem.CIDInfo).CID()
+	time.Sleep(time.Millisecond) // Expire the cached record.
+	if _, err := fs.Stat(ipns, fixtureIPNSName+"/empty"); err == nil {
+		t.Fatal("name was not updated")
+	}
+	if err := fstest.TestFS(snapshot, fixture.files...); err != nil {
+		t.Error(err)
+	}
+	snapshotCIDMatch(t, snapshot, fixture.root)
+}
+
+func testSnapshotIPFS(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		root    = fixture.root.String()
+	)
+	snapshot, err := NewSnapshotFS(fixture.newIPFS(t), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotCIDMatch(t, snapshot, fixture.root)
+	if err := fstest.TestFS(snapshot, fixture.files...); err != nil {
+		t.Error(err)
+	}
+	rooted, err := NewIPFS(fixture.core, WithPathPrefix(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rooted.Close()
+	if snapshot, err = NewSnapshotFS(rooted, filesystem.Root); err != nil {
+		t.Fatal(err)
+	}
+	snapshotCIDMatch(t, snapshot, fixture.root)
+}
+
+func testSnapshotInvalid(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		ipfs    = fixture.newIPFS(t)
+	)
+	for _, test := range []struct {
+		fsys fs.FS
+		name string
+		kind fserrors.Kind
+	}{
+		{fsys: ipfs, name: filesystem.Root, kind: fserrors.InvalidItem},
+		{fsys: ipfs, name: "/invalid", kind: fserrors.InvalidItem},
+		{
+			fsys: fstest.MapFS{"file": {}},
+			name: filesystem.Root,
+			kind: fserrors.InvalidItem,
+		},
+	} {
+		_, err := NewSnapshotFS(test.fsys, test.name)
+		var fsErr *fserrors.Error
+		if !errors.As(err, &fsErr) || fsErr.Kind != test.kind {
+			t.Errorf("error mismatch for \"%s\""+
+				"\ngot: %v"+
+				"\nwant kind: %v",
+				test.name, err, test.kind,
+			)
+		}
+	}
+}
+
+func snapshotCIDMatch(t *testing.T, snapshot *SnapshotFS, want cid.Cid) {
+	t.Helper()
+	if got := snapshot.CID(); !got.Equals(want) {
+		t.Errorf("snapshot CID mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, want,
+		)
+	}
+}
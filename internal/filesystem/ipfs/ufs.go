@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
 	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
 	files "github.com/ipfs/boxo/files"
 	unixfsfile "github.com/ipfs/boxo/ipld/unixfs/file"
@@ -17,6 +18,7 @@ type (
 		files.File
 		cancel context.CancelFunc
 		info   nodeInfo
+		closed bool
 	}
 )
 
@@ -51,10 +53,34 @@ func ufsOpenErr(err error) fserrors.Kind {
 	return fserrors.IO
 }
 
-func (uio *ufsFile) Close() error { defer uio.cancel(); return uio.File.Close() }
+func (uio *ufsFile) Close() error {
+	const op = "close"
+	if uio.closed {
+		return uio.closedErr(op)
+	}
+	uio.closed = true
+	defer uio.cancel()
+	return uio.File.Close()
+}
+
+func (uio *ufsFile) closedErr(op string) error {
+	return fserrors.New(op, uio.info.name, filesystem.ErrNotOpen, fserrors.Closed)
+}
 
 func (uio *ufsFile) Stat() (fs.FileInfo, error) { return &uio.info, nil }
 
+func (uio *ufsFile) Read(b []byte) (int, error) {
+	const op = "read"
+	if uio.closed {
+		return 0, uio.closedErr(op)
+	}
+	return uio.File.Read(b)
+}
+
 func (uio *ufsFile) Seek(offset int64, whence int) (int64, error) {
+	const op = "seek"
+	if uio.closed {
+		return 0, uio.closedErr(op)
+	}
 	return uio.File.Seek(offset, whence)
 }
@@ -0,0 +1,265 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	// PinStatus describes the result
+	// of verifying a single pin.
+	PinStatus struct {
+		CID     string `json:"cid"`
+		Error   string `json:"error,omitempty"`
+		Healthy bool   `json:"healthy"`
+	}
+	verifySettings struct {
+		progress   VerifyProgressFunc
+		timeout    time.Duration
+		workers    int
+		readLength int64
+	}
+	VerifyOption func(*verifySettings) error
+	// VerifyProgressFunc is called after each pin is verified,
+	// with the amount of data that was read from it.
+	VerifyProgressFunc func(status PinStatus, bytesRead int64)
+	// verifyResult is sent from workers to the collector.
+	verifyResult struct {
+		PinStatus
+		bytesRead int64
+	}
+	// makeProbeFSFunc constructs a file system whose
+	// operations are bound to the context (of a single probe).
+	makeProbeFSFunc func(context.Context) (fs.FS, error)
+)
+
+const (
+	// DefaultVerifyTimeout is the duration each pin has
+	// to be retrieved within, if [WithVerifyTimeout]
+	// is not provided.
+	DefaultVerifyTimeout = 30 * time.Second
+	// DefaultVerifyWorkers is the amount of pins verified
+	// concurrently, if [WithVerifyWorkers] is not provided.
+	DefaultVerifyWorkers = 8
+	// DefaultVerifyReadLength is the amount of bytes read
+	// from each (file) pin, if [WithVerifyReadLength]
+	// is not provided.
+	DefaultVerifyReadLength = 1024
+	// verifyBatchSize is the amount of pins
+	// requested from the pin directory at a time.
+	verifyBatchSize = 64
+)
+
+// WithVerifyTimeout sets the duration each pin
+// has to be retrieved within, before it's
+// considered unreachable.
+// Values <= 0 disable the timeout.
+func WithVerifyTimeout(timeout time.Duration) VerifyOption {
+	return func(settings *verifySettings) error {
+		settings.timeout = timeout
+		return nil
+	}
+}
+
+// WithVerifyWorkers sets the maximum number
+// of pins which are verified concurrently.
+func WithVerifyWorkers(workers int) VerifyOption {
+	const errWorkers = generic.ConstError("worker count must be positive")
+	return func(settings *verifySettings) error {
+		if workers < 1 {
+			return errWorkers
+		}
+		settings.workers = workers
+		return nil
+	}
+}
+
+// WithVerifyReadLength sets the maximum number
+// of bytes read from each (file) pin.
+// Values <= 0 only retrieve the pin's metadata.
+func WithVerifyReadLength(length int64) VerifyOption {
+	return func(settings *verifySettings) error {
+		settings.readLength = length
+		return nil
+	}
+}
+
+// WithVerifyProgress sets a function which is called
+// after each pin is verified. Calls are made sequentially
+// from the goroutine collecting results, so the function
+// should return quickly to not delay verification.
+func WithVerifyProgress(fn VerifyProgressFunc) VerifyOption {
+	return func(settings *verifySettings) error {
+		settings.progress = fn
+		return nil
+	}
+}
+
+// VerifyPins attempts to retrieve each recursive pin
+// from the node and reports the status of each one.
+// If the pins could not be listed in full, the
+// statuses of the pins that were listed are returned
+// along with the error.
+func (pg *PinFSGuest) VerifyPins(ctx context.Context, options ...VerifyOption) ([]PinStatus, error) {
+	settings := verifySettings{
+		timeout:    DefaultVerifyTimeout,
+		workers:    DefaultVerifyWorkers,
+		readLength: DefaultVerifyReadLength,
+	}
+	if err := generic.ApplyOptions(&settings, options...); err != nil {
+		return nil, err
+	}
+	client, err := pg.makeCoreAPI()
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: The pin file system is constructed
+	// without IPFS, so that listing pins does not
+	// depend on their content being retrievable.
+	pins, err := NewPinFS(client.Pin())
+	if err != nil {
+		return nil, err
+	}
+	// Each probe gets its own IPFS file system,
+	// so that requests made on its behalf are
+	// canceled when it times out.
+	makeProbeFS := func(ctx context.Context) (fs.FS, error) {
+		return pg.IPFSGuest.makeOverlayFS(client,
+			WithContext[IPFSOption](ctx),
+		)
+	}
+	statuses, err := verifyPins(ctx, pins, makeProbeFS, &settings)
+	return statuses, errors.Join(err, pins.Close())
+}
+
+func closeFS(fsys fs.FS) error {
+	if closer, ok := fsys.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func verifyPins(ctx context.Context, pins fs.FS, makeProbeFS makeProbeFSFunc, settings *verifySettings) ([]PinStatus, error) {
+	root, err := pins.Open(filesystem.Root)
+	if err != nil {
+		return nil, err
+	}
+	directory, ok := root.(fs.ReadDirFile)
+	if !ok {
+		const op = "verify"
+		return nil, errors.Join(
+			fserrors.New(op, filesystem.Root, filesystem.ErrIsNotDir, fserrors.NotDir),
+			root.Close(),
+		)
+	}
+	var (
+		listCtx, cancel = context.WithCancel(ctx)
+		entries         = filesystem.StreamDir(listCtx, verifyBatchSize, directory)
+		names           = make(chan string)
+		results         = make(chan verifyResult)
+		wg              sync.WaitGroup
+		listErr         error
+	)
+	defer cancel()
+	for i := 0; i < settings.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				results <- verifyPin(ctx, makeProbeFS, name, settings)
+			}
+		}()
+	}
+	go func() {
+		defer func() {
+			close(names)
+			wg.Wait()
+			close(results)
+		}()
+		for entry := range entries {
+			if err := entry.Error(); err != nil {
+				listErr = err
+				return
+			}
+			select {
+			case names <- entry.Name():
+			case <-listCtx.Done():
+				listErr = listCtx.Err()
+				return
+			}
+		}
+	}()
+	var statuses []PinStatus
+	for result := range results {
+		statuses = append(statuses, result.PinStatus)
+		if progress := settings.progress; progress != nil {
+			progress(result.PinStatus, result.bytesRead)
+		}
+	}
+	return statuses, errors.Join(listErr, directory.Close())
+}
+
+func verifyPin(ctx context.Context, makeProbeFS makeProbeFSFunc, name string, settings *verifySettings) verifyResult {
+	if timeout := settings.timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	read, err := probeWith(ctx, makeProbeFS, name, settings.readLength)
+	if err != nil {
+		return verifyResult{
+			PinStatus: PinStatus{CID: name, Error: err.Error()},
+			bytesRead: read,
+		}
+	}
+	return verifyResult{
+		PinStatus: PinStatus{CID: name, Healthy: true},
+		bytesRead: read,
+	}
+}
+
+// probeWith probes the pin using a file system bound
+// to `ctx`, and returns once the probe has stopped.
+// If `ctx` is done, its error is returned
+// in place of the probe's error.
+func probeWith(ctx context.Context, makeProbeFS makeProbeFSFunc, name string, readLength int64) (int64, error) {
+	ipfs, err := makeProbeFS(ctx)
+	if err != nil {
+		return 0, err
+	}
+	read, err := probe(ipfs, name, readLength)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		err = ctxErr
+	}
+	return read, errors.Join(err, closeFS(ipfs))
+}
+
+// probe retrieves the pin's metadata and
+// (if it's a file) the first bytes of its data,
+// returning how many bytes were read.
+func probe(ipfs fs.FS, name string, readLength int64) (int64, error) {
+	info, err := fs.Stat(ipfs, name)
+	if err != nil {
+		return 0, err
+	}
+	if info.IsDir() || readLength <= 0 {
+		return 0, nil
+	}
+	file, err := ipfs.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	read, err := io.CopyN(io.Discard, file, readLength)
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return read, errors.Join(err, file.Close())
+}
@@ -0,0 +1,238 @@
+package ipfs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type (
+	// probeFS delays (or blocks) operations
+	// and tracks how many were in progress at once.
+	probeFS struct {
+		fs.FS
+		blocked      map[string]bool
+		delay        time.Duration
+		mu           sync.Mutex
+		active, peak int
+	}
+	// boundProbeFS is a view of a [probeFS] whose
+	// blocked operations return when its context is done.
+	boundProbeFS struct {
+		*probeFS
+		ctx context.Context
+	}
+)
+
+// makeFS returns `fsys` for each probe (regardless of context).
+func makeFS(fsys fs.FS) makeProbeFSFunc {
+	return func(context.Context) (fs.FS, error) { return fsys, nil }
+}
+
+func (pf *probeFS) bind(ctx context.Context) (fs.FS, error) {
+	return &boundProbeFS{probeFS: pf, ctx: ctx}, nil
+}
+
+func (bf *boundProbeFS) Stat(name string) (fs.FileInfo, error) {
+	return bf.probeFS.stat(bf.ctx, name)
+}
+
+func (pf *probeFS) stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	pf.mu.Lock()
+	if pf.active++; pf.active > pf.peak {
+		pf.peak = pf.active
+	}
+	pf.mu.Unlock()
+	defer func() {
+		pf.mu.Lock()
+		pf.active--
+		pf.mu.Unlock()
+	}()
+	if pf.blocked[name] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	time.Sleep(pf.delay)
+	return fs.Stat(pf.FS, name)
+}
+
+func TestVerifyPins(t *testing.T) {
+	t.Parallel()
+	t.Run("status", testVerifyPinsStatus)
+	t.Run("workers", testVerifyPinsWorkers)
+	t.Run("progress", testVerifyPinsProgress)
+}
+
+func testVerifyPinsStatus(t *testing.T) {
+	t.Parallel()
+	const (
+		file      = "file"
+		directory = "directory"
+		missing   = "missing"
+		blocked   = "blocked"
+	)
+	var (
+		pins = fstest.MapFS{
+			file:      new(fstest.MapFile),
+			directory: new(fstest.MapFile),
+			missing:   new(fstest.MapFile),
+			blocked:   new(fstest.MapFile),
+		}
+		ipfs = &probeFS{
+			FS: fstest.MapFS{
+				file:                   &fstest.MapFile{Data: []byte(t.Name())},
+				directory + "/" + file: new(fstest.MapFile),
+				blocked:                new(fstest.MapFile),
+			},
+			blocked: map[string]bool{blocked: true},
+		}
+		settings = verifySettings{
+			timeout:    10 * time.Millisecond,
+			workers:    DefaultVerifyWorkers,
+			readLength: DefaultVerifyReadLength,
+		}
+	)
+	statuses, err := verifyPins(context.Background(), pins, ipfs.bind, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active := ipfs.active; active != 0 {
+		t.Errorf("probes still running after verification: %d", active)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].CID < statuses[j].CID
+	})
+	want := map[string]bool{
+		blocked:   false,
+		directory: true,
+		file:      true,
+		missing:   false,
+	}
+	if got, want := len(statuses), len(want); got != want {
+		t.Fatalf("status count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+	for _, status := range statuses {
+		healthy, ok := want[status.CID]
+		if !ok {
+			t.Errorf("unexpected pin in results: %s", status.CID)
+			continue
+		}
+		if status.Healthy != healthy {
+			t.Errorf("health mismatch for \"%s\""+
+				"\ngot: %t (%s)"+
+				"\nwant: %t",
+				status.CID, status.Healthy, status.Error, healthy,
+			)
+		}
+		if !status.Healthy && status.Error == "" {
+			t.Errorf("unhealthy pin \"%s\" has no error", status.CID)
+		}
+	}
+}
+
+func testVerifyPinsWorkers(t *testing.T) {
+	t.Parallel()
+	const (
+		pinCount = 16
+		workers  = 2
+	)
+	var (
+		pins     = make(fstest.MapFS, pinCount)
+		ipfsPins = make(fstest.MapFS, pinCount)
+	)
+	for i := 0; i < pinCount; i++ {
+		name := fmt.Sprintf("pin%d", i)
+		pins[name] = new(fstest.MapFile)
+		ipfsPins[name] = new(fstest.MapFile)
+	}
+	var (
+		ipfs = &probeFS{
+			FS:    ipfsPins,
+			delay: time.Millisecond,
+		}
+		settings = verifySettings{workers: workers}
+	)
+	statuses, err := verifyPins(context.Background(), pins, ipfs.bind, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(statuses); got != pinCount {
+		t.Errorf("status count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, pinCount,
+		)
+	}
+	if peak := ipfs.peak; peak > workers {
+		t.Errorf("too many concurrent checks"+
+			"\ngot: %d"+
+			"\nwant: <= %d",
+			peak, workers,
+		)
+	}
+}
+
+func testVerifyPinsProgress(t *testing.T) {
+	t.Parallel()
+	const (
+		file      = "file"
+		directory = "directory"
+		missing   = "missing"
+	)
+	var (
+		data = []byte(t.Name())
+		pins = fstest.MapFS{
+			file:      new(fstest.MapFile),
+			directory: new(fstest.MapFile),
+			missing:   new(fstest.MapFile),
+		}
+		ipfs = fstest.MapFS{
+			file:                   &fstest.MapFile{Data: data},
+			directory + "/" + file: new(fstest.MapFile),
+		}
+		got      = make(map[string]int64, len(pins))
+		settings = verifySettings{
+			workers:    DefaultVerifyWorkers,
+			readLength: DefaultVerifyReadLength,
+			progress: func(status PinStatus, bytesRead int64) {
+				if _, ok := got[status.CID]; ok {
+					t.Errorf("progress reported twice for \"%s\"", status.CID)
+				}
+				got[status.CID] = bytesRead
+			},
+		}
+	)
+	if _, err := verifyPins(context.Background(), pins, makeFS(ipfs), &settings); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{
+		file:      int64(len(data)),
+		directory: 0,
+		missing:   0,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("progress count mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, want,
+		)
+	}
+	for name, wantRead := range want {
+		if gotRead := got[name]; gotRead != wantRead {
+			t.Errorf("bytes read mismatch for \"%s\""+
+				"\ngot: %d"+
+				"\nwant: %d",
+				name, gotRead, wantRead,
+			)
+		}
+	}
+}
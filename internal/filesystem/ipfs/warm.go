@@ -0,0 +1,170 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/ipfs/go-cid"
+)
+
+type (
+	warmSettings struct {
+		workers int
+		entries bool
+	}
+	WarmOption func(*warmSettings) error
+)
+
+const (
+	warmWorkersDefault = 8
+
+	errWarmCacheDisabled = generic.ConstError("node cache is disabled")
+	errWarmCapacity      = generic.ConstError("more nodes than the cache can hold")
+)
+
+// WithWarmWorkers sets the maximum number
+// of nodes which are fetched concurrently.
+// If the file system was constructed with
+// [WithConcurrentReaddir], its worker count
+// is used as an upper bound.
+func WithWarmWorkers(workers int) WarmOption {
+	const errWorkers = generic.ConstError("worker count must be positive")
+	return func(settings *warmSettings) error {
+		if workers < 1 {
+			return errWorkers
+		}
+		settings.workers = workers
+		return nil
+	}
+}
+
+// WithWarmEntries additionally lists the entries of
+// directories, and stores them in the directory cache
+// (if enabled).
+func WithWarmEntries(entries bool) WarmOption {
+	return func(settings *warmSettings) error {
+		settings.entries = entries
+		return nil
+	}
+}
+
+// Warm fetches the nodes of `cids` and stores them
+// in the node cache, so that their first use
+// does not require a request to the node.
+// Requesting more nodes than the cache can hold
+// is considered an error, as nodes warmed first
+// would be evicted by those warmed last.
+// Nodes which could not be fetched are reported
+// together, after the others have been fetched.
+func (fsys *IPFS) Warm(ctx context.Context, cids []cid.Cid, options ...WarmOption) error {
+	settings := warmSettings{
+		workers: warmWorkersDefault,
+	}
+	if err := generic.ApplyOptions(&settings, options...); err != nil {
+		return err
+	}
+	if fsys.nodeCache == nil {
+		return errWarmCacheDisabled
+	}
+	// NOTE: Duplicates are only fetched once.
+	indices := make(map[cid.Cid]int, len(cids))
+	for i, c := range cids {
+		indices[c] = i
+	}
+	if count, capacity := len(indices), fsys.nodeCacheCount; count > capacity {
+		return fmt.Errorf("%w: requested %d, cache holds %d",
+			errWarmCapacity, count, capacity,
+		)
+	}
+	workers := settings.workers
+	if limit := fsys.readdirWorkers; limit > 0 {
+		workers = generic.Min(workers, limit)
+	}
+	var (
+		queue = make(chan cid.Cid)
+		errs  = make([]error, len(cids))
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range queue {
+				errs[indices[c]] = fsys.warm(ctx, c, settings.entries)
+			}
+		}()
+	}
+	var ctxErr error
+dispatch:
+	for c := range indices {
+		select {
+		case queue <- c:
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return errors.Join(append(errs, ctxErr)...)
+}
+
+func (fsys *IPFS) warm(ctx context.Context, c cid.Cid, entries bool) error {
+	info, err := fsys.getCachedInfo(ctx, c.String(), c)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c, err)
+	}
+	if !entries || !info.IsDir() ||
+		fsys.dirCache == nil {
+		return nil
+	}
+	if cached, _ := fsys.dirCache.Get(c); cached != nil {
+		return nil
+	}
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := fsys.fetchEntries(listCtx, c, info)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c, err)
+	}
+	// NOTE: Entries are cached here rather than
+	// via [IPFS.getEntries], so that they're
+	// in the cache before we return.
+	var snapshot []filesystem.StreamDirEntry
+	for entry := range stream {
+		if err := entry.Error(); err != nil {
+			return fmt.Errorf("%s: %w", c, err)
+		}
+		snapshot = append(snapshot, entry)
+	}
+	fsys.dirCache.Add(c, generic.CompactSlice(snapshot))
+	return nil
+}
+
+// Warm constructs the guest's file system,
+// and warms it with `cids` (see [IPFS.Warm]).
+// The file system is closed before returning;
+// the effect that remains is the node having
+// retrieved the blocks, so that later mounts do
+// not have to wait on the network for them.
+func (ig *IPFSGuest) Warm(ctx context.Context, cids []cid.Cid, options ...WarmOption) error {
+	client, err := ig.makeCoreAPI()
+	if err != nil {
+		return err
+	}
+	// NOTE: The path prefix (if any) is not used;
+	// nodes are warmed by CID.
+	fsys, err := ig.makeFS(client)
+	if err != nil {
+		return err
+	}
+	ipfs := fsys.(*IPFS)
+	return errors.Join(
+		ipfs.Warm(ctx, cids, options...),
+		ipfs.Close(),
+	)
+}
@@ -0,0 +1,142 @@
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+)
+
+func TestWarm(t *testing.T) {
+	t.Parallel()
+	t.Run("cache", testWarmCache)
+	t.Run("entries", testWarmEntries)
+	t.Run("capacity", testWarmCapacity)
+	t.Run("disabled", testWarmDisabled)
+}
+
+// newWarmFS returns a file system backed by the
+// fixture, along with the DAG that counts its fetches,
+// and the CIDs of the fixture's root and its children.
+func newWarmFS(t *testing.T, options ...IPFSOption) (*IPFS, *shardDAG, []cid.Cid) {
+	t.Helper()
+	var (
+		fixture  = newFixture(t)
+		shardDAG = newShardDAG(fixture.core.dag.DAGService)
+		core     = &fixtureCore{
+			dag:   fixtureDag{DAGService: shardDAG},
+			names: fixture.core.names,
+		}
+	)
+	root, err := fixture.core.dag.Get(context.Background(), fixture.root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cids := []cid.Cid{fixture.root}
+	for _, link := range root.Links() {
+		cids = append(cids, link.Cid)
+	}
+	fsys, err := NewIPFS(core, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return fsys, shardDAG, cids
+}
+
+func testWarmCache(t *testing.T) {
+	t.Parallel()
+	fsys, shardDAG, cids := newWarmFS(t, WithNodeCacheCount(8))
+	if err := fsys.Warm(context.Background(), cids); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cids {
+		if got := shardDAG.fetchCount(c); got != 1 {
+			t.Fatalf("fetch count mismatch for %s after warming"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				c, got, 1,
+			)
+		}
+		if _, err := fs.Stat(fsys, c.String()); err != nil {
+			t.Fatal(err)
+		}
+		if got := shardDAG.fetchCount(c); got != 1 {
+			t.Errorf("warmed node %s was not served from cache"+
+				"\ngot: %d fetches"+
+				"\nwant: %d",
+				c, got, 1,
+			)
+		}
+	}
+}
+
+func testWarmEntries(t *testing.T) {
+	t.Parallel()
+	fsys, _, cids := newWarmFS(t, WithNodeCacheCount(8))
+	root := cids[0]
+	if err := fsys.Warm(context.Background(), cids[:1],
+		WithWarmEntries(true),
+	); err != nil {
+		t.Fatal(err)
+	}
+	entries, ok := fsys.dirCache.Get(root)
+	if !ok {
+		t.Fatal("directory entries were not cached")
+	}
+	if got, want := len(entries), len(cids)-1; got != want {
+		t.Errorf("entry count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+}
+
+func testWarmCapacity(t *testing.T) {
+	t.Parallel()
+	fsys, shardDAG, cids := newWarmFS(t, WithNodeCacheCount(1))
+	err := fsys.Warm(context.Background(), cids)
+	if !errors.Is(err, errWarmCapacity) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, errWarmCapacity,
+		)
+	}
+	for _, c := range cids {
+		if got := shardDAG.fetchCount(c); got != 0 {
+			t.Errorf("node %s was fetched despite exceeding capacity", c)
+		}
+	}
+	// Duplicates do not count against the capacity.
+	duplicates := []cid.Cid{cids[0], cids[0]}
+	if err := fsys.Warm(context.Background(), duplicates); err != nil {
+		t.Fatal(err)
+	}
+	if got := shardDAG.fetchCount(cids[0]); got != 1 {
+		t.Errorf("fetch count mismatch for duplicates"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, 1,
+		)
+	}
+}
+
+func testWarmDisabled(t *testing.T) {
+	t.Parallel()
+	fsys, _, cids := newWarmFS(t, WithNodeCacheCount(0))
+	if err := fsys.Warm(context.Background(), cids); !errors.Is(err, errWarmCacheDisabled) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, errWarmCacheDisabled,
+		)
+	}
+}
@@ -0,0 +1,297 @@
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	chunk "github.com/ipfs/boxo/chunker"
+	dag "github.com/ipfs/boxo/ipld/merkledag"
+	"github.com/ipfs/boxo/ipld/unixfs"
+	"github.com/ipfs/boxo/ipld/unixfs/importer"
+	unixpb "github.com/ipfs/boxo/ipld/unixfs/pb"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+)
+
+// ipfsWriter buffers the data written to it,
+// and commits it to the DAG service when closed.
+type ipfsWriter struct {
+	fsys *IPFS
+	// names are the path components
+	// of the file, relative to `root`.
+	names  []string
+	data   []byte
+	info   nodeInfo
+	root   cid.Cid
+	synced cid.Cid
+	offset int
+	closed bool
+}
+
+const (
+	// openWriteFlags are the flags
+	// supported by [IPFS.OpenFile].
+	openWriteFlags = os.O_WRONLY | os.O_CREATE |
+		os.O_EXCL | os.O_TRUNC
+
+	errWriteRoot = generic.ConstError("a root CID cannot be written to")
+)
+
+// OpenFile opens the named file with the specified flags.
+// Read-only opens are the same as [IPFS.Open].
+// If a DAG service was provided (see: [WithDagService]),
+// files may be opened with [os.O_WRONLY], optionally combined
+// with [os.O_CREATE], [os.O_EXCL], and [os.O_TRUNC].
+// Otherwise, an error of kind [fserrors.ReadOnly] is returned.
+// Writes are buffered, and committed when the file is closed;
+// producing a new root node which contains the file
+// (the file's `Synced` method returns its CID).
+// Since nodes are immutable, names continue
+// to resolve to their original nodes.
+// Directories along the path must not be sharded,
+// and `perm` is ignored.
+func (fsys *IPFS) OpenFile(name string, flag int, perm fs.FileMode) (fs.File, error) {
+	if flag == os.O_RDONLY {
+		return fsys.Open(name)
+	}
+	const op = "open"
+	if fsys.dagService == nil {
+		return nil, fserrors.New(op, name, fserrors.ErrUnsupported, fserrors.ReadOnly)
+	}
+	if flag&os.O_WRONLY == 0 || flag&^openWriteFlags != 0 ||
+		fsys.ipldDirectories {
+		return nil, fserrors.New(op, name, fserrors.ErrUnsupported, fserrors.InvalidOperation)
+	}
+	name = filesystem.NormalizeRoot(name)
+	if name == filesystem.Root {
+		return nil, fserrors.New(op, name, filesystem.ErrIsDir, fserrors.IsDir)
+	}
+	if !fs.ValidPath(name) {
+		return nil, fserrors.New(op, name, filesystem.ErrPath, fserrors.InvalidItem)
+	}
+	root, names, err := fsys.splitWritePath(op, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fsys.prepareWrite(op, name, flag)
+	if err != nil {
+		return nil, err
+	}
+	return &ipfsWriter{
+		fsys:  fsys,
+		root:  root,
+		names: names,
+		data:  data,
+		info: nodeInfo{
+			name:    path.Base(name),
+			modTime: fsys.info.modTime,
+			mode:    fsys.info.mode.Perm(),
+		},
+	}, nil
+}
+
+// splitWritePath returns the root node of the
+// name, and the path components beneath it.
+func (fsys *IPFS) splitWritePath(op, name string) (cid.Cid, []string, error) {
+	names := strings.Split(name, "/")
+	if root := fsys.root; root.Defined() {
+		return root, names, nil
+	}
+	if len(names) == 1 {
+		return cid.Cid{}, nil, fserrors.New(op, name, errWriteRoot, fserrors.InvalidItem)
+	}
+	root, err := cid.Decode(names[0])
+	if err != nil {
+		return cid.Cid{}, nil, fserrors.New(op, name, err, cidErrKind(err))
+	}
+	return root, names[1:], nil
+}
+
+// prepareWrite checks the name against the flags,
+// and returns the data the file should start with.
+func (fsys *IPFS) prepareWrite(op, name string, flag int) ([]byte, error) {
+	info, err := fsys.Stat(name)
+	if err != nil {
+		var fsErr *fserrors.Error
+		if flag&os.O_CREATE == 0 ||
+			!errors.As(err, &fsErr) || fsErr.Kind != fserrors.NotExist {
+			return nil, err
+		}
+		parent, err := fsys.Stat(path.Dir(name))
+		if err != nil {
+			return nil, err
+		}
+		if !parent.IsDir() {
+			return nil, fserrors.New(op, name, filesystem.ErrIsNotDir, fserrors.NotDir)
+		}
+		return nil, nil
+	}
+	switch {
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, fserrors.New(op, name, fs.ErrExist, fserrors.Exist)
+	case info.IsDir():
+		return nil, fserrors.New(op, name, filesystem.ErrIsDir, fserrors.IsDir)
+	case !info.Mode().IsRegular():
+		return nil, fserrors.New(op, name, errUnexpectedType, fserrors.InvalidItem)
+	case flag&os.O_TRUNC != 0:
+		return nil, nil
+	default:
+		return fs.ReadFile(fsys, name)
+	}
+}
+
+// symlink returns the root of a copy of `name`'s root,
+// which contains a symbolic link to `target` at `name`.
+func (fsys *IPFS) symlink(op, target, name string) (cid.Cid, error) {
+	if _, err := fsys.checkLinkTarget(op, name, target); err != nil {
+		return cid.Cid{}, err
+	}
+	root, names, err := fsys.splitWritePath(op, name)
+	if err != nil {
+		return cid.Cid{}, err
+	}
+	if _, err := fsys.prepareWrite(op, name, os.O_CREATE|os.O_EXCL); err != nil {
+		return cid.Cid{}, err
+	}
+	data, err := unixfs.SymlinkData(target)
+	if err != nil {
+		return cid.Cid{}, fserrors.New(op, name, err, fserrors.InvalidItem)
+	}
+	var (
+		link        = dag.NodeWithData(data)
+		ctx, cancel = fsys.nodeContext(fsys.ctx)
+	)
+	defer cancel()
+	if err := fsys.dagService.Add(ctx, link); err != nil {
+		return cid.Cid{}, fserrors.New(op, name, err, fserrors.IO)
+	}
+	node, err := fsys.linkInto(ctx, root, names, link)
+	if err != nil {
+		return cid.Cid{}, fserrors.New(op, name, err, fserrors.IO)
+	}
+	return node.Cid(), nil
+}
+
+// linkInto returns a copy of the directory `parent`,
+// with the node at `names` (relative to it) replaced
+// by `child`. Each directory copied is added
+// to the DAG service.
+func (fsys *IPFS) linkInto(ctx context.Context, parent cid.Cid, names []string, child ipld.Node) (ipld.Node, error) {
+	node, err := fsys.getNode(parent)
+	if err != nil {
+		return nil, err
+	}
+	directory, err := unixfsDirectory(node)
+	if err != nil {
+		return nil, err
+	}
+	name := names[0]
+	if len(names) > 1 {
+		link, err := directory.GetNodeLink(name)
+		if err != nil {
+			return nil, err
+		}
+		if child, err = fsys.linkInto(ctx, link.Cid, names[1:], child); err != nil {
+			return nil, err
+		}
+	}
+	directory = directory.Copy().(*dag.ProtoNode)
+	if err := directory.RemoveNodeLink(name); err != nil &&
+		!errors.Is(err, dag.ErrLinkNotFound) {
+		return nil, err
+	}
+	if err := directory.AddNodeLink(name, child); err != nil {
+		return nil, err
+	}
+	return directory, fsys.dagService.Add(ctx, directory)
+}
+
+func unixfsDirectory(node ipld.Node) (*dag.ProtoNode, error) {
+	if protoNode, ok := node.(*dag.ProtoNode); ok {
+		fsNode, err := unixfs.FSNodeFromBytes(protoNode.Data())
+		if err != nil {
+			return nil, err
+		}
+		if fsNode.Type() == unixpb.Data_Directory {
+			return protoNode, nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"%w got: \"%T\" want: UnixFS directory (not sharded)",
+		errUnexpectedType, node,
+	)
+}
+
+func (iw *ipfsWriter) closedErr(op string) error {
+	return fserrors.New(op, iw.info.name, filesystem.ErrNotOpen, fserrors.Closed)
+}
+
+func (iw *ipfsWriter) Stat() (fs.FileInfo, error) {
+	info := iw.info
+	info.size = int64(len(iw.data))
+	return &info, nil
+}
+
+func (iw *ipfsWriter) Read([]byte) (int, error) {
+	const op = "read"
+	if iw.closed {
+		return 0, iw.closedErr(op)
+	}
+	return 0, fserrors.New(op, iw.info.name, fserrors.ErrUnsupported, fserrors.InvalidOperation)
+}
+
+func (iw *ipfsWriter) Write(p []byte) (int, error) {
+	if iw.closed {
+		return 0, iw.closedErr("write")
+	}
+	overwritten := copy(iw.data[iw.offset:], p)
+	iw.data = append(iw.data, p[overwritten:]...)
+	iw.offset += len(p)
+	return len(p), nil
+}
+
+// Synced returns the CID of the root which contains
+// the file's data. It is only defined after
+// the file has been closed successfully.
+func (iw *ipfsWriter) Synced() cid.Cid { return iw.synced }
+
+func (iw *ipfsWriter) Close() error {
+	const op = "close"
+	if iw.closed {
+		return iw.closedErr(op)
+	}
+	iw.closed = true
+	synced, err := iw.commit()
+	if err != nil {
+		return fserrors.New(op, iw.info.name, err, fserrors.IO)
+	}
+	iw.synced = synced
+	return nil
+}
+
+func (iw *ipfsWriter) commit() (cid.Cid, error) {
+	var (
+		fsys        = iw.fsys
+		splitter    = chunk.DefaultSplitter(bytes.NewReader(iw.data))
+		file, err   = importer.BuildDagFromReader(fsys.dagService, splitter)
+		ctx, cancel = fsys.nodeContext(fsys.ctx)
+	)
+	defer cancel()
+	if err != nil {
+		return cid.Cid{}, err
+	}
+	root, err := fsys.linkInto(ctx, iw.root, iw.names, file)
+	if err != nil {
+		return cid.Cid{}, err
+	}
+	return root.Cid(), nil
+}
@@ -0,0 +1,189 @@
+package ipfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/ipfs/go-cid"
+)
+
+var _ filesystem.OpenFileFS = (*IPFS)(nil)
+
+type syncedFile interface {
+	io.WriteCloser
+	Synced() cid.Cid
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	var (
+		fixture = newFixture(t)
+		fsys    = fixture.newWritableIPFS(t)
+	)
+	root := fixture.root.String()
+	t.Run("read only", func(t *testing.T) {
+		t.Parallel()
+		var (
+			readOnly = fixture.newIPFS(t)
+			name     = root + "/file"
+		)
+		_, err := readOnly.OpenFile(name, os.O_WRONLY, 0)
+		if !errors.Is(err, fserrors.ErrUnsupported) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, fserrors.ErrUnsupported,
+			)
+		}
+		writeKindMatch(t, err, fserrors.ReadOnly)
+	})
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		const (
+			name = "nested/new"
+			data = "new data"
+		)
+		synced := writeFile(t, fsys, root+"/"+name, os.O_WRONLY|os.O_CREATE, data)
+		writeDataMatch(t, fsys, synced+"/"+name, data)
+		writeDataMatch(t, fsys, synced+"/file", "file data")
+		_, err := fs.Stat(fsys, root+"/"+name)
+		writeKindMatch(t, err, fserrors.NotExist)
+	})
+	t.Run("overwrite", func(t *testing.T) {
+		t.Parallel()
+		name := root + "/file"
+		file, err := fsys.OpenFile(name, os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer := file.(syncedFile)
+		if _, err := writer.Write([]byte("FILE")); err != nil {
+			t.Fatal(err)
+		}
+		// Not committed until closed, and even then
+		// the original name remains the same.
+		writeDataMatch(t, fsys, name, "file data")
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		writeDataMatch(t, fsys, name, "file data")
+		writeDataMatch(t, fsys, writer.Synced().String()+"/file", "FILE data")
+		synced := writeFile(t, fsys, name, os.O_WRONLY|os.O_TRUNC, "new")
+		writeDataMatch(t, fsys, synced+"/file", "new")
+	})
+	t.Run("prefix", func(t *testing.T) {
+		t.Parallel()
+		prefixed, err := NewIPFS(fixture.core,
+			WithDagService(fixture.core.dag.DAGService),
+			WithPathPrefix(root),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := prefixed.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+		synced := writeFile(t, prefixed, "new", os.O_WRONLY|os.O_CREATE, "data")
+		writeDataMatch(t, fsys, synced+"/new", "data")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, test := range []struct {
+			name string
+			flag int
+			kind fserrors.Kind
+		}{
+			{root + "/file", os.O_WRONLY | os.O_CREATE | os.O_EXCL, fserrors.Exist},
+			{root + "/missing", os.O_WRONLY, fserrors.NotExist},
+			{root + "/missing/file", os.O_WRONLY | os.O_CREATE, fserrors.NotExist},
+			{root + "/nested", os.O_WRONLY, fserrors.IsDir},
+			{root, os.O_WRONLY, fserrors.InvalidItem},
+			{root + "/file", os.O_RDWR, fserrors.InvalidOperation},
+			{root + "/file", os.O_WRONLY | os.O_APPEND, fserrors.InvalidOperation},
+		} {
+			file, err := fsys.OpenFile(test.name, test.flag, 0)
+			if err == nil {
+				t.Errorf("expected error for \"%s\" (flags %#x)", test.name, test.flag)
+				file.Close()
+				continue
+			}
+			writeKindMatch(t, err, test.kind)
+		}
+	})
+}
+
+// newWritableIPFS constructs an IPFS
+// which writes to the fixture's DAG.
+func (fx *fixture) newWritableIPFS(t *testing.T) *IPFS {
+	t.Helper()
+	fsys, err := NewIPFS(fx.core,
+		WithDagService(fx.core.dag.DAGService),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := fsys.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return fsys
+}
+
+func writeFile(t *testing.T, fsys filesystem.OpenFileFS, name string, flag int, data string) string {
+	t.Helper()
+	file, err := fsys.OpenFile(name, flag, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := file.(syncedFile)
+	if _, err := io.WriteString(writer, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	synced := writer.Synced()
+	if !synced.Defined() {
+		t.Fatalf("\"%s\" was closed without a synced CID", name)
+	}
+	return synced.String()
+}
+
+func writeDataMatch(t *testing.T, fsys fs.FS, name, want string) {
+	t.Helper()
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != want {
+		t.Errorf("\"%s\" data mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			name, got, want,
+		)
+	}
+}
+
+func writeKindMatch(t *testing.T, err error, want fserrors.Kind) {
+	t.Helper()
+	var fsErr *fserrors.Error
+	if !errors.As(err, &fsErr) {
+		t.Errorf("expected %T, got: %#v", fsErr, err)
+		return
+	}
+	if got := fsErr.Kind; got != want {
+		t.Errorf("error kind mismatch for \"%v\""+
+			"\ngot: %s"+
+			"\nwant: %s",
+			err, got, want,
+		)
+	}
+}
@@ -0,0 +1,136 @@
+package filesystem
+
+import (
+	"io"
+	"io/fs"
+	"sync"
+
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	// HandleLimitFS wraps a file system and bounds
+	// the number of files which may be open at once.
+	// Opening a file while the limit is reached
+	// returns an error of kind [fserrors.TooManyOpen].
+	// A handle is released when its file is closed.
+	HandleLimitFS struct {
+		forwardFS
+		slots chan struct{}
+	}
+	limitedFile struct {
+		forwardFile
+		fsys *HandleLimitFS
+		once sync.Once
+	}
+	limitedSeeker struct {
+		*limitedFile
+		seeker io.Seeker
+	}
+	limitedDirectory struct {
+		*limitedFile
+		forwardDirectory
+	}
+)
+
+const errTooManyOpen = generic.ConstError("too many open files")
+
+// NewHandleLimitFS wraps `fsys`, allowing at most
+// `limit` of its files to be open concurrently.
+func NewHandleLimitFS(fsys fs.FS, limit int) (*HandleLimitFS, error) {
+	if limit < 1 {
+		return nil, generic.ConstError("handle limit must be positive")
+	}
+	return &HandleLimitFS{
+		forwardFS: forwardFS{fsys: fsys},
+		slots:     make(chan struct{}, limit),
+	}, nil
+}
+
+func (lfs *HandleLimitFS) Open(name string) (fs.File, error) {
+	return lfs.open("open", name, lfs.fsys.Open)
+}
+
+func (lfs *HandleLimitFS) OpenFile(name string, flag int, perm fs.FileMode) (fs.File, error) {
+	const op = "open"
+	opener, ok := Extension[OpenFileFS](lfs.fsys)
+	if !ok {
+		return nil, unsupportedOp(op, name)
+	}
+	return lfs.open(op, name, func(name string) (fs.File, error) {
+		return opener.OpenFile(name, flag, perm)
+	})
+}
+
+func (lfs *HandleLimitFS) CreateFile(name string) (fs.File, error) {
+	const op = "create"
+	creator, ok := Extension[CreateFileFS](lfs.fsys)
+	if !ok {
+		return nil, unsupportedOp(op, name)
+	}
+	return lfs.open(op, name, creator.CreateFile)
+}
+
+// Handles returns the amount of files
+// currently open through the file system.
+func (lfs *HandleLimitFS) Handles() int { return len(lfs.slots) }
+
+func (lfs *HandleLimitFS) open(op, name string, openFn func(string) (fs.File, error)) (fs.File, error) {
+	if err := lfs.acquire(op, name); err != nil {
+		return nil, err
+	}
+	file, err := openFn(name)
+	if err != nil {
+		lfs.release()
+		return nil, err
+	}
+	return lfs.wrap(file, name), nil
+}
+
+func (lfs *HandleLimitFS) acquire(op, name string) error {
+	select {
+	case lfs.slots <- struct{}{}:
+		return nil
+	default:
+		return fserrors.New(op, name, errTooManyOpen, fserrors.TooManyOpen)
+	}
+}
+
+func (lfs *HandleLimitFS) release() { <-lfs.slots }
+
+func (lfs *HandleLimitFS) wrap(file fs.File, name string) fs.File {
+	limited := &limitedFile{
+		forwardFile: forwardFile{
+			File: file,
+			name: name,
+		},
+		fsys: lfs,
+	}
+	switch typed := file.(type) {
+	case fs.ReadDirFile:
+		return &limitedDirectory{
+			limitedFile: limited,
+			forwardDirectory: forwardDirectory{
+				ReadDirFile: typed,
+				name:        name,
+			},
+		}
+	case io.Seeker:
+		return &limitedSeeker{
+			limitedFile: limited,
+			seeker:      typed,
+		}
+	default:
+		return limited
+	}
+}
+
+func (lf *limitedFile) Close() error {
+	defer lf.once.Do(lf.fsys.release)
+	return lf.File.Close()
+}
+
+func (ls *limitedSeeker) Seek(offset int64, whence int) (int64, error) {
+	return ls.seeker.Seek(offset, whence)
+}
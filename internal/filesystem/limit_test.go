@@ -0,0 +1,199 @@
+package filesystem_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+)
+
+func TestHandleLimitFS(t *testing.T) {
+	t.Parallel()
+	const (
+		fileName = "file"
+		limit    = 3
+	)
+	memfs := fstest.MapFS{
+		fileName:                {Data: []byte("data"), Mode: 0o644},
+		"directory/" + fileName: {Data: []byte("data"), Mode: 0o644},
+	}
+	t.Run("limit", func(t *testing.T) {
+		t.Parallel()
+		fsys := newHandleLimitFS(t, memfs, limit)
+		files := make([]fs.File, limit)
+		for i := range files {
+			file, err := fsys.Open(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[i] = file
+		}
+		limitOpenFails(t, fsys, fileName)
+		if err := files[0].Close(); err != nil {
+			t.Fatal(err)
+		}
+		files[0].Close() // Must not release another handle.
+		file, err := fsys.Open(fileName)
+		if err != nil {
+			t.Fatalf("closed handle was not released: %v", err)
+		}
+		files[0] = file
+		if handles := fsys.Handles(); handles != limit {
+			t.Errorf("handle count mismatch"+
+				"\ngot: %d"+
+				"\nwant: %d",
+				handles, limit,
+			)
+		}
+		limitOpenFails(t, fsys, fileName)
+		for _, file := range files {
+			if err := file.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if handles := fsys.Handles(); handles != 0 {
+			t.Errorf("handles remain after closing all files: %d", handles)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		const limit = 1
+		fsys := newHandleLimitFS(t, memfs, limit)
+		if _, err := fsys.Open("missing"); err == nil {
+			t.Fatal("expected error when opening missing file, got nil")
+		}
+		file, err := fsys.Open(fileName)
+		if err != nil {
+			t.Fatalf("failed open retained a handle: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("interfaces", func(t *testing.T) {
+		t.Parallel()
+		fsys := newHandleLimitFS(t, memfs, limit)
+		file, err := fsys.Open(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, ok := file.(io.Seeker); !ok {
+			t.Errorf("%T does not implement %T", file, (*io.Seeker)(nil))
+		}
+		directory, err := fsys.Open("directory")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer directory.Close()
+		if _, ok := directory.(fs.ReadDirFile); !ok {
+			t.Errorf("%T does not implement %T", directory, (*fs.ReadDirFile)(nil))
+		}
+	})
+	t.Run("extensions", func(t *testing.T) {
+		t.Parallel()
+		const link = "link"
+		linkfs := linkMapFS{MapFS: fstest.MapFS{
+			fileName:                {Data: []byte("data")},
+			"directory/" + fileName: new(fstest.MapFile),
+			link: {
+				Data: []byte(fileName),
+				Mode: fs.ModeSymlink,
+			},
+		}}
+		fsys := newHandleLimitFS(t, linkfs, limit)
+		if _, ok := filesystem.Extension[filesystem.SymlinkFS](fsys); ok {
+			t.Error("wrapper reported extension which the wrapped system lacks")
+		}
+		linker, ok := filesystem.Extension[filesystem.ReadlinkFS](fsys)
+		if !ok {
+			t.Fatal("wrapper did not forward extension")
+		}
+		if target, err := linker.Readlink(link); err != nil || target != fileName {
+			t.Errorf("link target mismatch"+
+				"\ngot: %s (%v)"+
+				"\nwant: %s",
+				target, err, fileName,
+			)
+		}
+		file, err := fsys.Open(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, ok := filesystem.FileExtension[io.ReaderAt](file); !ok {
+			t.Errorf("%T did not forward %T", file, (*io.ReaderAt)(nil))
+		}
+		if _, ok := filesystem.FileExtension[io.Writer](file); ok {
+			t.Errorf("%T reported %T which the wrapped file lacks", file, (*io.Writer)(nil))
+		}
+		directory, err := fsys.Open("directory")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer directory.Close()
+		if _, ok := filesystem.FileExtension[filesystem.StreamDirFile](directory); ok {
+			t.Errorf("%T reported %T which the wrapped directory lacks",
+				directory, (*filesystem.StreamDirFile)(nil))
+		}
+		names, err := filesystem.ReadDirNames(directory.(fs.ReadDirFile), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(names) != 1 || names[0] != fileName {
+			t.Errorf("directory names mismatch"+
+				"\ngot: %v"+
+				"\nwant: [%s]",
+				names, fileName,
+			)
+		}
+	})
+	t.Run("fstest", func(t *testing.T) {
+		t.Parallel()
+		// [fstest.TestFS] holds several files open at once.
+		const limit = 16
+		fsys := newHandleLimitFS(t, memfs, limit)
+		if err := fstest.TestFS(fsys,
+			fileName, "directory/"+fileName,
+		); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		if _, err := filesystem.NewHandleLimitFS(memfs, 0); err == nil {
+			t.Error("expected error for non-positive limit, got nil")
+		}
+	})
+}
+
+func newHandleLimitFS(t *testing.T, fsys fs.FS, limit int) *filesystem.HandleLimitFS {
+	t.Helper()
+	lfs, err := filesystem.NewHandleLimitFS(fsys, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lfs
+}
+
+func limitOpenFails(t *testing.T, fsys fs.FS, name string) {
+	t.Helper()
+	file, err := fsys.Open(name)
+	if err == nil {
+		file.Close()
+		t.Fatal("expected error when opening beyond limit, got nil")
+	}
+	var fsErr *fserrors.Error
+	if !errors.As(err, &fsErr) ||
+		fsErr.Kind != fserrors.TooManyOpen {
+		t.Errorf("unexpected error when opening beyond limit"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, fserrors.TooManyOpen,
+		)
+	}
+}
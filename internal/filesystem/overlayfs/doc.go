@@ -0,0 +1,3 @@
+// Package overlayfs unions multiple [fs.FS] layers
+// into a single file system.
+package overlayfs
@@ -0,0 +1,196 @@
+package overlayfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+type (
+	// FS unions multiple file systems (layers).
+	// Names are resolved by the first layer which has them,
+	// with the exception of the root directory; whose
+	// entries are merged from every layer.
+	//
+	// Errors other than not-exist (from any layer)
+	// are returned immediately, rather than falling
+	// through to the layers beneath it.
+	FS struct{ layers []fs.FS }
+	// rootDirectory is the merged root directory.
+	// Its entries are read when first requested.
+	rootDirectory struct {
+		info    fs.FileInfo
+		fsys    *FS
+		entries []fs.DirEntry
+		loaded  bool
+		closed  bool
+	}
+)
+
+// New unions the layers, with
+// earlier layers taking precedence.
+func New(layers ...fs.FS) *FS {
+	return &FS{layers: layers}
+}
+
+func (ofs *FS) Open(name string) (fs.File, error) {
+	const op = "open"
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == filesystem.Root {
+		info, err := ofs.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		return &rootDirectory{
+			info: info,
+			fsys: ofs,
+		}, nil
+	}
+	return firstLayer(ofs, op, name,
+		func(layer fs.FS) (fs.File, error) { return layer.Open(name) },
+	)
+}
+
+func (ofs *FS) Stat(name string) (fs.FileInfo, error) {
+	const op = "stat"
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return firstLayer(ofs, op, name,
+		func(layer fs.FS) (fs.FileInfo, error) { return fs.Stat(layer, name) },
+	)
+}
+
+// ReadDir returns the entries of the root directory
+// from each layer, in layer order then name order.
+// Names which exist in multiple layers are only
+// listed once; for the layer that takes precedence.
+// Other directories are read from the first
+// layer which has them.
+func (ofs *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	const op = "readdir"
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name != filesystem.Root {
+		return firstLayer(ofs, op, name,
+			func(layer fs.FS) ([]fs.DirEntry, error) { return fs.ReadDir(layer, name) },
+		)
+	}
+	var (
+		merged []fs.DirEntry
+		seen   = make(map[string]struct{})
+		found  bool
+	)
+	for _, layer := range ofs.layers {
+		entries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if isNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range entries {
+			entryName := entry.Name()
+			if _, ok := seen[entryName]; ok {
+				continue
+			}
+			seen[entryName] = struct{}{}
+			merged = append(merged, entry)
+		}
+	}
+	if !found {
+		return nil, fserrors.New(op, name, filesystem.ErrNotFound, fserrors.NotExist)
+	}
+	return merged, nil
+}
+
+// Close closes each layer which implements
+// [io.Closer], and returns their errors (if any).
+func (ofs *FS) Close() error {
+	errs := make([]error, 0, len(ofs.layers))
+	for _, layer := range ofs.layers {
+		if closer, ok := layer.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// firstLayer returns the result of `fn` for the first layer
+// which does not return a not-exist error.
+func firstLayer[T any](ofs *FS, op, name string, fn func(fs.FS) (T, error)) (T, error) {
+	for _, layer := range ofs.layers {
+		value, err := fn(layer)
+		if err == nil || !isNotExist(err) {
+			return value, err
+		}
+	}
+	var zero T
+	return zero, fserrors.New(op, name, filesystem.ErrNotFound, fserrors.NotExist)
+}
+
+func isNotExist(err error) bool {
+	var fsErr *fserrors.Error
+	if errors.As(err, &fsErr) {
+		return fsErr.Kind == fserrors.NotExist
+	}
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+func (or *rootDirectory) Stat() (fs.FileInfo, error) {
+	const op = "stat"
+	if or.closed {
+		return nil, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	return or.info, nil
+}
+
+func (or *rootDirectory) Read([]byte) (int, error) {
+	const op = "read"
+	if or.closed {
+		return -1, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	return -1, fserrors.New(op, filesystem.Root, filesystem.ErrIsDir, fserrors.IsDir)
+}
+
+func (or *rootDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
+	const op = "readdir"
+	if or.closed {
+		return nil, fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	if !or.loaded {
+		entries, err := or.fsys.ReadDir(filesystem.Root)
+		if err != nil {
+			return nil, err
+		}
+		or.entries, or.loaded = entries, true
+	}
+	entries := or.entries
+	if count <= 0 {
+		or.entries = nil
+		return entries, nil
+	}
+	if len(entries) == 0 {
+		return nil, io.EOF
+	}
+	count = generic.Min(count, len(entries))
+	or.entries = entries[count:]
+	return entries[:count], nil
+}
+
+func (or *rootDirectory) Close() error {
+	const op = "close"
+	if or.closed {
+		return fserrors.New(op, filesystem.Root, filesystem.ErrNotOpen, fserrors.Closed)
+	}
+	or.closed = true
+	return nil
+}
@@ -0,0 +1,150 @@
+package overlayfs_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	fserrors "github.com/djdv/go-filesystem-utils/internal/filesystem/errors"
+	"github.com/djdv/go-filesystem-utils/internal/filesystem/overlayfs"
+)
+
+// failingFS fails every operation with `err`.
+type failingFS struct{ err error }
+
+func (ffs failingFS) Open(string) (fs.File, error) { return nil, ffs.err }
+
+func TestOverlayFS(t *testing.T) {
+	t.Parallel()
+	var (
+		upper = fstest.MapFS{
+			"shared":      {Data: []byte("upper")},
+			"upper/file":  {Data: []byte("upper file")},
+			"zz-upper":    {Data: []byte("upper only")},
+			"nested/file": {Data: []byte("upper nested")},
+		}
+		lower = fstest.MapFS{
+			"shared":       {Data: []byte("lower")},
+			"a-lower":      {Data: []byte("lower only")},
+			"nested/file":  {Data: []byte("lower nested")},
+			"nested/other": {Data: []byte("lower other")},
+		}
+		fsys = overlayfs.New(upper, lower)
+	)
+	t.Run("open", func(t *testing.T) {
+		t.Parallel()
+		for name, want := range map[string]string{
+			"shared":       "upper",
+			"zz-upper":     "upper only",
+			"a-lower":      "lower only",
+			"nested/file":  "upper nested",
+			"nested/other": "lower other",
+		} {
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); got != want {
+				t.Errorf("\"%s\" data mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					name, got, want,
+				)
+			}
+		}
+		_, err := fs.Stat(fsys, "missing")
+		overlayKindMatch(t, err, fserrors.NotExist)
+	})
+	t.Run("readdir", func(t *testing.T) {
+		t.Parallel()
+		// Layer order, then name order.
+		want := []string{"nested", "shared", "upper", "zz-upper", "a-lower"}
+		entries, err := fs.ReadDir(fsys, filesystem.Root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		overlayNamesMatch(t, entries, want)
+		// Only the root is merged.
+		entries, err = fs.ReadDir(fsys, "nested")
+		if err != nil {
+			t.Fatal(err)
+		}
+		overlayNamesMatch(t, entries, []string{"file"})
+		root, err := fsys.Open(filesystem.Root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer root.Close()
+		directory := root.(fs.ReadDirFile)
+		var paged []fs.DirEntry
+		for {
+			entries, err := directory.ReadDir(2)
+			paged = append(paged, entries...)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		overlayNamesMatch(t, paged, want)
+	})
+	t.Run("short circuit", func(t *testing.T) {
+		t.Parallel()
+		errLayer := fs.ErrPermission
+		fsys := overlayfs.New(failingFS{err: errLayer}, lower)
+		if _, err := fsys.Open("a-lower"); !errors.Is(err, errLayer) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, errLayer,
+			)
+		}
+		if _, err := fs.ReadDir(fsys, filesystem.Root); !errors.Is(err, errLayer) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, errLayer,
+			)
+		}
+		fsys = overlayfs.New(failingFS{err: fs.ErrNotExist}, lower)
+		if _, err := fs.ReadFile(fsys, "a-lower"); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func overlayNamesMatch(t *testing.T, entries []fs.DirEntry, want []string) {
+	t.Helper()
+	got := make([]string, len(entries))
+	for i, entry := range entries {
+		got[i] = entry.Name()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			got, want,
+		)
+	}
+}
+
+func overlayKindMatch(t *testing.T, err error, want fserrors.Kind) {
+	t.Helper()
+	var fsErr *fserrors.Error
+	if !errors.As(err, &fsErr) {
+		t.Errorf("expected %T, got: %#v", fsErr, err)
+		return
+	}
+	if got := fsErr.Kind; got != want {
+		t.Errorf("error kind mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, want,
+		)
+	}
+}
@@ -0,0 +1,250 @@
+package filesystem
+
+import (
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/u-root/uio/ulog"
+)
+
+type (
+	// SlowLogFS wraps a file system and logs
+	// operations which take longer than a threshold
+	// to complete, along with the name they were
+	// called on and their duration.
+	//
+	// Without [WithSlowLog], logging is disabled
+	// and operations are passed through to the
+	// wrapped system unmeasured.
+	SlowLogFS struct {
+		forwardFS
+		log       ulog.Logger
+		threshold time.Duration
+	}
+	SlowLogOption func(*SlowLogFS) error
+
+	slowFile struct {
+		forwardFile
+		fsys *SlowLogFS
+	}
+	slowSeeker struct {
+		*slowFile
+		seeker io.Seeker
+	}
+	slowDirectory struct {
+		*slowFile
+		forwardDirectory
+	}
+)
+
+// NewSlowLogFS wraps `fsys`, logging
+// operations that exceed the threshold
+// set via [WithSlowLog].
+func NewSlowLogFS(fsys fs.FS, options ...SlowLogOption) (*SlowLogFS, error) {
+	sfs := &SlowLogFS{forwardFS: forwardFS{fsys: fsys}}
+	if err := generic.ApplyOptions(sfs, options...); err != nil {
+		return nil, err
+	}
+	return sfs, nil
+}
+
+// WithSlowLog enables logging of operations
+// which take longer than `threshold`.
+func WithSlowLog(threshold time.Duration, logger ulog.Logger) SlowLogOption {
+	return func(sfs *SlowLogFS) error {
+		if threshold <= 0 {
+			return generic.ConstError("slow log threshold must be positive")
+		}
+		if logger == nil {
+			return generic.ConstError("slow log logger is nil")
+		}
+		sfs.threshold = threshold
+		sfs.log = logger
+		return nil
+	}
+}
+
+func (sfs *SlowLogFS) Open(name string) (fs.File, error) {
+	return sfs.open("open", name, sfs.fsys.Open)
+}
+
+func (sfs *SlowLogFS) Stat(name string) (fs.FileInfo, error) {
+	return slowCall(sfs, "stat", name, func() (fs.FileInfo, error) {
+		return fs.Stat(sfs.fsys, name)
+	})
+}
+
+func (sfs *SlowLogFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return slowCall(sfs, "readdir", name, func() ([]fs.DirEntry, error) {
+		return fs.ReadDir(sfs.fsys, name)
+	})
+}
+
+func (sfs *SlowLogFS) OpenFile(name string, flag int, perm fs.FileMode) (fs.File, error) {
+	const op = "open"
+	opener, ok := Extension[OpenFileFS](sfs.fsys)
+	if !ok {
+		return nil, unsupportedOp(op, name)
+	}
+	return sfs.open(op, name, func(name string) (fs.File, error) {
+		return opener.OpenFile(name, flag, perm)
+	})
+}
+
+func (sfs *SlowLogFS) CreateFile(name string) (fs.File, error) {
+	const op = "create"
+	creator, ok := Extension[CreateFileFS](sfs.fsys)
+	if !ok {
+		return nil, unsupportedOp(op, name)
+	}
+	return sfs.open(op, name, creator.CreateFile)
+}
+
+func (sfs *SlowLogFS) Remove(name string) error {
+	return slowDo(sfs, "remove", name, func() error {
+		return sfs.forwardFS.Remove(name)
+	})
+}
+
+func (sfs *SlowLogFS) Readlink(name string) (string, error) {
+	return slowCall(sfs, "readlink", name, func() (string, error) {
+		return sfs.forwardFS.Readlink(name)
+	})
+}
+
+func (sfs *SlowLogFS) Symlink(oldname, newname string) error {
+	return slowDo(sfs, "symlink", newname, func() error {
+		return sfs.forwardFS.Symlink(oldname, newname)
+	})
+}
+
+func (sfs *SlowLogFS) Rename(oldName, newName string) error {
+	return slowDo(sfs, "rename", oldName, func() error {
+		return sfs.forwardFS.Rename(oldName, newName)
+	})
+}
+
+func (sfs *SlowLogFS) Truncate(name string, size int64) error {
+	return slowDo(sfs, "truncate", name, func() error {
+		return sfs.forwardFS.Truncate(name, size)
+	})
+}
+
+func (sfs *SlowLogFS) Mkdir(name string, perm fs.FileMode) error {
+	return slowDo(sfs, "mkdir", name, func() error {
+		return sfs.forwardFS.Mkdir(name, perm)
+	})
+}
+
+func (sfs *SlowLogFS) Chown(name string, uid, gid int) error {
+	return slowDo(sfs, "chown", name, func() error {
+		return sfs.forwardFS.Chown(name, uid, gid)
+	})
+}
+
+func (sfs *SlowLogFS) open(op, name string, openFn func(string) (fs.File, error)) (fs.File, error) {
+	if !sfs.enabled() {
+		return openFn(name)
+	}
+	file, err := slowCall(sfs, op, name, func() (fs.File, error) {
+		return openFn(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sfs.wrap(file, name), nil
+}
+
+// slowCall calls `fn`, and logs the call
+// if it exceeds the file system's threshold.
+func slowCall[T any](sfs *SlowLogFS, op, name string, fn func() (T, error)) (T, error) {
+	if !sfs.enabled() {
+		return fn()
+	}
+	start := time.Now()
+	value, err := fn()
+	sfs.observe(op, name, start)
+	return value, err
+}
+
+// slowDo is like [slowCall]
+// for functions which only return an error.
+func slowDo(sfs *SlowLogFS, op, name string, fn func() error) error {
+	_, err := slowCall(sfs, op, name, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+func (sfs *SlowLogFS) enabled() bool { return sfs.log != nil }
+
+func (sfs *SlowLogFS) observe(op, name string, start time.Time) {
+	if elapsed := time.Since(start); elapsed > sfs.threshold {
+		sfs.log.Printf(`slow %s: "%s" took %s`, op, name, elapsed)
+	}
+}
+
+func (sfs *SlowLogFS) wrap(file fs.File, name string) fs.File {
+	slow := &slowFile{
+		forwardFile: forwardFile{
+			File: file,
+			name: name,
+		},
+		fsys: sfs,
+	}
+	switch typed := file.(type) {
+	case fs.ReadDirFile:
+		return &slowDirectory{
+			slowFile: slow,
+			forwardDirectory: forwardDirectory{
+				ReadDirFile: typed,
+				name:        name,
+			},
+		}
+	case io.Seeker:
+		return &slowSeeker{
+			slowFile: slow,
+			seeker:   typed,
+		}
+	default:
+		return slow
+	}
+}
+
+func (sf *slowFile) Read(b []byte) (int, error) {
+	return slowCall(sf.fsys, "read", sf.name, func() (int, error) {
+		return sf.File.Read(b)
+	})
+}
+
+func (sf *slowFile) ReadAt(b []byte, offset int64) (int, error) {
+	return slowCall(sf.fsys, "readat", sf.name, func() (int, error) {
+		return sf.forwardFile.ReadAt(b, offset)
+	})
+}
+
+func (sf *slowFile) Write(b []byte) (int, error) {
+	return slowCall(sf.fsys, "write", sf.name, func() (int, error) {
+		return sf.forwardFile.Write(b)
+	})
+}
+
+func (ss *slowSeeker) Seek(offset int64, whence int) (int64, error) {
+	return slowCall(ss.fsys, "seek", ss.name, func() (int64, error) {
+		return ss.seeker.Seek(offset, whence)
+	})
+}
+
+func (sd *slowDirectory) ReadDir(count int) ([]fs.DirEntry, error) {
+	return slowCall(sd.fsys, "readdir", sd.slowFile.name, func() ([]fs.DirEntry, error) {
+		return sd.ReadDirFile.ReadDir(count)
+	})
+}
+
+func (sd *slowDirectory) ReadDirNames(count int) ([]string, error) {
+	return slowCall(sd.fsys, "readdirnames", sd.slowFile.name, func() ([]string, error) {
+		return sd.forwardDirectory.ReadDirNames(count)
+	})
+}
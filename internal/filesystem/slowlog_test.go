@@ -0,0 +1,183 @@
+package filesystem_test
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+)
+
+type (
+	// delayFS sleeps before opening files
+	// that have a `slow` prefix.
+	delayFS struct {
+		fsys  fstest.MapFS
+		delay time.Duration
+	}
+	recordLogger struct {
+		messages []string
+		mu       sync.Mutex
+	}
+)
+
+func (dfs delayFS) Open(name string) (fs.File, error) {
+	if strings.HasPrefix(name, "slow") {
+		time.Sleep(dfs.delay)
+	}
+	return dfs.fsys.Open(name)
+}
+
+func (rl *recordLogger) Printf(format string, v ...any) {
+	rl.Print(fmt.Sprintf(format, v...))
+}
+
+func (rl *recordLogger) Print(v ...any) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.messages = append(rl.messages, fmt.Sprint(v...))
+}
+
+func TestSlowLogFS(t *testing.T) {
+	t.Parallel()
+	const (
+		threshold = 20 * time.Millisecond
+		delay     = 2 * threshold
+	)
+	guest := delayFS{
+		fsys: fstest.MapFS{
+			"fast":      {Data: []byte("fast")},
+			"slow":      {Data: []byte("slow")},
+			"slowdir/a": {Data: []byte("a")},
+		},
+		delay: delay,
+	}
+	t.Run("threshold", func(t *testing.T) {
+		t.Parallel()
+		logger := new(recordLogger)
+		fsys, err := filesystem.NewSlowLogFS(guest,
+			filesystem.WithSlowLog(threshold, logger),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"fast", "slow"} {
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); got != name {
+				t.Errorf("data mismatch"+
+					"\ngot: %s"+
+					"\nwant: %s",
+					got, name,
+				)
+			}
+		}
+		if _, err := fs.ReadDir(fsys, "slowdir"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fs.Stat(fsys, "fast"); err != nil {
+			t.Fatal(err)
+		}
+		logger.mu.Lock()
+		defer logger.mu.Unlock()
+		want := []string{
+			`slow open: "slow"`,
+			`slow readdir: "slowdir"`,
+		}
+		if len(logger.messages) != len(want) {
+			t.Fatalf("log count mismatch"+
+				"\ngot: %q"+
+				"\nwant: %q",
+				logger.messages, want,
+			)
+		}
+		for i, message := range logger.messages {
+			if !strings.HasPrefix(message, want[i]) {
+				t.Errorf("log message mismatch"+
+					"\ngot: %s"+
+					"\nwant prefix: %s",
+					message, want[i],
+				)
+			}
+		}
+	})
+	t.Run("conformance", func(t *testing.T) {
+		t.Parallel()
+		for _, options := range [][]filesystem.SlowLogOption{
+			nil,
+			{filesystem.WithSlowLog(time.Hour, new(recordLogger))},
+		} {
+			fsys, err := filesystem.NewSlowLogFS(guest.fsys, options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := fstest.TestFS(fsys, "fast", "slow", "slowdir/a"); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	t.Run("extensions", func(t *testing.T) {
+		t.Parallel()
+		const (
+			fileName = "file"
+			link     = "link"
+		)
+		linkfs := linkMapFS{MapFS: fstest.MapFS{
+			fileName: {Data: []byte("data")},
+			link: {
+				Data: []byte(fileName),
+				Mode: fs.ModeSymlink,
+			},
+		}}
+		logger := new(recordLogger)
+		fsys, err := filesystem.NewSlowLogFS(linkfs,
+			filesystem.WithSlowLog(time.Hour, logger),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := filesystem.Extension[filesystem.SymlinkFS](fsys); ok {
+			t.Error("wrapper reported extension which the wrapped system lacks")
+		}
+		linker, ok := filesystem.Extension[filesystem.ReadlinkFS](fsys)
+		if !ok {
+			t.Fatal("wrapper did not forward extension")
+		}
+		if target, err := linker.Readlink(link); err != nil || target != fileName {
+			t.Errorf("link target mismatch"+
+				"\ngot: %s (%v)"+
+				"\nwant: %s",
+				target, err, fileName,
+			)
+		}
+		file, err := fsys.Open(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, ok := filesystem.FileExtension[io.ReaderAt](file); !ok {
+			t.Errorf("%T did not forward %T", file, (*io.ReaderAt)(nil))
+		}
+		if _, ok := filesystem.FileExtension[io.Writer](file); ok {
+			t.Errorf("%T reported %T which the wrapped file lacks", file, (*io.Writer)(nil))
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		for _, option := range []filesystem.SlowLogOption{
+			filesystem.WithSlowLog(0, new(recordLogger)),
+			filesystem.WithSlowLog(threshold, nil),
+		} {
+			if _, err := filesystem.NewSlowLogFS(guest, option); err == nil {
+				t.Error("expected option to be rejected")
+			}
+		}
+	})
+}
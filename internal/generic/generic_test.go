@@ -8,4 +8,5 @@ func TestGeneric(t *testing.T) {
 	t.Parallel()
 	t.Run("channel", channel)
 	t.Run("slice", slice)
+	t.Run("retry", retry)
 }
@@ -0,0 +1,64 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// MakeJitterFunc returns a function which returns
+// an interval starting at `initial`, doubling each
+// call (up to a maximum), with 10% jitter added.
+func MakeJitterFunc(initial time.Duration) func() time.Duration {
+	// Adapted from an inlined [net/http] closure.
+	const pollIntervalMax = 500 * time.Millisecond
+	return func() time.Duration {
+		// Add 10% jitter.
+		interval := initial
+		if jitter := int64(initial / 10); jitter > 0 {
+			interval += time.Duration(rand.Int63n(jitter))
+		}
+		// Double and clamp for next time.
+		initial *= 2
+		if initial > pollIntervalMax {
+			initial = pollIntervalMax
+		}
+		return interval
+	}
+}
+
+// RetryWithJitter calls `fn` until it succeeds, returns an
+// error that is not `retryable`, or has been called `attempts` times.
+// If `attempts` is < 1, calls are only bounded by the context.
+// If `retryable` is nil, all errors are retried.
+// Calls are separated by intervals from [MakeJitterFunc],
+// starting with `base`.
+// If the context is done while waiting, its error
+// is returned along with the last error from `fn`.
+func RetryWithJitter(ctx context.Context,
+	attempts int, base time.Duration,
+	fn func() error, retryable func(error) bool,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	nextInterval := MakeJitterFunc(base)
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts ||
+			(retryable != nil && !retryable(err)) {
+			return err
+		}
+		timer := time.NewTimer(nextInterval())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
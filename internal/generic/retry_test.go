@@ -0,0 +1,180 @@
+package generic_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+)
+
+const (
+	errRetry     = generic.ConstError("retryable")
+	errPermanent = generic.ConstError("permanent")
+)
+
+func retry(t *testing.T) {
+	t.Parallel()
+	t.Run("succeeds", retrySucceeds)
+	t.Run("exhausted", retryExhausted)
+	t.Run("not retryable", retryPermanent)
+	t.Run("canceled", retryCanceled)
+	t.Run("jitter", retryJitter)
+}
+
+func isRetryable(err error) bool { return errors.Is(err, errRetry) }
+
+func retrySucceeds(t *testing.T) {
+	t.Parallel()
+	const (
+		succeedOn = 3
+		attempts  = 5
+	)
+	var calls int
+	err := generic.RetryWithJitter(context.Background(),
+		attempts, time.Microsecond,
+		func() error {
+			if calls++; calls < succeedOn {
+				return errRetry
+			}
+			return nil
+		}, isRetryable,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callsMatch(t, calls, succeedOn)
+}
+
+func retryExhausted(t *testing.T) {
+	t.Parallel()
+	const attempts = 3
+	var calls int
+	err := generic.RetryWithJitter(context.Background(),
+		attempts, time.Microsecond,
+		func() error { calls++; return errRetry },
+		nil,
+	)
+	if !errors.Is(err, errRetry) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, errRetry,
+		)
+	}
+	callsMatch(t, calls, attempts)
+}
+
+func retryPermanent(t *testing.T) {
+	t.Parallel()
+	const attempts = 3
+	var calls int
+	err := generic.RetryWithJitter(context.Background(),
+		attempts, time.Hour,
+		func() error { calls++; return errPermanent },
+		isRetryable,
+	)
+	if !errors.Is(err, errPermanent) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, errPermanent,
+		)
+	}
+	callsMatch(t, calls, 1)
+}
+
+func retryCanceled(t *testing.T) {
+	t.Parallel()
+	const unbounded = 0
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		calls       int
+		result      = make(chan error, 1)
+	)
+	defer cancel()
+	called := make(chan struct{})
+	go func() {
+		result <- generic.RetryWithJitter(ctx,
+			unbounded, time.Hour,
+			func() error {
+				calls++
+				close(called)
+				return errRetry
+			}, isRetryable,
+		)
+	}()
+	<-called
+	cancel()
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.Canceled) ||
+			!errors.Is(err, errRetry) {
+			t.Errorf("error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v and %v",
+				err, context.Canceled, errRetry,
+			)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry did not return after cancellation")
+	}
+	callsMatch(t, calls, 1)
+	if err := generic.RetryWithJitter(ctx,
+		unbounded, time.Hour,
+		func() error {
+			t.Error("function called with done context")
+			return nil
+		}, nil,
+	); !errors.Is(err, context.Canceled) {
+		t.Errorf("error mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, context.Canceled,
+		)
+	}
+}
+
+func retryJitter(t *testing.T) {
+	t.Parallel()
+	const (
+		initial = 100 * time.Millisecond
+		maximum = 500 * time.Millisecond
+	)
+	var (
+		nextInterval = generic.MakeJitterFunc(initial)
+		want         = initial
+	)
+	for i := 0; i < 5; i++ {
+		got := nextInterval()
+		if limit := want + want/10; got < want || got >= limit {
+			t.Errorf("interval %d out of range"+
+				"\ngot: %v"+
+				"\nwant: [%v, %v)",
+				i, got, want, limit,
+			)
+		}
+		if want *= 2; want > maximum {
+			want = maximum
+		}
+	}
+	if interval := generic.MakeJitterFunc(time.Nanosecond)(); interval != time.Nanosecond {
+		t.Errorf("interval mismatch"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			interval, time.Nanosecond,
+		)
+	}
+}
+
+func callsMatch(t *testing.T, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Errorf("call count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+}
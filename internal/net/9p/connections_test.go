@@ -0,0 +1,130 @@
+package p9_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	p9net "github.com/djdv/go-filesystem-utils/internal/net/9p"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+func TestMaxConnections(t *testing.T) {
+	t.Parallel()
+	t.Run("gate", testMaxConnectionsGate)
+	t.Run("shutdown", testMaxConnectionsShutdown)
+}
+
+func testMaxConnectionsGate(t *testing.T) {
+	t.Parallel()
+	var (
+		server = p9net.NewServer(nopAttacher{},
+			p9net.WithMaxConnections(1),
+		)
+		listener  = newTrackingListener(t)
+		serveErrs = make(chan error, 1)
+	)
+	go func() { serveErrs <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		if err := server.Close(); err != nil {
+			t.Error(err)
+		}
+		<-serveErrs
+	})
+	first := dialConnection(t, listener)
+	<-listener.conns
+	waitForConnections(t, server, 1)
+	second := dialConnection(t, listener)
+	const settle = 100 * time.Millisecond
+	select {
+	case <-listener.conns:
+		t.Fatal("connection was accepted while at the limit")
+	case <-time.After(settle):
+	}
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-listener.conns:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not accepted after a slot was released")
+	}
+	waitForConnections(t, server, 1)
+	if _, err := second.Write(makeVersionMessage("9P2000.L")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readVersionMessage(second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testMaxConnectionsShutdown(t *testing.T) {
+	t.Parallel()
+	var (
+		server = p9net.NewServer(nopAttacher{},
+			p9net.WithMaxConnections(1),
+			p9net.WithIdleDuration(0),
+		)
+		listener  = newTrackingListener(t)
+		serveErrs = make(chan error, 1)
+	)
+	go func() { serveErrs <- server.Serve(listener) }()
+	dialConnection(t, listener)
+	<-listener.conns
+	waitForConnections(t, server, 1)
+	// Serve is now waiting for a slot,
+	// which must not block the shutdown.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-serveErrs:
+		if !errors.Is(err, p9net.ErrServerClosed) {
+			t.Errorf("serve error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, p9net.ErrServerClosed,
+			)
+		}
+	case <-ctx.Done():
+		t.Fatal("serve did not return after shutdown")
+	}
+	if got := server.ActiveConnections(); got != 0 {
+		t.Errorf("connections remain after shutdown: %d", got)
+	}
+}
+
+func dialConnection(t *testing.T, listener trackingListener) manet.Conn {
+	t.Helper()
+	conn, err := manet.Dial(listener.Multiaddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitForConnections polls until the server
+// is handling the `want` amount of connections.
+func waitForConnections(t *testing.T, server *p9net.Server, want int) {
+	t.Helper()
+	const (
+		timeout  = 5 * time.Second
+		interval = time.Millisecond
+	)
+	deadline := time.Now().Add(timeout)
+	for server.ActiveConnections() != want &&
+		time.Now().Before(deadline) {
+		time.Sleep(interval)
+	}
+	if got := server.ActiveConnections(); got != want {
+		t.Fatalf("active connection count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+}
@@ -0,0 +1,176 @@
+package p9
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// deadlineSetter is implemented by connections
+	// that support I/O deadlines (E.g. [net.Conn]).
+	deadlineSetter interface {
+		SetReadDeadline(time.Time) error
+		SetWriteDeadline(time.Time) error
+	}
+	// connDeadline extends a connection's deadlines
+	// whenever it's active, or busy.
+	// Each 9P request receives exactly one response,
+	// so the connection is considered busy if
+	// fewer responses have been written than
+	// requests which have been read.
+	connDeadline struct {
+		setter              deadlineSetter
+		reads               trackedReads
+		writes              trackedWrites
+		requests, responses frameCounter
+		duration            time.Duration
+	}
+	deadlineReader struct {
+		*connDeadline
+		trackedReads
+	}
+	deadlineWriter struct {
+		*connDeadline
+		trackedWrites
+	}
+	// frameCounter counts the 9P messages
+	// observed within a byte stream.
+	frameCounter struct {
+		count     atomic.Uint64
+		mu        sync.Mutex
+		remaining uint32
+		header    [sizeFieldLength]byte
+		headerLen uint8
+	}
+)
+
+func withDeadlines(rc io.ReadCloser, wc io.WriteCloser,
+	reads trackedReads, writes trackedWrites,
+	duration time.Duration,
+) (trackedReads, trackedWrites) {
+	setter := getDeadlineSetter(rc, wc)
+	if setter == nil {
+		return reads, writes
+	}
+	var (
+		deadline = &connDeadline{
+			setter:   setter,
+			reads:    reads,
+			writes:   writes,
+			duration: duration,
+		}
+		reader = deadlineReader{
+			connDeadline: deadline,
+			trackedReads: reads,
+		}
+		writer = deadlineWriter{
+			connDeadline:  deadline,
+			trackedWrites: writes,
+		}
+	)
+	return reader, writer
+}
+
+func getDeadlineSetter(rc io.ReadCloser, wc io.WriteCloser) deadlineSetter {
+	if setter, ok := rc.(deadlineSetter); ok {
+		return setter
+	}
+	if setter, ok := wc.(deadlineSetter); ok {
+		return setter
+	}
+	return nil
+}
+
+// busy reports whether a request is pending a response.
+func (cd *connDeadline) busy() bool {
+	return cd.requests.count.Load() > cd.responses.count.Load()
+}
+
+// next returns the deadline relative
+// to the last operation on the connection.
+func (cd *connDeadline) next() time.Time {
+	var (
+		read  = cd.reads.LastRead()
+		write = cd.writes.LastWrite()
+	)
+	if read.After(write) {
+		return read.Add(cd.duration)
+	}
+	return write.Add(cd.duration)
+}
+
+func (dr deadlineReader) Read(b []byte) (int, error) {
+	for {
+		deadline := dr.next()
+		if dr.busy() {
+			// Requests may legitimately take longer
+			// than the duration (E.g. large directories),
+			// the peer is not expected to send anything
+			// else while waiting for them.
+			deadline = time.Now().Add(dr.duration)
+		}
+		if err := dr.setter.SetReadDeadline(deadline); err != nil {
+			return 0, err
+		}
+		read, err := dr.trackedReads.Read(b)
+		if read > 0 {
+			dr.requests.observe(b[:read])
+		}
+		if read != 0 || !errors.Is(err, os.ErrDeadlineExceeded) {
+			return read, err
+		}
+		if !dr.busy() &&
+			!time.Now().Before(dr.next()) {
+			return read, err
+		}
+	}
+}
+
+func (dw deadlineWriter) Write(b []byte) (int, error) {
+	deadline := time.Now().Add(dw.duration)
+	if err := dw.setter.SetWriteDeadline(deadline); err != nil {
+		return 0, err
+	}
+	wrote, err := dw.trackedWrites.Write(b)
+	if wrote > 0 {
+		dw.responses.observe(b[:wrote])
+	}
+	return wrote, err
+}
+
+// observe counts each message
+// which is completed within `b`.
+func (fc *frameCounter) observe(b []byte) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	for len(b) > 0 {
+		if fc.remaining == 0 {
+			copied := copy(fc.header[fc.headerLen:], b)
+			b = b[copied:]
+			if fc.headerLen += uint8(copied); fc.headerLen < sizeFieldLength {
+				return
+			}
+			fc.headerLen = 0
+			size := binary.LittleEndian.Uint32(fc.header[:])
+			if size <= sizeFieldLength {
+				fc.count.Add(1) // Malformed; nothing to wait for.
+				continue
+			}
+			fc.remaining = size - sizeFieldLength
+			continue
+		}
+		consumed := len(b)
+		if remaining := int(fc.remaining); consumed > remaining {
+			consumed = remaining
+		}
+		b = b[consumed:]
+		if fc.remaining -= uint32(consumed); fc.remaining == 0 {
+			fc.count.Add(1)
+		}
+	}
+}
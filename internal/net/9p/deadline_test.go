@@ -0,0 +1,125 @@
+package p9_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	p9net "github.com/djdv/go-filesystem-utils/internal/net/9p"
+	"github.com/djdv/p9/p9"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+type slowAttacher struct{ delay time.Duration }
+
+func (sa slowAttacher) Attach() (p9.File, error) {
+	time.Sleep(sa.delay)
+	return nil, io.EOF
+}
+
+func TestConnDeadline(t *testing.T) {
+	t.Parallel()
+	const deadline = 50 * time.Millisecond
+	t.Run("unresponsive", func(t *testing.T) {
+		t.Parallel()
+		conn := dialDeadlineServer(t, nopAttacher{}, deadline)
+		// The peer never sends anything,
+		// the server should drop it.
+		if err := conn.SetReadDeadline(time.Now().Add(deadline * 100)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := conn.Read(make([]byte, 1))
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected connection to be closed by server"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, io.EOF,
+			)
+		}
+	})
+	t.Run("slow request", func(t *testing.T) {
+		t.Parallel()
+		var (
+			attacher = slowAttacher{delay: deadline * 4}
+			conn     = dialDeadlineServer(t, attacher, deadline)
+		)
+		if _, err := conn.Write(makeVersionMessage("9P2000.L")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readVersionMessage(conn); err != nil {
+			t.Fatal(err)
+		}
+		// The server must still respond, and
+		// keep the connection open, even though
+		// requests take longer than the deadline.
+		for i := 0; i < 2; i++ {
+			if _, err := conn.Write(makeAttachMessage()); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := readMessage(conn); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
+
+func dialDeadlineServer(t *testing.T, attacher p9.Attacher, deadline time.Duration) manet.Conn {
+	t.Helper()
+	var (
+		server = p9net.NewServer(attacher,
+			p9net.WithConnDeadline(deadline),
+		)
+		listener  = newTrackingListener(t)
+		serveErrs = make(chan error, 1)
+	)
+	go func() { serveErrs <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		if err := server.Close(); err != nil {
+			t.Error(err)
+		}
+		<-serveErrs
+	})
+	conn, err := manet.Dial(listener.Multiaddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	<-listener.conns
+	return conn
+}
+
+func makeAttachMessage() []byte {
+	const (
+		tattach = 104
+		tag     = 1
+		fid     = 0
+		nofid   = ^uint32(0)
+		nouid   = ^uint32(0)
+		header  = 4 + 1 + 2 + 4 + 4 + 2 + 2 + 4
+	)
+	message := make([]byte, 0, header)
+	message = binary.LittleEndian.AppendUint32(message, header)
+	message = append(message, tattach)
+	message = binary.LittleEndian.AppendUint16(message, tag)
+	message = binary.LittleEndian.AppendUint32(message, fid)
+	message = binary.LittleEndian.AppendUint32(message, nofid)
+	message = binary.LittleEndian.AppendUint16(message, 0) // uname
+	message = binary.LittleEndian.AppendUint16(message, 0) // aname
+	return binary.LittleEndian.AppendUint32(message, nouid)
+}
+
+func readMessage(r io.Reader) ([]byte, error) {
+	const sizeLength = 4
+	sizeBuffer := make([]byte, sizeLength)
+	if _, err := io.ReadFull(r, sizeBuffer); err != nil {
+		return nil, err
+	}
+	var (
+		size    = binary.LittleEndian.Uint32(sizeBuffer)
+		message = make([]byte, size-sizeLength)
+	)
+	_, err := io.ReadFull(r, message)
+	return message, err
+}
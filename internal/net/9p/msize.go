@@ -0,0 +1,143 @@
+package p9
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+type (
+	// messageLimiter rewrites client requests so that
+	// server responses fit within the message size limit.
+	// The `msize` of version requests is lowered to the
+	// limit, and the `count` of read and readdir requests
+	// is lowered to fit within the negotiated size.
+	// Requests are otherwise passed through unmodified.
+	messageLimiter struct {
+		trackedReads
+		// pending holds the (possibly modified)
+		// header of the current request, which
+		// has not been read by the caller yet.
+		pending []byte
+		// remaining is the amount of bytes in the
+		// current request which follow its header.
+		remaining uint32
+		limit     uint32
+		// messageSize is the size negotiated
+		// by the (limited) version request.
+		messageSize uint32
+		header      [requestHeaderSize]byte
+	}
+)
+
+const (
+	// MinimumMessageSize is the smallest
+	// message size the server will negotiate.
+	MinimumMessageSize = 4096
+
+	// See: 9P2000 `read(5)` and 9P2000.L `readdir`.
+	msgTreaddir = 40
+	msgTread    = 116
+
+	fidFieldLength    = 4
+	offsetFieldLength = 8
+	countFieldLength  = 4
+	countOffset       = msizeOffset + fidFieldLength + offsetFieldLength
+	// requestHeaderSize is the length of the
+	// largest request header which is rewritten.
+	requestHeaderSize = countOffset + countFieldLength
+	// responseHeaderSize is the length of the
+	// header which precedes `Rread` and `Rreaddir` data.
+	responseHeaderSize = sizeFieldLength + typeFieldLength +
+		tagFieldLength + countFieldLength
+)
+
+// limitMessages wraps reads with a [messageLimiter]
+// if the limit is not 0.
+func limitMessages(reads trackedReads, limit uint32) trackedReads {
+	if limit == 0 {
+		return reads
+	}
+	return &messageLimiter{
+		trackedReads: reads,
+		limit:        limit,
+	}
+}
+
+func (ml *messageLimiter) Read(b []byte) (int, error) {
+	if len(ml.pending) == 0 && ml.remaining == 0 {
+		if err := ml.readHeader(); err != nil {
+			return 0, err
+		}
+	}
+	if pending := ml.pending; len(pending) != 0 {
+		copied := copy(b, pending)
+		ml.pending = pending[copied:]
+		return copied, nil
+	}
+	if uint32(len(b)) > ml.remaining {
+		b = b[:ml.remaining]
+	}
+	read, err := ml.trackedReads.Read(b)
+	ml.remaining -= uint32(read)
+	return read, err
+}
+
+// readHeader reads the beginning of the next request
+// (without reading beyond it), and rewrites it if needed.
+func (ml *messageLimiter) readHeader() error {
+	header := ml.header[:sizeFieldLength]
+	if _, err := io.ReadFull(ml.trackedReads, header); err != nil {
+		return err
+	}
+	size := binary.LittleEndian.Uint32(header)
+	headerSize := uint32(requestHeaderSize)
+	if size < headerSize {
+		headerSize = size
+	}
+	if headerSize > sizeFieldLength {
+		header = ml.header[:headerSize]
+		if _, err := io.ReadFull(ml.trackedReads, header[sizeFieldLength:]); err != nil {
+			return err
+		}
+	}
+	ml.rewrite(header)
+	ml.pending = header
+	if headerLength := uint32(len(header)); size > headerLength {
+		ml.remaining = size - headerLength
+	} else {
+		ml.remaining = 0 // Malformed; let the server reject it.
+	}
+	return nil
+}
+
+func (ml *messageLimiter) rewrite(header []byte) {
+	if len(header) <= typeOffset {
+		return
+	}
+	switch header[typeOffset] {
+	case msgTversion:
+		if len(header) < msizeOffset+msizeFieldLength {
+			return
+		}
+		field := header[msizeOffset:]
+		messageSize := binary.LittleEndian.Uint32(field)
+		if messageSize > ml.limit {
+			messageSize = ml.limit
+			binary.LittleEndian.PutUint32(field, messageSize)
+		}
+		ml.messageSize = messageSize
+	case msgTread, msgTreaddir:
+		if len(header) < countOffset+countFieldLength ||
+			ml.messageSize <= responseHeaderSize {
+			return
+		}
+		var (
+			field    = header[countOffset:]
+			count    = binary.LittleEndian.Uint32(field)
+			maxCount = ml.messageSize - responseHeaderSize
+		)
+		if count > maxCount {
+			binary.LittleEndian.PutUint32(field, maxCount)
+		}
+	}
+}
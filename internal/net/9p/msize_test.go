@@ -0,0 +1,195 @@
+package p9_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/filesystem"
+	p9fs "github.com/djdv/go-filesystem-utils/internal/filesystem/9p"
+	p9net "github.com/djdv/go-filesystem-utils/internal/net/9p"
+	"github.com/djdv/p9/p9"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+type directoryAttacher struct{ directory p9.File }
+
+func (da directoryAttacher) Attach() (p9.File, error) {
+	_, file, err := da.directory.Walk(nil)
+	return file, err
+}
+
+func TestMessageSize(t *testing.T) {
+	t.Parallel()
+	const limit = p9net.MinimumMessageSize
+	t.Run("negotiated", func(t *testing.T) {
+		t.Parallel()
+		testMessageSizeNegotiated(t, limit)
+	})
+	t.Run("readdir", func(t *testing.T) {
+		t.Parallel()
+		testMessageSizeReaddir(t, limit)
+	})
+}
+
+func serveLimited(t *testing.T, attacher p9.Attacher, limit uint32) (trackingListener, func()) {
+	t.Helper()
+	var (
+		server = p9net.NewServer(attacher,
+			p9net.WithMessageSize(limit),
+		)
+		listener  = newTrackingListener(t)
+		serveErrs = make(chan error, 1)
+	)
+	go func() { serveErrs <- server.Serve(listener) }()
+	return listener, func() {
+		if err := server.Close(); err != nil {
+			t.Error(err)
+		}
+		<-serveErrs
+	}
+}
+
+func testMessageSizeNegotiated(t *testing.T, limit uint32) {
+	listener, stop := serveLimited(t, nopAttacher{}, limit)
+	defer stop()
+	conn, err := manet.Dial(listener.Multiaddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	serverConn := <-listener.conns
+	// NOTE: The request's size is larger than the limit.
+	if _, err := conn.Write(makeVersionMessage(p9.HighestVersionString())); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readVersionMessageSize(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != limit {
+		t.Errorf("negotiated message size mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, limit,
+		)
+	}
+	const (
+		timeout  = 5 * time.Second
+		interval = time.Millisecond
+	)
+	deadline := time.Now().Add(timeout)
+	for serverConn.MessageSize() != limit &&
+		time.Now().Before(deadline) {
+		time.Sleep(interval)
+	}
+	if got := serverConn.MessageSize(); got != limit {
+		t.Errorf("recorded message size mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, limit,
+		)
+	}
+}
+
+func testMessageSizeReaddir(t *testing.T, limit uint32) {
+	const (
+		entryCount = 512
+		// Larger than a response may be.
+		requestCount = 1 << 20
+	)
+	_, directory, err := p9fs.NewDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]bool, entryCount)
+	for i := 0; i < entryCount; i++ {
+		name := fmt.Sprintf("%s-%04d", strings.Repeat("entry", 8), i)
+		if _, err := directory.Mkdir(name, 0o755, p9.NoUID, p9.NoGID); err != nil {
+			t.Fatal(err)
+		}
+		want[name] = true
+	}
+	// Directories also list their self and parent entries.
+	want[filesystem.SelfName] = true
+	want[filesystem.ParentName] = true
+	listener, stop := serveLimited(t, directoryAttacher{directory: directory}, limit)
+	defer stop()
+	conn, err := manet.Dial(listener.Multiaddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-listener.conns
+	// NOTE: The client rejects messages
+	// larger than its own message size.
+	client, err := p9.NewClient(conn, p9.WithMessageSize(limit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	root, err := client.Attach("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+	if _, _, err := root.Open(p9.ReadOnly); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		offset uint64
+		reads  int
+		got    = make(map[string]bool, entryCount)
+	)
+	for {
+		entries, err := root.Readdir(offset, requestCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+		reads++
+		for _, entry := range entries {
+			if got[entry.Name] {
+				t.Fatalf(`entry "%s" returned multiple times`, entry.Name)
+			}
+			got[entry.Name] = true
+			offset = entry.Offset
+		}
+	}
+	if reads < 2 {
+		t.Errorf("expected directory to be read in multiple responses, got %d", reads)
+	}
+	for name := range want {
+		if !got[name] {
+			t.Errorf(`entry "%s" was not returned`, name)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("entry count mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			len(got), len(want),
+		)
+	}
+}
+
+func readVersionMessageSize(r io.Reader) (uint32, error) {
+	const (
+		sizeLength  = 4
+		msizeOffset = 1 + 2
+	)
+	sizeBuffer := make([]byte, sizeLength)
+	if _, err := io.ReadFull(r, sizeBuffer); err != nil {
+		return 0, err
+	}
+	size := binary.LittleEndian.Uint32(sizeBuffer)
+	message := make([]byte, size-sizeLength)
+	if _, err := io.ReadFull(r, message); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(message[msizeOffset:]), nil
+}
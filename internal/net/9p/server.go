@@ -5,7 +5,6 @@ import (
 	"context"
 	"errors"
 	"io"
-	"math/rand"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -27,12 +26,20 @@ type (
 	// Server adds Close and Shutdown methods
 	// similar to [net/http.Server], for a [p9.Server].
 	Server struct {
-		log          ulog.Logger
-		server       *p9.Server
-		connections  connectionMap
-		listeners    listenerMap
+		log         ulog.Logger
+		server      *p9.Server
+		connections connectionMap
+		listeners   listenerMap
+		// slots limits the amount of connections
+		// accepted by [Server.Serve] (if set).
+		slots chan struct{}
+		// closing is closed when the
+		// server begins to shut down.
+		closing      chan struct{}
 		listenersWg  sync.WaitGroup
 		idleDuration time.Duration
+		connDeadline time.Duration
+		messageSize  uint32
 		mu           sync.Mutex
 		shutdown     atomic.Bool
 	}
@@ -40,6 +47,14 @@ type (
 	TrackedIO interface {
 		LastRead() time.Time
 		LastWrite() time.Time
+		// Version returns the 9P protocol version
+		// negotiated on the connection (if any).
+		Version() string
+		SetVersion(string)
+		// MessageSize returns the maximum message
+		// size negotiated on the connection (if any).
+		MessageSize() uint32
+		SetMessageSize(uint32)
 		io.ReadWriteCloser
 	}
 	trackedReads interface {
@@ -74,6 +89,8 @@ type (
 	// of a network connection.
 	TrackedConn struct {
 		read, wrote *atomic.Pointer[time.Time]
+		version     *atomic.Pointer[string]
+		messageSize *atomic.Uint32
 		manetConn
 	}
 	trackedReader struct {
@@ -97,10 +114,12 @@ type (
 	// on both its arguments).
 	onceCloseIO struct {
 		io.ReadWriteCloser
+		deadlineSetter
 		*onceCloser
 	}
 	onceCloseTrackedIO struct {
 		TrackedIO
+		deadlineSetter
 		*onceCloser
 	}
 	onceCloser struct {
@@ -122,6 +141,7 @@ func NewServer(attacher p9.Attacher, options ...ServerOpt) *Server {
 		srv         = Server{
 			log:          ulog.Null,
 			idleDuration: defaultIdleDuration,
+			closing:      make(chan struct{}),
 		}
 	)
 	for _, applyAndUnwrap := range options {
@@ -152,18 +172,73 @@ func WithIdleDuration(d time.Duration) ServerOpt {
 	}
 }
 
+// WithConnDeadline sets the duration a connection
+// may go without activity before it is dropped.
+// Unlike [WithIdleDuration], this applies while the
+// server is running, and is intended to detect peers
+// which are unresponsive (E.g. half-open sockets).
+// The deadline is extended on every read or write,
+// and while requests are still being processed.
+// If <= 0 (the default), no deadlines are set.
+func WithConnDeadline(d time.Duration) ServerOpt {
+	return func(s *Server) p9.ServerOpt {
+		s.connDeadline = d
+		return nil
+	}
+}
+
+// WithMessageSize limits the maximum message size (`msize`)
+// negotiated with clients, and constrains the `count` of
+// read and readdir requests so that responses fit within
+// the negotiated size. Sizes below [MinimumMessageSize]
+// are raised to it.
+// If 0 (the default), the size requested by the client
+// is used (up to the 9P library's maximum).
+func WithMessageSize(size uint32) ServerOpt {
+	return func(s *Server) p9.ServerOpt {
+		if size != 0 && size < MinimumMessageSize {
+			size = MinimumMessageSize
+		}
+		s.messageSize = size
+		return nil
+	}
+}
+
+// WithMaxConnections limits the amount of connections
+// [Server.Serve] will handle at once. When the limit is
+// reached, no more connections are accepted until
+// an existing connection is closed.
+// If <= 0 (the default), connections are not limited.
+func WithMaxConnections(n int) ServerOpt {
+	return func(s *Server) p9.ServerOpt {
+		if n > 0 {
+			s.slots = make(chan struct{}, n)
+		} else {
+			s.slots = nil
+		}
+		return nil
+	}
+}
+
 // Handle handles a single connection.
 // If [TrackedIO] is passed in for either or both
 // of the transmit and receive parameters, they will be
 // asserted and re-used. This allows the [Server] and caller
 // to share metrics without requiring extra overhead.
 func (srv *Server) Handle(t io.ReadCloser, r io.WriteCloser) error {
+	trackedT, trackedR := makeTrackedIO(t, r)
+	if duration := srv.connDeadline; duration > 0 {
+		trackedT, trackedR = withDeadlines(t, r, trackedT, trackedR, duration)
+	}
 	var (
-		trackedT, trackedR = makeTrackedIO(t, r)
-		connection         = &trackedIOpair{
+		requests   = limitMessages(trackedT, srv.messageSize)
+		connection = &trackedIOpair{
 			trackedReads:  trackedT,
 			trackedWrites: trackedR,
 		}
+		negotiation = newNegotiation(srv.log,
+			getVersionSetter(t, r), connection,
+		)
 		connections             = srv.getConnections()
 		closedRead, closedWrite bool
 		deleteFn                = func() {
@@ -172,7 +247,10 @@ func (srv *Server) Handle(t io.ReadCloser, r io.WriteCloser) error {
 			delete(connections, connection)
 		}
 		cleanupT = trackedReadCloser{
-			trackedReads: trackedT,
+			trackedReads: negotiatingReader{
+				negotiation:  negotiation,
+				trackedReads: requests,
+			},
 			postCloseFn: func() {
 				closedRead = true
 				if closedWrite {
@@ -181,7 +259,10 @@ func (srv *Server) Handle(t io.ReadCloser, r io.WriteCloser) error {
 			},
 		}
 		cleanupR = trackedWriteCloser{
-			trackedWrites: trackedR,
+			trackedWrites: negotiatingWriter{
+				negotiation:   negotiation,
+				trackedWrites: trackedR,
+			},
 			postCloseFn: func() {
 				closedWrite = true
 				if closedRead {
@@ -235,6 +316,16 @@ func makeTrackedIO(rc io.ReadCloser, wc io.WriteCloser) (trackedReads, trackedWr
 	return trackedR, trackedW
 }
 
+func getVersionSetter(rc io.ReadCloser, wc io.WriteCloser) versionSetter {
+	if setter, ok := rc.(versionSetter); ok {
+		return setter
+	}
+	if setter, ok := wc.(versionSetter); ok {
+		return setter
+	}
+	return nil
+}
+
 func (srv *Server) getConnections() connectionMap {
 	if connections := srv.connections; connections != nil {
 		return connections
@@ -273,27 +364,65 @@ func (srv *Server) Serve(listener manet.Listener) error {
 		}
 	)
 	for {
+		if err := srv.acquireSlot(); err != nil {
+			return errors.Join(err, listener.Close())
+		}
 		connection, err := listener.Accept()
 		if err != nil {
+			srv.releaseSlot()
 			if srv.shuttingDown() {
 				return errors.Join(ErrServerClosed, listener.Close())
 			}
 			return errors.Join(err, listener.Close())
 		}
-		go handleConn(splitConn(connection))
+		go func() {
+			defer srv.releaseSlot()
+			handleConn(splitConn(connection))
+		}()
 	}
 }
 
+// acquireSlot blocks until a connection may be
+// accepted, or the server begins to shut down.
+func (srv *Server) acquireSlot() error {
+	slots := srv.slots
+	if slots == nil {
+		return nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-srv.closing:
+		return ErrServerClosed
+	}
+}
+
+func (srv *Server) releaseSlot() {
+	if slots := srv.slots; slots != nil {
+		<-slots
+	}
+}
+
+// ActiveConnections returns the amount of
+// connections currently handled by the server.
+func (srv *Server) ActiveConnections() int {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return len(srv.connections)
+}
+
 func splitConn(connection manet.Conn) (io.ReadCloser, io.WriteCloser) {
 	if tracked, ok := connection.(TrackedIO); ok {
 		closeConnOnce := onceCloseTrackedIO{
-			TrackedIO:  tracked,
-			onceCloser: new(onceCloser),
+			TrackedIO:      tracked,
+			deadlineSetter: connection,
+			onceCloser:     new(onceCloser),
 		}
 		return closeConnOnce, closeConnOnce
 	}
 	closeConnOnce := onceCloseIO{
 		ReadWriteCloser: connection,
+		deadlineSetter:  connection,
 		onceCloser:      new(onceCloser),
 	}
 	return closeConnOnce, closeConnOnce
@@ -303,6 +432,14 @@ func (srv *Server) shuttingDown() bool {
 	return srv.shutdown.Load()
 }
 
+// beginShutdown marks the server as shutting down,
+// and wakes anything waiting on the server.
+func (srv *Server) beginShutdown() {
+	if srv.shutdown.CompareAndSwap(false, true) {
+		close(srv.closing)
+	}
+}
+
 func (srv *Server) trackListener(listener manet.Listener) (*manet.Listener, error) {
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
@@ -333,7 +470,7 @@ func (srv *Server) dropListener(listener *manet.Listener) {
 // Listeners and connections associated with the server
 // become closed by this call.
 func (srv *Server) Close() error {
-	srv.shutdown.Store(true)
+	srv.beginShutdown()
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
 	err := srv.closeListenersLocked()
@@ -361,7 +498,7 @@ func (srv *Server) closeListenersLocked() error {
 // and connections become closed when they are considered idle.
 // If the context is done, connections become closed immediately.
 func (srv *Server) Shutdown(ctx context.Context) error {
-	srv.shutdown.Store(true)
+	srv.beginShutdown()
 	srv.mu.Lock()
 	var errs []error
 	if err := srv.closeListenersLocked(); err != nil {
@@ -372,7 +509,7 @@ func (srv *Server) Shutdown(ctx context.Context) error {
 	srv.mu.Unlock()
 	srv.listenersWg.Wait()
 	var (
-		nextPollInterval = makeJitterFunc(time.Millisecond)
+		nextPollInterval = generic.MakeJitterFunc(time.Millisecond)
 		timer            = time.NewTimer(nextPollInterval())
 	)
 	defer timer.Stop()
@@ -399,22 +536,6 @@ func (srv *Server) Shutdown(ctx context.Context) error {
 	}
 }
 
-func makeJitterFunc(initial time.Duration) func() time.Duration {
-	// Adapted from an inlined [net/http] closure.
-	const pollIntervalMax = 500 * time.Millisecond
-	return func() time.Duration {
-		// Add 10% jitter.
-		interval := initial +
-			time.Duration(rand.Intn(int(initial/10)))
-		// Double and clamp for next time.
-		initial *= 2
-		if initial > pollIntervalMax {
-			initial = pollIntervalMax
-		}
-		return interval
-	}
-}
-
 func (srv *Server) closeIdleConns() (allIdle bool, err error) {
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
@@ -456,10 +577,14 @@ func NewTrackedConn(conn manet.Conn) TrackedConn {
 		now         = time.Now()
 		nowAddr     = &now
 		read, wrote atomic.Pointer[time.Time]
+		version     atomic.Pointer[string]
+		messageSize atomic.Uint32
 		tracked     = TrackedConn{
-			read:      &read,
-			wrote:     &wrote,
-			manetConn: conn,
+			read:        &read,
+			wrote:       &wrote,
+			version:     &version,
+			messageSize: &messageSize,
+			manetConn:   conn,
 		}
 	)
 	read.Store(nowAddr)
@@ -489,6 +614,34 @@ func (tc TrackedConn) LastWrite() time.Time {
 	return *tc.wrote.Load()
 }
 
+// Version returns the protocol version negotiated
+// by the server, or an empty string if negotiation
+// has not completed.
+func (tc TrackedConn) Version() string {
+	if version := tc.version.Load(); version != nil {
+		return *version
+	}
+	return ""
+}
+
+// SetVersion records the protocol version
+// negotiated for this connection.
+func (tc TrackedConn) SetVersion(version string) {
+	tc.version.Store(&version)
+}
+
+// MessageSize returns the maximum message size negotiated
+// by the server, or 0 if negotiation has not completed.
+func (tc TrackedConn) MessageSize() uint32 {
+	return tc.messageSize.Load()
+}
+
+// SetMessageSize records the maximum message
+// size negotiated for this connection.
+func (tc TrackedConn) SetMessageSize(size uint32) {
+	tc.messageSize.Store(size)
+}
+
 // Close closes the connection.
 func (tc TrackedConn) Close() error {
 	return tc.manetConn.Close()
@@ -545,7 +698,7 @@ func trackWrite(w io.Writer, stamp *atomic.Pointer[time.Time], b []byte) (int, e
 	return wrote, nil
 }
 
-func lastActive(tio TrackedIO) time.Time {
+func lastActive(tio *trackedIOpair) time.Time {
 	var (
 		read  = tio.LastRead()
 		write = tio.LastWrite()
@@ -0,0 +1,210 @@
+package p9
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/djdv/p9/p9"
+	"github.com/u-root/uio/ulog"
+)
+
+type (
+	// versionSetter is implemented by connections
+	// that can record the negotiated protocol version
+	// and message size.
+	versionSetter interface {
+		SetVersion(string)
+		SetMessageSize(uint32)
+	}
+	// versionSniffer observes the beginning of a 9P
+	// byte stream, and extracts the version string
+	// from the first message (if it's a version message).
+	versionSniffer struct {
+		buffer      []byte
+		onVersion   func(version string, messageSize uint32)
+		messageType uint8
+		done        bool
+	}
+	// negotiation tracks both sides of a version exchange.
+	negotiation struct {
+		log       ulog.Logger
+		setter    versionSetter
+		closer    io.Closer
+		requested atomic.Pointer[string]
+		// rejection is set if the server
+		// refused the client's version.
+		rejection atomic.Pointer[error]
+		// negotiated is set once the server's reply
+		// was observed; after which neither side
+		// of the stream needs to be inspected.
+		negotiated atomic.Bool
+		sync.Mutex
+		request, response versionSniffer
+	}
+	negotiatingReader struct {
+		*negotiation
+		trackedReads
+	}
+	negotiatingWriter struct {
+		*negotiation
+		trackedWrites
+	}
+)
+
+// ErrVersionRejected is returned by [Server.Handle]
+// if the client requested a protocol version
+// which the server does not support.
+const ErrVersionRejected generic.ConstError = "p9: client protocol version rejected"
+
+const (
+	// See: 9P2000 `version(5)`.
+	msgTversion = 100
+	msgRversion = 101
+
+	sizeFieldLength    = 4
+	typeFieldLength    = 1
+	tagFieldLength     = 2
+	msizeFieldLength   = 4
+	stringPrefixLength = 2
+	versionHeaderSize  = sizeFieldLength + typeFieldLength +
+		tagFieldLength + msizeFieldLength + stringPrefixLength
+	typeOffset  = sizeFieldLength
+	msizeOffset = typeOffset + typeFieldLength + tagFieldLength
+
+	// versionUnknown is the reply value a server must
+	// send when it does not understand the requested version.
+	versionUnknown = "unknown"
+	// dialectLinux is the base version the server implements.
+	dialectLinux = "9P2000.L"
+)
+
+// newNegotiation observes the version exchange.
+// If the client's version is rejected, `closer`
+// is closed after the server's reply is sent.
+func newNegotiation(log ulog.Logger, setter versionSetter, closer io.Closer) *negotiation {
+	n := &negotiation{
+		log:    log,
+		setter: setter,
+		closer: closer,
+	}
+	n.request = versionSniffer{
+		messageType: msgTversion,
+		onVersion:   n.requestedVersion,
+	}
+	n.response = versionSniffer{
+		messageType: msgRversion,
+		onVersion:   n.negotiatedVersion,
+	}
+	return n
+}
+
+func (n *negotiation) requestedVersion(version string, _ uint32) {
+	n.requested.Store(&version)
+}
+
+func (n *negotiation) negotiatedVersion(version string, messageSize uint32) {
+	var requested string
+	if ptr := n.requested.Load(); ptr != nil {
+		requested = *ptr
+	}
+	if version == versionUnknown {
+		err := fmt.Errorf(
+			"%w: %q (server only supports %s dialects, up to %s)",
+			ErrVersionRejected, requested,
+			dialectLinux, p9.HighestVersionString(),
+		)
+		n.rejection.Store(&err)
+		return
+	}
+	if setter := n.setter; setter != nil {
+		setter.SetVersion(version)
+		setter.SetMessageSize(messageSize)
+	}
+}
+
+func (nr negotiatingReader) Read(b []byte) (int, error) {
+	read, err := nr.trackedReads.Read(b)
+	if read > 0 && !nr.negotiated.Load() {
+		nr.Lock()
+		nr.request.observe(b[:read])
+		nr.Unlock()
+	}
+	if err != nil {
+		// The connection was closed
+		// because of the rejection;
+		// report it instead.
+		if rejection := nr.rejection.Load(); rejection != nil {
+			err = *rejection
+		}
+	}
+	return read, err
+}
+
+func (nw negotiatingWriter) Write(b []byte) (int, error) {
+	wrote, err := nw.trackedWrites.Write(b)
+	if wrote > 0 && !nw.negotiated.Load() {
+		nw.Lock()
+		nw.response.observe(b[:wrote])
+		done := nw.response.done
+		nw.Unlock()
+		if done {
+			nw.negotiated.Store(true)
+			nw.closeIfRejected()
+		}
+	}
+	return wrote, err
+}
+
+// closeIfRejected closes the connection (once the
+// server's reply was sent) if the version was rejected;
+// so the client observes the end of the session.
+func (n *negotiation) closeIfRejected() {
+	if n.rejection.Load() == nil {
+		return
+	}
+	if err := n.closer.Close(); err != nil {
+		n.log.Printf("closing rejected connection: %s\n", err)
+	}
+}
+
+// observe accumulates bytes until the version
+// message is complete, then stops observing.
+// Only the first message of a stream is inspected;
+// later version messages (session resets) are ignored.
+func (vs *versionSniffer) observe(b []byte) {
+	if vs.done {
+		return
+	}
+	vs.buffer = append(vs.buffer, b...)
+	buffer := vs.buffer
+	if len(buffer) < versionHeaderSize {
+		return
+	}
+	if buffer[typeOffset] != vs.messageType {
+		vs.finish()
+		return
+	}
+	const stringOffset = versionHeaderSize - stringPrefixLength
+	var (
+		stringLength = int(binary.LittleEndian.Uint16(buffer[stringOffset:]))
+		end          = versionHeaderSize + stringLength
+	)
+	if len(buffer) < end {
+		return
+	}
+	var (
+		version     = string(buffer[versionHeaderSize:end])
+		messageSize = binary.LittleEndian.Uint32(buffer[msizeOffset:])
+	)
+	vs.finish()
+	vs.onVersion(version, messageSize)
+}
+
+func (vs *versionSniffer) finish() {
+	vs.done = true
+	vs.buffer = nil
+}
@@ -0,0 +1,238 @@
+package p9_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	p9net "github.com/djdv/go-filesystem-utils/internal/net/9p"
+	"github.com/djdv/p9/p9"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+type (
+	nopAttacher      struct{}
+	trackingListener struct {
+		manet.Listener
+		conns chan p9net.TrackedConn
+	}
+)
+
+func (nopAttacher) Attach() (p9.File, error) { return nil, io.EOF }
+
+func (tl trackingListener) Accept() (manet.Conn, error) {
+	conn, err := tl.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tracked := p9net.NewTrackedConn(conn)
+	tl.conns <- tracked
+	return tracked, nil
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name, requested, negotiated, recorded string
+	}{
+		{
+			name:       "9P2000.L",
+			requested:  "9P2000.L",
+			negotiated: "9P2000.L",
+			recorded:   "9P2000.L",
+		},
+		{
+			name:       "highest",
+			requested:  p9.HighestVersionString(),
+			negotiated: p9.HighestVersionString(),
+			recorded:   p9.HighestVersionString(),
+		},
+		{
+			name:       "9P2000.u",
+			requested:  "9P2000.u",
+			negotiated: "unknown",
+		},
+		{
+			name:       "9P2000",
+			requested:  "9P2000",
+			negotiated: "unknown",
+		},
+		{
+			name:       "invalid",
+			requested:  "not a version",
+			negotiated: "unknown",
+		},
+	} {
+		var (
+			requested  = test.requested
+			negotiated = test.negotiated
+			recorded   = test.recorded
+		)
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			versionNegotiate(t, requested, negotiated, recorded)
+		})
+	}
+}
+
+func versionNegotiate(t *testing.T, requested, negotiated, recorded string) {
+	var (
+		server      = p9net.NewServer(nopAttacher{})
+		listener    = newTrackingListener(t)
+		serveErrs   = make(chan error, 1)
+		serverMaddr = listener.Multiaddr()
+	)
+	go func() { serveErrs <- server.Serve(listener) }()
+	defer func() {
+		if err := server.Close(); err != nil {
+			t.Error(err)
+		}
+		<-serveErrs
+	}()
+	conn, err := manet.Dial(serverMaddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	serverConn := <-listener.conns
+	if _, err := conn.Write(makeVersionMessage(requested)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readVersionMessage(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != negotiated {
+		t.Errorf("negotiated version mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, negotiated,
+		)
+	}
+	// NOTE: The server records the version
+	// after its reply is written, so we poll.
+	const (
+		timeout  = 5 * time.Second
+		interval = time.Millisecond
+	)
+	deadline := time.Now().Add(timeout)
+	for serverConn.Version() != recorded &&
+		time.Now().Before(deadline) {
+		time.Sleep(interval)
+	}
+	if got := serverConn.Version(); got != recorded {
+		t.Errorf("recorded version mismatch"+
+			"\ngot: %q"+
+			"\nwant: %q",
+			got, recorded,
+		)
+	}
+	if negotiated != "unknown" {
+		return
+	}
+	// Rejected clients should see the session end.
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("rejected connection was not closed"+
+			"\ngot: %v"+
+			"\nwant: %v",
+			err, io.EOF,
+		)
+	}
+}
+
+func TestVersionRejected(t *testing.T) {
+	t.Parallel()
+	var (
+		server                   = p9net.NewServer(nopAttacher{})
+		requests, clientRequests = io.Pipe()
+		clientReplies, replies   = io.Pipe()
+		handled                  = make(chan error, 1)
+	)
+	go func() { handled <- server.Handle(requests, replies) }()
+	if _, err := clientRequests.Write(makeVersionMessage("9P2000")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readVersionMessage(clientReplies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "unknown"; got != want {
+		t.Errorf("negotiated version mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, want,
+		)
+	}
+	const timeout = 5 * time.Second
+	select {
+	case err := <-handled:
+		if !errors.Is(err, p9net.ErrVersionRejected) {
+			t.Errorf("handler error mismatch"+
+				"\ngot: %v"+
+				"\nwant: %v",
+				err, p9net.ErrVersionRejected,
+			)
+		}
+	case <-time.After(timeout):
+		t.Fatal("handler did not return after rejecting the client")
+	}
+}
+
+func newTrackingListener(t *testing.T) trackingListener {
+	t.Helper()
+	listener, err := manet.Listen(multiaddr.StringCast("/ip4/127.0.0.1/tcp/0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trackingListener{
+		Listener: listener,
+		conns:    make(chan p9net.TrackedConn, 1),
+	}
+}
+
+func makeVersionMessage(version string) []byte {
+	const (
+		tversion = 100
+		notag    = ^uint16(0)
+		msize    = 1 << 16
+		header   = 4 + 1 + 2 + 4 + 2
+	)
+	var (
+		size    = header + len(version)
+		message = make([]byte, 0, size)
+	)
+	message = binary.LittleEndian.AppendUint32(message, uint32(size))
+	message = append(message, tversion)
+	message = binary.LittleEndian.AppendUint16(message, notag)
+	message = binary.LittleEndian.AppendUint32(message, msize)
+	message = binary.LittleEndian.AppendUint16(message, uint16(len(version)))
+	return append(message, version...)
+}
+
+func readVersionMessage(r io.Reader) (string, error) {
+	const (
+		sizeLength   = 4
+		stringOffset = 1 + 2 + 4
+		prefixLength = 2
+	)
+	sizeBuffer := make([]byte, sizeLength)
+	if _, err := io.ReadFull(r, sizeBuffer); err != nil {
+		return "", err
+	}
+	size := binary.LittleEndian.Uint32(sizeBuffer)
+	message := make([]byte, size-sizeLength)
+	if _, err := io.ReadFull(r, message); err != nil {
+		return "", err
+	}
+	var (
+		stringLength = binary.LittleEndian.Uint16(message[stringOffset:])
+		start        = stringOffset + prefixLength
+	)
+	return string(message[start : start+int(stringLength)]), nil
+}
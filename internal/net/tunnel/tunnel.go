@@ -0,0 +1,58 @@
+// Package tunnel carries 9P connections
+// over HTTP and WebSocket [multiaddr] transports.
+//
+// A multiaddr ending with `/http` is served by an HTTP server.
+// Clients send a `GET /` request with the headers
+// `Connection: Upgrade` and `Upgrade: 9P2000.L`.
+// The server replies with `101 Switching Protocols`,
+// after which the connection carries the 9P stream as-is.
+// Other requests are answered with `426 Upgrade Required`.
+//
+// A multiaddr ending with `/ws` is served by a WebSocket server at `/`.
+// 9P messages are carried in binary frames, in both directions.
+// Requests which contain an `Origin` header (E.g. from browsers)
+// are only accepted if the origin's host matches the request's host.
+//
+// All other multiaddrs are handled by [manet].
+package tunnel
+
+import (
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+// Listen is like [manet.Listen], but also accepts
+// multiaddrs ending with an HTTP or WebSocket component.
+func Listen(maddr multiaddr.Multiaddr) (manet.Listener, error) {
+	base, transport := splitTransport(maddr)
+	if transport == nil {
+		return manet.Listen(maddr)
+	}
+	return listen(base, transport)
+}
+
+// Dial is like [manet.Dial], but also accepts
+// multiaddrs ending with an HTTP or WebSocket component.
+func Dial(maddr multiaddr.Multiaddr) (manet.Conn, error) {
+	base, transport := splitTransport(maddr)
+	if transport == nil {
+		return manet.Dial(maddr)
+	}
+	return dial(base, transport)
+}
+
+// splitTransport returns the HTTP or WebSocket
+// component of the multiaddr (if it ends with one)
+// along with the multiaddr that precedes it.
+func splitTransport(maddr multiaddr.Multiaddr) (multiaddr.Multiaddr, *multiaddr.Component) {
+	base, last := multiaddr.SplitLast(maddr)
+	if last == nil || base == nil {
+		return maddr, nil
+	}
+	switch last.Protocol().Code {
+	case multiaddr.P_HTTP, multiaddr.P_WS:
+		return base, last
+	default:
+		return maddr, nil
+	}
+}
@@ -0,0 +1,349 @@
+//go:build !nohttp
+
+package tunnel
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/djdv/go-filesystem-utils/internal/generic"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"golang.org/x/net/websocket"
+)
+
+type (
+	// listener serves HTTP on its base listener,
+	// and emits connections as they're upgraded.
+	listener struct {
+		base      manet.Listener
+		maddr     multiaddr.Multiaddr
+		conns     chan manet.Conn
+		closing   chan struct{}
+		closeOnce sync.Once
+		closeErr  error
+	}
+	// conn associates multiaddrs with a
+	// connection that's been upgraded.
+	conn struct {
+		net.Conn
+		local, remote multiaddr.Multiaddr
+	}
+	// bufferedConn reads through the reader that was
+	// used during the HTTP exchange, since it may
+	// contain data which followed the headers.
+	bufferedConn struct {
+		net.Conn
+		reader *bufio.Reader
+	}
+	// closeNotifier signals when the connection is closed.
+	closeNotifier struct {
+		net.Conn
+		closed    chan struct{}
+		closeOnce sync.Once
+	}
+)
+
+const (
+	// upgradeProtocol is the value of the
+	// `Upgrade` header in HTTP requests and responses.
+	upgradeProtocol = "9P2000.L"
+	// headerTimeout limits how long a client
+	// may take to send its request headers.
+	headerTimeout = 30 * time.Second
+	// localHost is used as the HTTP host
+	// for sockets which don't have one.
+	localHost = "localhost"
+
+	errUpgradeRequired = generic.ConstError("upgrade required")
+)
+
+func listen(base multiaddr.Multiaddr, transport *multiaddr.Component) (manet.Listener, error) {
+	baseListener, err := manet.Listen(base)
+	if err != nil {
+		return nil, err
+	}
+	l := &listener{
+		base:    baseListener,
+		maddr:   baseListener.Multiaddr().Encapsulate(transport),
+		conns:   make(chan manet.Conn),
+		closing: make(chan struct{}),
+	}
+	var handler http.Handler
+	if transport.Protocol().Code == multiaddr.P_WS {
+		handler = websocket.Server{
+			Handshake: checkOrigin,
+			Handler:   l.serveWebSocket,
+		}
+	} else {
+		handler = http.HandlerFunc(l.serveUpgrade)
+	}
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: headerTimeout,
+	}
+	// Connections are handed off after the exchange,
+	// so there's no reason to keep them for reuse.
+	server.SetKeepAlivesEnabled(false)
+	go func() {
+		// NOTE: Serve will only return when the
+		// base listener is closed (or fails).
+		// Connections which were already accepted
+		// remain open, like any other listener.
+		serveErr := server.Serve(manet.NetListener(baseListener))
+		if errors.Is(serveErr, http.ErrServerClosed) {
+			serveErr = nil
+		}
+		l.close(serveErr)
+	}()
+	return l, nil
+}
+
+func (l *listener) Accept() (manet.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closing:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *listener) Close() error {
+	l.close(nil)
+	return l.closeErr
+}
+
+func (l *listener) close(err error) {
+	l.closeOnce.Do(func() {
+		close(l.closing)
+		l.closeErr = l.base.Close()
+		if errors.Is(l.closeErr, net.ErrClosed) {
+			l.closeErr = err
+		}
+	})
+}
+
+func (l *listener) Multiaddr() multiaddr.Multiaddr { return l.maddr }
+func (l *listener) Addr() net.Addr                 { return l.base.Addr() }
+
+// emit hands the connection to [listener.Accept],
+// or closes it if the listener is closed first.
+func (l *listener) emit(netConn net.Conn, remote multiaddr.Multiaddr) bool {
+	upgraded := &conn{
+		Conn:   netConn,
+		local:  l.maddr,
+		remote: remote,
+	}
+	select {
+	case l.conns <- upgraded:
+		return true
+	case <-l.closing:
+		netConn.Close()
+		return false
+	}
+}
+
+// remoteMaddr returns the multiaddr of the client.
+// If the address can not be represented
+// (E.g. unnamed Unix sockets), the listener's
+// multiaddr is used instead.
+func (l *listener) remoteMaddr(address string) multiaddr.Multiaddr {
+	_, transport := multiaddr.SplitLast(l.maddr)
+	// NOTE: HTTP servers provide the remote address
+	// as a string, which (for TCP) is always an IP.
+	if tcpAddr, err := net.ResolveTCPAddr("tcp", address); err == nil {
+		if remote, err := manet.FromNetAddr(tcpAddr); err == nil {
+			return remote.Encapsulate(transport)
+		}
+	}
+	return l.maddr
+}
+
+func (l *listener) serveUpgrade(writer http.ResponseWriter, request *http.Request) {
+	if !isUpgradeRequest(request) {
+		header := writer.Header()
+		header.Set("Connection", "Upgrade")
+		header.Set("Upgrade", upgradeProtocol)
+		http.Error(writer, errUpgradeRequired.Error(), http.StatusUpgradeRequired)
+		return
+	}
+	remote := l.remoteMaddr(request.RemoteAddr)
+	hijacker, ok := writer.(http.Hijacker)
+	if !ok {
+		err := fmt.Errorf("%T does not support hijacking", writer)
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	netConn, buffer, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	const response = "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Upgrade: " + upgradeProtocol + "\r\n\r\n"
+	if _, err := buffer.WriteString(response); err != nil {
+		netConn.Close()
+		return
+	}
+	if err := buffer.Flush(); err != nil {
+		netConn.Close()
+		return
+	}
+	l.emit(&bufferedConn{
+		Conn:   netConn,
+		reader: buffer.Reader,
+	}, remote)
+}
+
+func isUpgradeRequest(request *http.Request) bool {
+	return request.Method == http.MethodGet &&
+		headerContains(request.Header, "Connection", "upgrade") &&
+		strings.EqualFold(request.Header.Get("Upgrade"), upgradeProtocol)
+}
+
+// headerContains reports whether the comma separated
+// values of the header contain the token.
+func headerContains(header http.Header, key, token string) bool {
+	for _, value := range header.Values(key) {
+		for _, element := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(element), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkOrigin permits requests without an `Origin` header
+// (non-browser clients), or with an origin on the same host.
+func checkOrigin(config *websocket.Config, request *http.Request) error {
+	origin, err := websocket.Origin(config, request)
+	if err != nil {
+		return err
+	}
+	if origin != nil && origin.Host != request.Host {
+		return fmt.Errorf(`origin "%s" is not permitted`, origin)
+	}
+	config.Origin = origin
+	return nil
+}
+
+func (l *listener) serveWebSocket(wsConn *websocket.Conn) {
+	remote := l.remoteMaddr(wsConn.Request().RemoteAddr)
+	wsConn.PayloadType = websocket.BinaryFrame
+	notifier := &closeNotifier{
+		Conn:   wsConn,
+		closed: make(chan struct{}),
+	}
+	if !l.emit(notifier, remote) {
+		return
+	}
+	// The connection is closed when the handler returns,
+	// so wait until the connection's owner closes it.
+	<-notifier.closed
+}
+
+func dial(base multiaddr.Multiaddr, transport *multiaddr.Component) (manet.Conn, error) {
+	baseConn, err := manet.Dial(base)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		host    = hostName(baseConn.RemoteAddr())
+		netConn net.Conn
+	)
+	if transport.Protocol().Code == multiaddr.P_WS {
+		netConn, err = dialWebSocket(baseConn, host)
+	} else {
+		netConn, err = dialUpgrade(baseConn, host)
+	}
+	if err != nil {
+		return nil, errors.Join(err, baseConn.Close())
+	}
+	return &conn{
+		Conn:   netConn,
+		local:  baseConn.LocalMultiaddr().Encapsulate(transport),
+		remote: baseConn.RemoteMultiaddr().Encapsulate(transport),
+	}, nil
+}
+
+func hostName(address net.Addr) string {
+	if _, ok := address.(*net.TCPAddr); ok {
+		return address.String()
+	}
+	return localHost
+}
+
+func dialUpgrade(netConn net.Conn, host string) (net.Conn, error) {
+	request := &http.Request{
+		Method: http.MethodGet,
+		URL: &url.URL{
+			Scheme: "http",
+			Host:   host,
+			Path:   "/",
+		},
+		Host: host,
+		Header: http.Header{
+			"Connection": {"Upgrade"},
+			"Upgrade":    {upgradeProtocol},
+		},
+	}
+	if err := request.Write(netConn); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(netConn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := response.Body.Close(); err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf(
+			"%w: server responded with %s",
+			errUpgradeRequired, response.Status,
+		)
+	}
+	return &bufferedConn{
+		Conn:   netConn,
+		reader: reader,
+	}, nil
+}
+
+func dialWebSocket(netConn net.Conn, host string) (net.Conn, error) {
+	config, err := websocket.NewConfig(
+		"ws://"+host+"/",
+		"http://"+host+"/",
+	)
+	if err != nil {
+		return nil, err
+	}
+	wsConn, err := websocket.NewClient(config, netConn)
+	if err != nil {
+		return nil, err
+	}
+	wsConn.PayloadType = websocket.BinaryFrame
+	return wsConn, nil
+}
+
+func (c *conn) LocalMultiaddr() multiaddr.Multiaddr  { return c.local }
+func (c *conn) RemoteMultiaddr() multiaddr.Multiaddr { return c.remote }
+
+func (bc *bufferedConn) Read(p []byte) (int, error) {
+	return bc.reader.Read(p)
+}
+
+func (cn *closeNotifier) Close() error {
+	err := cn.Conn.Close()
+	cn.closeOnce.Do(func() { close(cn.closed) })
+	return err
+}
@@ -0,0 +1,26 @@
+//go:build nohttp
+
+package tunnel
+
+import (
+	"fmt"
+
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+func listen(_ multiaddr.Multiaddr, transport *multiaddr.Component) (manet.Listener, error) {
+	return nil, unbuiltErr(transport)
+}
+
+func dial(_ multiaddr.Multiaddr, transport *multiaddr.Component) (manet.Conn, error) {
+	return nil, unbuiltErr(transport)
+}
+
+func unbuiltErr(transport *multiaddr.Component) error {
+	return fmt.Errorf(
+		"%s transport support was not built into this binary"+
+			" (rebuild without -tags=nohttp)",
+		transport.Protocol().Name,
+	)
+}
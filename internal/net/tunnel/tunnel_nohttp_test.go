@@ -0,0 +1,26 @@
+//go:build nohttp
+
+package tunnel_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/net/tunnel"
+	"github.com/multiformats/go-multiaddr"
+)
+
+func TestUnbuilt(t *testing.T) {
+	t.Parallel()
+	for _, transport := range []string{"http", "ws"} {
+		maddr := multiaddr.StringCast("/ip4/127.0.0.1/tcp/0/" + transport)
+		if _, err := tunnel.Listen(maddr); err == nil ||
+			!strings.Contains(err.Error(), "nohttp") {
+			t.Errorf("expected unbuilt error for %s, got: %v", maddr, err)
+		}
+		if _, err := tunnel.Dial(maddr); err == nil ||
+			!strings.Contains(err.Error(), "nohttp") {
+			t.Errorf("expected unbuilt error for %s, got: %v", maddr, err)
+		}
+	}
+}
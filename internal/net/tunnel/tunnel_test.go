@@ -0,0 +1,117 @@
+//go:build !nohttp
+
+package tunnel_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/djdv/go-filesystem-utils/internal/net/tunnel"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+func TestTunnel(t *testing.T) {
+	t.Parallel()
+	for _, transport := range []string{"http", "ws"} {
+		transport := transport
+		t.Run(transport, func(t *testing.T) {
+			t.Parallel()
+			testEcho(t, "/ip4/127.0.0.1/tcp/0/"+transport)
+		})
+	}
+	t.Run("tcp", func(t *testing.T) {
+		t.Parallel()
+		testEcho(t, "/ip4/127.0.0.1/tcp/0")
+	})
+	t.Run("upgrade required", func(t *testing.T) {
+		t.Parallel()
+		testUpgradeRequired(t)
+	})
+}
+
+func listen(t *testing.T, maddrString string) manet.Listener {
+	t.Helper()
+	listener, err := tunnel.Listen(multiaddr.StringCast(maddrString))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := listener.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return listener
+}
+
+func testEcho(t *testing.T, maddrString string) {
+	t.Helper()
+	var (
+		listener = listen(t, maddrString)
+		maddr    = listener.Multiaddr()
+		// Larger than a single read, so that
+		// streams spanning frames are exercised.
+		payload  = bytes.Repeat([]byte("9P"), 64*1024)
+		received = make(chan error, 1)
+	)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- err
+			return
+		}
+		_, err = io.Copy(conn, io.LimitReader(conn, int64(len(payload))))
+		if cErr := conn.Close(); err == nil {
+			err = cErr
+		}
+		received <- err
+	}()
+	conn, err := tunnel.Dial(maddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if got, want := conn.RemoteMultiaddr(), maddr; !got.Equal(want) {
+		t.Errorf("remote multiaddr mismatch"+
+			"\ngot: %s"+
+			"\nwant: %s",
+			got, want,
+		)
+	}
+	go func() {
+		if _, err := conn.Write(payload); err != nil {
+			t.Error(err)
+		}
+	}()
+	echoed := make([]byte, len(payload))
+	if _, err := io.ReadFull(conn, echoed); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-received; err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(echoed, payload) {
+		t.Error("echoed data does not match payload")
+	}
+}
+
+func testUpgradeRequired(t *testing.T) {
+	t.Helper()
+	listener := listen(t, "/ip4/127.0.0.1/tcp/0/http")
+	response, err := http.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := response.Body.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := response.StatusCode, http.StatusUpgradeRequired; got != want {
+		t.Errorf("status mismatch"+
+			"\ngot: %d"+
+			"\nwant: %d",
+			got, want,
+		)
+	}
+}